@@ -0,0 +1,319 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"github.com/devtron-labs/git-sensor/pkg"
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	pb "github.com/devtron-labs/git-sensor/protos/gitSensorApi"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+	"time"
+)
+
+// GrpcApiHandlerImpl serves gitSensorApi.GitSensorApiService, whose messages are generated from
+// protos/gitSensorApi/api.proto and carry all the fields of the manager outputs
+type GrpcApiHandlerImpl struct {
+	pb.UnimplementedGitSensorApiServiceServer
+	logger            *zap.SugaredLogger
+	repositoryManager pkg.RepoManager
+}
+
+func NewGrpcApiHandlerImpl(repositoryManager pkg.RepoManager, logger *zap.SugaredLogger) *GrpcApiHandlerImpl {
+	return &GrpcApiHandlerImpl{
+		repositoryManager: repositoryManager,
+		logger:            logger,
+	}
+}
+
+func (impl *GrpcApiHandlerImpl) FetchChanges(ctx context.Context, req *pb.FetchChangesRequest) (*pb.MaterialChanges, error) {
+	res, err := impl.repositoryManager.FetchChanges(int(req.PipelineMaterialId), req.From, req.To, int(req.Count), req.ShowAll)
+	if err != nil {
+		impl.logger.Errorw("error while fetching scm changes", "pipelineMaterialId", req.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	if res == nil {
+		return &pb.MaterialChanges{}, nil
+	}
+	return &pb.MaterialChanges{
+		Commits:         toCommitProtos(res.Commits),
+		LastFetchTime:   toTimestampProto(res.LastFetchTime),
+		IsRepoError:     res.IsRepoError,
+		RepoErrorMsg:    res.RepoErrorMsg,
+		IsBranchError:   res.IsBranchError,
+		BranchErrorMsg:  res.BranchErrorMsg,
+		IsBranchDeleted: res.IsBranchDeleted,
+	}, nil
+}
+
+func (impl *GrpcApiHandlerImpl) GetCommit(ctx context.Context, req *pb.CommitRequest) (*pb.Commit, error) {
+	commit, err := impl.repositoryManager.GetCommitMetadata(git.BuildGitContext(ctx), int(req.PipelineMaterialId), req.GitHash)
+	if err != nil {
+		impl.logger.Errorw("error while fetching commit metadata", "pipelineMaterialId", req.PipelineMaterialId, "gitHash", req.GitHash, "err", err)
+		return nil, err
+	}
+	if commit == nil {
+		return &pb.Commit{}, nil
+	}
+	return toCommitProto(commit), nil
+}
+
+func (impl *GrpcApiHandlerImpl) GetDiff(ctx context.Context, req *pb.DiffRequest) (*pb.CommitRangeDiff, error) {
+	request := &git.CommitDiffRequest{
+		PipelineMaterialId: int(req.PipelineMaterialId),
+		FromCommit:         req.FromCommit,
+		ToCommit:           req.ToCommit,
+		Paths:              req.Paths,
+	}
+	if req.ContextLines != nil {
+		contextLines := int(req.GetContextLines())
+		request.ContextLines = &contextLines
+	}
+	diff, err := impl.repositoryManager.GetDiffBetweenCommits(git.BuildGitContext(ctx), request)
+	if err != nil {
+		impl.logger.Errorw("error while fetching diff", "request", request, "err", err)
+		return nil, err
+	}
+	return toCommitRangeDiffProto(diff), nil
+}
+
+func (impl *GrpcApiHandlerImpl) GetMaterialStatus(ctx context.Context, req *pb.MaterialStatusRequest) (*pb.MaterialStatus, error) {
+	diagnostics, err := impl.repositoryManager.GetMaterialDiagnostics(git.BuildGitContext(ctx), int(req.GitMaterialId))
+	if err != nil {
+		impl.logger.Errorw("error while fetching material status", "gitMaterialId", req.GitMaterialId, "err", err)
+		return nil, err
+	}
+	return toMaterialStatusProto(diagnostics), nil
+}
+
+func toTimestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toCommitProtos(commits []*git.GitCommitBase) []*pb.Commit {
+	mapped := make([]*pb.Commit, 0, len(commits))
+	for _, commit := range commits {
+		mapped = append(mapped, toCommitProto(commit))
+	}
+	return mapped
+}
+
+func toCommitProto(commit *git.GitCommitBase) *pb.Commit {
+	if commit == nil {
+		return nil
+	}
+	// strings of the proto messages have to be valid utf8 to be marshalled
+	commit.TruncateMessageIfExceedsMaxLength()
+	if !commit.IsMessageValidUTF8() {
+		commit.FixInvalidUTF8Message()
+	}
+	mapped := &pb.Commit{
+		Commit:                commit.Commit,
+		Parents:               commit.Parents,
+		Author:                commit.Author,
+		Date:                  toTimestampProto(commit.Date),
+		Message:               commit.Message,
+		Trailers:              toTrailerProtos(commit.Trailers),
+		CommitType:            commit.CommitType,
+		CommitScope:           commit.CommitScope,
+		BreakingChange:        commit.BreakingChange,
+		Branch:                commit.Branch,
+		Changes:               commit.Changes,
+		Excluded:              commit.Excluded,
+		ChangedFiles:          toFileChangeProtos(commit.ChangedFiles),
+		ContainsBinaryChanges: commit.ContainsBinaryChanges,
+		AuthorDetail:          toAuthorProto(commit.AuthorDetail),
+		CommitterDetail:       toCommitterProto(commit.CommitterDetail),
+	}
+	if commit.Tag != nil {
+		mapped.Tag = &pb.Tag{
+			Name:      commit.Tag.Name,
+			Commit:    commit.Tag.Commit,
+			Date:      toTimestampProto(commit.Tag.Date),
+			Annotated: commit.Tag.Annotated,
+			Message:   commit.Tag.Message,
+		}
+	}
+	for _, submodule := range commit.Submodules {
+		mapped.Submodules = append(mapped.Submodules, &pb.SubmoduleChange{
+			Path:    submodule.Path,
+			Url:     submodule.Url,
+			OldHash: submodule.OldHash,
+			NewHash: submodule.NewHash,
+			Commits: toCommitProtos(submodule.Commits),
+		})
+	}
+	if commit.Signature != nil {
+		mapped.Signature = &pb.Signature{
+			Status:     commit.Signature.Status,
+			Signed:     commit.Signature.Signed,
+			Verified:   commit.Signature.Verified,
+			KeyId:      commit.Signature.KeyId,
+			Signer:     commit.Signature.Signer,
+			TrustLevel: commit.Signature.TrustLevel,
+		}
+	}
+	if commit.FileStats != nil {
+		for _, fileStat := range *commit.FileStats {
+			mapped.FileStats = append(mapped.FileStats, &pb.FileStat{
+				Name:       fileStat.Name,
+				OldPath:    fileStat.OldPath,
+				Similarity: int64(fileStat.Similarity),
+				Addition:   int64(fileStat.Addition),
+				Deletion:   int64(fileStat.Deletion),
+				Binary:     fileStat.Binary,
+				Large:      fileStat.Large,
+			})
+		}
+	}
+	if commit.WebhookData != nil {
+		mapped.WebhookData = &pb.WebhookData{
+			Id:              int64(commit.WebhookData.Id),
+			EventActionType: commit.WebhookData.EventActionType,
+			Data:            commit.WebhookData.Data,
+		}
+	}
+	if commit.PolicyVerdict != nil {
+		mapped.PolicyVerdict = &pb.PolicyVerdict{
+			Allowed:    commit.PolicyVerdict.Allowed,
+			Mode:       commit.PolicyVerdict.Mode,
+			Violations: commit.PolicyVerdict.Violations,
+		}
+	}
+	if commit.ForcePush != nil {
+		mapped.ForcePush = &pb.ForcePush{
+			PreviousHead:    commit.ForcePush.PreviousHead,
+			OrphanedCommits: commit.ForcePush.OrphanedCommits,
+		}
+	}
+	for _, ticket := range commit.Tickets {
+		mapped.Tickets = append(mapped.Tickets, &pb.Ticket{Tracker: ticket.Tracker, Id: ticket.Id})
+	}
+	return mapped
+}
+
+// toTrailerProtos orders the trailers by key, the order of a map is not stable
+func toTrailerProtos(trailers map[string][]string) []*pb.Trailer {
+	mapped := make([]*pb.Trailer, 0, len(trailers))
+	for key, values := range trailers {
+		mapped = append(mapped, &pb.Trailer{Key: key, Values: values})
+	}
+	sort.Slice(mapped, func(i, j int) bool {
+		return mapped[i].Key < mapped[j].Key
+	})
+	return mapped
+}
+
+func toAuthorProto(author *git.Author) *pb.Person {
+	if author == nil {
+		return nil
+	}
+	return &pb.Person{Name: author.Name, Email: author.Email, Date: toTimestampProto(author.Date)}
+}
+
+func toCommitterProto(committer *git.Committer) *pb.Person {
+	if committer == nil {
+		return nil
+	}
+	return &pb.Person{Name: committer.Name, Email: committer.Email, Date: toTimestampProto(committer.Date)}
+}
+
+func toFileChangeProtos(fileChanges []*git.FileChange) []*pb.FileChange {
+	var mapped []*pb.FileChange
+	for _, fileChange := range fileChanges {
+		mapped = append(mapped, &pb.FileChange{
+			Path:       fileChange.Path,
+			OldPath:    fileChange.OldPath,
+			ChangeType: string(fileChange.ChangeType),
+			Similarity: int64(fileChange.Similarity),
+			Addition:   int64(fileChange.Addition),
+			Deletion:   int64(fileChange.Deletion),
+			Binary:     fileChange.Binary,
+		})
+	}
+	return mapped
+}
+
+func toCommitRangeDiffProto(diff *git.CommitRangeDiff) *pb.CommitRangeDiff {
+	mapped := &pb.CommitRangeDiff{
+		FromCommit: diff.FromCommit,
+		ToCommit:   diff.ToCommit,
+		Truncated:  diff.Truncated,
+	}
+	for _, file := range diff.Files {
+		fileDiff := &pb.FileDiff{
+			Path:       file.Path,
+			OldPath:    file.OldPath,
+			ChangeType: string(file.ChangeType),
+			Binary:     file.Binary,
+			Addition:   int64(file.Addition),
+			Deletion:   int64(file.Deletion),
+		}
+		for _, hunk := range file.Hunks {
+			fileDiff.Hunks = append(fileDiff.Hunks, &pb.DiffHunk{
+				OldStart: int64(hunk.OldStart),
+				OldLines: int64(hunk.OldLines),
+				NewStart: int64(hunk.NewStart),
+				NewLines: int64(hunk.NewLines),
+				Section:  hunk.Section,
+				Lines:    hunk.Lines,
+			})
+		}
+		mapped.Files = append(mapped.Files, fileDiff)
+	}
+	return mapped
+}
+
+func toMaterialStatusProto(diagnostics *git.MaterialDiagnostics) *pb.MaterialStatus {
+	mapped := &pb.MaterialStatus{
+		GitMaterialId:        int64(diagnostics.GitMaterialId),
+		Url:                  diagnostics.Url,
+		ApiMode:              diagnostics.ApiMode,
+		RemoteReachable:      diagnostics.RemoteReachable,
+		RemoteError:          diagnostics.RemoteError,
+		RemoteErrorCode:      diagnostics.RemoteErrorCode,
+		RemoteErrorHint:      diagnostics.RemoteErrorHint,
+		CheckedOut:           diagnostics.CheckedOut,
+		CheckoutLocation:     diagnostics.CheckoutLocation,
+		CheckoutSizeInBytes:  diagnostics.CheckoutSizeInBytes,
+		LastFetchTime:        toTimestampProto(diagnostics.LastFetchTime),
+		LastSuccessFetchTime: toTimestampProto(diagnostics.LastSuccessFetchTime),
+		LastFetchRemote:      diagnostics.LastFetchRemote,
+		LastFetchErrorCount:  int64(diagnostics.LastFetchErrorCount),
+		LastFetchError:       diagnostics.LastFetchError,
+		LastFetchErrorCode:   diagnostics.LastFetchErrorCode,
+		LastFetchErrorHint:   diagnostics.LastFetchErrorHint,
+		GitBinaryPath:        diagnostics.GitBinaryPath,
+		GitVersion:           diagnostics.GitVersion,
+		DisabledGitFeatures:  diagnostics.DisabledGitFeatures,
+	}
+	for _, branch := range diagnostics.Branches {
+		mapped.Branches = append(mapped.Branches, &pb.BranchStatus{
+			PipelineMaterialId: int64(branch.PipelineMaterialId),
+			Branch:             branch.Branch,
+			Exists:             branch.Exists,
+			RemoteCommit:       branch.RemoteCommit,
+			LastSeenCommit:     branch.LastSeenCommit,
+		})
+	}
+	return mapped
+}
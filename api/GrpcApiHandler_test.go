@@ -0,0 +1,274 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/pkg"
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	pb "github.com/devtron-labs/git-sensor/protos/gitSensorApi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"testing"
+	"time"
+)
+
+// fakeApiRepoManager returns the configured outputs and records the diff request
+type fakeApiRepoManager struct {
+	pkg.RepoManager
+	changes     *git.MaterialChangeResp
+	commit      *git.GitCommitBase
+	diff        *git.CommitRangeDiff
+	diagnostics *git.MaterialDiagnostics
+	err         error
+	diffRequest *git.CommitDiffRequest
+}
+
+func (impl *fakeApiRepoManager) FetchChanges(pipelineMaterialId int, from string, to string, count int, showAll bool) (*git.MaterialChangeResp, error) {
+	return impl.changes, impl.err
+}
+
+func (impl *fakeApiRepoManager) GetCommitMetadata(gitCtx git.GitContext, pipelineMaterialId int, gitHash string) (*git.GitCommitBase, error) {
+	return impl.commit, impl.err
+}
+
+func (impl *fakeApiRepoManager) GetDiffBetweenCommits(gitCtx git.GitContext, request *git.CommitDiffRequest) (*git.CommitRangeDiff, error) {
+	impl.diffRequest = request
+	return impl.diff, impl.err
+}
+
+func (impl *fakeApiRepoManager) GetMaterialDiagnostics(gitCtx git.GitContext, gitMaterialId int) (*git.MaterialDiagnostics, error) {
+	return impl.diagnostics, impl.err
+}
+
+func getTestGrpcApiHandler(repositoryManager *fakeApiRepoManager) *GrpcApiHandlerImpl {
+	logger, _ := utils.NewSugardLogger()
+	return NewGrpcApiHandlerImpl(repositoryManager, logger)
+}
+
+func TestGrpcApiHandlerImpl_FetchChanges(t *testing.T) {
+	fetchTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		changes *git.MaterialChangeResp
+		err     error
+		want    *pb.MaterialChanges
+		wantErr bool
+	}{
+		{name: "manager error", err: errors.New("material not found"), wantErr: true},
+		{name: "no changes", want: &pb.MaterialChanges{}},
+		{
+			name:    "changes of the material",
+			changes: &git.MaterialChangeResp{LastFetchTime: fetchTime, Commits: []*git.GitCommitBase{{Commit: "c2", Date: fetchTime}, {Commit: "c1"}}},
+			want: &pb.MaterialChanges{
+				LastFetchTime: toTimestampProto(fetchTime),
+				Commits:       []*pb.Commit{{Commit: "c2", Date: toTimestampProto(fetchTime), Trailers: []*pb.Trailer{}}, {Commit: "c1", Trailers: []*pb.Trailer{}}},
+			},
+		},
+		{
+			name:    "branch errors of the material",
+			changes: &git.MaterialChangeResp{IsBranchError: true, BranchErrorMsg: git.BRANCH_DELETED_ERROR_MESSAGE, IsBranchDeleted: true},
+			want:    &pb.MaterialChanges{Commits: []*pb.Commit{}, IsBranchError: true, BranchErrorMsg: git.BRANCH_DELETED_ERROR_MESSAGE, IsBranchDeleted: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := getTestGrpcApiHandler(&fakeApiRepoManager{changes: tt.changes, err: tt.err})
+			got, err := impl.FetchChanges(context.Background(), &pb.FetchChangesRequest{PipelineMaterialId: 1})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func TestGrpcApiHandlerImpl_GetCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  *git.GitCommitBase
+		err     error
+		want    *pb.Commit
+		wantErr bool
+	}{
+		{name: "manager error", err: errors.New("commit not found"), wantErr: true},
+		{name: "no commit", want: &pb.Commit{}},
+		{
+			name: "trailers are ordered by key",
+			commit: &git.GitCommitBase{Commit: "c1", Trailers: map[string][]string{
+				"Signed-off-by": {"dev <dev@example.com>"},
+				"Reviewed-by":   {"ops <ops@example.com>", "qa <qa@example.com>"},
+			}},
+			want: &pb.Commit{Commit: "c1", Trailers: []*pb.Trailer{
+				{Key: "Reviewed-by", Values: []string{"ops <ops@example.com>", "qa <qa@example.com>"}},
+				{Key: "Signed-off-by", Values: []string{"dev <dev@example.com>"}},
+			}},
+		},
+		{
+			name:   "invalid utf8 message is fixed",
+			commit: &git.GitCommitBase{Commit: "c1", Message: "fix \xff"},
+			want:   &pb.Commit{Commit: "c1", Message: "fix �", Trailers: []*pb.Trailer{}},
+		},
+		{
+			name: "nested details of the commit",
+			commit: &git.GitCommitBase{
+				Commit:        "c1",
+				Tag:           &git.GitTag{Name: "v1.0.0", Commit: "c1", Annotated: true, Message: "release"},
+				Signature:     &git.CommitSignature{Status: "G", Signed: true, Verified: true, KeyId: "ABC"},
+				FileStats:     &git.FileStats{{Name: "main.go", Addition: 2, Deletion: 1}},
+				Submodules:    []*git.SubmoduleChange{{Path: "lib", OldHash: "s1", NewHash: "s2", Commits: []*git.GitCommitBase{{Commit: "s2"}}}},
+				PolicyVerdict: &git.CommitPolicyVerdict{Allowed: false, Mode: "exclude", Violations: []string{"unsigned"}},
+				Tickets:       []*git.CommitTicket{{Tracker: "jira", Id: "GS-1"}},
+			},
+			want: &pb.Commit{
+				Commit:        "c1",
+				Trailers:      []*pb.Trailer{},
+				Tag:           &pb.Tag{Name: "v1.0.0", Commit: "c1", Annotated: true, Message: "release"},
+				Signature:     &pb.Signature{Status: "G", Signed: true, Verified: true, KeyId: "ABC"},
+				FileStats:     []*pb.FileStat{{Name: "main.go", Addition: 2, Deletion: 1}},
+				Submodules:    []*pb.SubmoduleChange{{Path: "lib", OldHash: "s1", NewHash: "s2", Commits: []*pb.Commit{{Commit: "s2", Trailers: []*pb.Trailer{}}}}},
+				PolicyVerdict: &pb.PolicyVerdict{Allowed: false, Mode: "exclude", Violations: []string{"unsigned"}},
+				Tickets:       []*pb.Ticket{{Tracker: "jira", Id: "GS-1"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := getTestGrpcApiHandler(&fakeApiRepoManager{commit: tt.commit, err: tt.err})
+			got, err := impl.GetCommit(context.Background(), &pb.CommitRequest{PipelineMaterialId: 1, GitHash: "c1"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			// the mapped messages have to be marshalled by grpc
+			_, err = proto.Marshal(got)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGrpcApiHandlerImpl_GetDiff(t *testing.T) {
+	contextLines := int64(0)
+	diff := &git.CommitRangeDiff{
+		FromCommit: "c1",
+		ToCommit:   "c2",
+		Truncated:  true,
+		Files: []*git.FileDiff{{
+			Path: "main.go", ChangeType: git.FILE_CHANGE_TYPE_MODIFIED, Addition: 1, Deletion: 1,
+			Hunks: []*git.DiffHunk{{OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 1, Section: "func main()", Lines: []string{"-a", "+b"}}},
+		}},
+	}
+	tests := []struct {
+		name             string
+		req              *pb.DiffRequest
+		diff             *git.CommitRangeDiff
+		err              error
+		wantContextLines *int
+		want             *pb.CommitRangeDiff
+		wantErr          bool
+	}{
+		{name: "manager error", req: &pb.DiffRequest{FromCommit: "c1", ToCommit: "c2"}, err: errors.New("invalid diff request"), wantErr: true},
+		{
+			name: "default context lines",
+			req:  &pb.DiffRequest{PipelineMaterialId: 1, FromCommit: "c1", ToCommit: "c2", Paths: []string{"main.go"}},
+			diff: diff,
+			want: &pb.CommitRangeDiff{FromCommit: "c1", ToCommit: "c2", Truncated: true, Files: []*pb.FileDiff{{
+				Path: "main.go", ChangeType: string(git.FILE_CHANGE_TYPE_MODIFIED), Addition: 1, Deletion: 1,
+				Hunks: []*pb.DiffHunk{{OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 1, Section: "func main()", Lines: []string{"-a", "+b"}}},
+			}}},
+		},
+		{
+			name:             "zero context lines are passed",
+			req:              &pb.DiffRequest{PipelineMaterialId: 1, FromCommit: "c1", ToCommit: "c2", ContextLines: &contextLines},
+			diff:             &git.CommitRangeDiff{FromCommit: "c1", ToCommit: "c2"},
+			wantContextLines: new(int),
+			want:             &pb.CommitRangeDiff{FromCommit: "c1", ToCommit: "c2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repositoryManager := &fakeApiRepoManager{diff: tt.diff, err: tt.err}
+			impl := getTestGrpcApiHandler(repositoryManager)
+			got, err := impl.GetDiff(context.Background(), tt.req)
+			assert.Equal(t, tt.req.FromCommit, repositoryManager.diffRequest.FromCommit)
+			assert.Equal(t, tt.req.Paths, repositoryManager.diffRequest.Paths)
+			assert.Equal(t, tt.wantContextLines, repositoryManager.diffRequest.ContextLines)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func TestGrpcApiHandlerImpl_GetMaterialStatus(t *testing.T) {
+	fetchTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		diagnostics *git.MaterialDiagnostics
+		err         error
+		want        *pb.MaterialStatus
+		wantErr     bool
+	}{
+		{name: "manager error", err: errors.New("material not found"), wantErr: true},
+		{
+			name: "status of the material",
+			diagnostics: &git.MaterialDiagnostics{
+				GitMaterialId:       4,
+				Url:                 "https://example.com/repo.git",
+				RemoteReachable:     true,
+				CheckedOut:          true,
+				LastFetchTime:       fetchTime,
+				LastFetchErrorCount: 2,
+				Branches:            []*git.BranchDiagnostics{{PipelineMaterialId: 7, Branch: "main", Exists: true, RemoteCommit: "c2", LastSeenCommit: "c1"}},
+			},
+			want: &pb.MaterialStatus{
+				GitMaterialId:       4,
+				Url:                 "https://example.com/repo.git",
+				RemoteReachable:     true,
+				CheckedOut:          true,
+				LastFetchTime:       toTimestampProto(fetchTime),
+				LastFetchErrorCount: 2,
+				Branches:            []*pb.BranchStatus{{PipelineMaterialId: 7, Branch: "main", Exists: true, RemoteCommit: "c2", LastSeenCommit: "c1"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := getTestGrpcApiHandler(&fakeApiRepoManager{diagnostics: tt.diagnostics, err: tt.err})
+			got, err := impl.GetMaterialStatus(context.Background(), &pb.MaterialStatusRequest{GitMaterialId: 4})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	pb "github.com/devtron-labs/protos/gitSensor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	// ARCHIVE_FORMAT_METADATA_KEY and ARCHIVE_PATH_METADATA_KEY carry the format and the paths of the archive, which
+	// have no field in the CommitMetadataRequest message. The path key is repeated for each path
+	ARCHIVE_FORMAT_METADATA_KEY = "archive-format"
+	ARCHIVE_PATH_METADATA_KEY   = "archive-path"
+)
+
+// GitSensorArchiveServiceServer is the server api of gitService.GitSensorArchiveService. The request reuses the
+// CommitMetadataRequest of the GitSensorService and the archive is streamed as BytesValue chunks
+type GitSensorArchiveServiceServer interface {
+	ExportArchive(req *pb.CommitMetadataRequest, stream grpc.ServerStream) error
+}
+
+var GitSensorArchiveServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitService.GitSensorArchiveService",
+	HandlerType: (*GitSensorArchiveServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportArchive",
+			Handler:       exportArchiveHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gitSensor/service.proto",
+}
+
+func exportArchiveHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(pb.CommitMetadataRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(GitSensorArchiveServiceServer).ExportArchive(req, stream)
+}
+
+// ExportArchive streams the archive of the commit of the pipeline material
+func (impl *GrpcHandlerImpl) ExportArchive(req *pb.CommitMetadataRequest, stream grpc.ServerStream) error {
+	request := &git.ArchiveRequest{
+		PipelineMaterialId: int(req.PipelineMaterialId),
+		GitHash:            req.GitHash,
+	}
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if formats := md.Get(ARCHIVE_FORMAT_METADATA_KEY); len(formats) > 0 {
+			request.Format = formats[0]
+		}
+		request.Paths = md.Get(ARCHIVE_PATH_METADATA_KEY)
+	}
+	if len(git.GetArchiveContentType(request.Format)) == 0 {
+		return status.Errorf(codes.InvalidArgument, "unsupported archive format %q", request.Format)
+	}
+	gitCtx := git.BuildGitContext(stream.Context())
+	err := impl.repositoryManager.WriteArchive(gitCtx, request, &archiveStreamWriter{stream: stream})
+	if err != nil {
+		impl.logger.Errorw("error while streaming archive", "pipelineMaterialId", request.PipelineMaterialId, "gitHash", request.GitHash, "err", err)
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(getGrpcCode(err), err.Error())
+	}
+	return nil
+}
+
+// archiveStreamWriter sends each write as a chunk, io.Copy keeps the chunks within its buffer size
+type archiveStreamWriter struct {
+	stream grpc.ServerStream
+}
+
+func (writer *archiveStreamWriter) Write(p []byte) (int, error) {
+	if err := writer.stream.SendMsg(wrapperspb.Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GitErrorUnaryServerInterceptor converts the classified git errors returned by the handlers to a status with a
+// matching code, so that clients can tell the errors to alert on from the ones to retry
+func GitErrorUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return resp, err
+	}
+	return resp, status.Error(getGrpcCode(err), err.Error())
+}
+
+func getGrpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, git.ErrAuthFailed):
+		return codes.Unauthenticated
+	case errors.Is(err, git.ErrRepoNotFound), errors.Is(err, git.ErrBranchNotFound):
+		return codes.NotFound
+	case errors.Is(err, git.ErrShallowRangeUnavailable):
+		return codes.FailedPrecondition
+	case errors.Is(err, git.ErrTimeout):
+		return codes.DeadlineExceeded
+	case errors.Is(err, git.ErrProviderRateLimited):
+		return codes.ResourceExhausted
+	case errors.Is(err, git.ErrNetwork):
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
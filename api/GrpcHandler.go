@@ -21,6 +21,7 @@ import (
 	"github.com/devtron-labs/git-sensor/internals/sql"
 	"github.com/devtron-labs/git-sensor/pkg"
 	"github.com/devtron-labs/git-sensor/pkg/git"
+	"github.com/devtron-labs/git-sensor/protos/gitSensorWatch"
 	pb "github.com/devtron-labs/protos/gitSensor"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -54,16 +55,19 @@ type GrpcHandler interface {
 
 type GrpcHandlerImpl struct {
 	pb.GitSensorServiceServer
-	logger            *zap.SugaredLogger
-	repositoryManager pkg.RepoManager
+	gitSensorWatch.UnimplementedGitSensorWatchServiceServer
+	logger                    *zap.SugaredLogger
+	repositoryManager         pkg.RepoManager
+	materialChangeBroadcaster git.MaterialChangeBroadcaster
 }
 
 func NewGrpcHandlerImpl(
-	repositoryManager pkg.RepoManager, logger *zap.SugaredLogger) *GrpcHandlerImpl {
+	repositoryManager pkg.RepoManager, logger *zap.SugaredLogger, materialChangeBroadcaster git.MaterialChangeBroadcaster) *GrpcHandlerImpl {
 
 	return &GrpcHandlerImpl{
-		repositoryManager: repositoryManager,
-		logger:            logger,
+		repositoryManager:         repositoryManager,
+		logger:                    logger,
+		materialChangeBroadcaster: materialChangeBroadcaster,
 	}
 }
 
@@ -221,7 +225,10 @@ func (impl *GrpcHandlerImpl) FetchChanges(ctx context.Context, req *pb.FetchScmC
 	if res == nil {
 		return nil, nil
 	}
+	return impl.mapMaterialChangeResponse(res), nil
+}
 
+func (impl *GrpcHandlerImpl) mapMaterialChangeResponse(res *git.MaterialChangeResp) *pb.MaterialChangeResponse {
 	// Mapping GitCommit
 	var pbGitCommits []*pb.GitCommit
 	if res.Commits != nil {
@@ -252,7 +259,7 @@ func (impl *GrpcHandlerImpl) FetchChanges(ctx context.Context, req *pb.FetchScmC
 	if !res.LastFetchTime.IsZero() {
 		mappedRes.LastFetchTime = timestamppb.New(res.LastFetchTime)
 	}
-	return mappedRes, nil
+	return mappedRes
 }
 
 func (impl *GrpcHandlerImpl) GetHeadForPipelineMaterials(ctx context.Context, req *pb.HeadRequest) (
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	pb "github.com/devtron-labs/protos/gitSensor"
+	"google.golang.org/grpc"
+)
+
+// GitSensorRefreshServiceServer is the server api of gitService.GitSensorRefreshService. The rpc reuses the messages of
+// the FetchChanges rpc of the GitSensorService
+type GitSensorRefreshServiceServer interface {
+	RefreshMaterial(ctx context.Context, req *pb.FetchScmChangesRequest) (*pb.MaterialChangeResponse, error)
+}
+
+var GitSensorRefreshServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitService.GitSensorRefreshService",
+	HandlerType: (*GitSensorRefreshServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RefreshMaterial",
+			Handler:    refreshMaterialHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gitSensor/service.proto",
+}
+
+func refreshMaterialHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(pb.FetchScmChangesRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GitSensorRefreshServiceServer).RefreshMaterial(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gitService.GitSensorRefreshService/RefreshMaterial",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GitSensorRefreshServiceServer).RefreshMaterial(ctx, req.(*pb.FetchScmChangesRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// RefreshMaterial polls the material of the pipeline material right away and returns the commits found by the poll,
+// a DeadlineExceeded status is returned when the poll takes longer than the deadline of the call or the configured timeout
+func (impl *GrpcHandlerImpl) RefreshMaterial(ctx context.Context, req *pb.FetchScmChangesRequest) (*pb.MaterialChangeResponse, error) {
+	gitCtx := git.BuildGitContext(ctx)
+	res, err := impl.repositoryManager.RefreshMaterial(gitCtx, int(req.PipelineMaterialId))
+	if err != nil {
+		impl.logger.Errorw("error while refreshing material", "pipelineMaterialId", req.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	return impl.mapMaterialChangeResponse(res), nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	"github.com/devtron-labs/git-sensor/protos/gitSensorWatch"
+	pb "github.com/devtron-labs/protos/gitSensor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// WatchMaterialChanges streams the new commits of the pipeline material as they are found by the watcher. When From is
+// set, the commits after it which are still in the commit history of the material are sent first, so a client can resume
+// from the last commit it has seen after a reconnect
+func (impl *GrpcHandlerImpl) WatchMaterialChanges(req *pb.FetchScmChangesRequest, stream gitSensorWatch.GitSensorWatchService_WatchMaterialChangesServer) error {
+	pipelineMaterialId := int(req.PipelineMaterialId)
+	// subscribe before reading the history so that commits found in between are not lost
+	subscription := impl.materialChangeBroadcaster.Subscribe(pipelineMaterialId)
+	defer impl.materialChangeBroadcaster.Unsubscribe(subscription)
+
+	sent := make(map[string]bool)
+	if len(req.From) > 0 {
+		res, err := impl.repositoryManager.FetchChanges(pipelineMaterialId, "", "", 0, false)
+		if err != nil {
+			impl.logger.Errorw("error while fetching scm changes to resume from", "pipelineMaterialId", pipelineMaterialId, "from", req.From, "err", err)
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, commit := range commitsAfter(res.Commits, req.From) {
+			if err = impl.sendMaterialChange(stream, commit, res.LastFetchTime); err != nil {
+				return err
+			}
+			sent[commit.Commit] = true
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case material, ok := <-subscription.Changes:
+			if !ok {
+				if subscription.Lagged {
+					return status.Error(codes.ResourceExhausted, "subscriber is lagging, resume from the last seen commit")
+				}
+				return nil
+			}
+			for _, commit := range materialChangeCommits(material) {
+				if sent[commit.Commit] {
+					continue
+				}
+				if err := impl.sendMaterialChange(stream, commit, time.Now()); err != nil {
+					return err
+				}
+				sent[commit.Commit] = true
+			}
+		}
+	}
+}
+
+// sendMaterialChange sends a copy of the commit, the commit itself is shared with the other subscribers and the publisher
+// of the change
+func (impl *GrpcHandlerImpl) sendMaterialChange(stream gitSensorWatch.GitSensorWatchService_WatchMaterialChangesServer, sharedCommit *git.GitCommitBase, lastFetchTime time.Time) error {
+	commitCopy := *sharedCommit
+	commit := &commitCopy
+	commit.TruncateMessageIfExceedsMaxLength()
+	if !commit.IsMessageValidUTF8() {
+		commit.FixInvalidUTF8Message()
+	}
+	mappedCommit, err := impl.mapGitCommit(commit)
+	if err != nil {
+		impl.logger.Errorw("failed to map git commit from bean to proto specified type", "commit", commit.Commit, "err", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.Send(&pb.MaterialChangeResponse{
+		Commits:       []*pb.GitCommit{mappedCommit},
+		LastFetchTime: timestamppb.New(lastFetchTime),
+	})
+}
+
+// commitsAfter returns the commits newer than from, oldest first. The commit history is newest first, all of it is
+// returned when from is no longer in it
+func commitsAfter(commits []*git.GitCommitBase, from string) []*git.GitCommitBase {
+	var newer []*git.GitCommitBase
+	for _, commit := range commits {
+		if commit.Commit == from {
+			break
+		}
+		newer = append([]*git.GitCommitBase{commit}, newer...)
+	}
+	return newer
+}
+
+// materialChangeCommits returns the new commits of the change, oldest first. Only the head is known on the first poll of
+// a material and for tags, a deleted branch has no new commits
+func materialChangeCommits(material *git.CiPipelineMaterialBean) []*git.GitCommitBase {
+	if material.BranchDeleted || material.GitCommit == nil {
+		return nil
+	}
+	if len(material.NewCommits) == 0 {
+		return []*git.GitCommitBase{material.GitCommit}
+	}
+	commits := make([]*git.GitCommitBase, 0, len(material.NewCommits))
+	for i := len(material.NewCommits) - 1; i >= 0; i-- {
+		commits = append(commits, material.NewCommits[i])
+	}
+	return commits
+}
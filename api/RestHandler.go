@@ -18,6 +18,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/devtron-labs/git-sensor/bean"
 	"github.com/devtron-labs/git-sensor/internals/sql"
@@ -26,7 +27,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/schema"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
 )
 
@@ -39,12 +42,41 @@ type RestHandler interface {
 	GetHeadForPipelineMaterials(w http.ResponseWriter, r *http.Request)
 	GetCommitMetadata(w http.ResponseWriter, r *http.Request)
 	GetCommitMetadataForPipelineMaterial(w http.ResponseWriter, r *http.Request)
+	GetCommitsMetadata(w http.ResponseWriter, r *http.Request)
 	ReloadAllMaterial(w http.ResponseWriter, r *http.Request)
 	ReloadMaterial(w http.ResponseWriter, r *http.Request)
 	ReloadMaterials(w http.ResponseWriter, r *http.Request)
 	GetChangesInRelease(w http.ResponseWriter, r *http.Request)
 	GetCommitInfoForTag(w http.ResponseWriter, r *http.Request)
+	GetFileContentAtCommit(w http.ResponseWriter, r *http.Request)
+	GetLfsPointers(w http.ResponseWriter, r *http.Request)
+	ExportArchive(w http.ResponseWriter, r *http.Request)
+	GetMaterialDiagnostics(w http.ResponseWriter, r *http.Request)
+	ValidateMaterial(w http.ResponseWriter, r *http.Request)
+	GetRepoMetrics(w http.ResponseWriter, r *http.Request)
+	GetGitConfig(w http.ResponseWriter, r *http.Request)
+	GetMaterialBranches(w http.ResponseWriter, r *http.Request)
+	UpdateGitConfig(w http.ResponseWriter, r *http.Request)
+	GetDiffBetweenCommits(w http.ResponseWriter, r *http.Request)
+	GetBlame(w http.ResponseWriter, r *http.Request)
+	GenerateChangeLog(w http.ResponseWriter, r *http.Request)
+	SuggestNextVersion(w http.ResponseWriter, r *http.Request)
+	GetMergeBase(w http.ResponseWriter, r *http.Request)
+	IsAncestor(w http.ResponseWriter, r *http.Request)
+	IngestWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request)
+	GetCommitHistory(w http.ResponseWriter, r *http.Request)
+	GetCommitsSince(w http.ResponseWriter, r *http.Request)
+	SearchCommits(w http.ResponseWriter, r *http.Request)
+	StartCloneJob(w http.ResponseWriter, r *http.Request)
+	StartDeepenJob(w http.ResponseWriter, r *http.Request)
+	StartArchiveJob(w http.ResponseWriter, r *http.Request)
+	GetJob(w http.ResponseWriter, r *http.Request)
+	CancelJob(w http.ResponseWriter, r *http.Request)
+	DownloadJobResult(w http.ResponseWriter, r *http.Request)
 	RefreshGitMaterial(w http.ResponseWriter, r *http.Request)
+	RefreshMaterial(w http.ResponseWriter, r *http.Request)
 	GetWebhookData(w http.ResponseWriter, r *http.Request)
 	GetAllWebhookEventConfigForHost(w http.ResponseWriter, r *http.Request)
 	GetWebhookEventConfig(w http.ResponseWriter, r *http.Request)
@@ -52,13 +84,15 @@ type RestHandler interface {
 	GetWebhookPayloadFilterDataForPipelineMaterialId(w http.ResponseWriter, r *http.Request)
 }
 
-func NewRestHandlerImpl(repositoryManager pkg.RepoManager, logger *zap.SugaredLogger) *RestHandlerImpl {
-	return &RestHandlerImpl{repositoryManager: repositoryManager, logger: logger}
+func NewRestHandlerImpl(repositoryManager pkg.RepoManager, logger *zap.SugaredLogger, webhookIngestionService git.WebhookIngestionService, jobManager git.JobManager) *RestHandlerImpl {
+	return &RestHandlerImpl{repositoryManager: repositoryManager, logger: logger, webhookIngestionService: webhookIngestionService, jobManager: jobManager}
 }
 
 type RestHandlerImpl struct {
-	repositoryManager pkg.RepoManager
-	logger            *zap.SugaredLogger
+	repositoryManager       pkg.RepoManager
+	logger                  *zap.SugaredLogger
+	webhookIngestionService git.WebhookIngestionService
+	jobManager              git.JobManager
 }
 
 type Response struct {
@@ -84,6 +118,10 @@ func (impl RestHandlerImpl) writeJsonResp(w http.ResponseWriter, err error, resp
 	} else {
 		apiErr := &ApiError{}
 		apiErr.Code = "000" // 000=unknown
+		if code := git.GetGitErrorCode(err); code != git.GIT_ERROR_CODE_UNKNOWN {
+			apiErr.Code = code
+			apiErr.UserDetailMessage = git.GetGitErrorUserMessage(err)
+		}
 		apiErr.InternalMessage = err.Error()
 		apiErr.UserMessage = respBody
 		response.Errors = []*ApiError{apiErr}
@@ -133,6 +171,16 @@ func (handler RestHandlerImpl) AddRepo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	handler.logger.Infow("add repo request ", "req", Repo)
+	if r.URL.Query().Get("async") == "true" {
+		// the repos are cloned in background jobs, polled with the returned job ids
+		jobs, err := handler.repositoryManager.AddRepoAsync(gitCtx, Repo)
+		if err != nil {
+			handler.writeJobResp(w, err, nil)
+		} else {
+			handler.writeJsonResp(w, err, jobs, http.StatusAccepted)
+		}
+		return
+	}
 	res, err := handler.repositoryManager.AddRepo(gitCtx, Repo)
 	if err != nil {
 		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
@@ -274,6 +322,27 @@ func (handler RestHandlerImpl) FetchChanges(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (handler RestHandlerImpl) RefreshMaterial(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	material := &git.FetchScmChangesRequest{}
+	err := decoder.Decode(material)
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("refresh material request", "req", material)
+	gitCtx := git.BuildGitContext(r.Context())
+	changes, err := handler.repositoryManager.RefreshMaterial(gitCtx, material.PipelineMaterialId)
+	if errors.Is(err, git.ErrTimeout) {
+		handler.writeJsonResp(w, err, nil, http.StatusGatewayTimeout)
+	} else if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, changes, http.StatusOK)
+	}
+}
+
 func (handler RestHandlerImpl) GetHeadForPipelineMaterials(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	material := &git.HeadRequest{}
@@ -302,7 +371,7 @@ func (handler RestHandlerImpl) GetCommitMetadata(w http.ResponseWriter, r *http.
 		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
 		return
 	}
-	gitCtx := git.BuildGitContext(r.Context())
+	gitCtx := git.BuildGitContext(r.Context()).WithSubmoduleResolution(material.ResolveSubmodules, material.FetchSubmoduleCommits)
 	handler.logger.Infow("commit detail request", "req", material)
 	var commits *git.GitCommitBase
 	if len(material.GitTag) > 0 {
@@ -319,6 +388,26 @@ func (handler RestHandlerImpl) GetCommitMetadata(w http.ResponseWriter, r *http.
 	}
 }
 
+func (handler RestHandlerImpl) GetCommitsMetadata(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CommitsMetadataRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding commits metadata request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("commits metadata request", "pipelineMaterialId", request.PipelineMaterialId, "hashes", len(request.GitHashes))
+	gitCtx := git.BuildGitContext(r.Context())
+
+	response, err := handler.repositoryManager.GetCommitsMetadata(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, response, http.StatusOK)
+	}
+}
+
 func (handler RestHandlerImpl) GetCommitMetadataForPipelineMaterial(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	material := &git.CommitMetadataRequest{}
@@ -360,6 +449,570 @@ func (handler RestHandlerImpl) GetCommitInfoForTag(w http.ResponseWriter, r *htt
 
 }
 
+func (handler RestHandlerImpl) GetFileContentAtCommit(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.FileContentRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding file content request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("file content request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	fileContent, err := handler.repositoryManager.GetFileContentAtCommit(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, fileContent, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetLfsPointers(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.LfsPointersRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding lfs pointers request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("lfs pointers request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	pointers, err := handler.repositoryManager.GetLfsPointers(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, pointers, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetMaterialDiagnostics(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gitCtx := git.BuildGitContext(r.Context())
+	materialId, err := strconv.Atoi(vars["materialId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("material diagnostics request", "id", materialId)
+	diagnostics, err := handler.repositoryManager.GetMaterialDiagnostics(gitCtx, materialId)
+	if err != nil {
+		handler.logger.Errorw("error in getting material diagnostics", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	} else {
+		handler.writeJsonResp(w, nil, diagnostics, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetRepoMetrics(w http.ResponseWriter, r *http.Request) {
+	gitCtx := git.BuildGitContext(r.Context())
+	materialId, err := strconv.Atoi(mux.Vars(r)["materialId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	largestBlobs := 0
+	if value := r.URL.Query().Get("largestBlobs"); len(value) > 0 {
+		largestBlobs, err = strconv.Atoi(value)
+		if err != nil {
+			handler.logger.Error(err)
+			handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	handler.logger.Infow("repo metrics request", "id", materialId, "largestBlobs", largestBlobs)
+	metrics, err := handler.repositoryManager.GetRepoMetrics(gitCtx, materialId, largestBlobs)
+	if err != nil {
+		handler.logger.Errorw("error in getting repo metrics", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	} else {
+		handler.writeJsonResp(w, nil, metrics, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetGitConfig(w http.ResponseWriter, r *http.Request) {
+	materialId, err := strconv.Atoi(mux.Vars(r)["materialId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	gitConfig, err := handler.repositoryManager.GetGitConfig(materialId)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	} else {
+		handler.writeJsonResp(w, nil, gitConfig, http.StatusOK)
+	}
+}
+
+// GetMaterialBranches lists the branches of the material with whether they are protected at the git provider
+func (handler RestHandlerImpl) GetMaterialBranches(w http.ResponseWriter, r *http.Request) {
+	gitCtx := git.BuildGitContext(r.Context())
+	materialId, err := strconv.Atoi(mux.Vars(r)["materialId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	branches, err := handler.repositoryManager.GetMaterialBranches(gitCtx, materialId)
+	if err != nil {
+		handler.logger.Errorw("error in getting branches of material", "id", materialId, "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	} else {
+		handler.writeJsonResp(w, nil, branches, http.StatusOK)
+	}
+}
+
+// UpdateGitConfig replaces the extra git config of the material with the key value map of the request body
+func (handler RestHandlerImpl) UpdateGitConfig(w http.ResponseWriter, r *http.Request) {
+	materialId, err := strconv.Atoi(mux.Vars(r)["materialId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	gitConfig := make(map[string]string)
+	err = json.NewDecoder(r.Body).Decode(&gitConfig)
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("update git config request", "id", materialId)
+	gitConfig, err = handler.repositoryManager.UpdateGitConfig(materialId, gitConfig)
+	switch {
+	case err == nil:
+		handler.writeJsonResp(w, nil, gitConfig, http.StatusOK)
+	case errors.Is(err, git.ErrInvalidGitConfig):
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	default:
+		handler.logger.Errorw("error in updating git config", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	}
+}
+
+// ExportArchive streams the archive of the commit as the response body. The headers are only written with the first
+// chunk of the archive, so that a failure before it is still returned as a json error
+func (handler RestHandlerImpl) ExportArchive(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.ArchiveRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding archive request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("archive request", "req", request)
+	if len(request.Format) == 0 {
+		request.Format = git.ARCHIVE_FORMAT_TAR
+	}
+	contentType := git.GetArchiveContentType(request.Format)
+	if len(contentType) == 0 {
+		handler.writeJsonResp(w, fmt.Errorf("unsupported archive format %q", request.Format), nil, http.StatusBadRequest)
+		return
+	}
+	gitCtx := git.BuildGitContext(r.Context())
+
+	archiveWriter := &archiveResponseWriter{ResponseWriter: w, contentType: contentType, fileName: request.GitHash + "." + request.Format}
+	err = handler.repositoryManager.WriteArchive(gitCtx, request, archiveWriter)
+	if err != nil && !archiveWriter.started {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else if err != nil {
+		handler.logger.Errorw("error in streaming archive", "request", request, "err", err)
+	}
+}
+
+type archiveResponseWriter struct {
+	http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (writer *archiveResponseWriter) Write(p []byte) (int, error) {
+	if !writer.started {
+		writer.started = true
+		writer.Header().Set("Content-Type", writer.contentType)
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", writer.fileName))
+		writer.WriteHeader(http.StatusOK)
+	}
+	return writer.ResponseWriter.Write(p)
+}
+
+func (handler RestHandlerImpl) GetDiffBetweenCommits(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CommitDiffRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding commit diff request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("commit diff request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	diff, err := handler.repositoryManager.GetDiffBetweenCommits(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, diff, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetBlame(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.BlameRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding blame request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("blame request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	fileBlame, err := handler.repositoryManager.GetBlame(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, fileBlame, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GenerateChangeLog(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.ChangeLogRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding change log request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("change log request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	changeLog, err := handler.repositoryManager.GenerateChangeLog(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, changeLog, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) SuggestNextVersion(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.VersionBumpRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding version bump request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("version bump request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	response, err := handler.repositoryManager.SuggestNextVersion(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, response, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) ValidateMaterial(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.MaterialValidationRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding material validation request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	// the request is not logged as is, it can have the credentials of an unsaved git provider
+	handler.logger.Infow("material validation request", "url", git.SanitizeText(request.Url), "gitProviderId", request.GitProviderId, "sourceType", request.SourceType, "value", request.Value)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	validation, err := handler.repositoryManager.ValidateMaterial(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, validation, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetMergeBase(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.MergeBaseRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding merge base request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("merge base request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	response, err := handler.repositoryManager.GetMergeBase(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, response, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) IsAncestor(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.IsAncestorRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding is ancestor request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("is ancestor request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	response, err := handler.repositoryManager.IsAncestor(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, response, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetCommitHistory(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CommitHistoryRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding commit history request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("commit history request", "req", request)
+	commits, err := handler.repositoryManager.GetCommitHistory(request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, commits, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) GetCommitsSince(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CommitsSinceRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding commits since request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("commits since request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context()).WithSubmoduleResolution(request.ResolveSubmodules, request.FetchSubmoduleCommits)
+
+	response, err := handler.repositoryManager.GetCommitsSince(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, response, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) SearchCommits(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CommitSearchRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding commit search request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("commit search request", "req", request)
+	gitCtx := git.BuildGitContext(r.Context())
+
+	results, err := handler.repositoryManager.SearchCommits(gitCtx, request)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	} else {
+		handler.writeJsonResp(w, err, results, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) StartCloneJob(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.CloneJobRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding clone job request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("clone job request", "req", request)
+	job, err := handler.repositoryManager.StartCloneJob(request)
+	if err != nil {
+		handler.writeJobResp(w, err, nil)
+	} else {
+		handler.writeJsonResp(w, err, job, http.StatusAccepted)
+	}
+}
+
+func (handler RestHandlerImpl) StartDeepenJob(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.DeepenJobRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding deepen job request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("deepen job request", "req", request)
+	job, err := handler.repositoryManager.StartDeepenJob(request)
+	if err != nil {
+		handler.writeJobResp(w, err, nil)
+	} else {
+		handler.writeJsonResp(w, err, job, http.StatusAccepted)
+	}
+}
+
+func (handler RestHandlerImpl) StartArchiveJob(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	request := &git.ArchiveRequest{}
+	err := decoder.Decode(request)
+	if err != nil {
+		handler.logger.Errorw("err in decoding archive job request", "err", err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("archive job request", "req", request)
+	job, err := handler.repositoryManager.StartArchiveJob(request)
+	if err != nil {
+		handler.writeJobResp(w, err, nil)
+	} else {
+		handler.writeJsonResp(w, err, job, http.StatusAccepted)
+	}
+}
+
+func (handler RestHandlerImpl) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := handler.jobManager.Get(mux.Vars(r)["jobId"])
+	handler.writeJobResp(w, err, job)
+}
+
+func (handler RestHandlerImpl) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobId := mux.Vars(r)["jobId"]
+	handler.logger.Infow("cancel job request", "jobId", jobId)
+	job, err := handler.jobManager.Cancel(jobId)
+	handler.writeJobResp(w, err, job)
+}
+
+func (handler RestHandlerImpl) writeJobResp(w http.ResponseWriter, err error, job *git.Job) {
+	switch {
+	case err == nil:
+		handler.writeJsonResp(w, nil, job, http.StatusOK)
+	case errors.Is(err, git.ErrJobNotFound):
+		handler.writeJsonResp(w, err, nil, http.StatusNotFound)
+	case errors.Is(err, git.ErrJobFinished):
+		handler.writeJsonResp(w, err, nil, http.StatusConflict)
+	case errors.Is(err, git.ErrJobQueueFull):
+		handler.writeJsonResp(w, err, nil, http.StatusTooManyRequests)
+	default:
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	}
+}
+
+// DownloadJobResult serves the file produced by a succeeded job, like the archive of an archive job
+func (handler RestHandlerImpl) DownloadJobResult(w http.ResponseWriter, r *http.Request) {
+	resultPath, err := handler.jobManager.GetResultPath(mux.Vars(r)["jobId"])
+	if err != nil {
+		handler.writeJobResp(w, err, nil)
+		return
+	}
+	fileName := filepath.Base(resultPath)
+	if contentType := git.GetArchiveFileContentType(fileName); len(contentType) > 0 {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	http.ServeFile(w, r, resultPath)
+}
+
+// WEBHOOK_MAX_PAYLOAD_SIZE is the largest payload github sends, larger webhooks are rejected before being read whole
+const WEBHOOK_MAX_PAYLOAD_SIZE = 25 << 20
+
+func (handler RestHandlerImpl) IngestWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, WEBHOOK_MAX_PAYLOAD_SIZE))
+	if err != nil {
+		handler.logger.Errorw("err in reading webhook payload", "provider", provider, "err", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handler.writeJsonResp(w, err, nil, http.StatusRequestEntityTooLarge)
+		} else {
+			handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		}
+		return
+	}
+	response, err := handler.webhookIngestionService.HandleWebhook(provider, r.Header, payload)
+	switch {
+	case err == nil:
+		handler.writeJsonResp(w, nil, response, http.StatusAccepted)
+	case errors.Is(err, git.ErrInvalidWebhookSignature):
+		handler.writeJsonResp(w, err, nil, http.StatusUnauthorized)
+	case errors.Is(err, git.ErrWebhookSecretNotConfigured):
+		handler.writeJsonResp(w, err, nil, http.StatusNotFound)
+	case errors.Is(err, git.ErrUnsupportedWebhookProvider):
+		handler.writeJsonResp(w, err, nil, http.StatusNotFound)
+	default:
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	}
+}
+
+// GetWebhookDeliveries lists the latest webhooks received, of the material when the materialId query param is set
+func (handler RestHandlerImpl) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	var materialId, limit int
+	var err error
+	if value := r.URL.Query().Get("materialId"); len(value) > 0 {
+		if materialId, err = strconv.Atoi(value); err != nil {
+			handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("limit"); len(value) > 0 {
+		if limit, err = strconv.Atoi(value); err != nil {
+			handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	deliveries, err := handler.webhookIngestionService.GetDeliveries(materialId, limit)
+	if err != nil {
+		handler.writeJsonResp(w, err, nil, http.StatusInternalServerError)
+	} else {
+		handler.writeJsonResp(w, nil, deliveries, http.StatusOK)
+	}
+}
+
+func (handler RestHandlerImpl) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryId, err := strconv.Atoi(mux.Vars(r)["deliveryId"])
+	if err != nil {
+		handler.logger.Error(err)
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.logger.Infow("replay webhook delivery request", "deliveryId", deliveryId)
+	response, err := handler.webhookIngestionService.ReplayDelivery(deliveryId)
+	switch {
+	case err == nil:
+		handler.writeJsonResp(w, nil, response, http.StatusAccepted)
+	case errors.Is(err, git.ErrWebhookDeliveryNotFound):
+		handler.writeJsonResp(w, err, nil, http.StatusNotFound)
+	default:
+		handler.writeJsonResp(w, err, nil, http.StatusBadRequest)
+	}
+}
+
 func (handler RestHandlerImpl) GetChangesInRelease(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	gitCtx := git.BuildGitContext(r.Context())
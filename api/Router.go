@@ -69,18 +69,47 @@ func (r MuxRouter) Init() {
 	r.Router.Path("/git-repo").HandlerFunc(r.restHandler.UpdateRepo).Methods("PUT")
 	r.Router.Path("/git-pipeline-material").HandlerFunc(r.restHandler.SavePipelineMaterial).Methods("POST")
 	r.Router.Path("/git-changes").HandlerFunc(r.restHandler.FetchChanges).Methods("POST")
+	r.Router.Path("/git-changes/refresh").HandlerFunc(r.restHandler.RefreshMaterial).Methods("POST")
 	r.Router.Path("/git-head").HandlerFunc(r.restHandler.GetHeadForPipelineMaterials).Methods("POST")
 	r.Router.Path("/commit-metadata").HandlerFunc(r.restHandler.GetCommitMetadata).Methods("POST")
+	r.Router.Path("/commits-metadata").HandlerFunc(r.restHandler.GetCommitsMetadata).Methods("POST")
 	r.Router.Path("/pipeline-material-commit-metadata").HandlerFunc(r.restHandler.GetCommitMetadataForPipelineMaterial).Methods("GET")
 	r.Router.Path("/tag-commit-metadata").HandlerFunc(r.restHandler.GetCommitInfoForTag).Methods("POST")
+	r.Router.Path("/file-content").HandlerFunc(r.restHandler.GetFileContentAtCommit).Methods("POST")
+	r.Router.Path("/archive").HandlerFunc(r.restHandler.ExportArchive).Methods("POST")
+	r.Router.Path("/commit-diff").HandlerFunc(r.restHandler.GetDiffBetweenCommits).Methods("POST")
+	r.Router.Path("/blame").HandlerFunc(r.restHandler.GetBlame).Methods("POST")
+	r.Router.Path("/lfs-pointers").HandlerFunc(r.restHandler.GetLfsPointers).Methods("POST")
+	r.Router.Path("/merge-base").HandlerFunc(r.restHandler.GetMergeBase).Methods("POST")
+	r.Router.Path("/is-ancestor").HandlerFunc(r.restHandler.IsAncestor).Methods("POST")
+	r.Router.Path("/commit-history").HandlerFunc(r.restHandler.GetCommitHistory).Methods("POST")
+	r.Router.Path("/commits-since").HandlerFunc(r.restHandler.GetCommitsSince).Methods("POST")
+	r.Router.Path("/commits/search").HandlerFunc(r.restHandler.SearchCommits).Methods("POST")
+	r.Router.Path("/jobs/clone").HandlerFunc(r.restHandler.StartCloneJob).Methods("POST")
+	r.Router.Path("/jobs/deepen").HandlerFunc(r.restHandler.StartDeepenJob).Methods("POST")
+	r.Router.Path("/jobs/archive").HandlerFunc(r.restHandler.StartArchiveJob).Methods("POST")
+	r.Router.Path("/jobs/{jobId}").HandlerFunc(r.restHandler.GetJob).Methods("GET")
+	r.Router.Path("/jobs/{jobId}/cancel").HandlerFunc(r.restHandler.CancelJob).Methods("POST")
+	r.Router.Path("/jobs/{jobId}/result").HandlerFunc(r.restHandler.DownloadJobResult).Methods("GET")
+	r.Router.Path("/git-repo/branches/{materialId}").HandlerFunc(r.restHandler.GetMaterialBranches).Methods("GET")
 	r.Router.Path("/git-repo/refresh").HandlerFunc(r.restHandler.RefreshGitMaterial).Methods("POST")
+	r.Router.Path("/git-repo/validate").HandlerFunc(r.restHandler.ValidateMaterial).Methods("POST")
 
 	r.Router.Path("/admin/reload-all").HandlerFunc(r.restHandler.ReloadAllMaterial).Methods("POST")
 	r.Router.Path("/admin/reload/{materialId}").HandlerFunc(r.restHandler.ReloadMaterial).Methods("POST")
+	r.Router.Path("/admin/diagnostics/{materialId}").HandlerFunc(r.restHandler.GetMaterialDiagnostics).Methods("GET")
+	r.Router.Path("/admin/repo-metrics/{materialId}").HandlerFunc(r.restHandler.GetRepoMetrics).Methods("GET")
+	r.Router.Path("/admin/git-config/{materialId}").HandlerFunc(r.restHandler.GetGitConfig).Methods("GET")
+	r.Router.Path("/admin/git-config/{materialId}").HandlerFunc(r.restHandler.UpdateGitConfig).Methods("POST")
 	r.Router.Path("/admin/reload-multi/materials").HandlerFunc(r.restHandler.ReloadMaterials).Methods("POST")
 
 	r.Router.Path("/release/changes").HandlerFunc(r.restHandler.GetChangesInRelease).Methods("POST")
+	r.Router.Path("/release/changelog").HandlerFunc(r.restHandler.GenerateChangeLog).Methods("POST")
+	r.Router.Path("/release/next-version").HandlerFunc(r.restHandler.SuggestNextVersion).Methods("POST")
 
+	r.Router.Path("/webhook/ingest/{provider}").HandlerFunc(r.restHandler.IngestWebhook).Methods("POST")
+	r.Router.Path("/webhook/deliveries").HandlerFunc(r.restHandler.GetWebhookDeliveries).Methods("GET")
+	r.Router.Path("/webhook/deliveries/{deliveryId}/replay").HandlerFunc(r.restHandler.ReplayWebhookDelivery).Methods("POST")
 	r.Router.Path("/webhook/data").HandlerFunc(r.restHandler.GetWebhookData).Methods("GET")
 	r.Router.Path("/webhook/host/events").HandlerFunc(r.restHandler.GetAllWebhookEventConfigForHost).Methods("GET")
 	r.Router.Path("/webhook/host/event").HandlerFunc(r.restHandler.GetWebhookEventConfig).Methods("GET")
@@ -25,8 +25,12 @@ import (
 	"github.com/devtron-labs/common-lib/pubsub-lib/metrics"
 	"github.com/devtron-labs/git-sensor/api"
 	"github.com/devtron-labs/git-sensor/bean"
+	"github.com/devtron-labs/git-sensor/internals"
 	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
 	"github.com/devtron-labs/git-sensor/pkg/git"
+	"github.com/devtron-labs/git-sensor/protos/gitSensorApi"
+	"github.com/devtron-labs/git-sensor/protos/gitSensorWatch"
 	pb "github.com/devtron-labs/protos/gitSensor"
 	"github.com/go-pg/pg"
 	"github.com/gorilla/handlers"
@@ -54,10 +58,16 @@ type App struct {
 	db                 *pg.DB
 	pubSubClient       *pubsub.PubSubClientServiceImpl
 	GrpcControllerImpl *api.GrpcHandlerImpl
+	grpcApiHandler     *api.GrpcApiHandlerImpl
+	configuration      *internals.Configuration
 	StartupConfig      *bean.StartupConfig
+	storageManager     git.StorageManager
+	eventPublisher     git.CommitEventPublisher
+	webhookIngestion   git.WebhookIngestionService
+	shutdownTracing    func(ctx context.Context) error
 }
 
-func NewApp(MuxRouter *api.MuxRouter, Logger *zap.SugaredLogger, impl *git.GitWatcherImpl, db *pg.DB, pubSubClient *pubsub.PubSubClientServiceImpl, GrpcControllerImpl *api.GrpcHandlerImpl) *App {
+func NewApp(MuxRouter *api.MuxRouter, Logger *zap.SugaredLogger, impl *git.GitWatcherImpl, db *pg.DB, pubSubClient *pubsub.PubSubClientServiceImpl, GrpcControllerImpl *api.GrpcHandlerImpl, grpcApiHandler *api.GrpcApiHandlerImpl, storageManager git.StorageManager, eventPublisher git.CommitEventPublisher, webhookIngestion git.WebhookIngestionService, configuration *internals.Configuration) *App {
 	return &App{
 		MuxRouter:          MuxRouter,
 		Logger:             Logger,
@@ -65,6 +75,11 @@ func NewApp(MuxRouter *api.MuxRouter, Logger *zap.SugaredLogger, impl *git.GitWa
 		db:                 db,
 		pubSubClient:       pubSubClient,
 		GrpcControllerImpl: GrpcControllerImpl,
+		grpcApiHandler:     grpcApiHandler,
+		storageManager:     storageManager,
+		eventPublisher:     eventPublisher,
+		webhookIngestion:   webhookIngestion,
+		configuration:      configuration,
 	}
 }
 
@@ -86,6 +101,11 @@ func (app *App) Start() {
 		app.Logger.Errorw("failed to parse configuration")
 		os.Exit(2)
 	}
+	app.shutdownTracing, err = tracing.Init(app.StartupConfig.OtelCollectorUrl)
+	if err != nil {
+		app.Logger.Errorw("error in initializing tracing", "err", err)
+		os.Exit(2)
+	}
 
 	go func() {
 		// Start REST server
@@ -113,6 +133,7 @@ func (app *App) initRestServer(port int) error {
 
 	app.restServer = &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: h}
 	app.MuxRouter.Router.Use(middleware.PrometheusMiddleware)
+	app.MuxRouter.Router.Use(tracing.HttpMiddleware)
 
 	return app.restServer.ListenAndServe()
 }
@@ -139,9 +160,12 @@ func (app *App) initGrpcServer(port int) error {
 		}),
 		grpc.ChainStreamInterceptor(
 			grpc_prometheus.StreamServerInterceptor,
+			tracing.StreamServerInterceptor,
 			recovery.StreamServerInterceptor(recoveryOption)), // panic interceptor, should be at last
 		grpc.ChainUnaryInterceptor(
 			grpc_prometheus.UnaryServerInterceptor,
+			tracing.UnaryServerInterceptor,
+			api.GitErrorUnaryServerInterceptor,
 			recovery.UnaryServerInterceptor(recoveryOption)), // panic interceptor, should be at last
 	}
 	// create a new gRPC grpcServer
@@ -149,6 +173,10 @@ func (app *App) initGrpcServer(port int) error {
 
 	// register GitSensor service
 	pb.RegisterGitSensorServiceServer(app.grpcServer, app.GrpcControllerImpl)
+	gitSensorWatch.RegisterGitSensorWatchServiceServer(app.grpcServer, app.GrpcControllerImpl)
+	app.grpcServer.RegisterService(&api.GitSensorArchiveServiceDesc, app.GrpcControllerImpl)
+	app.grpcServer.RegisterService(&api.GitSensorRefreshServiceDesc, app.GrpcControllerImpl)
+	gitSensorApi.RegisterGitSensorApiServiceServer(app.grpcServer, app.grpcApiHandler)
 	grpc_prometheus.Register(app.grpcServer)
 	grpc_prometheus.EnableHandlingTimeHistogram()
 
@@ -164,11 +192,33 @@ func (app *App) initGrpcServer(port int) error {
 func (app *App) Stop() {
 	app.Logger.Infow("orchestrator shutdown initiating")
 
-	app.Logger.Infow("stopping cron")
-	app.watcher.StopCron()
-
-	app.Logger.Infow("gracefully stopping GitSensor")
-	app.grpcServer.GracefulStop()
+	drained := make(chan struct{})
+	go func() {
+		// no new polls are scheduled after this, the running ones are waited for
+		app.Logger.Infow("stopping cron")
+		app.watcher.StopCron()
+		app.storageManager.StopCron()
+		// pending events are left in the outbox and published after the restart
+		app.eventPublisher.StopCron()
+		app.webhookIngestion.StopCron()
+
+		app.Logger.Infow("gracefully stopping GitSensor")
+		app.grpcServer.GracefulStop()
+		close(drained)
+	}()
+	drainTimeout := time.Duration(app.configuration.ShutdownDrainTimeoutInSec) * time.Second
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		app.Logger.Warnw("running operations not finished in drain timeout, killing git commands", "drainTimeout", drainTimeout)
+		git.KillChildProcesses()
+		app.grpcServer.Stop()
+		select {
+		case <-drained:
+		case <-time.After(2 * git.PROCESS_WAIT_DELAY):
+			app.Logger.Errorw("running operations not finished after killing git commands")
+		}
+	}
 
 	timeoutContext, _ := context.WithTimeout(context.Background(), 5*time.Second)
 	app.Logger.Infow("closing router")
@@ -177,6 +227,14 @@ func (app *App) Stop() {
 		app.Logger.Errorw("error in mux router shutdown", "err", err)
 	}
 
+	if app.shutdownTracing != nil {
+		app.Logger.Infow("flushing traces")
+		err = app.shutdownTracing(timeoutContext)
+		if err != nil {
+			app.Logger.Errorw("error in flushing traces", "err", err)
+		}
+	}
+
 	app.Logger.Infow("closing db connection")
 	err = app.db.Close()
 	if err != nil {
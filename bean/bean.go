@@ -21,6 +21,8 @@ import "fmt"
 type StartupConfig struct {
 	RestPort int `env:"SERVER_REST_PORT" envDefault:"8080"`
 	GrpcPort int `env:"SERVER_GRPC_PORT" envDefault:"8081"`
+	// OtelCollectorUrl is the otlp http endpoint the traces are sent to, tracing is disabled when empty
+	OtelCollectorUrl string `env:"OTEL_COLLECTOR_URL" envDefault:""`
 }
 
 const ReloadAllLogPrefix = "RELOAD_ALL_LOG"
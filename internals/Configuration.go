@@ -19,16 +19,105 @@ package internals
 import "github.com/caarlos0/env"
 
 type Configuration struct {
-	CommitStatsTimeoutInSec int    `env:"COMMIT_STATS_TIMEOUT_IN_SEC" envDefault:"2"`
-	EnableFileStats         bool   `env:"ENABLE_FILE_STATS" envDefault:"false"`
-	GitHistoryCount         int    `env:"GIT_HISTORY_COUNT" envDefault:"15"`
-	MinLimit                int    `env:"MIN_LIMIT_FOR_PVC" envDefault:"1"` // in MB
-	UseGitCli               bool   `env:"USE_GIT_CLI" envDefault:"false"`
-	UseGitCliAnalytics      bool   `env:"USE_GIT_CLI_ANALYTICS" envDefault:"false"` // This flag is used to compute commitDiff using git-cli only for analytics
-	AnalyticsDebug          bool   `env:"ANALYTICS_DEBUG" envDefault:"false"`
-	CliCmdTimeoutGlobal     int    `env:"CLI_CMD_TIMEOUT_GLOBAL_SECONDS" envDefault:"0"`
-	CliCmdTimeoutJson       string `env:"CLI_CMD_TIMEOUT_JSON" envDefault:""`
-	GoGitTimeout            int    `env:"GOGIT_TIMEOUT_SECONDS" envDefault:"10" `
+	CommitStatsTimeoutInSec       int    `env:"COMMIT_STATS_TIMEOUT_IN_SEC" envDefault:"2"`
+	EnableFileStats               bool   `env:"ENABLE_FILE_STATS" envDefault:"false"`
+	CommitStatsCacheSize          int    `env:"COMMIT_STATS_CACHE_SIZE" envDefault:"1000"`     // commits whose file stats are cached, 0 to disable the cache
+	CommitStatsCacheTtlInMin      int    `env:"COMMIT_STATS_CACHE_TTL_IN_MIN" envDefault:"60"` // 0 to keep the stats until evicted
+	EnableChangedFiles            bool   `env:"ENABLE_CHANGED_FILES" envDefault:"false"`       // list the changed files with change type and line stats on each commit
+	GitHistoryCount               int    `env:"GIT_HISTORY_COUNT" envDefault:"15"`
+	MinLimit                      int    `env:"MIN_LIMIT_FOR_PVC" envDefault:"1"` // in MB
+	UseGitCli                     bool   `env:"USE_GIT_CLI" envDefault:"false"`
+	UseGitCliAnalytics            bool   `env:"USE_GIT_CLI_ANALYTICS" envDefault:"false"` // This flag is used to compute commitDiff using git-cli only for analytics
+	UseGoGitFetch                 bool   `env:"USE_GO_GIT_FETCH" envDefault:"false"`      // fetch using go-git instead of git cli, applicable only when USE_GIT_CLI is false
+	AnalyticsDebug                bool   `env:"ANALYTICS_DEBUG" envDefault:"false"`
+	CliCmdTimeoutGlobal           int    `env:"CLI_CMD_TIMEOUT_GLOBAL_SECONDS" envDefault:"0"`
+	CliCmdTimeoutJson             string `env:"CLI_CMD_TIMEOUT_JSON" envDefault:""`
+	CliCloneTimeout               int    `env:"CLI_CLONE_TIMEOUT_SECONDS" envDefault:"0"` // timeout of the first fetch of a repo, 0 to use the global timeout
+	CliFetchTimeout               int    `env:"CLI_FETCH_TIMEOUT_SECONDS" envDefault:"0"` // timeout of fetch and ls-remote, 0 to use the global timeout
+	CliLogTimeout                 int    `env:"CLI_LOG_TIMEOUT_SECONDS" envDefault:"0"`   // timeout of log, rev-list, show and diff, 0 to use the global timeout
+	GoGitTimeout                  int    `env:"GOGIT_TIMEOUT_SECONDS" envDefault:"10" `
+	ShallowCloneDepth             int    `env:"SHALLOW_CLONE_DEPTH" envDefault:"0"`                  // commits fetched per branch on the first fetch of a repo, 0 for full clone. applicable only when USE_GIT_CLI is true
+	ShallowDeepenBy               int    `env:"SHALLOW_DEEPEN_BY" envDefault:"50"`                   // commits fetched at a time when requested commits fall outside the shallow boundary
+	PartialCloneFilter            string `env:"PARTIAL_CLONE_FILTER" envDefault:""`                  // e.g. blob:none, blobs are then fetched on demand. applicable only when USE_GIT_CLI is true
+	ResolveSubmoduleChanges       bool   `env:"RESOLVE_SUBMODULE_CHANGES" envDefault:"false"`        // report submodule pointer changes with each commit
+	FetchSubmoduleCommits         bool   `env:"FETCH_SUBMODULE_COMMITS" envDefault:"false"`          // fetch submodules to report their commits, needs RESOLVE_SUBMODULE_CHANGES
+	VerifyCommitSignatures        bool   `env:"VERIFY_COMMIT_SIGNATURES" envDefault:"false"`         // report gpg/ssh signature verification status with each commit
+	GpgHomeDir                    string `env:"GNUPG_HOME_DIR" envDefault:""`                        // gpg keyring used to verify gpg signed commits
+	SshAllowedSignersFile         string `env:"SSH_ALLOWED_SIGNERS_FILE" envDefault:""`              // allowed signers file used to verify ssh signed commits
+	PathFilteredPolling           bool   `env:"PATH_FILTERED_POLLING" envDefault:"false"`            // poll only commits touching the paths in the filter pattern of the git material
+	EnableTagPolling              bool   `env:"ENABLE_TAG_POLLING" envDefault:"false"`               // poll SOURCE_TYPE_TAG_ANY materials for new or moved tags matching the glob or semver constraint in their value
+	EnableBranchRegexPolling      bool   `env:"ENABLE_BRANCH_REGEX_POLLING" envDefault:"false"`      // track all branches matching the regex of SOURCE_TYPE_BRANCH_REGEX materials
+	FileContentMaxSizeInBytes     int64  `env:"FILE_CONTENT_MAX_SIZE_IN_BYTES" envDefault:"1048576"` // files larger than this are not returned by the file content api, 0 for no limit
+	StorageReconcileIntervalInMin int    `env:"STORAGE_RECONCILE_INTERVAL_IN_MIN" envDefault:"0"`    // interval of gc and eviction of checkouts, 0 to disable
+	CheckoutSizeIntervalInMin     int    `env:"CHECKOUT_SIZE_INTERVAL_IN_MIN" envDefault:"15"`       // interval of the measure of the checkout sizes for the metrics, 0 to disable
+	DiskQuotaInMB                 int64  `env:"DISK_QUOTA_IN_MB" envDefault:"0"`                     // least recently polled checkouts are evicted above this usage, 0 for no quota
+	WebhookSecret                 string `env:"WEBHOOK_SECRET" envDefault:""`                        // secret used to validate push webhooks of github, gitlab and bitbucket, the ingestion endpoint is disabled when empty
+	PrWebhookSourceBranchRegex    string `env:"PR_WEBHOOK_SOURCE_BRANCH_REGEX" envDefault:""`        // only pull requests from branches matching the regex are sent to ci
+	PrWebhookTargetBranchRegex    string `env:"PR_WEBHOOK_TARGET_BRANCH_REGEX" envDefault:""`        // only pull requests into branches matching the regex are sent to ci
+	PrWebhookLabels               string `env:"PR_WEBHOOK_LABELS" envDefault:""`                     // comma separated labels, when set only pull requests with one of them are sent to ci
+	PrWebhookAllowDraft           bool   `env:"PR_WEBHOOK_ALLOW_DRAFT" envDefault:"false"`           // send draft pull requests to ci
+	WebhookRetentionInDays        int    `env:"WEBHOOK_RETENTION_IN_DAYS" envDefault:"7"`            // received webhooks are kept for it to be listed and replayed, 0 to keep them forever
+	PersistCommitHistory          bool   `env:"PERSIST_COMMIT_HISTORY" envDefault:"false"`           // save the commits found by the watcher in the git_commit table and serve the commit history api from it
+	SshHostKeyChecking            string `env:"SSH_HOST_KEY_CHECKING" envDefault:"no"`               // host key verification of ssh remotes, one of no, accept-new and strict
+	SshKnownHosts                 string `env:"SSH_KNOWN_HOSTS" envDefault:""`                       // known_hosts entries, one per line, added to the managed known hosts file
+	GitHttpProxy                  string `env:"GIT_HTTP_PROXY" envDefault:""`                        // proxy for http(s) remotes, overridden by the proxy of the material
+	GitNoProxy                    string `env:"GIT_NO_PROXY" envDefault:""`                          // comma separated hosts and domain suffixes reached without the proxy
+	CommitIncludeMessageRegex     string `env:"COMMIT_INCLUDE_MESSAGE_REGEX" envDefault:""`          // only commits with a message matching the regex trigger ci
+	CommitExcludeMessageRegex     string `env:"COMMIT_EXCLUDE_MESSAGE_REGEX" envDefault:""`          // commits with a message matching the regex don't trigger ci
+	CommitIncludeAuthorRegex      string `env:"COMMIT_INCLUDE_AUTHOR_REGEX" envDefault:""`           // only commits with an author matching the regex trigger ci
+	CommitExcludeAuthorRegex      string `env:"COMMIT_EXCLUDE_AUTHOR_REGEX" envDefault:""`           // commits with an author matching the regex don't trigger ci
+	HonorSkipCiMarkers            bool   `env:"HONOR_SKIP_CI_MARKERS" envDefault:"false"`            // commits with [skip ci] or [ci skip] in the message don't trigger ci
+	RecoverCorruptedCheckouts     bool   `env:"RECOVER_CORRUPTED_CHECKOUTS" envDefault:"true"`       // clone a corrupted checkout again instead of failing every poll
+	GitLfsSkipSmudge              bool   `env:"GIT_LFS_SKIP_SMUDGE" envDefault:"false"`              // git lfs keeps the pointer files instead of downloading the objects
+	ProviderApiTimeoutInSec       int    `env:"PROVIDER_API_TIMEOUT_IN_SEC" envDefault:"30"`         // timeout of the calls to the provider api of the materials in api mode
+	ProviderApiMaxWaitSec         int    `env:"PROVIDER_API_MAX_WAIT_SEC" envDefault:"60"`           // wait for the rate limit of the provider api to reset if it is within this, else the poll fails
+	FetchGerritChangeRefs         bool   `env:"FETCH_GERRIT_CHANGE_REFS" envDefault:"false"`         // fetch the refs/changes/* patch set refs of gerrit as changes/* branches of origin
+	DiffMaxSizeInBytes            int64  `env:"DIFF_MAX_SIZE_IN_BYTES" envDefault:"1048576"`         // output of the commit diff api beyond this is left out and the diff marked truncated, 0 for no limit
+	RefreshMaterialTimeoutInSec   int    `env:"REFRESH_MATERIAL_TIMEOUT_IN_SEC" envDefault:"60"`     // wait of the refresh material api for the poll to finish, it goes on in the background after this. 0 to wait till it finishes
+	FirstParentPolling            bool   `env:"FIRST_PARENT_POLLING" envDefault:"false"`             // poll only the first parent chain of branches, a merge triggers ci once instead of once per merged commit
+	MailmapFile                   string `env:"MAILMAP_FILE" envDefault:""`                          // mailmap of canonical author names and emails, applied over the .mailmap of the repo
+	HostRateLimitPerMin           int    `env:"HOST_RATE_LIMIT_PER_MIN" envDefault:"0"`              // fetches per minute to each git host, 0 for no limit
+	HostRateLimitBurst            int    `env:"HOST_RATE_LIMIT_BURST" envDefault:"10"`               // fetches to a git host allowed at once above its rate limit
+	HostCircuitFailureThreshold   int    `env:"HOST_CIRCUIT_FAILURE_THRESHOLD" envDefault:"0"`       // consecutive failed fetches of a git host to pause its fetches, 0 to never pause
+	HostCircuitCooldownInSec      int    `env:"HOST_CIRCUIT_COOLDOWN_IN_SEC" envDefault:"300"`       // pause of the fetches of a git host after repeated failures, a single fetch probes it after
+	DeterministicGitConfig        bool   `env:"DETERMINISTIC_GIT_CONFIG" envDefault:"true"`          // pass core.autocrlf=false and core.longpaths=true to every git command, regardless of the global git config of the image
+	GitSafeDirectory              string `env:"GIT_SAFE_DIRECTORY" envDefault:""`                    // safe.directory passed to every git command, e.g. * to accept the checkouts owned by another user. not passed when empty
+	CommitPolicyMode              string `env:"COMMIT_POLICY_MODE" envDefault:"annotate"`            // annotate only reports the policy verdict with the commits, exclude also keeps denied commits from triggering ci
+	CommitPolicyAllowedDomains    string `env:"COMMIT_POLICY_ALLOWED_DOMAINS" envDefault:""`         // comma separated email domains, commits of authors outside them and their sub domains are denied
+	CommitPolicyDenyAuthorRegex   string `env:"COMMIT_POLICY_DENY_AUTHOR_REGEX" envDefault:""`       // commits with an author matching the regex are denied
+	CommitPolicyDenyEmptyMessage  bool   `env:"COMMIT_POLICY_DENY_EMPTY_MESSAGE" envDefault:"false"` // commits with an empty message are denied
+	CommitPolicyDenyForcePush     bool   `env:"COMMIT_POLICY_DENY_FORCE_PUSH" envDefault:"false"`    // commits which rewrote the history of the branch with a force push are denied
+	SharedWorktreeCheckouts       bool   `env:"SHARED_WORKTREE_CHECKOUTS" envDefault:"false"`        // materials of the same url are checked out as worktrees of a single shared clone, applicable only when USE_GIT_CLI is true
+	ShutdownDrainTimeoutInSec     int    `env:"SHUTDOWN_DRAIN_TIMEOUT_IN_SEC" envDefault:"25"`       // wait of the shutdown for the running polls and requests, the git commands still running after it are killed
+	CommitTicketPatterns          string `env:"COMMIT_TICKET_PATTERNS" envDefault:""`                // json of tracker name to regex of the ticket ids annotated on the commits, e.g. {"jira": "\\b[A-Z][A-Z0-9]+-[0-9]+\\b"}
+	LargeFileThresholdInKb        int    `env:"LARGE_FILE_THRESHOLD_IN_KB" envDefault:"0"`           // files of the commit stats above it are flagged as large, 0 to disable
+	CommitPolicyDenyBinary        bool   `env:"COMMIT_POLICY_DENY_BINARY" envDefault:"false"`        // commits changing binary files are denied by the commit policy
+	MaxConcurrentJobs             int    `env:"MAX_CONCURRENT_JOBS" envDefault:"2"`                  // background jobs like clones and archive exports run at a time, the others wait in queue
+	MaxQueuedJobs                 int    `env:"MAX_QUEUED_JOBS" envDefault:"20"`                     // background jobs waiting for a slot, more are rejected
+	JobRetentionInMin             int    `env:"JOB_RETENTION_IN_MIN" envDefault:"60"`                // finished background jobs and their results are kept for it
+	ProtectedBranchCacheSec       int    `env:"PROTECTED_BRANCH_CACHE_SEC" envDefault:"300"`         // protected branches of the repos are fetched from the provider api at most once in it
+	GitBinaryPath                 string `env:"GIT_BINARY_PATH" envDefault:""`                       // git executable the cli commands run with, the one in PATH when not set
+	MinGitVersion                 string `env:"MIN_GIT_VERSION" envDefault:"2.20.0"`                 // git-sensor refuses to start with an older git
+	CredentialsDir                string `env:"CREDENTIALS_DIR" envDefault:""`                       // secrets of the file credential provider are read from its sub directories
+	CredentialCacheSec            int    `env:"CREDENTIAL_CACHE_SEC" envDefault:"60"`                // credentials of the secret backends are kept in memory for it
+	CredentialK8sNamespaces       string `env:"CREDENTIAL_K8S_NAMESPACES" envDefault:""`             // comma separated namespaces the kubernetes credential refs may read secrets of besides the one of git-sensor
+	VaultAddr                     string `env:"VAULT_ADDR" envDefault:""`                            // address of vault for the vault credential provider
+	VaultToken                    string `env:"VAULT_TOKEN" envDefault:""`                           // token of vault, the kubernetes auth of VAULT_K8S_ROLE is used when not set
+	VaultKubernetesRole           string `env:"VAULT_K8S_ROLE" envDefault:""`                        // role of the kubernetes auth method of vault
+	VaultKvMount                  string `env:"VAULT_KV_MOUNT" envDefault:"secret"`                  // mount of the kv v2 secrets engine the vault credentials are read from
+	GitRetryMaxAttempts           int    `env:"GIT_RETRY_MAX_ATTEMPTS" envDefault:"3"`               // attempts of a fetch, ls-remote or clone failing with a transient network error, 1 to not retry
+	GitRetryBackoffInMs           int    `env:"GIT_RETRY_BACKOFF_IN_MS" envDefault:"500"`            // backoff before the first retry, doubled for each next one
+	GitRetryMaxBackoffInSec       int    `env:"GIT_RETRY_MAX_BACKOFF_IN_SEC" envDefault:"30"`        // cap of the backoff between retries
+	GitRetryDeadlineInSec         int    `env:"GIT_RETRY_DEADLINE_IN_SEC" envDefault:"300"`          // time since the first attempt after which an operation is not retried, 0 for no limit
+	FetchGitNotes                 bool   `env:"FETCH_GIT_NOTES" envDefault:"false"`                  // fetch refs/notes/* and report the notes of the commits
+	CommitEventBus                string `env:"COMMIT_EVENT_BUS" envDefault:""`                      // event bus the new commits, tags and pull requests are published to, nats or kafka. empty to not publish
+	CommitEventTopic              string `env:"COMMIT_EVENT_TOPIC" envDefault:"git-sensor.commits"`  // topic of the commit events of the materials without a topic of their own
+	KafkaRestProxyUrl             string `env:"KAFKA_REST_PROXY_URL" envDefault:""`                  // kafka rest proxy the commit events are produced through
+	CommitEventPublishIntervalSec int    `env:"COMMIT_EVENT_PUBLISH_INTERVAL_SEC" envDefault:"5"`    // interval of publishing the events of the outbox
+	CommitEventBatchSize          int    `env:"COMMIT_EVENT_BATCH_SIZE" envDefault:"100"`            // events published in one run of the outbox
+	CommitEventMaxAttempts        int    `env:"COMMIT_EVENT_MAX_ATTEMPTS" envDefault:"10"`           // failed publishes of an event before it is dead lettered in the outbox, 0 to retry it forever
+	UseBareRepo                   bool   `env:"USE_BARE_REPO" envDefault:"false"`                    // new checkouts are created as bare repos without a working tree, applicable only when USE_GIT_CLI is true
+	UseStreamingGitLog            bool   `env:"USE_STREAMING_GIT_LOG" envDefault:"false"`            // parse git log output as it is read instead of loading all commits in memory, applicable only when USE_GIT_CLI is true
 }
 
 func ParseConfiguration() (*Configuration, error) {
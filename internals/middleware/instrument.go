@@ -125,3 +125,67 @@ var CommitStatParsingErrorCounter = promauto.NewCounterVec(
 		ConstLabels: constLabels,
 	},
 	[]string{})
+
+var PollQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name:        "git_poll_queue_depth",
+	Help:        "no of git repositories waiting for a poll worker",
+	ConstLabels: constLabels,
+}, []string{})
+
+var PollRunningCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name:        "git_poll_running_count",
+	Help:        "no of git repositories being polled",
+	ConstLabels: constLabels,
+}, []string{})
+
+var GitStorageUsage = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name:        "git_storage_usage_bytes",
+	Help:        "disk used by the git checkouts as of the last storage reconcile or measure",
+	ConstLabels: constLabels,
+}, []string{})
+
+var GitStorageReclaimedBytes = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "git_storage_reclaimed_bytes",
+		Help:        "disk reclaimed by gc, eviction of checkouts and removal of checkouts of deleted materials",
+		ConstLabels: constLabels,
+	},
+	[]string{"reason"})
+
+var GitFetchCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "git_fetch_total",
+		Help:        "no of fetches of the git materials by the watcher, partitioned by material and status",
+		ConstLabels: constLabels,
+	},
+	[]string{"gitMaterialId", "status"})
+
+var GitCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:        "git_command_duration_seconds",
+	Help:        "Duration of git cli commands, partitioned by sub command and status",
+	ConstLabels: constLabels,
+}, []string{"command", "status"})
+
+var GitCheckoutSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name:        "git_checkout_size_bytes",
+	Help:        "disk used by the checkout of the git material as of the last storage reconcile or measure",
+	ConstLabels: constLabels,
+}, []string{"gitMaterialId"})
+
+var CommitStatsCacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name:        "commit_stats_cache_total",
+	Help:        "lookups of commit file stats by result, hit or miss",
+	ConstLabels: constLabels,
+}, []string{"result"})
+
+var GitRetryCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name:        "git_retry_total",
+	Help:        "retries of remote git operations after transient network errors, partitioned by operation",
+	ConstLabels: constLabels,
+}, []string{"operation"})
+
+var CommitEventPublishCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name:        "commit_event_publish_total",
+	Help:        "commit events published from the outbox to the event bus, partitioned by event type and result",
+	ConstLabels: constLabels,
+}, []string{"eventType", "result"})
@@ -15,3 +15,133 @@
  */
 
 package queueManager
+
+import (
+	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// LocalWorker runs tasks keyed by repository on a fixed number of goroutines. a repository is queued at most once
+// and never runs on two workers at a time, tasks are picked in submission order skipping the repositories already running,
+// so a slow or frequently submitted repository can't hold up the others
+type LocalWorker struct {
+	logger  *zap.SugaredLogger
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	queue   []*repositoryTask
+	queued  map[int]bool
+	running map[int]bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+type repositoryTask struct {
+	repositoryId int
+	run          func()
+}
+
+func NewLocalWorker(logger *zap.SugaredLogger, workerCount int) *LocalWorker {
+	impl := &LocalWorker{
+		logger:  logger,
+		queued:  make(map[int]bool),
+		running: make(map[int]bool),
+	}
+	impl.cond = sync.NewCond(&impl.mutex)
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	for i := 0; i < workerCount; i++ {
+		impl.wg.Add(1)
+		go impl.work()
+	}
+	return impl
+}
+
+// Submit queues the task for the repository, it returns false if the repository is already queued or the worker is stopped
+func (impl *LocalWorker) Submit(repositoryId int, run func()) bool {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if impl.stopped || impl.queued[repositoryId] {
+		return false
+	}
+	impl.queue = append(impl.queue, &repositoryTask{repositoryId: repositoryId, run: run})
+	impl.queued[repositoryId] = true
+	impl.updateMetrics()
+	impl.cond.Broadcast()
+	return true
+}
+
+// QueueDepth returns the number of tasks waiting for a worker
+func (impl *LocalWorker) QueueDepth() int {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	return len(impl.queue)
+}
+
+// Stop drops the queued tasks and waits for the running ones to finish
+func (impl *LocalWorker) Stop() {
+	impl.mutex.Lock()
+	impl.stopped = true
+	impl.logger.Infow("stopping local worker", "droppedTasks", len(impl.queue), "runningTasks", len(impl.running))
+	impl.queue = nil
+	impl.queued = make(map[int]bool)
+	impl.updateMetrics()
+	impl.cond.Broadcast()
+	impl.mutex.Unlock()
+	impl.wg.Wait()
+}
+
+func (impl *LocalWorker) work() {
+	defer impl.wg.Done()
+	for {
+		task, ok := impl.next()
+		if !ok {
+			return
+		}
+		impl.runTask(task)
+	}
+}
+
+// next blocks till a task of a repository which is not running is available
+func (impl *LocalWorker) next() (*repositoryTask, bool) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	for {
+		if impl.stopped {
+			return nil, false
+		}
+		for i, task := range impl.queue {
+			if impl.running[task.repositoryId] {
+				continue
+			}
+			impl.queue = append(impl.queue[:i], impl.queue[i+1:]...)
+			delete(impl.queued, task.repositoryId)
+			impl.running[task.repositoryId] = true
+			impl.updateMetrics()
+			return task, true
+		}
+		impl.cond.Wait()
+	}
+}
+
+func (impl *LocalWorker) runTask(task *repositoryTask) {
+	defer func() {
+		if err := recover(); err != nil {
+			impl.logger.Errorw("recovered from panic in task", "repositoryId", task.repositoryId, "panic", err)
+		}
+		impl.mutex.Lock()
+		delete(impl.running, task.repositoryId)
+		impl.updateMetrics()
+		// a task of this repository may be waiting for it to finish
+		impl.cond.Broadcast()
+		impl.mutex.Unlock()
+	}()
+	task.run()
+}
+
+// updateMetrics must be called with the mutex held
+func (impl *LocalWorker) updateMetrics() {
+	middleware.PollQueueDepth.WithLabelValues().Set(float64(len(impl.queue)))
+	middleware.PollRunningCount.WithLabelValues().Set(float64(len(impl.running)))
+}
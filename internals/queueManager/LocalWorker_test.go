@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package queueManager
+
+import (
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLocalWorker(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	worker := NewLocalWorker(logger, 4)
+
+	block := make(chan struct{})
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	runRepo1 := func() {
+		defer wg.Done()
+		current := atomic.AddInt32(&running, 1)
+		if current > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, current)
+		}
+		<-block
+		atomic.AddInt32(&running, -1)
+	}
+	wg.Add(2)
+	assert.True(t, worker.Submit(1, runRepo1))
+	// wait till the first task is picked so that the next one is queued behind it
+	for worker.QueueDepth() > 0 {
+	}
+	assert.True(t, worker.Submit(1, runRepo1))
+	assert.False(t, worker.Submit(1, runRepo1), "repository already queued")
+
+	// other repositories are not held up by the running one
+	done := make(chan struct{})
+	wg.Add(1)
+	assert.True(t, worker.Submit(2, func() {
+		defer wg.Done()
+		close(done)
+	}))
+	<-done
+	assert.Equal(t, 1, worker.QueueDepth())
+
+	close(block)
+	wg.Wait()
+	assert.Equal(t, int32(1), maxRunning, "tasks of a repository never run concurrently")
+	worker.Stop()
+	assert.False(t, worker.Submit(3, func() {}))
+}
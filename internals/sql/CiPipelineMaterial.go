@@ -42,6 +42,9 @@ type CiPipelineMaterial struct {
 type CiPipelineMaterialRepository interface {
 	FindByGitMaterialId(gitMaterialId int) ([]*CiPipelineMaterial, error)
 	Update(material []*CiPipelineMaterial) error
+	// UpdateWithCommitEvents updates the materials and saves the commit events of the updates to the outbox in one
+	// transaction, so that the outbox has the events of exactly the updates which were saved
+	UpdateWithCommitEvents(materials []*CiPipelineMaterial, events []*CommitEventOutbox) error
 	FindByIds(ids []int) ([]*CiPipelineMaterial, error)
 	FindById(id int) (*CiPipelineMaterial, error)
 	Exists(id int) (bool, error)
@@ -90,6 +93,24 @@ func (impl CiPipelineMaterialRepositoryImpl) Update(materials []*CiPipelineMater
 	return err
 }
 
+func (impl CiPipelineMaterialRepositoryImpl) UpdateWithCommitEvents(materials []*CiPipelineMaterial, events []*CommitEventOutbox) error {
+	err := impl.dbConnection.RunInTransaction(func(tx *pg.Tx) error {
+		for _, material := range materials {
+			_, err := tx.Model(material).WherePK().UpdateNotNull()
+			if err != nil {
+				return err
+			}
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		// an event found again while it is still pending is already in the outbox
+		_, err := tx.Model(&events).OnConflict("DO NOTHING").Insert()
+		return err
+	})
+	return err
+}
+
 func (impl CiPipelineMaterialRepositoryImpl) FindByIds(ids []int) (materials []*CiPipelineMaterial, err error) {
 	err = impl.dbConnection.Model(&materials).
 		Where("id in (?)", pg.In(ids)).
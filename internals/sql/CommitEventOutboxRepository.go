@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sql
+
+import (
+	"github.com/go-pg/pg"
+	"time"
+)
+
+// CommitEventOutbox is a commit event waiting to be published to the event bus. It is saved with the material update
+// and deleted once the bus has acknowledged it, so an event is published at least once even if the sensor restarts
+type CommitEventOutbox struct {
+	tableName            struct{}  `sql:"commit_event_outbox" pg:",discard_unknown_columns"`
+	Id                   int       `sql:"id,pk"`
+	EventId              string    `sql:"event_id,notnull"` // sent as the message id, for the consumers to drop the duplicates
+	EventType            string    `sql:"event_type,notnull"`
+	Topic                string    `sql:"topic,notnull"`
+	GitMaterialId        int       `sql:"git_material_id,notnull"`
+	CiPipelineMaterialId int       `sql:"ci_pipeline_material_id"`
+	Payload              string    `sql:"payload,notnull"`
+	Attempts             int       `sql:"attempts,notnull"`
+	Error                string    `sql:"error"`                 // error of the last failed publish
+	DeadLettered         bool      `sql:"dead_lettered,notnull"` // failed COMMIT_EVENT_MAX_ATTEMPTS times, kept for inspection and not published again
+	CreatedOn            time.Time `sql:"created_on,notnull"`
+	UpdatedOn            time.Time `sql:"updated_on"`
+}
+
+type CommitEventOutboxRepository interface {
+	Save(events []*CommitEventOutbox) error
+	Update(event *CommitEventOutbox) error
+	Delete(event *CommitEventOutbox) error
+	// FindPending returns the oldest events not published yet, without the dead lettered ones
+	FindPending(limit int) ([]*CommitEventOutbox, error)
+}
+
+type CommitEventOutboxRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewCommitEventOutboxRepositoryImpl(dbConnection *pg.DB) *CommitEventOutboxRepositoryImpl {
+	return &CommitEventOutboxRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl CommitEventOutboxRepositoryImpl) Save(events []*CommitEventOutbox) error {
+	if len(events) == 0 {
+		return nil
+	}
+	// an event found again while it is still pending is already in the outbox
+	_, err := impl.dbConnection.Model(&events).OnConflict("DO NOTHING").Insert()
+	return err
+}
+
+func (impl CommitEventOutboxRepositoryImpl) Update(event *CommitEventOutbox) error {
+	_, err := impl.dbConnection.Model(event).WherePK().Update()
+	return err
+}
+
+func (impl CommitEventOutboxRepositoryImpl) Delete(event *CommitEventOutbox) error {
+	_, err := impl.dbConnection.Model(event).WherePK().Delete()
+	return err
+}
+
+func (impl CommitEventOutboxRepositoryImpl) FindPending(limit int) ([]*CommitEventOutbox, error) {
+	var events []*CommitEventOutbox
+	err := impl.dbConnection.Model(&events).
+		Where("dead_lettered = ?", false).
+		Order("id ASC").Limit(limit).Select()
+	return events, err
+}
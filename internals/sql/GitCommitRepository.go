@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sql
+
+import (
+	"github.com/devtron-labs/git-sensor/util"
+	"github.com/go-pg/pg"
+	"time"
+	"unicode/utf8"
+)
+
+// GIT_COMMIT_AUTHOR_MAX_LENGTH is the size of the author column, the full author is kept in the commit data
+const GIT_COMMIT_AUTHOR_MAX_LENGTH = 250
+
+// GitCommit is a commit seen on a pipeline material, CommitData is the json of the parsed commit
+type GitCommit struct {
+	tableName            struct{}  `sql:"git_commit" pg:",discard_unknown_columns"`
+	Id                   int       `sql:"id,pk"`
+	CiPipelineMaterialId int       `sql:"ci_pipeline_material_id,notnull"`
+	CommitHash           string    `sql:"commit_hash,notnull"`
+	Author               string    `sql:"author"`
+	CommitDate           time.Time `sql:"commit_date"`
+	CommitData           string    `sql:"commit_data,notnull"`
+	CreatedOn            time.Time `sql:"created_on,notnull"`
+}
+
+type GitCommitRepository interface {
+	SaveAll(commits []*GitCommit) error
+	FindByHash(ciPipelineMaterialId int, commitHash string) (*GitCommit, error)
+	FindByDateRange(ciPipelineMaterialId int, from time.Time, to time.Time, limit int) ([]*GitCommit, error)
+	FindLatest(ciPipelineMaterialId int, limit int) ([]*GitCommit, error)
+}
+
+type GitCommitRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewGitCommitRepositoryImpl(dbConnection *pg.DB) *GitCommitRepositoryImpl {
+	return &GitCommitRepositoryImpl{dbConnection: dbConnection}
+}
+
+// SaveAll skips the commits already saved for the material. longer authors are truncated to the size of the column,
+// so that one of them doesn't fail the insert of the whole batch
+func (impl GitCommitRepositoryImpl) SaveAll(commits []*GitCommit) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	for _, commit := range commits {
+		commit.Author = truncateAuthor(commit.Author)
+	}
+	_, err := impl.dbConnection.Model(&commits).
+		OnConflict("(ci_pipeline_material_id, commit_hash) DO NOTHING").
+		Insert()
+	return err
+}
+
+// truncateAuthor cuts the author to GIT_COMMIT_AUTHOR_MAX_LENGTH runes, so that a multi byte character is not split
+func truncateAuthor(author string) string {
+	if utf8.RuneCountInString(author) > GIT_COMMIT_AUTHOR_MAX_LENGTH {
+		return string([]rune(author)[:GIT_COMMIT_AUTHOR_MAX_LENGTH])
+	}
+	return author
+}
+
+func (impl GitCommitRepositoryImpl) FindByHash(ciPipelineMaterialId int, commitHash string) (*GitCommit, error) {
+	var commit GitCommit
+	err := impl.dbConnection.Model(&commit).
+		Where("ci_pipeline_material_id = ?", ciPipelineMaterialId).
+		Where("commit_hash = ?", commitHash).
+		Select()
+	if err != nil {
+		if util.IsErrNoRows(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &commit, nil
+}
+
+func (impl GitCommitRepositoryImpl) FindByDateRange(ciPipelineMaterialId int, from time.Time, to time.Time, limit int) ([]*GitCommit, error) {
+	var commits []*GitCommit
+	query := impl.dbConnection.Model(&commits).
+		Where("ci_pipeline_material_id = ?", ciPipelineMaterialId)
+	if !from.IsZero() {
+		query = query.Where("commit_date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("commit_date <= ?", to)
+	}
+	err := query.Order("commit_date DESC").Limit(limit).Select()
+	return commits, err
+}
+
+func (impl GitCommitRepositoryImpl) FindLatest(ciPipelineMaterialId int, limit int) ([]*GitCommit, error) {
+	var commits []*GitCommit
+	err := impl.dbConnection.Model(&commits).
+		Where("ci_pipeline_material_id = ?", ciPipelineMaterialId).
+		Order("commit_date DESC").
+		Limit(limit).
+		Select()
+	return commits, err
+}
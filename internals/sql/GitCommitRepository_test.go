@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateAuthor(t *testing.T) {
+	tests := []struct {
+		name   string
+		author string
+		want   string
+	}{
+		{name: "empty author", author: "", want: ""},
+		{name: "short author is kept", author: "dev <dev@example.com>", want: "dev <dev@example.com>"},
+		{name: "author of the column size is kept", author: strings.Repeat("a", GIT_COMMIT_AUTHOR_MAX_LENGTH), want: strings.Repeat("a", GIT_COMMIT_AUTHOR_MAX_LENGTH)},
+		{name: "longer author is cut to the column size", author: strings.Repeat("a", GIT_COMMIT_AUTHOR_MAX_LENGTH+10), want: strings.Repeat("a", GIT_COMMIT_AUTHOR_MAX_LENGTH)},
+		{name: "multi byte author is cut by runes", author: strings.Repeat("é", GIT_COMMIT_AUTHOR_MAX_LENGTH+1), want: strings.Repeat("é", GIT_COMMIT_AUTHOR_MAX_LENGTH)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateAuthor(tt.author)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package sql
 import (
 	"github.com/go-pg/pg"
 	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap/zapcore"
 	"time"
 )
 
@@ -29,6 +30,7 @@ const (
 	SOURCE_TYPE_BRANCH_REGEX SourceType = "SOURCE_TYPE_BRANCH_REGEX"
 	SOURCE_TYPE_TAG_ANY      SourceType = "SOURCE_TYPE_TAG_ANY"
 	SOURCE_TYPE_WEBHOOK      SourceType = "WEBHOOK"
+	SOURCE_TYPE_PULL_REQUEST SourceType = "SOURCE_TYPE_PULL_REQUEST"
 )
 
 // TODO: add support for submodule
@@ -44,14 +46,56 @@ type GitMaterial struct {
 	CheckoutMsgAny   string   `sql:"checkout_msg_any"`
 	Deleted          bool     `sql:"deleted,notnull"`
 	//------
-	LastFetchTime       time.Time `json:"last_fetch_time"`
-	FetchStatus         bool      `json:"fetch_status"`
-	LastFetchErrorCount int       `json:"last_fetch_error_count"` //continues fetch error
-	FetchErrorMessage   string    `json:"fetch_error_message"`
-	CloningMode         string    `json:"cloning_mode" sql:"-"`
-	FilterPattern       []string  `sql:"filter_pattern"`
-	GitProvider         *GitProvider
-	CiPipelineMaterials []*CiPipelineMaterial
+	LastFetchTime         time.Time `json:"last_fetch_time"`
+	FetchStatus           bool      `json:"fetch_status"`
+	LastSuccessFetchTime  time.Time `sql:"last_successful_fetch_time"`
+	LastFetchErrorCount   int       `json:"last_fetch_error_count"` //continues fetch error
+	FetchErrorMessage     string    `json:"fetch_error_message"`
+	CloningMode           string    `json:"cloning_mode" sql:"-"`
+	FilterPattern         []string  `sql:"filter_pattern"`
+	PollIntervalInMin     int       `sql:"poll_interval_in_min"` // 0 to poll at POLL_DURATION
+	ProxyUrl              string    `sql:"proxy_url"`            // overrides GIT_HTTP_PROXY for the material
+	TlsCaCert             string    `sql:"tls_ca_cert"`          // the tls fields override the ones of the git provider when set
+	TlsCert               string    `sql:"tls_cert"`
+	TlsKey                string    `sql:"tls_key"`
+	TlsInsecureSkipVerify bool      `sql:"tls_insecure_skip_verify,notnull"`
+	ApiMode               bool      `sql:"api_mode,notnull"`  // commits are polled through the provider api, the repo is cloned only when its content is needed
+	MirrorUrls            []string  `sql:"mirror_urls"`       // fetched from in order when the fetch from url fails with a remote error
+	LastFetchRemote       string    `sql:"last_fetch_remote"` // url which served the last successful fetch
+	GitProvider           *GitProvider
+	CiPipelineMaterials   []*CiPipelineMaterial
+	// GitConfig is the extra git config of the material, passed as -c key=value to its git commands
+	GitConfig map[string]string `sql:"git_config"`
+	// ProtectedBranchesOnly emits the triggers of the material only for the branches protected at the git provider
+	ProtectedBranchesOnly bool `sql:"protected_branches_only,notnull"`
+	// CredentialProvider is the secret backend the credentials of the material are read from instead of its git
+	// provider, one of env, file, kubernetes and vault. CredentialRef is the secret in it
+	CredentialProvider string `sql:"credential_provider"`
+	CredentialRef      string `sql:"credential_ref"`
+	// RetryMaxAttempts and RetryMaxBackoffInSec override GIT_RETRY_MAX_ATTEMPTS and GIT_RETRY_MAX_BACKOFF_IN_SEC for
+	// the material when set, up to the caps of the retry policy
+	RetryMaxAttempts     int `sql:"retry_max_attempts"`
+	RetryMaxBackoffInSec int `sql:"retry_max_backoff_in_sec"`
+	// EventTopic is the topic the commit events of the material are published to, COMMIT_EVENT_TOPIC when empty
+	EventTopic string `sql:"event_topic"`
+}
+
+// MarshalLogObject logs the material without the tls key and the secrets of its git provider
+func (material *GitMaterial) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("id", material.Id)
+	enc.AddString("name", material.Name)
+	enc.AddString("url", material.Url)
+	enc.AddInt("gitProviderId", material.GitProviderId)
+	enc.AddString("checkoutLocation", material.CheckoutLocation)
+	enc.AddBool("checkoutStatus", material.CheckoutStatus)
+	enc.AddBool("deleted", material.Deleted)
+	enc.AddBool("apiMode", material.ApiMode)
+	enc.AddString("credentialProvider", material.CredentialProvider)
+	enc.AddString("credentialRef", material.CredentialRef)
+	if material.GitProvider != nil {
+		return enc.AddObject("gitProvider", material.GitProvider)
+	}
+	return nil
 }
 
 type MaterialRepository interface {
@@ -89,7 +133,7 @@ func (repo MaterialRepositoryImpl) FindActive() ([]*GitMaterial, error) {
 			return q.Where("active IS TRUE"), nil
 		}).
 		Where("deleted =? ", false).
-		Where("checkout_status=? ", true).
+		Where("(checkout_status=? OR api_mode=?)", true, true).
 		Order("id ASC").
 		Select()
 	return materials, err
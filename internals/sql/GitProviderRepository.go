@@ -16,7 +16,10 @@
 
 package sql
 
-import "github.com/go-pg/pg"
+import (
+	"github.com/go-pg/pg"
+	"go.uber.org/zap/zapcore"
+)
 
 type AuthMode string
 
@@ -45,6 +48,17 @@ type GitProvider struct {
 	//models.AuditLog
 }
 
+// MarshalLogObject logs the git provider without its password, access token, ssh key and tls key
+func (provider *GitProvider) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("id", provider.Id)
+	enc.AddString("name", provider.Name)
+	enc.AddString("url", provider.Url)
+	enc.AddString("authMode", string(provider.AuthMode))
+	enc.AddString("userName", provider.UserName)
+	enc.AddBool("active", provider.Active)
+	return nil
+}
+
 type GitProviderRepository interface {
 	GetById(id int) (*GitProvider, error)
 	Save(provider *GitProvider) error
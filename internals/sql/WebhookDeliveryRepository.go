@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sql
+
+import (
+	"fmt"
+	"github.com/devtron-labs/git-sensor/util"
+	"github.com/go-pg/pg"
+	"time"
+)
+
+const (
+	WEBHOOK_DELIVERY_STATUS_RECEIVED  = "RECEIVED"  // saved, not processed yet
+	WEBHOOK_DELIVERY_STATUS_PROCESSED = "PROCESSED" // matched materials were polled or sent to ci
+	WEBHOOK_DELIVERY_STATUS_IGNORED   = "IGNORED"   // not a push or pull request event, or filtered out
+	WEBHOOK_DELIVERY_STATUS_FAILED    = "FAILED"    // the payload could not be parsed or processed
+)
+
+// WebhookDelivery is a webhook with a valid signature received from a git provider on the ingestion endpoint, kept to
+// list and replay it
+type WebhookDelivery struct {
+	tableName   struct{}          `sql:"webhook_delivery" pg:",discard_unknown_columns"`
+	Id          int               `sql:"id,pk"`
+	Provider    string            `sql:"provider,notnull"`
+	Headers     map[string]string `sql:"headers"` // event type headers of the provider, needed to parse the payload
+	Payload     string            `sql:"payload"`
+	Status      string            `sql:"status,notnull"`
+	Error       string            `sql:"error"`
+	MaterialIds []int             `sql:"material_ids"`
+	ReplayCount int               `sql:"replay_count,notnull"`
+	CreatedOn   time.Time         `sql:"created_on,notnull"`
+	UpdatedOn   time.Time         `sql:"updated_on"`
+}
+
+type WebhookDeliveryRepository interface {
+	Save(delivery *WebhookDelivery) error
+	Update(delivery *WebhookDelivery) error
+	FindById(id int) (*WebhookDelivery, error)
+	// FindRecent returns the latest deliveries, of all materials when materialId is 0
+	FindRecent(materialId int, limit int) ([]*WebhookDelivery, error)
+	// DeleteCreatedBefore deletes the deliveries received before the given time and returns how many were deleted
+	DeleteCreatedBefore(createdBefore time.Time) (int, error)
+}
+
+type WebhookDeliveryRepositoryImpl struct {
+	dbConnection *pg.DB
+}
+
+func NewWebhookDeliveryRepositoryImpl(dbConnection *pg.DB) *WebhookDeliveryRepositoryImpl {
+	return &WebhookDeliveryRepositoryImpl{dbConnection: dbConnection}
+}
+
+func (impl WebhookDeliveryRepositoryImpl) Save(delivery *WebhookDelivery) error {
+	_, err := impl.dbConnection.Model(delivery).Insert()
+	return err
+}
+
+func (impl WebhookDeliveryRepositoryImpl) Update(delivery *WebhookDelivery) error {
+	_, err := impl.dbConnection.Model(delivery).WherePK().Update()
+	return err
+}
+
+func (impl WebhookDeliveryRepositoryImpl) FindById(id int) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := impl.dbConnection.Model(&delivery).
+		Where("id = ?", id).
+		Select()
+	if err != nil {
+		if util.IsErrNoRows(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (impl WebhookDeliveryRepositoryImpl) FindRecent(materialId int, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	query := impl.dbConnection.Model(&deliveries)
+	if materialId > 0 {
+		query = query.Where("material_ids @> ?", fmt.Sprintf("[%d]", materialId))
+	}
+	err := query.Order("created_on DESC").Limit(limit).Select()
+	return deliveries, err
+}
+
+func (impl WebhookDeliveryRepositoryImpl) DeleteCreatedBefore(createdBefore time.Time) (int, error) {
+	result, err := impl.dbConnection.Model((*WebhookDelivery)(nil)).
+		Where("created_on < ?", createdBefore).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const SERVICE_NAME = "git-sensor"
+
+const (
+	ATTRIBUTE_REPO_URL     = attribute.Key("git.repo.url")
+	ATTRIBUTE_BRANCH       = attribute.Key("git.branch")
+	ATTRIBUTE_COMMIT_COUNT = attribute.Key("git.commit.count")
+	ATTRIBUTE_COMMAND      = attribute.Key("git.command")
+	ATTRIBUTE_MATERIAL_ID  = attribute.Key("git.material.id")
+)
+
+// Init exports the spans to the otlp http collector at collectorUrl, like http://otel-collector:4318. Spans are not
+// recorded when it is empty. The returned func flushes the pending spans on shutdown
+func Init(collectorUrl string) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if len(collectorUrl) == 0 {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	endpoint, err := url.Parse(collectorUrl)
+	if err != nil {
+		return nil, err
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint.Host)}
+	if endpoint.Scheme != "https" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(endpoint.Path) > 0 && endpoint.Path != "/" {
+		options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(SERVICE_NAME))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	return tracerProvider.Shutdown, nil
+}
+
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(SERVICE_NAME).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan sets the status of the span from err and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SanitizeUrl drops the credentials and query of a repo url, ssh urls like git@host:org/repo are returned as they are
+func SanitizeUrl(repoUrl string) string {
+	parsedUrl, err := url.Parse(repoUrl)
+	if err != nil || len(parsedUrl.Scheme) == 0 || len(parsedUrl.Host) == 0 {
+		return repoUrl
+	}
+	parsedUrl.User = nil
+	parsedUrl.RawQuery = ""
+	parsedUrl.Fragment = ""
+	return parsedUrl.String()
+}
+
+// HttpMiddleware continues the trace of the caller and starts a span per request, named after the route
+func HttpMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		name := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				name = template
+			}
+		}
+		ctx, span := otel.Tracer(SERVICE_NAME).Start(ctx, r.Method+" "+name, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startGrpcSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	EndSpan(span, err)
+	return resp, err
+}
+
+func StreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startGrpcSpan(stream.Context(), info.FullMethod)
+	err := handler(srv, &tracedServerStream{ServerStream: stream, ctx: ctx})
+	EndSpan(span, err)
+	return err
+}
+
+func startGrpcSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	return otel.Tracer(SERVICE_NAME).Start(ctx, strings.TrimPrefix(fullMethod, "/"), trace.WithSpanKind(trace.SpanKindServer))
+}
+
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *tracedServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// metadataCarrier reads the trace headers from the grpc metadata
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	values := metadata.MD(carrier).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+	return keys
+}
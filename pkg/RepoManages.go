@@ -23,28 +23,59 @@ import (
 	"github.com/devtron-labs/git-sensor/bean"
 	"github.com/devtron-labs/git-sensor/internals"
 	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
 	"github.com/devtron-labs/git-sensor/internals/util"
 	"github.com/devtron-labs/git-sensor/pkg/git"
 	_ "github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"io"
+	"os"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type RepoManager interface {
 	GetHeadForPipelineMaterials(ids []int) ([]*git.CiPipelineMaterialBean, error)
 	FetchChanges(pipelineMaterialId int, from string, to string, count int, showAll bool) (*git.MaterialChangeResp, error) //limit
 	GetCommitMetadata(gitCtx git.GitContext, pipelineMaterialId int, gitHash string) (*git.GitCommitBase, error)
+	GetCommitsMetadata(gitCtx git.GitContext, request *git.CommitsMetadataRequest) (*git.CommitsMetadataResponse, error)
 	GetLatestCommitForBranch(gitCtx git.GitContext, pipelineMaterialId int, branchName string) (*git.GitCommitBase, error)
 	GetCommitMetadataForPipelineMaterial(gitCtx git.GitContext, pipelineMaterialId int, gitHash string) (*git.GitCommitBase, error)
+	GetFileContentAtCommit(gitCtx git.GitContext, request *git.FileContentRequest) (*git.FileContent, error)
+	GetLfsPointers(gitCtx git.GitContext, request *git.LfsPointersRequest) (*git.LfsPointersResponse, error)
+	WriteArchive(gitCtx git.GitContext, request *git.ArchiveRequest, writer io.Writer) error
+	GetMaterialDiagnostics(gitCtx git.GitContext, gitMaterialId int) (*git.MaterialDiagnostics, error)
+	ValidateMaterial(gitCtx git.GitContext, request *git.MaterialValidationRequest) (*git.MaterialValidation, error)
+	GetRepoMetrics(gitCtx git.GitContext, gitMaterialId int, largestBlobs int) (*git.RepoMetrics, error)
+	GetDiffBetweenCommits(gitCtx git.GitContext, request *git.CommitDiffRequest) (*git.CommitRangeDiff, error)
+	GetBlame(gitCtx git.GitContext, request *git.BlameRequest) (*git.FileBlame, error)
+	GenerateChangeLog(gitCtx git.GitContext, request *git.ChangeLogRequest) (*git.ChangeLog, error)
+	SuggestNextVersion(gitCtx git.GitContext, request *git.VersionBumpRequest) (*git.VersionBumpResponse, error)
+	GetMergeBase(gitCtx git.GitContext, request *git.MergeBaseRequest) (*git.MergeBaseResponse, error)
+	IsAncestor(gitCtx git.GitContext, request *git.IsAncestorRequest) (*git.IsAncestorResponse, error)
+	GetCommitHistory(request *git.CommitHistoryRequest) ([]*git.GitCommitBase, error)
+	GetCommitsSince(gitCtx git.GitContext, request *git.CommitsSinceRequest) (*git.CommitsSinceResponse, error)
+	SearchCommits(gitCtx git.GitContext, request *git.CommitSearchRequest) ([]*git.CommitSearchResult, error)
 	SaveGitProvider(provider *sql.GitProvider) (*sql.GitProvider, error)
 	AddRepo(gitCtx git.GitContext, material []*sql.GitMaterial) ([]*sql.GitMaterial, error)
+	// AddRepoAsync saves the materials and clones them in background jobs
+	AddRepoAsync(gitCtx git.GitContext, materials []*sql.GitMaterial) ([]*git.Job, error)
+	StartCloneJob(request *git.CloneJobRequest) (*git.Job, error)
+	StartDeepenJob(request *git.DeepenJobRequest) (*git.Job, error)
+	StartArchiveJob(request *git.ArchiveRequest) (*git.Job, error)
 	UpdateRepo(gitCtx git.GitContext, material *sql.GitMaterial) (*sql.GitMaterial, error)
 	SavePipelineMaterial(gitCtx git.GitContext, material []*sql.CiPipelineMaterial) ([]*sql.CiPipelineMaterial, error)
 	ReloadAllRepo(gitCtx git.GitContext, req *bean.ReloadAllMaterialQuery) (err error)
 	ResetRepo(gitCtx git.GitContext, materialId int) error
+	GetGitConfig(gitMaterialId int) (map[string]string, error)
+	GetMaterialBranches(gitCtx git.GitContext, gitMaterialId int) ([]*git.MaterialBranch, error)
+	UpdateGitConfig(gitMaterialId int, gitConfig map[string]string) (map[string]string, error)
 	GetReleaseChanges(gitCtx git.GitContext, request *ReleaseChangesRequest) (*git.GitChanges, error)
 	GetCommitInfoForTag(gitCtx git.GitContext, request *git.CommitMetadataRequest) (*git.GitCommitBase, error)
 	RefreshGitMaterial(req *git.RefreshGitMaterialRequest) (*git.RefreshGitMaterialResponse, error)
+	RefreshMaterial(gitCtx git.GitContext, pipelineMaterialId int) (*git.MaterialChangeResp, error)
 
 	GetWebhookAndCiDataById(id int, ciPipelineMaterialId int) (*git.WebhookAndCiData, error)
 	GetAllWebhookEventConfigForHost(req *git.WebhookEventConfigRequest) ([]*git.WebhookEventConfig, error)
@@ -69,6 +100,11 @@ type RepoManagerImpl struct {
 	webhookEventBeanConverter                     git.WebhookEventBeanConverter
 	configuration                                 *internals.Configuration
 	gitManager                                    git.GitManager
+	gitCommitRepository                           sql.GitCommitRepository
+	providerApiClient                             git.ProviderApiClient
+	ticketExtractor                               *git.TicketExtractor
+	jobManager                                    git.JobManager
+	credentialResolver                            git.CredentialResolver
 }
 
 func NewRepoManagerImpl(
@@ -86,6 +122,11 @@ func NewRepoManagerImpl(
 	webhookEventBeanConverter git.WebhookEventBeanConverter,
 	configuration *internals.Configuration,
 	gitManager git.GitManager,
+	gitCommitRepository sql.GitCommitRepository,
+	providerApiClient git.ProviderApiClient,
+	ticketExtractor *git.TicketExtractor,
+	jobManager git.JobManager,
+	credentialResolver git.CredentialResolver,
 ) *RepoManagerImpl {
 	return &RepoManagerImpl{
 		logger:                            logger,
@@ -103,6 +144,11 @@ func NewRepoManagerImpl(
 		webhookEventBeanConverter:                     webhookEventBeanConverter,
 		configuration:                                 configuration,
 		gitManager:                                    gitManager,
+		gitCommitRepository:                           gitCommitRepository,
+		providerApiClient:                             providerApiClient,
+		ticketExtractor:                               ticketExtractor,
+		jobManager:                                    jobManager,
+		credentialResolver:                            credentialResolver,
 	}
 }
 
@@ -197,12 +243,28 @@ func (impl RepoManagerImpl) updatePipelineMaterialCommit(gitCtx git.GitContext,
 			continue
 		}
 
-		gitCtx = gitCtx.WithCredentials(material.GitProvider.UserName, material.GitProvider.Password).
-			WithTLSData(material.GitProvider.CaCert, material.GitProvider.TlsKey, material.GitProvider.TlsCert, material.GitProvider.EnableTLSVerification)
+		gitProvider, err := impl.credentialResolver.ResolveGitProvider(gitCtx, material, material.GitProvider)
+		if err != nil {
+			continue
+		}
+		gitCtx = gitCtx.WithCredentials(gitProvider.UserName, gitProvider.Password).
+			WithCredentialScope(git.GetCredentialScope(material)).
+			WithRetryPolicy(git.GetRetryPolicy(impl.configuration, material)).
+			WithTLSData(git.GetTLSData(material, gitProvider)).
+			WithExtraGitConfig(material.GitConfig).
+			WithInsecureSkipTLS(material.TlsInsecureSkipVerify).
+			WithSubmoduleResolution(impl.configuration.ResolveSubmoduleChanges, impl.configuration.FetchSubmoduleCommits).
+			WithSignatureVerification(impl.configuration.VerifyCommitSignatures).
+			WithProxy(git.ResolveProxy(material.Url, material.ProxyUrl, impl.configuration.GitHttpProxy, impl.configuration.GitNoProxy))
 
 		fetchCount := impl.configuration.GitHistoryCount
-		var repository *git.GitRepository
-		commits, err := impl.repositoryManager.ChangesSinceByRepository(gitCtx, repository, pipelineMaterial.Value, "", "", fetchCount, material.CheckoutLocation, true)
+		var commits []*git.GitCommitBase
+		if material.ApiMode {
+			commits, err = impl.providerApiClient.ListCommits(gitCtx, material, pipelineMaterial.Value, "", fetchCount)
+		} else {
+			var repository *git.GitRepository
+			commits, err = impl.repositoryManager.ChangesSinceByRepository(gitCtx, repository, pipelineMaterial.Value, "", "", fetchCount, material.CheckoutLocation, true)
+		}
 		//commits, err := impl.FetchChanges(pipelineMaterial.Id, "", "", 0)
 		if gitCtx.Err() != nil {
 			impl.logger.Errorw("context error in getting commits", "err", gitCtx.Err())
@@ -249,7 +311,7 @@ func (impl RepoManagerImpl) SaveGitProvider(provider *sql.GitProvider) (*sql.Git
 	}
 
 	if (err == nil) && (provider.AuthMode == sql.AUTH_MODE_SSH) {
-		err = git.CreateOrUpdateSshPrivateKeyOnDisk(provider.Id, provider.SshPrivateKey)
+		err = git.CreateOrUpdateSshPrivateKeyOnDisk(strconv.Itoa(provider.Id), provider.SshPrivateKey)
 		if err != nil {
 			impl.logger.Errorw("error in creating/updating ssh private key ", "err", err)
 		}
@@ -271,18 +333,42 @@ func (impl RepoManagerImpl) AddRepo(gitCtx git.GitContext, materials []*sql.GitM
 }
 
 func (impl RepoManagerImpl) UpdateRepo(gitCtx git.GitContext, material *sql.GitMaterial) (*sql.GitMaterial, error) {
+	if err := git.ValidateCredentialProvider(material); err != nil {
+		impl.logger.Errorw("invalid credential provider of material", "id", material.Id, "err", err)
+		return nil, err
+	}
 	existingMaterial, err := impl.materialRepository.FindById(material.Id)
 	if err != nil {
 		impl.logger.Errorw("error in fetching material", err)
 		return nil, err
 	}
+	// a change of the credentials, of the git provider or of the url of the same repo is applied to the checkout in
+	// place, other changes need the repo to be cloned again
+	updateInPlace := existingMaterial.CheckoutStatus && !material.Deleted && !material.ApiMode && !existingMaterial.ApiMode &&
+		existingMaterial.FetchSubmodules == material.FetchSubmodules &&
+		git.GetRepoIdentity(existingMaterial.Url) == git.GetRepoIdentity(material.Url)
 	existingMaterial.Name = material.Name
 	existingMaterial.Url = material.Url
 	existingMaterial.GitProviderId = material.GitProviderId
 	existingMaterial.Deleted = material.Deleted
+	// the checkout no longer matches the material until it is updated, the status is saved again once it is
 	existingMaterial.CheckoutStatus = false
 	existingMaterial.FetchSubmodules = material.FetchSubmodules
 	existingMaterial.FilterPattern = material.FilterPattern
+	existingMaterial.PollIntervalInMin = material.PollIntervalInMin
+	existingMaterial.ProxyUrl = material.ProxyUrl
+	existingMaterial.TlsCaCert = material.TlsCaCert
+	existingMaterial.TlsCert = material.TlsCert
+	existingMaterial.TlsKey = material.TlsKey
+	existingMaterial.TlsInsecureSkipVerify = material.TlsInsecureSkipVerify
+	existingMaterial.ApiMode = material.ApiMode
+	existingMaterial.MirrorUrls = material.MirrorUrls
+	existingMaterial.ProtectedBranchesOnly = material.ProtectedBranchesOnly
+	existingMaterial.CredentialProvider = material.CredentialProvider
+	existingMaterial.CredentialRef = material.CredentialRef
+	existingMaterial.RetryMaxAttempts = material.RetryMaxAttempts
+	existingMaterial.RetryMaxBackoffInSec = material.RetryMaxBackoffInSec
+	existingMaterial.EventTopic = material.EventTopic
 	err = impl.materialRepository.Update(existingMaterial)
 	if err != nil {
 		impl.logger.Errorw("error in updating material ", "material", material, "err", err)
@@ -296,13 +382,26 @@ func (impl RepoManagerImpl) UpdateRepo(gitCtx git.GitContext, material *sql.GitM
 		impl.locker.ReturnLocker(material.Id)
 	}()
 
+	if updateInPlace {
+		err = impl.updateCredentials(gitCtx, existingMaterial)
+		if err == nil {
+			existingMaterial.CheckoutStatus = true
+			err = impl.materialRepository.Update(existingMaterial)
+			if err != nil {
+				impl.logger.Errorw("error in updating checkout status of material", "gitMaterialId", material.Id, "err", err)
+				return nil, err
+			}
+			return existingMaterial, nil
+		}
+		impl.logger.Errorw("error in updating credentials of checkout, cloning again", "gitMaterialId", material.Id, "err", err)
+	}
 	err = impl.repositoryManager.Clean(existingMaterial.CheckoutLocation)
 	if err != nil {
 		impl.logger.Errorw("error in refreshing material ", "err", err)
 		return nil, err
 	}
 
-	if !existingMaterial.Deleted {
+	if !existingMaterial.Deleted && !existingMaterial.ApiMode {
 		err = impl.checkoutUpdatedRepo(gitCtx, material.Id)
 		if err != nil {
 			impl.logger.Errorw("error in checking out updated repo", "err", err)
@@ -312,6 +411,20 @@ func (impl RepoManagerImpl) UpdateRepo(gitCtx git.GitContext, material *sql.GitM
 	return existingMaterial, nil
 }
 
+func (impl RepoManagerImpl) updateCredentials(gitCtx git.GitContext, material *sql.GitMaterial) error {
+	gitProvider, err := impl.gitProviderRepository.GetById(material.GitProviderId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching git provider", "gitProviderId", material.GitProviderId, "err", err)
+		return err
+	}
+	gitProvider, err = impl.credentialResolver.ResolveGitProvider(gitCtx, material, gitProvider)
+	if err != nil {
+		return err
+	}
+	gitCtx = gitCtx.WithCredentialScope(git.GetCredentialScope(material))
+	return impl.repositoryManager.UpdateCredentials(gitCtx, material.CheckoutLocation, gitProvider.Id, material.Url, gitProvider.AuthMode, gitProvider.SshPrivateKey)
+}
+
 func (impl RepoManagerImpl) checkoutUpdatedRepo(gitCtx git.GitContext, materialId int) error {
 	material, err := impl.materialRepository.FindById(materialId)
 	if err != nil {
@@ -327,11 +440,25 @@ func (impl RepoManagerImpl) checkoutUpdatedRepo(gitCtx git.GitContext, materialI
 }
 
 func (impl RepoManagerImpl) addRepo(gitCtx git.GitContext, material *sql.GitMaterial) (*sql.GitMaterial, error) {
-	err := impl.materialRepository.Save(material)
+	err := git.ValidateCredentialProvider(material)
+	if err != nil {
+		impl.logger.Errorw("invalid credential provider of material", "url", tracing.SanitizeUrl(material.Url), "err", err)
+		return material, err
+	}
+	err = git.ValidateGitConfig(material.GitConfig)
+	if err != nil {
+		impl.logger.Errorw("invalid git config of material", "url", tracing.SanitizeUrl(material.Url), "err", err)
+		return material, err
+	}
+	err = impl.materialRepository.Save(material)
 	if err != nil {
 		impl.logger.Errorw("error in saving material ", "material", material, "err", err)
 		return material, err
 	}
+	if material.ApiMode {
+		// cloned on the first request needing the content of the repo
+		return material, nil
+	}
 	return impl.checkoutRepo(gitCtx, material)
 }
 
@@ -351,13 +478,22 @@ func (impl RepoManagerImpl) checkoutMaterial(gitCtx git.GitContext, material *sq
 	if err != nil {
 		return material, err
 	}
+	gitProvider, err = impl.credentialResolver.ResolveGitProvider(gitCtx, material, gitProvider)
+	if err != nil {
+		return material, err
+	}
 	userName, password, err := git.GetUserNamePassword(gitProvider)
 	if err != nil {
 		return material, nil
 	}
 
 	gitCtx = gitCtx.WithCredentials(userName, password).
-		WithTLSData(gitProvider.CaCert, gitProvider.TlsKey, gitProvider.TlsCert, gitProvider.EnableTLSVerification)
+		WithCredentialScope(git.GetCredentialScope(material)).
+		WithRetryPolicy(git.GetRetryPolicy(impl.configuration, material)).
+		WithTLSData(git.GetTLSData(material, gitProvider)).
+		WithExtraGitConfig(material.GitConfig).
+		WithInsecureSkipTLS(material.TlsInsecureSkipVerify).
+		WithProxy(git.ResolveProxy(material.Url, material.ProxyUrl, impl.configuration.GitHttpProxy, impl.configuration.GitNoProxy))
 
 	checkoutPath, _, _, err := impl.repositoryManager.GetCheckoutLocationFromGitUrl(material, gitCtx.CloningMode)
 	if err != nil {
@@ -441,6 +577,218 @@ func (impl RepoManagerImpl) ResetRepo(gitCtx git.GitContext, materialId int) err
 	return nil
 }
 
+func (impl RepoManagerImpl) AddRepoAsync(gitCtx git.GitContext, materials []*sql.GitMaterial) ([]*git.Job, error) {
+	for _, material := range materials {
+		if err := git.ValidateGitConfig(material.GitConfig); err != nil {
+			impl.logger.Errorw("invalid git config of material", "url", tracing.SanitizeUrl(material.Url), "err", err)
+			return nil, err
+		}
+	}
+	jobs := make([]*git.Job, 0, len(materials))
+	for _, material := range materials {
+		err := impl.materialRepository.Save(material)
+		if err != nil {
+			impl.logger.Errorw("error in saving material ", "material", material, "err", err)
+			return jobs, err
+		}
+		if material.ApiMode {
+			// cloned on the first request needing the content of the repo
+			continue
+		}
+		job, err := impl.submitCloneJob(material, gitCtx.CloningMode)
+		if err != nil {
+			impl.logger.Errorw("error in submitting clone job", "gitMaterialId", material.Id, "err", err)
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// StartCloneJob clones the material again in a background job, like a reset of the repo
+func (impl RepoManagerImpl) StartCloneJob(request *git.CloneJobRequest) (*git.Job, error) {
+	material, err := impl.materialRepository.FindById(request.GitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "id", request.GitMaterialId, "err", err)
+		return nil, err
+	}
+	if material.Deleted {
+		return nil, fmt.Errorf("material %d is deleted", material.Id)
+	}
+	return impl.submitCloneJob(material, request.CloningMode)
+}
+
+func (impl RepoManagerImpl) submitCloneJob(material *sql.GitMaterial, cloningMode string) (*git.Job, error) {
+	return impl.jobManager.Submit(git.JOB_TYPE_CLONE, material.Id, func(gitCtx git.GitContext, jobId string) (string, error) {
+		material, err := impl.checkoutRepo(gitCtx.WithCloningMode(cloningMode), material)
+		if err != nil {
+			return "", err
+		}
+		if !material.CheckoutStatus {
+			return "", errors.New(material.CheckoutMsgAny)
+		}
+		return "", nil
+	})
+}
+
+// StartDeepenJob fetches more of the history of a shallow checkout in a background job
+func (impl RepoManagerImpl) StartDeepenJob(request *git.DeepenJobRequest) (*git.Job, error) {
+	if request.DeepenBy <= 0 {
+		return nil, fmt.Errorf("invalid deepenBy %d, it must be positive", request.DeepenBy)
+	}
+	material, err := impl.materialRepository.FindById(request.GitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "id", request.GitMaterialId, "err", err)
+		return nil, err
+	}
+	if !material.CheckoutStatus {
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(material.Url))
+	}
+	if !git.IsShallowRepository(material.CheckoutLocation) {
+		return nil, fmt.Errorf("checkout of material %d is not shallow, it has the full history", material.Id)
+	}
+	return impl.jobManager.Submit(git.JOB_TYPE_DEEPEN, material.Id, func(gitCtx git.GitContext, jobId string) (string, error) {
+		repoLock := impl.locker.LeaseLocker(material.Id)
+		repoLock.Mutex.Lock()
+		defer func() {
+			repoLock.Mutex.Unlock()
+			impl.locker.ReturnLocker(material.Id)
+		}()
+		gitCtx, err := impl.withRemoteAccess(gitCtx, material)
+		if err != nil {
+			return "", err
+		}
+		unlockStore := impl.gitManager.GetWorktreeStores().LockStoreOf(material.CheckoutLocation)
+		defer unlockStore()
+		if _, err = impl.gitManager.RemoveLockFilesOfKilledCommands(material.CheckoutLocation); err != nil {
+			return "", err
+		}
+		_, errMsg, err := impl.gitManager.Deepen(gitCtx, material.CheckoutLocation, request.DeepenBy)
+		if err != nil {
+			impl.logger.Errorw("error in deepening checkout", "gitMaterialId", material.Id, "deepenBy", request.DeepenBy, "errMsg", errMsg, "err", err)
+		}
+		return "", err
+	})
+}
+
+// StartArchiveJob writes the archive to a file in a background job, downloaded once the job succeeds
+func (impl RepoManagerImpl) StartArchiveJob(request *git.ArchiveRequest) (*git.Job, error) {
+	if len(request.Format) == 0 {
+		request.Format = git.ARCHIVE_FORMAT_TAR
+	}
+	if len(git.GetArchiveContentType(request.Format)) == 0 {
+		return nil, fmt.Errorf("unsupported archive format %q", request.Format)
+	}
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(request.PipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", request.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	return impl.jobManager.Submit(git.JOB_TYPE_ARCHIVE, pipelineMaterial.GitMaterialId, func(gitCtx git.GitContext, jobId string) (string, error) {
+		err := os.MkdirAll(git.JOB_RESULTS_DIR, os.ModePerm)
+		if err != nil {
+			return "", err
+		}
+		resultPath := path.Join(git.JOB_RESULTS_DIR, jobId+"."+request.Format)
+		file, err := os.Create(resultPath)
+		if err != nil {
+			return "", err
+		}
+		err = impl.WriteArchive(gitCtx, request, git.NewProgressWriter(file, gitCtx.Progress))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return resultPath, err
+	})
+}
+
+func (impl RepoManagerImpl) GetGitConfig(gitMaterialId int) (map[string]string, error) {
+	material, err := impl.materialRepository.FindById(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	return material.GitConfig, nil
+}
+
+// UpdateGitConfig replaces the extra git config of the material, it is passed to the git commands of the material
+// from the next one on
+func (impl RepoManagerImpl) UpdateGitConfig(gitMaterialId int, gitConfig map[string]string) (map[string]string, error) {
+	err := git.ValidateGitConfig(gitConfig)
+	if err != nil {
+		return nil, err
+	}
+	// the material is saved by the poll under the lock too
+	repoLock := impl.locker.LeaseLocker(gitMaterialId)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterialId)
+	}()
+	material, err := impl.materialRepository.FindById(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	material.GitConfig = gitConfig
+	err = impl.materialRepository.Update(material)
+	if err != nil {
+		impl.logger.Errorw("error in updating git config of material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	impl.logger.Infow("updated git config of material", "gitMaterialId", gitMaterialId, "keys", len(gitConfig))
+	return material.GitConfig, nil
+}
+
+// GetMaterialBranches lists the branches of the material as of its last fetch with their protection at the git
+// provider. Materials in api mode are not fetched, only their protected branches are listed
+func (impl RepoManagerImpl) GetMaterialBranches(gitCtx git.GitContext, gitMaterialId int) ([]*git.MaterialBranch, error) {
+	material, err := impl.materialRepository.FindById(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	gitCtx, err = impl.withRemoteAccess(gitCtx, material)
+	if err != nil {
+		return nil, err
+	}
+	protectedBranches, err := impl.providerApiClient.ListProtectedBranches(gitCtx, material)
+	if err != nil {
+		impl.logger.Errorw("error in getting protected branches", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	materialBranches := make([]*git.MaterialBranch, 0)
+	if material.ApiMode {
+		for _, protectedBranch := range protectedBranches {
+			materialBranches = append(materialBranches, &git.MaterialBranch{Name: protectedBranch, Protected: true})
+		}
+		return materialBranches, nil
+	}
+	if !material.CheckoutStatus {
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(material.Url))
+	}
+	repoLock := impl.locker.LeaseLocker(material.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(material.Id)
+	}()
+	branches, err := impl.repositoryManager.ListBranches(gitCtx, material.CheckoutLocation)
+	if err != nil {
+		impl.logger.Errorw("error in listing branches", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	for _, branch := range branches {
+		materialBranches = append(materialBranches, &git.MaterialBranch{
+			Name:      branch.Name,
+			Commit:    branch.Commit,
+			Date:      branch.Date,
+			Protected: git.IsBranchProtected(protectedBranches, branch.Name),
+		})
+	}
+	return materialBranches, nil
+}
+
 func (impl RepoManagerImpl) GetHeadForPipelineMaterials(ids []int) (materialBeans []*git.CiPipelineMaterialBean, err error) {
 	materials, err := impl.ciPipelineMaterialRepository.FindByIds(ids)
 	for _, material := range materials {
@@ -501,6 +849,7 @@ func (impl RepoManagerImpl) FetchGitCommitsForBranchFixPipeline(pipelineMaterial
 		} else {
 			response.IsBranchError = true
 			response.BranchErrorMsg = pipelineMaterial.ErrorMsg
+			response.IsBranchDeleted = pipelineMaterial.ErrorMsg == git.BRANCH_DELETED_ERROR_MESSAGE
 		}
 
 		return response, nil
@@ -510,14 +859,14 @@ func (impl RepoManagerImpl) FetchGitCommitsForBranchFixPipeline(pipelineMaterial
 	if err != nil {
 		return nil, err
 	}
-	if len(gitMaterial.FilterPattern) == 0 {
-		response.Commits = commits
-		return response, nil
-	}
 
 	filterCommits := make([]*git.GitCommitBase, 0)
 	for _, commit := range commits {
-		excluded := impl.gitManager.PathMatcher(commit.FileStats, gitMaterial)
+		// commits denied by the commit policy in exclude mode are left out like the ones of excluded paths
+		excluded := commit.PolicyVerdict.IsExcluded()
+		if !excluded && len(gitMaterial.FilterPattern) > 0 {
+			excluded = impl.gitManager.PathMatcher(commit.FileStats, gitMaterial)
+		}
 		impl.logger.Debugw("include exclude result", "excluded", excluded)
 		if showAll {
 			commit.Excluded = excluded
@@ -596,8 +945,12 @@ func (impl RepoManagerImpl) GetCommitInfoForTag(gitCtx git.GitContext, request *
 	if err != nil {
 		return nil, err
 	}
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
 	if !gitMaterial.CheckoutStatus {
-		return nil, fmt.Errorf("checkout not succeed please checkout first %s", gitMaterial.Url)
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
 	}
 	//refresh repo. and notify all pending
 	//lock inside watcher itself
@@ -614,6 +967,7 @@ func (impl RepoManagerImpl) GetCommitInfoForTag(gitCtx git.GitContext, request *
 		impl.locker.ReturnLocker(gitMaterial.Id)
 	}()
 	commit, err := impl.repositoryManager.GetCommitForTag(gitCtx, gitMaterial.CheckoutLocation, request.GitTag)
+	impl.ticketExtractor.Annotate(commit)
 	return commit, err
 }
 
@@ -626,8 +980,12 @@ func (impl RepoManagerImpl) GetCommitMetadata(gitCtx git.GitContext, pipelineMat
 	if err != nil {
 		return nil, err
 	}
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
 	if !gitMaterial.CheckoutStatus {
-		return nil, fmt.Errorf("checkout not succeed please checkout first %s", gitMaterial.Url)
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
 	}
 	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
 	repoLock.Mutex.Lock()
@@ -636,9 +994,617 @@ func (impl RepoManagerImpl) GetCommitMetadata(gitCtx git.GitContext, pipelineMat
 		impl.locker.ReturnLocker(gitMaterial.Id)
 	}()
 	commit, err := impl.repositoryManager.GetCommitMetadata(gitCtx, gitMaterial.CheckoutLocation, gitHash)
+	if err != nil {
+		// the commit may be no longer on any branch, like the head of a closed pull request or one dropped by a force push
+		commit, err = impl.fetchAndGetCommitMetadata(gitCtx, gitMaterial, gitHash)
+	}
+	impl.ticketExtractor.Annotate(commit)
 	return commit, err
 }
 
+// fetchAndGetCommitMetadata fetches the commit by its hash from the remote of the material and returns its metadata,
+// the caller must hold the lock of the material
+func (impl RepoManagerImpl) fetchAndGetCommitMetadata(gitCtx git.GitContext, gitMaterial *sql.GitMaterial, gitHash string) (*git.GitCommitBase, error) {
+	gitCtx, err := impl.withRemoteAccess(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.repositoryManager.FetchCommit(gitCtx, gitMaterial.CheckoutLocation, gitHash)
+	if err != nil {
+		impl.logger.Errorw("error in fetching commit not found in checkout", "gitMaterialId", gitMaterial.Id, "gitHash", gitHash, "err", err)
+		return nil, err
+	}
+	return impl.repositoryManager.GetCommitMetadata(gitCtx, gitMaterial.CheckoutLocation, gitHash)
+}
+
+// withRemoteAccess sets the credentials, tls data, git config and proxy of the material needed to fetch its remote
+func (impl RepoManagerImpl) withRemoteAccess(gitCtx git.GitContext, gitMaterial *sql.GitMaterial) (git.GitContext, error) {
+	gitProvider, err := impl.credentialResolver.ResolveGitProvider(gitCtx, gitMaterial, gitMaterial.GitProvider)
+	if err != nil {
+		return gitCtx, err
+	}
+	userName, password, err := git.GetUserNamePassword(gitProvider)
+	if err != nil {
+		return gitCtx, err
+	}
+	return gitCtx.WithCredentials(userName, password).
+		WithCredentialScope(git.GetCredentialScope(gitMaterial)).
+		WithRetryPolicy(git.GetRetryPolicy(impl.configuration, gitMaterial)).
+		WithTLSData(git.GetTLSData(gitMaterial, gitMaterial.GitProvider)).
+		WithExtraGitConfig(gitMaterial.GitConfig).
+		WithInsecureSkipTLS(gitMaterial.TlsInsecureSkipVerify).
+		WithProxy(git.ResolveProxy(gitMaterial.Url, gitMaterial.ProxyUrl, impl.configuration.GitHttpProxy, impl.configuration.GitNoProxy)), nil
+}
+
+func (impl RepoManagerImpl) GetCommitsMetadata(gitCtx git.GitContext, request *git.CommitsMetadataRequest) (*git.CommitsMetadataResponse, error) {
+	if len(request.GitHashes) > git.MAX_COMMITS_PER_LOOKUP {
+		return nil, fmt.Errorf("too many hashes %d, at most %d are allowed", len(request.GitHashes), git.MAX_COMMITS_PER_LOOKUP)
+	}
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	commits, err := impl.repositoryManager.GetCommitsMetadata(gitCtx, gitMaterial.CheckoutLocation, request.GitHashes)
+	if err != nil {
+		return nil, err
+	}
+	response := &git.CommitsMetadataResponse{Commits: make([]*git.GitCommitBase, 0, len(commits)), MissingHashes: make([]string, 0)}
+	for _, gitHash := range request.GitHashes {
+		if commit, ok := commits[gitHash]; ok {
+			impl.ticketExtractor.Annotate(commit)
+			response.Commits = append(response.Commits, commit)
+		} else {
+			response.MissingHashes = append(response.MissingHashes, gitHash)
+		}
+	}
+	return response, nil
+}
+
+// checkoutApiModeMaterial clones a material in api mode on the first request needing its content and fetches it on the
+// later ones, as the watcher finds the commits of such materials through the provider api without fetching
+func (impl RepoManagerImpl) checkoutApiModeMaterial(gitCtx git.GitContext, gitMaterial *sql.GitMaterial) (*sql.GitMaterial, error) {
+	if !gitMaterial.ApiMode {
+		return gitMaterial, nil
+	}
+	if !gitMaterial.CheckoutStatus {
+		impl.logger.Infow("cloning material in api mode for its content", "gitMaterialId", gitMaterial.Id)
+		return impl.checkoutRepo(gitCtx, gitMaterial)
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	gitCtx, err := impl.withRemoteAccess(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
+	_, _, _, err = impl.repositoryManager.FetchWithMirrors(gitCtx, gitMaterial.Url, gitMaterial.MirrorUrls, gitMaterial.CheckoutLocation)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material in api mode", "gitMaterialId", gitMaterial.Id, "err", err)
+		return nil, err
+	}
+	return gitMaterial, nil
+}
+
+// getCheckedOutGitMaterial returns the git material of the pipeline material, erroring out if it is not checked out yet
+func (impl RepoManagerImpl) getCheckedOutGitMaterial(gitCtx git.GitContext, pipelineMaterialId int) (*sql.GitMaterial, error) {
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(pipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", pipelineMaterialId, "err", err)
+		return nil, err
+	}
+	gitMaterial, err := impl.materialRepository.FindById(pipelineMaterial.GitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting material ", "gitMaterialId", pipelineMaterial.GitMaterialId, "err", err)
+		return nil, err
+	}
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
+	if !gitMaterial.CheckoutStatus {
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
+	}
+	return gitMaterial, nil
+}
+
+func (impl RepoManagerImpl) GetFileContentAtCommit(gitCtx git.GitContext, request *git.FileContentRequest) (*git.FileContent, error) {
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	return impl.repositoryManager.GetFileContentAtCommit(gitCtx, gitMaterial.CheckoutLocation, request.GitHash, request.Path)
+}
+
+func (impl RepoManagerImpl) GetLfsPointers(gitCtx git.GitContext, request *git.LfsPointersRequest) (*git.LfsPointersResponse, error) {
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	pointers, err := impl.repositoryManager.GetLfsPointersInCommit(gitCtx, gitMaterial.CheckoutLocation, request.GitHash)
+	if err != nil {
+		return nil, err
+	}
+	return &git.LfsPointersResponse{Commit: request.GitHash, Pointers: pointers}, nil
+}
+
+// GetMaterialDiagnostics checks the remote of the material with its credentials and the branches of its pipeline
+// materials at it, along with the state of the checkout and of the last poll
+func (impl RepoManagerImpl) GetMaterialDiagnostics(gitCtx git.GitContext, gitMaterialId int) (*git.MaterialDiagnostics, error) {
+	material, err := impl.materialRepository.FindById(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	pipelineMaterials, err := impl.ciPipelineMaterialRepository.FindByGitMaterialId(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipeline materials", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	diagnostics := &git.MaterialDiagnostics{
+		GitMaterialId:        material.Id,
+		Url:                  material.Url,
+		ApiMode:              material.ApiMode,
+		CheckedOut:           material.CheckoutStatus,
+		CheckoutLocation:     material.CheckoutLocation,
+		LastFetchTime:        material.LastFetchTime,
+		LastSuccessFetchTime: material.LastSuccessFetchTime,
+		LastFetchRemote:      material.LastFetchRemote,
+		LastFetchErrorCount:  material.LastFetchErrorCount,
+	}
+	diagnostics.SetLastFetchError(material.FetchErrorMessage)
+	gitBinary := impl.gitManager.GetGitBinary()
+	diagnostics.GitBinaryPath = gitBinary.Path
+	diagnostics.GitVersion = gitBinary.GetVersion()
+	diagnostics.DisabledGitFeatures = gitBinary.GetDisabledFeatures()
+	if material.CheckoutStatus {
+		diagnostics.CheckoutSizeInBytes, err = git.GetDirSize(material.CheckoutLocation)
+		if err != nil {
+			impl.logger.Errorw("error in getting checkout size", "gitMaterialId", gitMaterialId, "err", err)
+		}
+	}
+
+	var refs []string
+	for _, pipelineMaterial := range pipelineMaterials {
+		if !pipelineMaterial.Active || pipelineMaterial.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+			continue
+		}
+		branch, _ := git.GetBranchReference(pipelineMaterial.Value)
+		diagnostics.Branches = append(diagnostics.Branches, &git.BranchDiagnostics{
+			PipelineMaterialId: pipelineMaterial.Id,
+			Branch:             branch,
+			LastSeenCommit:     pipelineMaterial.LastSeenHash,
+		})
+		refs = append(refs, git.GetRemoteRefOfBranch(branch, impl.configuration.FetchGerritChangeRefs))
+	}
+	gitCtx, err = impl.withRemoteAccess(gitCtx, material)
+	if err != nil {
+		return nil, err
+	}
+	// the checkout has the ssh command of the material configured, so its origin is listed when it is there
+	rootDir, remote := "", material.Url
+	if material.CheckoutStatus {
+		rootDir, remote = material.CheckoutLocation, "origin"
+	}
+	lsRemoteRefs := refs
+	if len(lsRemoteRefs) == 0 {
+		// only the reachability is checked then, listing all the refs of a large repo is wasteful
+		lsRemoteRefs = []string{"HEAD"}
+	}
+	remoteRefs, err := impl.repositoryManager.LsRemote(gitCtx, rootDir, remote, lsRemoteRefs)
+	diagnostics.SetRemoteError(err)
+	for i, branchDiagnostics := range diagnostics.Branches {
+		branchDiagnostics.RemoteCommit = remoteRefs[refs[i]]
+		branchDiagnostics.Exists = len(branchDiagnostics.RemoteCommit) > 0
+	}
+	return diagnostics, nil
+}
+
+// ValidateMaterial dry runs a material before its configuration is saved, with the credentials of its git provider or
+// of the unsaved one in the request, nothing of it is stored
+func (impl RepoManagerImpl) ValidateMaterial(gitCtx git.GitContext, request *git.MaterialValidationRequest) (*git.MaterialValidation, error) {
+	if len(strings.TrimSpace(request.Url)) == 0 {
+		return nil, fmt.Errorf("url is required")
+	}
+	gitProvider := request.GitProvider
+	if request.GitProviderId > 0 {
+		var err error
+		gitProvider, err = impl.gitProviderRepository.GetById(request.GitProviderId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching git provider", "gitProviderId", request.GitProviderId, "err", err)
+			return nil, err
+		}
+	}
+	if gitProvider == nil {
+		gitProvider = &sql.GitProvider{AuthMode: sql.AUTH_MODE_ANONYMOUS}
+	}
+	material := &sql.GitMaterial{
+		Url:                request.Url,
+		GitProviderId:      gitProvider.Id,
+		GitProvider:        gitProvider,
+		CredentialProvider: request.CredentialProvider,
+		CredentialRef:      request.CredentialRef,
+	}
+	if err := git.ValidateCredentialProvider(material); err != nil {
+		return nil, err
+	}
+	resolvedProvider, err := impl.credentialResolver.ResolveGitProvider(gitCtx, material, gitProvider)
+	if err != nil {
+		return nil, err
+	}
+	// the credentials are resolved once, the material uses them as the ones of its git provider from here
+	material.GitProvider, material.CredentialProvider = resolvedProvider, git.CREDENTIAL_PROVIDER_GIT_PROVIDER
+	gitCtx, err = impl.withRemoteAccess(gitCtx, material)
+	if err != nil {
+		return nil, err
+	}
+	var sshPrivateKey string
+	if resolvedProvider.AuthMode == sql.AUTH_MODE_SSH {
+		sshPrivateKey = resolvedProvider.SshPrivateKey
+	}
+	return impl.repositoryManager.ValidateMaterial(gitCtx, request, sshPrivateKey)
+}
+
+// GetRepoMetrics reports the object store usage of the checkout of the material and its largest blobs, so that the
+// repos which need a gc or lfs can be found
+func (impl RepoManagerImpl) GetRepoMetrics(gitCtx git.GitContext, gitMaterialId int, largestBlobs int) (*git.RepoMetrics, error) {
+	material, err := impl.materialRepository.FindById(gitMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching material", "gitMaterialId", gitMaterialId, "err", err)
+		return nil, err
+	}
+	if !material.CheckoutStatus {
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(material.Url))
+	}
+	if largestBlobs <= 0 {
+		largestBlobs = git.REPO_METRICS_LARGEST_BLOBS
+	}
+	largestBlobs = min(largestBlobs, git.REPO_METRICS_MAX_LARGEST_BLOBS)
+	repoLock := impl.locker.LeaseLocker(material.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(material.Id)
+	}()
+	metrics, err := impl.repositoryManager.GetRepoMetrics(gitCtx, material.CheckoutLocation, largestBlobs)
+	if err != nil {
+		return nil, err
+	}
+	metrics.GitMaterialId = material.Id
+	return metrics, nil
+}
+
+// WriteArchive holds the repo lock while the archive is streamed so that a gc or re-clone doesn't run underneath it
+func (impl RepoManagerImpl) WriteArchive(gitCtx git.GitContext, request *git.ArchiveRequest, writer io.Writer) error {
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	return impl.repositoryManager.WriteArchive(gitCtx, gitMaterial.CheckoutLocation, request.GitHash, request.Format, request.Paths, writer)
+}
+
+func (impl RepoManagerImpl) GetDiffBetweenCommits(gitCtx git.GitContext, request *git.CommitDiffRequest) (*git.CommitRangeDiff, error) {
+	contextLines := -1
+	if request.ContextLines != nil {
+		if *request.ContextLines < 0 {
+			return nil, fmt.Errorf("invalid context lines %d", *request.ContextLines)
+		}
+		contextLines = *request.ContextLines
+	}
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	return impl.repositoryManager.GetDiffBetweenCommits(gitCtx, gitMaterial.CheckoutLocation, request.FromCommit, request.ToCommit, request.Paths, contextLines)
+}
+
+func (impl RepoManagerImpl) GetBlame(gitCtx git.GitContext, request *git.BlameRequest) (*git.FileBlame, error) {
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(request.PipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", request.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	ref := request.Ref
+	if len(ref) == 0 {
+		if pipelineMaterial.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+			return nil, fmt.Errorf("ref is required for non branch materials")
+		}
+		_, ref = git.GetBranchReference(pipelineMaterial.Value)
+	}
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	return impl.repositoryManager.GetBlame(gitCtx, gitMaterial.CheckoutLocation, request.Path, request.StartLine, request.EndLine, ref)
+}
+
+func (impl RepoManagerImpl) GenerateChangeLog(gitCtx git.GitContext, request *git.ChangeLogRequest) (*git.ChangeLog, error) {
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(request.PipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", request.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	toRef := request.ToRef
+	if len(toRef) == 0 {
+		if pipelineMaterial.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+			return nil, fmt.Errorf("to ref is required for non branch materials")
+		}
+		_, toRef = git.GetBranchReference(pipelineMaterial.Value)
+	}
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	return impl.repositoryManager.GenerateChangeLog(gitCtx, gitMaterial.CheckoutLocation, request.FromRef, toRef)
+}
+
+// SuggestNextVersion bumps the base version by the change log of the range, major for breaking changes, minor for
+// features and patch for any other commit
+func (impl RepoManagerImpl) SuggestNextVersion(gitCtx git.GitContext, request *git.VersionBumpRequest) (*git.VersionBumpResponse, error) {
+	if _, err := git.BumpVersion(request.BaseVersion, git.VERSION_BUMP_NONE); err != nil {
+		return nil, err
+	}
+	fromRef := request.FromRef
+	if len(fromRef) == 0 {
+		fromRef = request.BaseVersion
+	}
+	changeLog, err := impl.GenerateChangeLog(gitCtx, &git.ChangeLogRequest{PipelineMaterialId: request.PipelineMaterialId, FromRef: fromRef, ToRef: request.ToRef})
+	if err != nil {
+		return nil, err
+	}
+	bump := git.GetVersionBump(changeLog)
+	nextVersion, err := git.BumpVersion(request.BaseVersion, bump)
+	if err != nil {
+		return nil, err
+	}
+	commits := 0
+	for _, group := range changeLog.Groups {
+		commits += len(group.Commits)
+	}
+	return &git.VersionBumpResponse{
+		BaseVersion: request.BaseVersion,
+		NextVersion: nextVersion,
+		Bump:        bump,
+		Commits:     commits,
+		Truncated:   changeLog.Truncated,
+	}, nil
+}
+
+func (impl RepoManagerImpl) GetMergeBase(gitCtx git.GitContext, request *git.MergeBaseRequest) (*git.MergeBaseResponse, error) {
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	mergeBase, err := impl.repositoryManager.GetMergeBase(gitCtx, gitMaterial.CheckoutLocation, request.CommitA, request.CommitB)
+	if err != nil {
+		return nil, err
+	}
+	return &git.MergeBaseResponse{MergeBase: mergeBase}, nil
+}
+
+func (impl RepoManagerImpl) IsAncestor(gitCtx git.GitContext, request *git.IsAncestorRequest) (*git.IsAncestorResponse, error) {
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	isAncestor, err := impl.repositoryManager.IsAncestor(gitCtx, gitMaterial.CheckoutLocation, request.Ancestor, request.Descendant)
+	if err != nil {
+		return nil, err
+	}
+	return &git.IsAncestorResponse{IsAncestor: isAncestor}, nil
+}
+
+func (impl RepoManagerImpl) GetCommitHistory(request *git.CommitHistoryRequest) ([]*git.GitCommitBase, error) {
+	if !impl.configuration.PersistCommitHistory {
+		return nil, fmt.Errorf("commit history persistence is not enabled")
+	}
+	limit := request.Limit
+	if limit <= 0 || limit > git.COMMIT_HISTORY_MAX_LIMIT {
+		limit = git.COMMIT_HISTORY_MAX_LIMIT
+	}
+	var commits []*sql.GitCommit
+	var err error
+	if len(request.CommitHash) > 0 {
+		var commit *sql.GitCommit
+		commit, err = impl.gitCommitRepository.FindByHash(request.PipelineMaterialId, request.CommitHash)
+		if commit != nil {
+			commits = append(commits, commit)
+		}
+	} else if !request.From.IsZero() || !request.To.IsZero() {
+		commits, err = impl.gitCommitRepository.FindByDateRange(request.PipelineMaterialId, request.From, request.To, limit)
+	} else {
+		commits, err = impl.gitCommitRepository.FindLatest(request.PipelineMaterialId, limit)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in fetching commit history", "request", request, "err", err)
+		return nil, err
+	}
+	gitCommits := make([]*git.GitCommitBase, 0, len(commits))
+	for _, commit := range commits {
+		gitCommit := &git.GitCommitBase{}
+		err = json.Unmarshal([]byte(commit.CommitData), gitCommit)
+		if err != nil {
+			impl.logger.Errorw("error in unmarshalling persisted commit", "id", commit.Id, "err", err)
+			return nil, err
+		}
+		gitCommits = append(gitCommits, gitCommit)
+	}
+	return gitCommits, nil
+}
+
+// GetCommitsSince returns a page of the commit history of the branch. A page is read with git log starting at the commit
+// of the cursor, so the earlier pages are not read again, and the cursor commit itself is dropped
+func (impl RepoManagerImpl) GetCommitsSince(gitCtx git.GitContext, request *git.CommitsSinceRequest) (*git.CommitsSinceResponse, error) {
+	if !request.Since.IsZero() && !request.Until.IsZero() && request.Since.After(request.Until) {
+		return nil, fmt.Errorf("invalid time window, since %s is after until %s", request.Since, request.Until)
+	}
+	limit := request.Limit
+	if limit <= 0 || limit > git.COMMITS_SINCE_MAX_LIMIT {
+		limit = git.COMMITS_SINCE_MAX_LIMIT
+	}
+	var cursor *git.CommitCursor
+	var err error
+	if len(request.Cursor) > 0 {
+		cursor, err = git.DecodeCommitCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(request.PipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", request.PipelineMaterialId, "err", err)
+		return nil, err
+	}
+	if pipelineMaterial.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+		return nil, fmt.Errorf("commits since is supported only for branch materials")
+	}
+	gitMaterial, err := impl.getCheckedOutGitMaterial(gitCtx, request.PipelineMaterialId)
+	if err != nil {
+		return nil, err
+	}
+	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(gitMaterial.Id)
+	}()
+	gitCtx, err = impl.withRemoteAccess(gitCtx, gitMaterial)
+	if err != nil {
+		impl.logger.Errorw("error in resolving credentials of material", "gitMaterialId", gitMaterial.Id, "err", err)
+		return nil, err
+	}
+	gitCtx = gitCtx.WithTimeWindow(request.Since, request.Until)
+
+	to := ""
+	count := limit
+	if cursor != nil {
+		to = cursor.Commit
+		count = limit + 1
+	}
+	commits, err := impl.repositoryManager.ChangesSinceByRepository(gitCtx, nil, pipelineMaterial.Value, "", to, count, gitMaterial.CheckoutLocation, true)
+	if err != nil {
+		impl.logger.Errorw("error in getting commits since", "request", request, "err", err)
+		return nil, err
+	}
+	if cursor != nil {
+		if len(commits) == 0 || commits[0].Commit != cursor.Commit {
+			return nil, fmt.Errorf("commit %s of the cursor not found in history", cursor.Commit)
+		}
+		commits = commits[1:]
+	}
+	response := &git.CommitsSinceResponse{Commits: commits}
+	if len(commits) == limit {
+		response.NextCursor = git.EncodeCommitCursor(commits[len(commits)-1])
+	}
+	return response, nil
+}
+
+// SearchCommits searches the checkouts of the git materials one by one, a material which can't be searched is reported
+// with its error instead of failing the search
+func (impl RepoManagerImpl) SearchCommits(gitCtx git.GitContext, request *git.CommitSearchRequest) ([]*git.CommitSearchResult, error) {
+	if len(request.Query) == 0 && len(request.Author) == 0 {
+		return nil, git.ErrEmptyCommitSearch
+	}
+	var materials []*sql.GitMaterial
+	var err error
+	if len(request.GitMaterialIds) == 0 {
+		materials, err = impl.materialRepository.FindActive()
+		if err != nil {
+			impl.logger.Errorw("error in getting active materials", "err", err)
+			return nil, err
+		}
+	}
+	for _, gitMaterialId := range request.GitMaterialIds {
+		material, err := impl.materialRepository.FindById(gitMaterialId)
+		if err != nil {
+			impl.logger.Errorw("error in getting material", "gitMaterialId", gitMaterialId, "err", err)
+			return nil, err
+		}
+		materials = append(materials, material)
+	}
+	results := make([]*git.CommitSearchResult, 0, len(materials))
+	for _, material := range materials {
+		result := &git.CommitSearchResult{GitMaterialId: material.Id, Url: material.Url}
+		result.Commits, err = impl.searchMaterialCommits(gitCtx, material, request)
+		if err != nil {
+			impl.logger.Errorw("error in searching commits of material", "gitMaterialId", material.Id, "err", err)
+			result.ErrorMsg = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (impl RepoManagerImpl) searchMaterialCommits(gitCtx git.GitContext, material *sql.GitMaterial, request *git.CommitSearchRequest) ([]*git.GitCommitBase, error) {
+	if !material.CheckoutStatus {
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(material.Url))
+	}
+	repoLock := impl.locker.LeaseLocker(material.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(material.Id)
+	}()
+	gitCtx = gitCtx.WithExtraGitConfig(material.GitConfig)
+	return impl.gitManager.SearchCommits(gitCtx, material.CheckoutLocation, request)
+}
+
 func (impl RepoManagerImpl) GetLatestCommitForBranch(gitCtx git.GitContext, pipelineMaterialId int, branchName string) (*git.GitCommitBase, error) {
 	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(pipelineMaterialId)
 
@@ -653,8 +1619,12 @@ func (impl RepoManagerImpl) GetLatestCommitForBranch(gitCtx git.GitContext, pipe
 		return nil, err
 	}
 
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
 	if !gitMaterial.CheckoutStatus {
-		return nil, fmt.Errorf("checkout not succeed please checkout first %s", gitMaterial.Url)
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
 	}
 
 	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
@@ -664,16 +1634,17 @@ func (impl RepoManagerImpl) GetLatestCommitForBranch(gitCtx git.GitContext, pipe
 		impl.locker.ReturnLocker(gitMaterial.Id)
 	}()
 
-	userName, password, err := git.GetUserNamePassword(gitMaterial.GitProvider)
-
-	gitCtx = gitCtx.WithCredentials(userName, password).
-		WithTLSData(gitMaterial.GitProvider.CaCert, gitMaterial.GitProvider.TlsKey, gitMaterial.GitProvider.TlsCert, gitMaterial.GitProvider.EnableTLSVerification)
-	updated, repo, err := impl.repositoryManager.Fetch(gitCtx, gitMaterial.Url, gitMaterial.CheckoutLocation)
+	gitCtx, err = impl.withRemoteAccess(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
+	updated, repo, fetchedFrom, err := impl.repositoryManager.FetchWithMirrors(gitCtx, gitMaterial.Url, gitMaterial.MirrorUrls, gitMaterial.CheckoutLocation)
 	if !updated {
 		impl.logger.Warn("repository is up to date")
 	}
 	if err == nil {
 		gitMaterial.CheckoutStatus = true
+		gitMaterial.LastFetchRemote = fetchedFrom
 	} else {
 		gitMaterial.CheckoutStatus = false
 		gitMaterial.CheckoutMsgAny = err.Error()
@@ -704,6 +1675,7 @@ func (impl RepoManagerImpl) GetLatestCommitForBranch(gitCtx git.GitContext, pipe
 	if commits == nil {
 		return nil, err
 	} else {
+		impl.ticketExtractor.Annotate(commits[0])
 		return commits[0], err
 	}
 }
@@ -735,10 +1707,15 @@ func (impl RepoManagerImpl) GetCommitMetadataForPipelineMaterial(gitCtx git.GitC
 	}
 
 	gitCtx = gitCtx.WithCredentials(gitMaterial.GitProvider.UserName, gitMaterial.GitProvider.Password).
-		WithTLSData(gitMaterial.GitProvider.CaCert, gitMaterial.GitProvider.TlsKey, gitMaterial.GitProvider.TlsCert, gitMaterial.GitProvider.EnableTLSVerification) // validate checkout status of gitMaterial
+		WithTLSData(gitMaterial.GitProvider.CaCert, gitMaterial.GitProvider.TlsKey, gitMaterial.GitProvider.TlsCert, gitMaterial.GitProvider.EnableTLSVerification).
+		WithExtraGitConfig(gitMaterial.GitConfig) // validate checkout status of gitMaterial
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
 	if !gitMaterial.CheckoutStatus {
 		impl.logger.Errorw("checkout not success", "gitMaterialId", gitMaterialId)
-		return nil, fmt.Errorf("checkout not succeed please checkout first %s", gitMaterial.Url)
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
 	}
 
 	// lock-unlock
@@ -771,6 +1748,7 @@ func (impl RepoManagerImpl) GetCommitMetadataForPipelineMaterial(gitCtx git.GitC
 	commit := commits[0]
 	excluded := impl.gitManager.PathMatcher(commit.FileStats, gitMaterial)
 	commit.Excluded = excluded
+	impl.ticketExtractor.Annotate(commit)
 	return commits[0], err
 }
 
@@ -783,8 +1761,12 @@ func (impl RepoManagerImpl) GetReleaseChanges(gitCtx git.GitContext, request *Re
 	if err != nil {
 		return nil, err
 	}
+	gitMaterial, err = impl.checkoutApiModeMaterial(gitCtx, gitMaterial)
+	if err != nil {
+		return nil, err
+	}
 	if !gitMaterial.CheckoutStatus {
-		return nil, fmt.Errorf("checkout not succeed please checkout first %s", gitMaterial.Url)
+		return nil, fmt.Errorf("checkout not succeed please checkout first %s", tracing.SanitizeUrl(gitMaterial.Url))
 	}
 	repoLock := impl.locker.LeaseLocker(gitMaterial.Id)
 	repoLock.Mutex.Lock()
@@ -794,7 +1776,8 @@ func (impl RepoManagerImpl) GetReleaseChanges(gitCtx git.GitContext, request *Re
 	}()
 
 	gitCtx = gitCtx.WithCredentials(gitMaterial.GitProvider.UserName, gitMaterial.GitProvider.Password).
-		WithTLSData(gitMaterial.GitProvider.CaCert, gitMaterial.GitProvider.TlsKey, gitMaterial.GitProvider.TlsCert, gitMaterial.GitProvider.EnableTLSVerification)
+		WithTLSData(gitMaterial.GitProvider.CaCert, gitMaterial.GitProvider.TlsKey, gitMaterial.GitProvider.TlsCert, gitMaterial.GitProvider.EnableTLSVerification).
+		WithExtraGitConfig(gitMaterial.GitConfig)
 
 	gitChanges, err := impl.repositoryManagerAnalytics.ChangesSinceByRepositoryForAnalytics(gitCtx, gitMaterial.CheckoutLocation, request.OldCommit, request.NewCommit)
 	if err != nil {
@@ -830,6 +1813,59 @@ func (impl RepoManagerImpl) RefreshGitMaterial(req *git.RefreshGitMaterialReques
 	return res, err
 }
 
+// RefreshMaterial polls the git material of the pipeline material right away and returns the commits found by the
+// poll, newest first. The poll goes on in the background when it takes longer than REFRESH_MATERIAL_TIMEOUT_IN_SEC
+func (impl RepoManagerImpl) RefreshMaterial(gitCtx git.GitContext, pipelineMaterialId int) (*git.MaterialChangeResp, error) {
+	pipelineMaterial, err := impl.ciPipelineMaterialRepository.FindById(pipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline material ", "pipelineMaterialId", pipelineMaterialId, "err", err)
+		return nil, err
+	}
+	if pipelineMaterial.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+		return nil, fmt.Errorf("only branch materials can be refreshed, type of material %d is %s", pipelineMaterialId, pipelineMaterial.Type)
+	}
+	lastSeenHash := pipelineMaterial.LastSeenHash
+	polled := make(chan error, 1)
+	go func() {
+		// lock inside watcher itself
+		_, err := impl.gitWatcher.PollAndUpdateGitMaterial(&sql.GitMaterial{Id: pipelineMaterial.GitMaterialId})
+		polled <- err
+	}()
+	var timeout <-chan time.Time
+	if impl.configuration.RefreshMaterialTimeoutInSec > 0 {
+		timeout = time.After(time.Duration(impl.configuration.RefreshMaterialTimeoutInSec) * time.Second)
+	}
+	select {
+	case err = <-polled:
+	case <-timeout:
+		return nil, fmt.Errorf("refresh of material %d is still in progress: %w", pipelineMaterial.GitMaterialId, git.ErrTimeout)
+	case <-gitCtx.Done():
+		return nil, gitCtx.Err()
+	}
+	if err != nil {
+		impl.logger.Errorw("error in refreshing material", "gitMaterialId", pipelineMaterial.GitMaterialId, "err", err)
+		return nil, err
+	}
+	// fetch errors are not returned by the poll, they are reported as repo and branch errors of the changes
+	response, err := impl.FetchChanges(pipelineMaterialId, "", "", 0, false)
+	if err != nil {
+		return nil, err
+	}
+	response.Commits = commitsNewerThan(response.Commits, lastSeenHash)
+	return response, nil
+}
+
+// commitsNewerThan returns the commits of the newest first history before the last seen commit, all of them when it
+// is no longer in the history
+func commitsNewerThan(commits []*git.GitCommitBase, lastSeenHash string) []*git.GitCommitBase {
+	for i, commit := range commits {
+		if commit.Commit == lastSeenHash {
+			return commits[:i]
+		}
+	}
+	return commits
+}
+
 func (impl RepoManagerImpl) GetWebhookAndCiDataById(id int, ciPipelineMaterialId int) (*git.WebhookAndCiData, error) {
 
 	impl.logger.Debugw("Getting webhook data ", "id", id)
@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// memoryGitCommitRepository answers the history queries from the commits, newest first, and records the query used
+type memoryGitCommitRepository struct {
+	commits []*sql.GitCommit
+	err     error
+	query   string
+	limit   int
+}
+
+func (impl *memoryGitCommitRepository) SaveAll(commits []*sql.GitCommit) error {
+	impl.commits = append(impl.commits, commits...)
+	return nil
+}
+
+func (impl *memoryGitCommitRepository) FindByHash(ciPipelineMaterialId int, commitHash string) (*sql.GitCommit, error) {
+	impl.query = "hash"
+	for _, commit := range impl.commits {
+		if commit.CiPipelineMaterialId == ciPipelineMaterialId && commit.CommitHash == commitHash {
+			return commit, impl.err
+		}
+	}
+	return nil, impl.err
+}
+
+func (impl *memoryGitCommitRepository) FindByDateRange(ciPipelineMaterialId int, from time.Time, to time.Time, limit int) ([]*sql.GitCommit, error) {
+	impl.query, impl.limit = "dateRange", limit
+	var commits []*sql.GitCommit
+	for _, commit := range impl.commits {
+		if commit.CiPipelineMaterialId != ciPipelineMaterialId || len(commits) == limit {
+			continue
+		}
+		if (from.IsZero() || !commit.CommitDate.Before(from)) && (to.IsZero() || !commit.CommitDate.After(to)) {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, impl.err
+}
+
+func (impl *memoryGitCommitRepository) FindLatest(ciPipelineMaterialId int, limit int) ([]*sql.GitCommit, error) {
+	impl.query, impl.limit = "latest", limit
+	var commits []*sql.GitCommit
+	for _, commit := range impl.commits {
+		if commit.CiPipelineMaterialId == ciPipelineMaterialId && len(commits) < limit {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, impl.err
+}
+
+func TestRepoManagerImpl_GetCommitHistory(t *testing.T) {
+	logger, _ := utils.NewSugardLogger()
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	persisted := func(hash string, date time.Time) *sql.GitCommit {
+		commitData, _ := json.Marshal(&git.GitCommitBase{Commit: hash, Date: date, Message: "message of " + hash})
+		return &sql.GitCommit{CiPipelineMaterialId: 1, CommitHash: hash, CommitDate: date, CommitData: string(commitData)}
+	}
+	commits := []*sql.GitCommit{
+		persisted("c3", now),
+		persisted("c2", now.Add(-24*time.Hour)),
+		persisted("c1", now.Add(-48*time.Hour)),
+	}
+	tests := []struct {
+		name       string
+		disabled   bool
+		commits    []*sql.GitCommit
+		repoErr    error
+		request    *git.CommitHistoryRequest
+		wantQuery  string
+		wantLimit  int
+		wantHashes []string
+		wantErr    bool
+	}{
+		{name: "history persistence disabled", disabled: true, request: &git.CommitHistoryRequest{PipelineMaterialId: 1}, wantErr: true},
+		{name: "latest commits", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1, Limit: 2}, wantQuery: "latest", wantLimit: 2, wantHashes: []string{"c3", "c2"}},
+		{name: "missing limit is the max limit", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1}, wantQuery: "latest", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantHashes: []string{"c3", "c2", "c1"}},
+		{name: "limit above the max limit", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1, Limit: git.COMMIT_HISTORY_MAX_LIMIT + 1}, wantQuery: "latest", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantHashes: []string{"c3", "c2", "c1"}},
+		{name: "commit by hash", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1, CommitHash: "c2"}, wantQuery: "hash", wantHashes: []string{"c2"}},
+		{name: "unknown hash", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1, CommitHash: "c9"}, wantQuery: "hash", wantHashes: []string{}},
+		{name: "commits of a date range", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 1, From: now.Add(-36 * time.Hour)}, wantQuery: "dateRange", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantHashes: []string{"c3", "c2"}},
+		{name: "other material", commits: commits, request: &git.CommitHistoryRequest{PipelineMaterialId: 2}, wantQuery: "latest", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantHashes: []string{}},
+		{name: "repository error", commits: commits, repoErr: errors.New("db down"), request: &git.CommitHistoryRequest{PipelineMaterialId: 1}, wantQuery: "latest", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantErr: true},
+		{name: "corrupt commit data", commits: []*sql.GitCommit{{CiPipelineMaterialId: 1, CommitHash: "c1", CommitData: "{"}}, request: &git.CommitHistoryRequest{PipelineMaterialId: 1}, wantQuery: "latest", wantLimit: git.COMMIT_HISTORY_MAX_LIMIT, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &memoryGitCommitRepository{commits: tt.commits, err: tt.repoErr}
+			impl := RepoManagerImpl{
+				logger:              logger,
+				configuration:       &internals.Configuration{PersistCommitHistory: !tt.disabled},
+				gitCommitRepository: repository,
+			}
+			got, err := impl.GetCommitHistory(tt.request)
+			assert.Equal(t, tt.wantQuery, repository.query)
+			assert.Equal(t, tt.wantLimit, repository.limit)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			hashes := make([]string, 0, len(got))
+			for _, commit := range got {
+				hashes = append(hashes, commit.Commit)
+				assert.Equal(t, "message of "+commit.Commit, commit.Message)
+			}
+			assert.Equal(t, tt.wantHashes, hashes)
+		})
+	}
+}
+
+// memoryCiPipelineMaterialRepository finds the pipeline materials by id
+type memoryCiPipelineMaterialRepository struct {
+	sql.CiPipelineMaterialRepository
+	materials map[int]*sql.CiPipelineMaterial
+}
+
+func (impl *memoryCiPipelineMaterialRepository) FindById(id int) (*sql.CiPipelineMaterial, error) {
+	material, ok := impl.materials[id]
+	if !ok {
+		return nil, errors.New("pg: no rows in result set")
+	}
+	found := *material
+	return &found, nil
+}
+
+// memoryMaterialRepository finds the git materials by id
+type memoryMaterialRepository struct {
+	sql.MaterialRepository
+	materials map[int]*sql.GitMaterial
+}
+
+func (impl *memoryMaterialRepository) FindById(id int) (*sql.GitMaterial, error) {
+	material, ok := impl.materials[id]
+	if !ok {
+		return nil, errors.New("pg: no rows in result set")
+	}
+	return material, nil
+}
+
+// pollingGitWatcher runs poll for the polled material, a nil poll blocks the poll until release is closed
+type pollingGitWatcher struct {
+	git.GitWatcher
+	poll    func(material *sql.GitMaterial) error
+	release chan struct{}
+}
+
+func (impl *pollingGitWatcher) PollAndUpdateGitMaterial(material *sql.GitMaterial) (*sql.GitMaterial, error) {
+	if impl.poll == nil {
+		<-impl.release
+		return material, nil
+	}
+	return material, impl.poll(material)
+}
+
+func TestRepoManagerImpl_RefreshMaterial(t *testing.T) {
+	logger, _ := utils.NewSugardLogger()
+	history := func(hashes ...string) string {
+		commits := make([]*git.GitCommitBase, 0, len(hashes))
+		for _, hash := range hashes {
+			commits = append(commits, &git.GitCommitBase{Commit: hash})
+		}
+		commitHistory, _ := json.Marshal(commits)
+		return string(commitHistory)
+	}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name               string
+		materialType       sql.SourceType
+		lastSeenHash       string
+		pipelineMaterialId int
+		ctx                context.Context
+		poll               func(pipelineMaterial *sql.CiPipelineMaterial) error
+		wantHashes         []string
+		wantRepoError      bool
+		wantErr            string
+	}{
+		{
+			name: "new commits of the poll", lastSeenHash: "c1",
+			poll: func(pipelineMaterial *sql.CiPipelineMaterial) error {
+				pipelineMaterial.LastSeenHash, pipelineMaterial.CommitHistory = "c3", history("c3", "c2", "c1")
+				return nil
+			},
+			wantHashes: []string{"c3", "c2"},
+		},
+		{
+			name: "no new commits", lastSeenHash: "c1",
+			poll:       func(pipelineMaterial *sql.CiPipelineMaterial) error { return nil },
+			wantHashes: []string{},
+		},
+		{
+			name: "last seen commit is no longer in the history", lastSeenHash: "c0",
+			poll: func(pipelineMaterial *sql.CiPipelineMaterial) error {
+				pipelineMaterial.LastSeenHash, pipelineMaterial.CommitHistory = "c3", history("c3", "c2")
+				return nil
+			},
+			wantHashes: []string{"c3", "c2"},
+		},
+		{
+			name: "fetch error of the poll is reported in the changes", lastSeenHash: "c1",
+			poll: func(pipelineMaterial *sql.CiPipelineMaterial) error {
+				pipelineMaterial.Errored, pipelineMaterial.ErrorMsg = true, "authentication failed"
+				return nil
+			},
+			wantRepoError: true,
+		},
+		{name: "unknown pipeline material", pipelineMaterialId: 2, wantErr: "pg: no rows in result set"},
+		{name: "webhook material", materialType: sql.SOURCE_TYPE_WEBHOOK, wantErr: "only branch materials can be refreshed, type of material 1 is WEBHOOK"},
+		{
+			name: "poll error", lastSeenHash: "c1",
+			poll:    func(pipelineMaterial *sql.CiPipelineMaterial) error { return errors.New("material is locked") },
+			wantErr: "material is locked",
+		},
+		{name: "poll slower than the timeout", lastSeenHash: "c1", wantErr: git.ErrTimeout.Error()},
+		{name: "cancelled call", lastSeenHash: "c1", ctx: cancelledCtx, wantErr: context.Canceled.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			materialType := tt.materialType
+			if len(materialType) == 0 {
+				materialType = sql.SOURCE_TYPE_BRANCH_FIXED
+			}
+			pipelineMaterial := &sql.CiPipelineMaterial{Id: 1, GitMaterialId: 10, Type: materialType, LastSeenHash: tt.lastSeenHash, CommitHistory: history(tt.lastSeenHash)}
+			watcher := &pollingGitWatcher{release: make(chan struct{})}
+			defer close(watcher.release)
+			if tt.poll != nil {
+				watcher.poll = func(material *sql.GitMaterial) error {
+					assert.Equal(t, 10, material.Id)
+					return tt.poll(pipelineMaterial)
+				}
+			}
+			impl := RepoManagerImpl{
+				logger:                       logger,
+				configuration:                &internals.Configuration{RefreshMaterialTimeoutInSec: 1},
+				ciPipelineMaterialRepository: &memoryCiPipelineMaterialRepository{materials: map[int]*sql.CiPipelineMaterial{1: pipelineMaterial}},
+				materialRepository:           &memoryMaterialRepository{materials: map[int]*sql.GitMaterial{10: {Id: 10}}},
+				gitWatcher:                   watcher,
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			pipelineMaterialId := tt.pipelineMaterialId
+			if pipelineMaterialId == 0 {
+				pipelineMaterialId = 1
+			}
+			got, err := impl.RefreshMaterial(git.BuildGitContext(ctx), pipelineMaterialId)
+			if len(tt.wantErr) > 0 {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRepoError, got.IsRepoError)
+			if tt.wantRepoError {
+				return
+			}
+			hashes := make([]string, 0, len(got.Commits))
+			for _, commit := range got.Commits {
+				hashes = append(hashes, commit.Commit)
+			}
+			assert.Equal(t, tt.wantHashes, hashes)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	ARCHIVE_FORMAT_TAR    = "tar"
+	ARCHIVE_FORMAT_TAR_GZ = "tar.gz"
+	ARCHIVE_FORMAT_ZIP    = "zip"
+)
+
+var archiveContentTypes = map[string]string{
+	ARCHIVE_FORMAT_TAR:    "application/x-tar",
+	ARCHIVE_FORMAT_TAR_GZ: "application/gzip",
+	ARCHIVE_FORMAT_ZIP:    "application/zip",
+}
+
+// GetArchiveContentType returns the mime type of the archive format, empty for unsupported formats
+func GetArchiveContentType(format string) string {
+	if len(format) == 0 {
+		format = ARCHIVE_FORMAT_TAR
+	}
+	return archiveContentTypes[format]
+}
+
+// GetArchiveFileContentType returns the mime type of the archive file by its extension, empty for other files
+func GetArchiveFileContentType(fileName string) string {
+	for _, format := range []string{ARCHIVE_FORMAT_TAR_GZ, ARCHIVE_FORMAT_TAR, ARCHIVE_FORMAT_ZIP} {
+		if strings.HasSuffix(fileName, "."+format) {
+			return archiveContentTypes[format]
+		}
+	}
+	return ""
+}
+
+// WriteArchive streams git archive of the commit to the writer, limited to the given paths when set. The archive is
+// not buffered, so nothing is written to the writer when git fails before producing any output
+func (impl *GitManagerBaseImpl) WriteArchive(gitCtx GitContext, rootDir, commitHash, format string, paths []string, writer io.Writer) error {
+	if len(format) == 0 {
+		format = ARCHIVE_FORMAT_TAR
+	}
+	if len(commitHash) == 0 || strings.HasPrefix(commitHash, "-") || len(GetArchiveContentType(format)) == 0 {
+		return fmt.Errorf("invalid archive request, commit: %q format: %q", commitHash, format)
+	}
+	cmdArgs := []string{"-C", rootDir, "archive", "--format=" + format, commitHash, "--"}
+	for _, path := range paths {
+		if path = strings.Trim(path, "/"); len(path) > 0 {
+			cmdArgs = append(cmdArgs, path)
+		}
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	stdout, wait, err := impl.StreamCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in starting git archive", "rootDir", rootDir, "err", err)
+		return err
+	}
+	_, copyErr := io.Copy(writer, stdout)
+	if copyErr != nil {
+		// unblock git if the writer went away, wait would hang on a full pipe otherwise
+		_ = stdout.Close()
+	}
+	_, err = wait()
+	if copyErr != nil {
+		return copyErr
+	}
+	return err
+}
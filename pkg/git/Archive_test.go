@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryManager_WriteArchive(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.MkdirAll(filepath.Join(workDir, "app"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app", "main.go"), []byte("package main\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "notes.txt"), []byte("notes\n"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add app")
+	commit := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	gitCtx := BuildGitContext(context.Background())
+	archive := &bytes.Buffer{}
+	err := impl.WriteArchive(gitCtx, workDir, commit, ARCHIVE_FORMAT_TAR, []string{"/app/"}, archive)
+	assert.Nil(t, err)
+	var names []string
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Contains(t, names, "app/main.go")
+	assert.NotContains(t, names, "notes.txt")
+
+	archive.Reset()
+	err = impl.WriteArchive(gitCtx, workDir, commit, ARCHIVE_FORMAT_ZIP, nil, archive)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(archive.Bytes(), []byte("PK")))
+
+	archive.Reset()
+	assert.NotNil(t, impl.WriteArchive(gitCtx, workDir, commit, "rar", nil, archive))
+	assert.NotNil(t, impl.WriteArchive(gitCtx, workDir, "0000000000000000000000000000000000000000", ARCHIVE_FORMAT_TAR, nil, archive))
+	assert.Zero(t, archive.Len())
+}
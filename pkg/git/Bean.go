@@ -23,6 +23,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"io"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
@@ -46,7 +47,16 @@ type CiPipelineMaterialBean struct {
 	Value                     string
 	Active                    bool
 	GitCommit                 *GitCommitBase
-	ExtraEnvironmentVariables map[string]string // extra env variables which will be used for CI
+	ExtraEnvironmentVariables map[string]string    // extra env variables which will be used for CI
+	ForcePushed               bool                 `json:",omitempty"` // last seen commit is not reachable from the new head
+	OrphanedCommits           []string             `json:",omitempty"` // commits dropped from the branch by the force push, newest first
+	Branch                    string               `json:",omitempty"` // branch matching the regex of a branch regex material
+	BranchCreated             bool                 `json:",omitempty"`
+	BranchDeleted             bool                 `json:",omitempty"`
+	PullRequest               *PullRequestMaterial `json:",omitempty"` // set for pull request materials, notified from the provider webhooks
+	// NewCommits are the commits of the branch since the last poll, newest first, for the commit events. Only the
+	// latest commit is notified to ci
+	NewCommits []*GitCommitBase `json:"-"`
 }
 
 type GitRepository struct {
@@ -56,6 +66,8 @@ type GitRepository struct {
 
 type CommitIterator interface {
 	Next() (GitCommit, error)
+	// Close releases the resources held by the iterator, it must be called when the iteration is stopped early
+	Close()
 }
 
 type CommitCliIterator struct {
@@ -65,13 +77,62 @@ type CommitCliIterator struct {
 
 type CommitGoGitIterator struct {
 	object.CommitIter
+	mailmap *Mailmap
+}
+
+// getGoGitCommitFileStats returns the file stats of the commit against its first parent. go-git leaves the binary files
+// out of its stats, they are added from the patch with the binary flag
+func getGoGitCommitFileStats(commit *object.Commit) (FileStats, error) {
+	toTree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	fromTree := &object.Tree{}
+	if commit.NumParents() != 0 {
+		parent, err := commit.Parents().Next()
+		if err != nil {
+			return nil, err
+		}
+		fromTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	patch, err := fromTree.Patch(toTree)
+	if err != nil {
+		return nil, err
+	}
+	fileStats := transformFileStats(patch.Stats())
+	for _, filePatch := range patch.FilePatches() {
+		if !filePatch.IsBinary() {
+			continue
+		}
+		from, to := filePatch.Files()
+		fileStat := FileStat{Binary: true}
+		if to != nil {
+			fileStat.Name = to.Path()
+		}
+		if from != nil && to == nil {
+			fileStat.Name = from.Path()
+		} else if from != nil && from.Path() != to.Path() {
+			fileStat.OldPath = from.Path()
+		}
+		fileStats = append(fileStats, fileStat)
+	}
+	return fileStats, nil
 }
 
 func transformFileStats(stats object.FileStats) FileStats {
 	fileStatList := make([]FileStat, 0)
 	for _, stat := range stats {
+		// go-git names renamed files as <old path> => <new path>
+		oldPath, name, renamed := strings.Cut(stat.Name, " => ")
+		if !renamed {
+			oldPath, name = "", stat.Name
+		}
 		fileStatList = append(fileStatList, FileStat{
-			Name:     stat.Name,
+			Name:     name,
+			OldPath:  oldPath,
 			Addition: stat.Addition,
 			Deletion: stat.Deletion,
 		})
@@ -84,18 +145,28 @@ func (itr *CommitGoGitIterator) Next() (GitCommit, error) {
 	if err != nil {
 		return nil, err
 	}
-	gitCommit := GitCommitBase{
-		Author:  commit.Author.String(),
-		Commit:  commit.Hash.String(),
-		Date:    commit.Author.When,
-		Message: commit.Message,
-	}
 	return &GitCommitGoGit{
-		GitCommitBase: gitCommit,
+		GitCommitBase: newGoGitCommitBase(commit, itr.mailmap),
 		Cm:            commit,
 	}, err
 }
 
+// newGoGitCommitBase builds the commit with the author and committer mapped through the mailmap
+func newGoGitCommitBase(commit *object.Commit, mailmap *Mailmap) GitCommitBase {
+	authorName, authorEmail := mailmap.Resolve(commit.Author.Name, commit.Author.Email)
+	committerName, committerEmail := mailmap.Resolve(commit.Committer.Name, commit.Committer.Email)
+	return GitCommitBase{
+		Author:          authorName + " <" + authorEmail + ">",
+		AuthorDetail:    &Author{Name: authorName, Email: authorEmail, Date: commit.Author.When},
+		CommitterDetail: &Committer{Name: committerName, Email: committerEmail, Date: commit.Committer.When},
+		Commit:          commit.Hash.String(),
+		Parents:         getParentHashes(commit),
+		Date:            commit.Author.When,
+		Message:         commit.Message,
+		Trailers:        ParseTrailersFromMessage(commit.Message),
+	}.withConventionalCommit().withTrailerIdentities()
+}
+
 func (itr *CommitCliIterator) Next() (GitCommit, error) {
 
 	if itr.index < len(itr.commits) {
@@ -106,13 +177,16 @@ func (itr *CommitCliIterator) Next() (GitCommit, error) {
 	return nil, io.EOF
 }
 
+func (itr *CommitCliIterator) Close() {}
+
 type MaterialChangeResp struct {
-	Commits        []*GitCommitBase `json:"commits"`
-	LastFetchTime  time.Time        `json:"lastFetchTime"`
-	IsRepoError    bool             `json:"isRepoError"`
-	RepoErrorMsg   string           `json:"repoErrorMsg"`
-	IsBranchError  bool             `json:"isBranchError"`
-	BranchErrorMsg string           `json:"branchErrorMsg"`
+	Commits         []*GitCommitBase `json:"commits"`
+	LastFetchTime   time.Time        `json:"lastFetchTime"`
+	IsRepoError     bool             `json:"isRepoError"`
+	RepoErrorMsg    string           `json:"repoErrorMsg"`
+	IsBranchError   bool             `json:"isBranchError"`
+	BranchErrorMsg  string           `json:"branchErrorMsg"`
+	IsBranchDeleted bool             `json:"isBranchDeleted"`
 }
 
 type GitCommit interface {
@@ -133,14 +207,47 @@ func (gitCommit *GitCommitBase) GetCommit() *GitCommitBase {
 }
 
 type GitCommitBase struct {
-	Commit      string
-	Author      string
-	Date        time.Time
-	Message     string
-	Changes     []string     `json:",omitempty"`
-	FileStats   *FileStats   `json:",omitempty"`
-	WebhookData *WebhookData `json:"webhookData"`
-	Excluded    bool         `json:",omitempty"`
+	Commit   string
+	Parents  []string `json:",omitempty"` // first parent first, merge commits have more than one
+	Author   string   // canonical "name <email>" after applying the mailmap, of the committer with the git cli and of the author with go-git
+	Date     time.Time
+	Message  string
+	Trailers map[string][]string `json:",omitempty"`
+	// SignedOffBy, CoAuthoredBy and ReviewedBy are the identities of the attribution trailers of the message
+	SignedOffBy  []*TrailerIdentity `json:",omitempty"`
+	CoAuthoredBy []*TrailerIdentity `json:",omitempty"`
+	ReviewedBy   []*TrailerIdentity `json:",omitempty"`
+	// Notes are the git notes of the commit keyed by the notes ref name, fetched when FETCH_GIT_NOTES is set
+	Notes map[string]string `json:",omitempty"`
+	// AuthorDetail and CommitterDetail are the mailmapped identities with their dates
+	AuthorDetail    *Author    `json:",omitempty"`
+	CommitterDetail *Committer `json:",omitempty"`
+	// CommitType, CommitScope and BreakingChange are parsed from the subject when it follows the conventional commits spec
+	CommitType     string             `json:",omitempty"`
+	CommitScope    string             `json:",omitempty"`
+	BreakingChange bool               `json:",omitempty"`
+	Tag            *GitTag            `json:",omitempty"` // set for commits of tag materials
+	Branch         string             `json:",omitempty"` // set for commits of branch regex materials
+	Submodules     []*SubmoduleChange `json:",omitempty"`
+	Signature      *CommitSignature   `json:",omitempty"`
+	Changes        []string           `json:",omitempty"`
+	FileStats      *FileStats         `json:",omitempty"`
+	ChangedFiles   []*FileChange      `json:",omitempty"`
+	WebhookData    *WebhookData       `json:"webhookData"`
+	Excluded       bool               `json:",omitempty"`
+	// PolicyVerdict is set for the commits found by the watcher when a commit policy is configured
+	PolicyVerdict *CommitPolicyVerdict `json:",omitempty"`
+	ForcePush     *ForcePush           `json:",omitempty"` // set on the new head of a branch whose history was rewritten
+	Tickets       []*CommitTicket      `json:",omitempty"` // ticket ids referenced in the message, with COMMIT_TICKET_PATTERNS
+	// ContainsBinaryChanges is set with the file stats when a binary file is changed by the commit
+	ContainsBinaryChanges bool `json:",omitempty"`
+}
+
+// ForcePush is the history of a branch rewritten by a force push, OrphanedCommits are the commits up to PreviousHead
+// which are no longer reachable from the branch. They are left out when the old commits are already pruned
+type ForcePush struct {
+	PreviousHead    string
+	OrphanedCommits []string `json:",omitempty"`
 }
 
 func AppendOldCommitsFromHistory(newCommits []*GitCommitBase, commitHistory string, fetchedCount int) ([]*GitCommitBase, error) {
@@ -161,6 +268,7 @@ func AppendOldCommitsFromHistory(newCommits []*GitCommitBase, commitHistory stri
 
 func (gitCommit *GitCommitBase) SetFileStats(stats *FileStats) {
 	gitCommit.FileStats = stats
+	gitCommit.ContainsBinaryChanges = stats != nil && stats.ContainsBinary()
 }
 
 func (gitCommit *GitCommitBase) TruncateMessageIfExceedsMaxLength() {
@@ -198,10 +306,187 @@ type WebhookData struct {
 }
 
 type CommitMetadataRequest struct {
+	PipelineMaterialId    int    `json:"pipelineMaterialId"`
+	GitHash               string `json:"gitHash"`
+	GitTag                string `json:"gitTag"`
+	BranchName            string `json:"branchName"`
+	ResolveSubmodules     bool   `json:"resolveSubmodules"`     // report the submodule pointer changes of the commit
+	FetchSubmoduleCommits bool   `json:"fetchSubmoduleCommits"` // also fetch the submodules to report their commits
+}
+
+// CommitsMetadataRequest asks for the metadata of many commits of a material in one call
+type CommitsMetadataRequest struct {
+	PipelineMaterialId int      `json:"pipelineMaterialId"`
+	GitHashes          []string `json:"gitHashes"`
+}
+
+type CommitsMetadataResponse struct {
+	Commits       []*GitCommitBase `json:"commits"`       // in the order of the hashes asked for
+	MissingHashes []string         `json:"missingHashes"` // hashes which are not commits of the material
+}
+
+type FileContentRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	GitHash            string `json:"gitHash"`
+	Path               string `json:"path"`
+}
+
+type LfsPointersRequest struct {
 	PipelineMaterialId int    `json:"pipelineMaterialId"`
 	GitHash            string `json:"gitHash"`
-	GitTag             string `json:"gitTag"`
-	BranchName         string `json:"branchName"`
+}
+
+// LfsPointersResponse lists the changed files of the commit whose content is stored in git lfs
+type LfsPointersResponse struct {
+	Commit   string        `json:"commit"`
+	Pointers []*LfsPointer `json:"pointers"`
+}
+
+// ArchiveRequest asks for the source snapshot of the commit as a tar, tar.gz or zip archive, limited to Paths when set
+type ArchiveRequest struct {
+	PipelineMaterialId int      `json:"pipelineMaterialId"`
+	GitHash            string   `json:"gitHash"`
+	Format             string   `json:"format"`
+	Paths              []string `json:"paths"`
+}
+
+type CloneJobRequest struct {
+	GitMaterialId int    `json:"gitMaterialId"`
+	CloningMode   string `json:"cloningMode"`
+}
+
+// DeepenJobRequest asks to fetch DeepenBy more commits of the history of a shallow checkout
+type DeepenJobRequest struct {
+	GitMaterialId int `json:"gitMaterialId"`
+	DeepenBy      int `json:"deepenBy"`
+}
+
+// MaterialBranch is a branch of a git material with its protection at the git provider, Commit and Date are not known
+// for the materials in api mode
+type MaterialBranch struct {
+	Name      string    `json:"name"`
+	Commit    string    `json:"commit,omitempty"`
+	Date      time.Time `json:"date"`
+	Protected bool      `json:"protected"`
+}
+
+// CommitDiffRequest asks for the diff from FromCommit to ToCommit, limited to Paths when set. ContextLines defaults to
+// the 3 lines of git when not set
+type CommitDiffRequest struct {
+	PipelineMaterialId int      `json:"pipelineMaterialId"`
+	FromCommit         string   `json:"fromCommit"`
+	ToCommit           string   `json:"toCommit"`
+	Paths              []string `json:"paths"`
+	ContextLines       *int     `json:"contextLines"`
+}
+
+// BlameRequest asks for the blame of the lines from StartLine to EndLine of the file, the whole file when the lines
+// are not set. Ref defaults to the branch of a branch material
+type BlameRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	Path               string `json:"path"`
+	StartLine          int    `json:"startLine"`
+	EndLine            int    `json:"endLine"`
+	Ref                string `json:"ref"`
+}
+
+// ChangeLogRequest asks for the change log between two refs of a material, ToRef defaults to the branch of a branch material
+type ChangeLogRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	FromRef            string `json:"fromRef"`
+	ToRef              string `json:"toRef"`
+}
+
+// VersionBumpRequest asks for the next version after BaseVersion based on the conventional commits between FromRef and
+// ToRef. FromRef defaults to the tag of BaseVersion and ToRef to the branch of a branch material
+type VersionBumpRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	BaseVersion        string `json:"baseVersion"`
+	FromRef            string `json:"fromRef"`
+	ToRef              string `json:"toRef"`
+}
+
+type VersionBumpResponse struct {
+	BaseVersion string `json:"baseVersion"`
+	NextVersion string `json:"nextVersion"`
+	Bump        string `json:"bump"` // major, minor, patch or none when there are no commits in the range
+	Commits     int    `json:"commits"`
+	Truncated   bool   `json:"truncated"`
+}
+
+type MergeBaseRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	CommitA            string `json:"commitA"`
+	CommitB            string `json:"commitB"`
+}
+
+type MergeBaseResponse struct {
+	MergeBase string `json:"mergeBase"` // empty when the commits have no common history
+}
+
+type IsAncestorRequest struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	Ancestor           string `json:"ancestor"`
+	Descendant         string `json:"descendant"`
+}
+
+type IsAncestorResponse struct {
+	IsAncestor bool `json:"isAncestor"`
+}
+
+const COMMIT_HISTORY_MAX_LIMIT = 1000
+
+// CommitHistoryRequest queries the persisted commits of a pipeline material, by hash when CommitHash is set,
+// else by the commit date range when From or To is set, else the latest Limit commits
+type CommitHistoryRequest struct {
+	PipelineMaterialId int       `json:"pipelineMaterialId"`
+	CommitHash         string    `json:"commitHash"`
+	From               time.Time `json:"from"`
+	To                 time.Time `json:"to"`
+	Limit              int       `json:"limit"`
+}
+
+const COMMITS_SINCE_MAX_LIMIT = 500
+
+// CommitsSinceRequest pages through the commit history of a branch material, newest first. The first page starts at the
+// head of the branch, the next ones after the Cursor returned with the previous page
+type CommitsSinceRequest struct {
+	PipelineMaterialId    int       `json:"pipelineMaterialId"`
+	Cursor                string    `json:"cursor"`
+	Limit                 int       `json:"limit"`
+	Since                 time.Time `json:"since"`                 // only commits committed at or after it when set
+	Until                 time.Time `json:"until"`                 // only commits committed at or before it when set
+	ResolveSubmodules     bool      `json:"resolveSubmodules"`     // report the submodule pointer changes of the commits
+	FetchSubmoduleCommits bool      `json:"fetchSubmoduleCommits"` // also fetch the submodules to report their commits
+}
+
+// CommitsSinceResponse has an empty NextCursor on the last page
+type CommitsSinceResponse struct {
+	Commits    []*GitCommitBase `json:"commits"`
+	NextCursor string           `json:"nextCursor"`
+}
+
+const COMMIT_SEARCH_MAX_LIMIT = 100
+
+// CommitSearchRequest finds the commits whose message contains Query and whose author contains Author, newest first.
+// Both are matched ignoring case, as regular expressions when Regex is set. The branches and tags of the git materials
+// are searched, of all the active ones when GitMaterialIds is empty. Limit applies per material
+type CommitSearchRequest struct {
+	GitMaterialIds []int      `json:"gitMaterialIds"`
+	Query          string     `json:"query"`
+	Author         string     `json:"author"`
+	Since          *time.Time `json:"since"`
+	Until          *time.Time `json:"until"`
+	Limit          int        `json:"limit"`
+	Regex          bool       `json:"regex"`
+}
+
+// CommitSearchResult has the ErrorMsg of a material which could not be searched, the others are still searched
+type CommitSearchResult struct {
+	GitMaterialId int              `json:"gitMaterialId"`
+	Url           string           `json:"url"`
+	Commits       []*GitCommitBase `json:"commits"`
+	ErrorMsg      string           `json:"errorMsg,omitempty"`
 }
 
 type WebhookDataRequest struct {
@@ -320,18 +605,57 @@ type FileStatsResult struct {
 
 // FileStat stores the status of changes in content of a file.
 type FileStat struct {
-	Name     string
-	Addition int
-	Deletion int
+	Name       string
+	OldPath    string `json:",omitempty"` // set for renamed and copied files, Name is then the new path
+	Similarity int    `json:",omitempty"` // percentage of the content of OldPath kept in Name
+	Addition   int
+	Deletion   int
+	Binary     bool `json:",omitempty"` // binary files have no line counts
+	Large      bool `json:",omitempty"` // the new content is above LARGE_FILE_THRESHOLD_IN_KB
 }
 
 // FileStats is a collection of FileStat.
 type FileStats []FileStat
 
+// ContainsBinary checks if any of the changed files is binary
+func (fileStats FileStats) ContainsBinary() bool {
+	for _, fileStat := range fileStats {
+		if fileStat.Binary {
+			return true
+		}
+	}
+	return false
+}
+
 type IteratorRequest struct {
 	BranchRef      string
 	Branch         string
 	CommitCount    int
 	FromCommitHash string
 	ToCommitHash   string
+	IncludePaths   []string  // glob pathspecs, commits not touching any of these are skipped
+	ExcludePaths   []string  // glob pathspecs, commits touching only these are skipped
+	FirstParent    bool      // follow only the first parent of merge commits
+	Since          time.Time // only commits committed at or after it, zero for no lower bound
+	Until          time.Time // only commits committed at or before it, zero for no upper bound
+}
+
+func (iteratorRequest IteratorRequest) IsPathFiltered() bool {
+	return len(iteratorRequest.IncludePaths) > 0 || len(iteratorRequest.ExcludePaths) > 0
+}
+
+func (iteratorRequest IteratorRequest) IsTimeWindowed() bool {
+	return !iteratorRequest.Since.IsZero() || !iteratorRequest.Until.IsZero()
+}
+
+// getTimeWindowArgs returns the git log args limiting the commits to the time window by their commit date
+func (iteratorRequest IteratorRequest) getTimeWindowArgs() []string {
+	var args []string
+	if !iteratorRequest.Since.IsZero() {
+		args = append(args, "--since="+iteratorRequest.Since.Format(time.RFC3339))
+	}
+	if !iteratorRequest.Until.IsZero() {
+		args = append(args, "--until="+iteratorRequest.Until.Format(time.RFC3339))
+	}
+	return args
 }
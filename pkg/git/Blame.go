@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is the commit which last changed a line of the file
+type BlameLine struct {
+	Line        int       `json:"line"`
+	Commit      string    `json:"commit"`
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"authorEmail"`
+	Date        time.Time `json:"date"`
+	Summary     string    `json:"summary"`
+	Content     string    `json:"content"`
+}
+
+type FileBlame struct {
+	Path  string       `json:"path"`
+	Ref   string       `json:"ref"`
+	Lines []*BlameLine `json:"lines"`
+}
+
+// GetBlame blames the lines from startLine to endLine of the file at the ref, the whole file when startLine is 0 and
+// up to the end of the file when endLine is 0
+func (impl *GitManagerBaseImpl) GetBlame(gitCtx GitContext, rootDir, filePath string, startLine, endLine int, ref string) (*FileBlame, error) {
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+	if strings.HasPrefix(ref, "-") || startLine < 0 || endLine < 0 || (endLine > 0 && endLine < startLine) {
+		return nil, fmt.Errorf("invalid blame request, ref: %q lines: %d-%d", ref, startLine, endLine)
+	}
+	if endLine > 0 && startLine == 0 {
+		startLine = 1
+	}
+	cmdArgs := []string{"-C", rootDir, "blame", "--porcelain"}
+	if startLine > 0 {
+		lineRange := strconv.Itoa(startLine) + ","
+		if endLine > 0 {
+			lineRange += strconv.Itoa(endLine)
+		}
+		cmdArgs = append(cmdArgs, "-L", lineRange)
+	}
+	cmdArgs = append(cmdArgs, ref, "--", strings.TrimPrefix(filePath, "/"))
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "errMsg", errMsg, "error", err)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := parseBlamePorcelain(output)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBlame{Path: filePath, Ref: ref, Lines: lines}, nil
+}
+
+// parseBlamePorcelain parses the output of git blame --porcelain, the header of a commit is only written for the
+// first line blamed on it so the headers are kept by commit for the later lines
+func parseBlamePorcelain(output string) ([]*BlameLine, error) {
+	var lines []*BlameLine
+	commits := make(map[string]*BlameLine)
+	var commit *BlameLine
+	line := 0
+	for _, row := range strings.Split(output, "\n") {
+		if commit == nil {
+			if len(strings.TrimSpace(row)) == 0 {
+				continue
+			}
+			// <commit> <line in original file> <line in final file> [<lines in group>]
+			fields := strings.Fields(row)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected git blame line %q", row)
+			}
+			var err error
+			if line, err = strconv.Atoi(fields[2]); err != nil {
+				return nil, fmt.Errorf("unexpected git blame line %q", row)
+			}
+			if commit = commits[fields[0]]; commit == nil {
+				commit = &BlameLine{Commit: fields[0]}
+				commits[fields[0]] = commit
+			}
+			continue
+		}
+		if strings.HasPrefix(row, "\t") {
+			blameLine := *commit
+			blameLine.Line = line
+			blameLine.Content = strings.TrimPrefix(row, "\t")
+			lines = append(lines, &blameLine)
+			commit = nil
+			continue
+		}
+		key, value, _ := strings.Cut(row, " ")
+		switch key {
+		case "author":
+			commit.Author = value
+		case "author-mail":
+			commit.AuthorEmail = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git blame line %q", row)
+			}
+			commit.Date = time.Unix(seconds, 0).UTC()
+		case "summary":
+			commit.Summary = value
+		}
+	}
+	if commit != nil {
+		return nil, fmt.Errorf("unexpected end of git blame output")
+	}
+	return lines, nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryManager_GetBlame(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	runTestGitCmd(t, workDir, "add", "app.go")
+	runTestGitCmd(t, workDir, "commit", "-m", "add app")
+	first := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.go"), []byte("package main\n\nfunc main() { run() }\n"), 0644))
+	runTestGitCmd(t, workDir, "commit", "-am", "run app")
+	second := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	gitCtx := BuildGitContext(context.Background())
+	blame, err := impl.GetBlame(gitCtx, workDir, "app.go", 0, 0, "main")
+	assert.Nil(t, err)
+	assert.Len(t, blame.Lines, 3)
+	assert.Equal(t, first, blame.Lines[0].Commit)
+	assert.Equal(t, "add app", blame.Lines[0].Summary)
+	assert.Equal(t, first, blame.Lines[1].Commit)
+	assert.Equal(t, second, blame.Lines[2].Commit)
+	assert.Equal(t, "func main() { run() }", blame.Lines[2].Content)
+	assert.Equal(t, 3, blame.Lines[2].Line)
+	assert.NotEmpty(t, blame.Lines[2].Author)
+	assert.False(t, blame.Lines[2].Date.IsZero())
+
+	blame, err = impl.GetBlame(gitCtx, workDir, "app.go", 2, 3, first)
+	assert.Nil(t, err)
+	assert.Len(t, blame.Lines, 2)
+	assert.Equal(t, 2, blame.Lines[0].Line)
+	assert.Equal(t, "func main() {}", blame.Lines[1].Content)
+
+	_, err = impl.GetBlame(gitCtx, workDir, "app.go", 3, 2, "main")
+	assert.NotNil(t, err)
+	_, err = impl.GetBlame(gitCtx, workDir, "missing.go", 0, 0, "main")
+	assert.NotNil(t, err)
+}
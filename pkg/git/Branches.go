@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strings"
+	"time"
+)
+
+// BRANCH_FORMAT prints the branch name without the refs/remotes/origin/ prefix, its head commit and the commit date
+const BRANCH_FORMAT = "--format=%(refname:lstrip=3)%1f%(objectname)%1f%(committerdate:iso-strict)"
+
+type GitBranch struct {
+	Name   string
+	Commit string
+	Date   time.Time
+}
+
+// ListBranches lists the branches of the origin remote as of the last fetch, most recently updated first
+func (impl *GitManagerBaseImpl) ListBranches(gitCtx GitContext, rootDir string) ([]*GitBranch, error) {
+	cmdArgs := []string{"-C", rootDir, "for-each-ref", "--sort=-committerdate", BRANCH_FORMAT, "refs/remotes/origin"}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in listing branches", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	return parseBranches(output), nil
+}
+
+func parseBranches(output string) []*GitBranch {
+	var branches []*GitBranch
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		// refs/remotes/origin/HEAD is a symbolic ref to the default branch
+		if len(fields) < 3 || fields[0] == "HEAD" {
+			continue
+		}
+		branch := &GitBranch{Name: fields[0], Commit: fields[1]}
+		branch.Date, _ = time.Parse(time.RFC3339, fields[2])
+		branches = append(branches, branch)
+	}
+	return branches
+}
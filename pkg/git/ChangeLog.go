@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CHANGE_LOG_MAX_COMMITS caps the commits read for a change log, Truncated is set on the change log when reached
+	CHANGE_LOG_MAX_COMMITS = 1000
+	CHANGE_LOG_TYPE_OTHER  = "other"
+
+	changeLogFieldSeparator  = "\x1f"
+	changeLogRecordSeparator = "\x1e"
+	changeLogFormat          = "%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%B%x1e"
+)
+
+// changeLogTypeTitles are the conventional commit types in the order their groups appear in the change log
+var changeLogTypeTitles = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"style", "Styles"},
+	{"chore", "Chores"},
+	{CHANGE_LOG_TYPE_OTHER, "Other Changes"},
+}
+
+var pullRequestRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^Merge pull request #(\d+)`),
+	regexp.MustCompile(`\(#(\d+)\)\s*$`),
+	regexp.MustCompile(`See merge request \S*!(\d+)`),
+}
+
+// ChangeLog is the commits reachable from ToRef but not from FromRef grouped by their conventional commit type
+type ChangeLog struct {
+	FromRef      string             `json:"fromRef"`
+	ToRef        string             `json:"toRef"`
+	Groups       []*ChangeLogGroup  `json:"groups"`
+	PullRequests []int              `json:"pullRequests"`
+	Authors      []*ChangeLogAuthor `json:"authors"`
+	Truncated    bool               `json:"truncated"`
+}
+
+type ChangeLogGroup struct {
+	Type    string             `json:"type"`
+	Title   string             `json:"title"`
+	Commits []*ChangeLogCommit `json:"commits"`
+}
+
+// ChangeLogCommit is a non merge commit of the change log, Subject has the conventional commit prefix removed
+type ChangeLogCommit struct {
+	Commit       string    `json:"commit"`
+	Type         string    `json:"type"`
+	Scope        string    `json:"scope,omitempty"`
+	Subject      string    `json:"subject"`
+	Breaking     bool      `json:"breaking"`
+	PullRequests []int     `json:"pullRequests,omitempty"`
+	Author       string    `json:"author"`
+	AuthorEmail  string    `json:"authorEmail"`
+	Date         time.Time `json:"date"`
+}
+
+// ChangeLogAuthor is a contributor of the change log with the number of non merge commits authored
+type ChangeLogAuthor struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"`
+}
+
+// changeLogEntry is a commit as read from git log
+type changeLogEntry struct {
+	Hash        string
+	Parents     []string
+	Author      string
+	AuthorEmail string
+	Date        time.Time
+	Message     string
+}
+
+// GenerateChangeLog reads the commits reachable from toRef but not from fromRef, the whole history of toRef when
+// fromRef is empty, and builds the change log out of them
+func (impl *GitManagerBaseImpl) GenerateChangeLog(gitCtx GitContext, rootDir, fromRef, toRef string) (*ChangeLog, error) {
+	if len(toRef) == 0 || strings.HasPrefix(toRef, "-") || strings.HasPrefix(fromRef, "-") {
+		return nil, fmt.Errorf("invalid refs for change log, from: %q to: %q", fromRef, toRef)
+	}
+	revRange := toRef
+	if len(fromRef) > 0 {
+		revRange = fromRef + ".." + toRef
+	}
+	cmdArgs := []string{"-C", rootDir, "log", "--no-color", "--no-decorate", "--format=" + changeLogFormat,
+		"-n", strconv.Itoa(CHANGE_LOG_MAX_COMMITS + 1), revRange, "--"}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "errMsg", errMsg, "error", err)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseChangeLogOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(entries) > CHANGE_LOG_MAX_COMMITS
+	if truncated {
+		entries = entries[:CHANGE_LOG_MAX_COMMITS]
+	}
+	changeLog := buildChangeLog(entries)
+	changeLog.FromRef = fromRef
+	changeLog.ToRef = toRef
+	changeLog.Truncated = truncated
+	return changeLog, nil
+}
+
+func parseChangeLogOutput(output string) ([]*changeLogEntry, error) {
+	var entries []*changeLogEntry
+	for _, record := range strings.Split(output, changeLogRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if len(record) == 0 {
+			continue
+		}
+		fields := strings.SplitN(record, changeLogFieldSeparator, 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log record %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &changeLogEntry{
+			Hash:        fields[0],
+			Parents:     strings.Fields(fields[1]),
+			Author:      fields[2],
+			AuthorEmail: fields[3],
+			Date:        date,
+			Message:     strings.TrimSpace(fields[5]),
+		})
+	}
+	return entries, nil
+}
+
+// buildChangeLog groups the commits by conventional commit type, commits whose subject does not follow the convention
+// go to the other group. Merge commits are not listed but the pull requests they merged are
+func buildChangeLog(entries []*changeLogEntry) *ChangeLog {
+	groups := make(map[string]*ChangeLogGroup)
+	authors := make(map[string]*ChangeLogAuthor)
+	var authorOrder []string
+	pullRequests := make(map[int]bool)
+	for _, entry := range entries {
+		subject, body, _ := strings.Cut(entry.Message, "\n")
+		prs := extractPullRequests(entry.Message)
+		for _, pr := range prs {
+			pullRequests[pr] = true
+		}
+		if len(entry.Parents) > 1 {
+			continue
+		}
+		commit := parseConventionalCommit(subject, body)
+		commit.Commit = entry.Hash
+		commit.PullRequests = prs
+		commit.Author = entry.Author
+		commit.AuthorEmail = entry.AuthorEmail
+		commit.Date = entry.Date
+		group, ok := groups[commit.Type]
+		if !ok {
+			group = &ChangeLogGroup{Type: commit.Type}
+			groups[commit.Type] = group
+		}
+		group.Commits = append(group.Commits, commit)
+
+		authorKey := strings.ToLower(entry.AuthorEmail)
+		author, ok := authors[authorKey]
+		if !ok {
+			author = &ChangeLogAuthor{Name: entry.Author, Email: entry.AuthorEmail}
+			authors[authorKey] = author
+			authorOrder = append(authorOrder, authorKey)
+		}
+		author.Commits++
+	}
+	changeLog := &ChangeLog{}
+	for _, typeTitle := range changeLogTypeTitles {
+		if group, ok := groups[typeTitle.Type]; ok {
+			group.Title = typeTitle.Title
+			changeLog.Groups = append(changeLog.Groups, group)
+		}
+	}
+	for pr := range pullRequests {
+		changeLog.PullRequests = append(changeLog.PullRequests, pr)
+	}
+	sort.Ints(changeLog.PullRequests)
+	for _, authorKey := range authorOrder {
+		changeLog.Authors = append(changeLog.Authors, authors[authorKey])
+	}
+	sort.SliceStable(changeLog.Authors, func(i, j int) bool {
+		return changeLog.Authors[i].Commits > changeLog.Authors[j].Commits
+	})
+	return changeLog
+}
+
+// parseConventionalCommit splits the subject into type, scope and description, types not known to the change log are
+// put in the other group with the subject kept as is
+func parseConventionalCommit(subject, body string) *ChangeLogCommit {
+	commit := &ChangeLogCommit{Type: CHANGE_LOG_TYPE_OTHER, Subject: subject}
+	if conventionalCommit := ParseConventionalCommit(subject + "\n" + body); conventionalCommit != nil {
+		commit.Type = conventionalCommit.Type
+		commit.Scope = conventionalCommit.Scope
+		commit.Breaking = conventionalCommit.Breaking
+		commit.Subject = conventionalCommit.Description
+	} else if breakingChangeRegex.MatchString(body) {
+		commit.Breaking = true
+	}
+	return commit
+}
+
+func extractPullRequests(message string) []int {
+	var prs []int
+	seen := make(map[int]bool)
+	for _, line := range strings.Split(message, "\n") {
+		for _, regex := range pullRequestRegexes {
+			match := regex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			pr, err := strconv.Atoi(match[1])
+			if err != nil || seen[pr] {
+				continue
+			}
+			seen[pr] = true
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
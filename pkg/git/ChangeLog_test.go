@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	commit := parseConventionalCommit("feat(api)!: add change log", "")
+	assert.Equal(t, "feat", commit.Type)
+	assert.Equal(t, "api", commit.Scope)
+	assert.Equal(t, "add change log", commit.Subject)
+	assert.True(t, commit.Breaking)
+
+	commit = parseConventionalCommit("fix: handle empty refs", "BREAKING CHANGE: refs are required")
+	assert.Equal(t, "fix", commit.Type)
+	assert.True(t, commit.Breaking)
+
+	commit = parseConventionalCommit("Update: readme", "")
+	assert.Equal(t, CHANGE_LOG_TYPE_OTHER, commit.Type)
+	assert.Equal(t, "Update: readme", commit.Subject)
+}
+
+func TestExtractPullRequests(t *testing.T) {
+	assert.Equal(t, []int{12}, extractPullRequests("Merge pull request #12 from devtron-labs/feature"))
+	assert.Equal(t, []int{34}, extractPullRequests("fix: trim refs (#34)"))
+	assert.Equal(t, []int{56}, extractPullRequests("Merge branch 'feature' into 'main'\n\nSee merge request group/repo!56"))
+	assert.Nil(t, extractPullRequests("chore: bump version to 1.2"))
+}
+
+func TestRepositoryManager_GenerateChangeLog(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	fromRef := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	runTestGitCmd(t, workDir, "checkout", "-b", "feature")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "feat(api): add change log (#7)")
+	runTestGitCmd(t, workDir, "checkout", "main")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "fix: handle empty refs", "-m", "BREAKING CHANGE: refs are required")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "update readme")
+	runTestGitCmd(t, workDir, "merge", "--no-ff", "-m", "Merge pull request #8 from devtron-labs/feature", "feature")
+
+	changeLog, err := impl.GenerateChangeLog(BuildGitContext(context.Background()), workDir, fromRef, "main")
+	assert.Nil(t, err)
+	assert.False(t, changeLog.Truncated)
+	assert.Equal(t, []int{7, 8}, changeLog.PullRequests)
+	assert.Len(t, changeLog.Groups, 3)
+	assert.Equal(t, "Features", changeLog.Groups[0].Title)
+	assert.Equal(t, "api", changeLog.Groups[0].Commits[0].Scope)
+	assert.Equal(t, []int{7}, changeLog.Groups[0].Commits[0].PullRequests)
+	assert.Equal(t, "Bug Fixes", changeLog.Groups[1].Title)
+	assert.True(t, changeLog.Groups[1].Commits[0].Breaking)
+	assert.Equal(t, CHANGE_LOG_TYPE_OTHER, changeLog.Groups[2].Type)
+	assert.Equal(t, []*ChangeLogAuthor{{Name: "devtron", Email: "devtron@devtron.ai", Commits: 3}}, changeLog.Authors)
+
+	_, err = impl.GenerateChangeLog(BuildGitContext(context.Background()), workDir, "--output=/tmp/x", "main")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+type FileChangeType string
+
+const (
+	FILE_CHANGE_TYPE_ADDED       FileChangeType = "A"
+	FILE_CHANGE_TYPE_MODIFIED    FileChangeType = "M"
+	FILE_CHANGE_TYPE_DELETED     FileChangeType = "D"
+	FILE_CHANGE_TYPE_RENAMED     FileChangeType = "R"
+	FILE_CHANGE_TYPE_COPIED      FileChangeType = "C"
+	FILE_CHANGE_TYPE_TYPE_CHANGE FileChangeType = "T"
+)
+
+type FileChange struct {
+	Path       string
+	OldPath    string `json:",omitempty"` // set for renamed and copied files
+	ChangeType FileChangeType
+	Similarity int `json:",omitempty"` // percentage of the content of OldPath kept in Path
+	Addition   int
+	Deletion   int
+	Binary     bool `json:",omitempty"`
+}
+
+// GetChangedFiles lists the files changed by the commit against its first parent, with rename detection
+func (impl *GitManagerBaseImpl) GetChangedFiles(gitCtx GitContext, rootDir string, commitHash string) ([]*FileChange, error) {
+	cmdArgs := []string{"-C", rootDir, "diff-tree", "-r", "--no-commit-id", "--root", "-m", "--first-parent", "-M", "--raw", "--numstat", "-z", commitHash}
+	impl.prefetchDiffBlobs(gitCtx, rootDir, []string{commitHash}, nil)
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in getting changed files", "rootDir", rootDir, "commitHash", commitHash, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	return parseChangedFiles(output), nil
+}
+
+// parseChangedFiles parses the NUL separated --raw and --numstat output of diff-tree, raw entries come first
+//
+//	:<old mode> <new mode> <old hash> <new hash> <status>\0<path>\0 or, for renames and copies, ...\0<old path>\0<new path>\0
+//	<additions>\t<deletions>\t<path>\0 or, for renames and copies, <additions>\t<deletions>\t\0<old path>\0<new path>\0
+func parseChangedFiles(output string) []*FileChange {
+	tokens := strings.Split(output, "\x00")
+	var changes []*FileChange
+	changesByPath := make(map[string]*FileChange)
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		if len(token) == 0 {
+			continue
+		}
+		if strings.HasPrefix(token, ":") {
+			fields := strings.Fields(token)
+			if len(fields) < 5 || i+1 >= len(tokens) {
+				continue
+			}
+			change := &FileChange{ChangeType: FileChangeType(fields[4][:1])}
+			if (change.ChangeType == FILE_CHANGE_TYPE_RENAMED || change.ChangeType == FILE_CHANGE_TYPE_COPIED) && i+2 < len(tokens) {
+				change.OldPath, change.Path = tokens[i+1], tokens[i+2]
+				change.Similarity, _ = strconv.Atoi(fields[4][1:])
+				i += 2
+			} else {
+				change.Path = tokens[i+1]
+				i++
+			}
+			changes = append(changes, change)
+			changesByPath[change.Path] = change
+			continue
+		}
+		stats := strings.SplitN(token, "\t", 3)
+		if len(stats) < 3 {
+			continue
+		}
+		path := stats[2]
+		if len(path) == 0 && i+2 < len(tokens) {
+			path = tokens[i+2]
+			i += 2
+		}
+		change, ok := changesByPath[path]
+		if !ok {
+			continue
+		}
+		// binary files have - for additions and deletions
+		change.Binary = stats[0] == "-"
+		change.Addition, _ = strconv.Atoi(stats[0])
+		change.Deletion, _ = strconv.Atoi(stats[1])
+	}
+	return changes
+}
+
+// toFileStats converts the changes to file stats, renamed and copied files are reported once with their old path
+func toFileStats(changes []*FileChange) FileStats {
+	fileStats := FileStats{}
+	for _, change := range changes {
+		fileStats = append(fileStats, FileStat{
+			Name:       change.Path,
+			OldPath:    change.OldPath,
+			Similarity: change.Similarity,
+			Addition:   change.Addition,
+			Deletion:   change.Deletion,
+			Binary:     change.Binary,
+		})
+	}
+	return fileStats
+}
+
+// MarkLargeFiles flags the files whose content at the commit is above LARGE_FILE_THRESHOLD_IN_KB, the deleted files
+// have no content at the commit and are left as is. Only the sizes of the blobs are read
+func (impl *GitManagerBaseImpl) MarkLargeFiles(gitCtx GitContext, rootDir string, commitHash string, fileStats FileStats) error {
+	threshold := int64(impl.conf.LargeFileThresholdInKb) * 1024
+	if threshold <= 0 || len(fileStats) == 0 {
+		return nil
+	}
+	var objects []string
+	var indexes []int
+	for i, fileStat := range fileStats {
+		if strings.ContainsAny(fileStat.Name, "\n") {
+			continue
+		}
+		objects = append(objects, commitHash+":"+fileStat.Name)
+		indexes = append(indexes, i)
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	sizes, err := impl.catFile(gitCtx, rootDir, "--batch-check=%(objecttype) %(objectsize)", objects)
+	if err != nil {
+		return err
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(string(sizes), "\n"), "\n") {
+		fields := strings.Fields(line)
+		if i >= len(indexes) || len(fields) != 2 || fields[0] != "blob" {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[1], 10, 64); err == nil && size > threshold {
+			fileStats[indexes[i]].Large = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseChangedFiles(t *testing.T) {
+	output := strings.Join([]string{
+		":000000 100644 0000000000000000000000000000000000000000 bdc955b7b2e610ad5a72302b139a2e6cb325519a A", "b.bin",
+		":100644 100644 45b983be36b73c0788dc9cbcb76cbb80fc7bb057 45b983be36b73c0788dc9cbcb76cbb80fc7bb058 M", "f.txt",
+		":100644 100644 ad3ded6f6de61846df1483d016c2aac68faac72a ad3ded6f6de61846df1483d016c2aac68faac72a R100", "old.txt", "new.txt",
+		"-\t-\tb.bin",
+		"3\t1\tf.txt",
+		"0\t0\t", "old.txt", "new.txt",
+		"",
+	}, "\x00")
+	assert.Equal(t, []*FileChange{
+		{Path: "b.bin", ChangeType: FILE_CHANGE_TYPE_ADDED, Binary: true},
+		{Path: "f.txt", ChangeType: FILE_CHANGE_TYPE_MODIFIED, Addition: 3, Deletion: 1},
+		{Path: "new.txt", OldPath: "old.txt", ChangeType: FILE_CHANGE_TYPE_RENAMED, Similarity: 100},
+	}, parseChangedFiles(output))
+	assert.Nil(t, parseChangedFiles(""))
+}
+
+func TestFetchDiffStatBetweenCommits_Renames(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.MkdirAll(filepath.Join(workDir, "src"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "src", "app.go"), []byte("package main\n\nfunc main() {\n\trun()\n}\n"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add app")
+	from := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	assert.Nil(t, os.MkdirAll(filepath.Join(workDir, "cmd"), 0755))
+	runTestGitCmd(t, workDir, "mv", "src/app.go", "cmd/main.go")
+	runTestGitCmd(t, workDir, "commit", "-m", "move app")
+	to := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	gitCtx := BuildGitContext(context.Background())
+	expected := FileStats{{Name: "cmd/main.go", OldPath: "src/app.go", Similarity: 100}}
+	stats, err := impl.gitManager.FetchDiffStatBetweenCommitsWithNumstat(gitCtx, from, to, workDir)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, stats)
+	stats, err = impl.gitManager.FetchDiffStatBetweenCommitsNameOnly(gitCtx, to, "", workDir)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, stats)
+}
+
+func TestTransformFileStats(t *testing.T) {
+	assert.Equal(t, FileStats{
+		{Name: "cmd/main.go", OldPath: "src/app.go", Addition: 1},
+		{Name: "README.md", Deletion: 2},
+	}, transformFileStats(object.FileStats{
+		{Name: "src/app.go => cmd/main.go", Addition: 1},
+		{Name: "README.md", Deletion: 2},
+	}))
+}
+
+func TestGetCommitStats_BinaryAndLargeFiles(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.bin"), []byte{0x7f, 'E', 'L', 'F', 0, 0, 1, 2}, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "data.txt"), []byte(strings.Repeat("line\n", 300)), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add build output")
+	headHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	managers := map[string]GitManager{
+		"cli":    NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true, LargeFileThresholdInKb: 1}),
+		"go-git": NewGitManagerImpl(logger, &internals.Configuration{LargeFileThresholdInKb: 1}),
+	}
+	for name, gitManager := range managers {
+		t.Run(name, func(t *testing.T) {
+			gitCtx := BuildGitContext(context.Background())
+			commit, err := gitManager.GetCommitForHash(gitCtx, workDir, headHash)
+			assert.Nil(t, err)
+			stats, err := gitManager.GetCommitStats(gitCtx, commit, workDir)
+			assert.Nil(t, err)
+			assert.Nil(t, gitManager.MarkLargeFiles(gitCtx, workDir, headHash, stats))
+			sort.Slice(stats, func(i, j int) bool {
+				return stats[i].Name < stats[j].Name
+			})
+			assert.Equal(t, FileStats{
+				{Name: "app.bin", Binary: true},
+				{Name: "data.txt", Addition: 300, Large: true},
+			}, stats)
+			gitCommit := commit.GetCommit()
+			gitCommit.SetFileStats(&stats)
+			assert.True(t, gitCommit.ContainsBinaryChanges)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"strings"
+)
+
+// MAX_COMMITS_PER_LOOKUP bounds the hashes of a batch lookup, they are passed as arguments of git log
+const MAX_COMMITS_PER_LOOKUP = 1000
+
+// ResolveCommits resolves the revisions to the full hashes of their commits with a single git cat-file, revisions
+// which don't name a commit of the repo are left out
+func (impl *GitManagerBaseImpl) ResolveCommits(gitCtx GitContext, rootDir string, revisions []string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	var input strings.Builder
+	for _, revision := range revisions {
+		if len(revision) == 0 || strings.ContainsAny(revision, " \t\n") {
+			return nil, fmt.Errorf("invalid revision %q", revision)
+		}
+		input.WriteString(revision + "^{commit}\n")
+	}
+	if len(revisions) == 0 {
+		return resolved, nil
+	}
+	impl.logger.Debugw("git", "-C", rootDir, "cat-file", "--batch-check", "revisions", len(revisions))
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "cat-file", "--batch-check=%(objectname)")
+	defer cancel()
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Env = append(cmd.Env, impl.getCommandEnv()...)
+	// stdout is read alone since runCommand mixes in the errors git writes for revisions which are not commits
+	output, err := cmd.Output()
+	if err != nil {
+		impl.logger.Errorw("error in resolving commits", "rootDir", rootDir, "err", err)
+		return nil, err
+	}
+	// one line per revision in order, the object name or <revision> missing/ambiguous when it doesn't resolve
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(lines) != len(revisions) {
+		return nil, fmt.Errorf("unexpected git cat-file output for %d revisions: %q", len(revisions), output)
+	}
+	for i, line := range lines {
+		if hash := strings.TrimSpace(line); !strings.Contains(hash, " ") {
+			resolved[revisions[i]] = hash
+		}
+	}
+	return resolved, nil
+}
+
+// GetCommitsForHashes resolves the commits in two git invocations however many hashes are asked for, the commits are
+// keyed by the hash asked for and unknown hashes are left out
+func (impl *GitCliManagerImpl) GetCommitsForHashes(gitCtx GitContext, checkoutPath string, commitHashes []string) (map[string]GitCommit, error) {
+	commits := make(map[string]GitCommit)
+	// unknown hashes would fail git log for all of them, so they are filtered out first
+	resolved, err := impl.ResolveCommits(gitCtx, checkoutPath, commitHashes)
+	if err != nil || len(resolved) == 0 {
+		return commits, err
+	}
+	cmdArgs := []string{"-C", checkoutPath, "log", "--no-walk=unsorted", "--date=iso-strict", GITFORMAT}
+	added := make(map[string]bool)
+	for _, hash := range resolved {
+		if !added[hash] {
+			added[hash] = true
+			cmdArgs = append(cmdArgs, hash)
+		}
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in getting commits for hashes", "checkoutPath", checkoutPath, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	gitCommits, err := impl.processGitLogOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	commitsByHash := make(map[string]GitCommit, len(gitCommits))
+	for _, gitCommit := range gitCommits {
+		commitsByHash[gitCommit.GetCommit().Commit] = gitCommit
+	}
+	for commitHash, hash := range resolved {
+		if gitCommit, ok := commitsByHash[hash]; ok {
+			commits[commitHash] = gitCommit
+		}
+	}
+	return commits, nil
+}
+
+// GetCommitsForHashes reads the commits from the object store of the repo, there is no process to save by batching
+// them with go-git. Unknown hashes are left out
+func (impl *GoGitSDKManagerImpl) GetCommitsForHashes(gitCtx GitContext, checkoutPath string, commitHashes []string) (map[string]GitCommit, error) {
+	commits := make(map[string]GitCommit)
+	for _, commitHash := range commitHashes {
+		gitCommit, err := impl.GetCommitForHash(gitCtx, checkoutPath, commitHash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		commits[commitHash] = gitCommit
+	}
+	return commits, nil
+}
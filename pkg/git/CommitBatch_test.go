@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepositoryManager_GetCommitsMetadata(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	second := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	first := runTestGitCmd(t, workDir, "rev-parse", "HEAD~1")
+	blob := runTestGitCmd(t, workDir, "hash-object", "-w", "--stdin")
+	missing := "0123456789012345678901234567890123456789"
+
+	gitCtx := BuildGitContext(context.Background())
+	commits, err := impl.GetCommitsMetadata(gitCtx, workDir, []string{second, first[:10], missing, blob, "main"})
+	assert.Nil(t, err)
+	assert.Len(t, commits, 3)
+	assert.Equal(t, second, commits[second].Commit)
+	assert.Equal(t, "second", commits[second].Message)
+	assert.Equal(t, first, commits[first[:10]].Commit)
+	assert.Equal(t, second, commits["main"].Commit)
+
+	commits, err = impl.GetCommitsMetadata(gitCtx, workDir, []string{missing})
+	assert.Nil(t, err)
+	assert.Empty(t, commits)
+	_, err = impl.GetCommitsMetadata(gitCtx, workDir, []string{"main two"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCommitCursor = errors.New("invalid commit cursor")
+
+// CommitCursor is the position in the commit history of a material after which the next page starts, clients get it
+// encoded as an opaque string
+type CommitCursor struct {
+	Commit string    `json:"c"`
+	Date   time.Time `json:"d"`
+}
+
+func EncodeCommitCursor(commit *GitCommitBase) string {
+	cursor, _ := json.Marshal(&CommitCursor{Commit: commit.Commit, Date: commit.Date})
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+// DecodeCommitCursor accepts only a full commit hash in the cursor, the commit is passed to git as a revision
+func DecodeCommitCursor(encoded string) (*CommitCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCommitCursor
+	}
+	cursor := &CommitCursor{}
+	if err = json.Unmarshal(data, cursor); err != nil || !fullCommitHashRegex.MatchString(cursor.Commit) {
+		return nil, ErrInvalidCommitCursor
+	}
+	return cursor, nil
+}
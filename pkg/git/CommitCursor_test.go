@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCommitCursor(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	encoded := EncodeCommitCursor(&GitCommitBase{Commit: "9f2c6b1e4a5d8c7b3e2f1a0d9c8b7a6f5e4d3c2b", Date: date})
+	cursor, err := DecodeCommitCursor(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "9f2c6b1e4a5d8c7b3e2f1a0d9c8b7a6f5e4d3c2b", cursor.Commit)
+	assert.True(t, date.Equal(cursor.Date))
+
+	_, err = DecodeCommitCursor("not a cursor!")
+	assert.Equal(t, ErrInvalidCommitCursor, err)
+	_, err = DecodeCommitCursor("e30")
+	assert.Equal(t, ErrInvalidCommitCursor, err)
+	// the commit is passed to git log, anything but a full hash could be taken as an option
+	for _, commit := range []string{"9f2c6b1e", "--output=/tmp/cursor", "HEAD", "9f2c6b1e4a5d8c7b3e2f1a0d9c8b7a6f5e4d3c2B"} {
+		_, err = DecodeCommitCursor(EncodeCommitCursor(&GitCommitBase{Commit: commit, Date: date}))
+		assert.Equal(t, ErrInvalidCommitCursor, err, commit)
+	}
+}
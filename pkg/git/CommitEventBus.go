@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	pubsub "github.com/devtron-labs/common-lib/pubsub-lib"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/nats-io/nats.go"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	COMMIT_EVENT_BUS_NATS  = "nats"
+	COMMIT_EVENT_BUS_KAFKA = "kafka"
+
+	// COMMIT_EVENT_NATS_STREAM is the jetstream stream the topics of the commit events are added to
+	COMMIT_EVENT_NATS_STREAM = "GIT-SENSOR-COMMITS"
+
+	KAFKA_REST_CONTENT_TYPE = "application/vnd.kafka.json.v2+json"
+
+	commitEventBusTimeout = 30 * time.Second
+)
+
+// EventBus publishes the commit events, Publish returns once the bus has persisted the message
+type EventBus interface {
+	Publish(ctx context.Context, topic string, eventId string, payload []byte) error
+}
+
+// NewEventBus returns the bus of COMMIT_EVENT_BUS, nil when the events are not published
+func NewEventBus(configuration *internals.Configuration, pubSubClient *pubsub.PubSubClientServiceImpl) (EventBus, error) {
+	switch configuration.CommitEventBus {
+	case "":
+		return nil, nil
+	case COMMIT_EVENT_BUS_NATS:
+		if pubSubClient == nil || pubSubClient.NatsClient == nil {
+			return nil, errors.New("nats client not initialised")
+		}
+		return newNatsEventBus(pubSubClient.NatsClient.JetStrCtxt), nil
+	case COMMIT_EVENT_BUS_KAFKA:
+		if len(configuration.KafkaRestProxyUrl) == 0 {
+			return nil, errors.New("KAFKA_REST_PROXY_URL is required for the kafka event bus")
+		}
+		return newKafkaRestEventBus(configuration.KafkaRestProxyUrl), nil
+	default:
+		return nil, fmt.Errorf("unsupported commit event bus %q, supported are %s and %s", configuration.CommitEventBus, COMMIT_EVENT_BUS_NATS, COMMIT_EVENT_BUS_KAFKA)
+	}
+}
+
+// natsEventBus publishes to jetstream with the event id as the message id, so that a publish retried after a lost ack
+// is dropped by the deduplication of the stream. The topics are added to COMMIT_EVENT_NATS_STREAM as they are first seen
+type natsEventBus struct {
+	jetStream     nats.JetStreamContext
+	mutex         sync.Mutex
+	streamSubject map[string]bool
+}
+
+func newNatsEventBus(jetStream nats.JetStreamContext) *natsEventBus {
+	return &natsEventBus{jetStream: jetStream, streamSubject: make(map[string]bool)}
+}
+
+func (impl *natsEventBus) Publish(ctx context.Context, topic string, eventId string, payload []byte) error {
+	if err := impl.addStreamSubject(topic); err != nil {
+		return err
+	}
+	_, err := impl.jetStream.Publish(topic, payload, nats.MsgId(eventId), nats.Context(ctx))
+	return err
+}
+
+func (impl *natsEventBus) addStreamSubject(topic string) error {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if impl.streamSubject[topic] {
+		return nil
+	}
+	streamInfo, err := impl.jetStream.StreamInfo(COMMIT_EVENT_NATS_STREAM)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = impl.jetStream.AddStream(&nats.StreamConfig{Name: COMMIT_EVENT_NATS_STREAM, Subjects: []string{topic}})
+	} else if err == nil && !contains(streamInfo.Config.Subjects, topic) {
+		streamConfig := streamInfo.Config
+		streamConfig.Subjects = append(streamConfig.Subjects, topic)
+		_, err = impl.jetStream.UpdateStream(&streamConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("error in adding topic %s to stream %s: %w", topic, COMMIT_EVENT_NATS_STREAM, err)
+	}
+	impl.streamSubject[topic] = true
+	return nil
+}
+
+// kafkaRestEventBus produces to kafka through the confluent rest proxy v2 api, with the event id as the record key
+type kafkaRestEventBus struct {
+	proxyUrl string
+	client   *http.Client
+}
+
+func newKafkaRestEventBus(proxyUrl string) *kafkaRestEventBus {
+	return &kafkaRestEventBus{
+		proxyUrl: strings.TrimSuffix(proxyUrl, "/"),
+		client:   &http.Client{Timeout: commitEventBusTimeout},
+	}
+}
+
+type kafkaRestRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaRestProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (impl *kafkaRestEventBus) Publish(ctx context.Context, topic string, eventId string, payload []byte) error {
+	body, err := json.Marshal(map[string][]kafkaRestRecord{"records": {{Key: eventId, Value: payload}}})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/topics/%s", impl.proxyUrl, url.PathEscape(topic)), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", KAFKA_REST_CONTENT_TYPE)
+	request.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	response, err := impl.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka rest proxy responded with status %d: %s", response.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+	produceResponse := &kafkaRestProduceResponse{}
+	if err = json.Unmarshal(responseBody, produceResponse); err != nil {
+		return err
+	}
+	for _, offset := range produceResponse.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rest proxy failed to produce the record, error code %d: %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	pubsub "github.com/devtron-labs/common-lib/pubsub-lib"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	COMMIT_EVENT_TYPE_COMMIT       = "COMMIT"
+	COMMIT_EVENT_TYPE_TAG          = "TAG"
+	COMMIT_EVENT_TYPE_PULL_REQUEST = "PULL_REQUEST"
+)
+
+// CommitEvent is the message published for each new commit of a branch, new or moved tag and pull request event
+type CommitEvent struct {
+	EventId              string
+	EventType            string
+	GitMaterialId        int
+	Url                  string // url of the material without credentials
+	CiPipelineMaterialId int
+	Branch               string               `json:",omitempty"`
+	Tag                  string               `json:",omitempty"`
+	ForcePushed          bool                 `json:",omitempty"`
+	Commit               *GitCommitBase       `json:",omitempty"`
+	PullRequest          *PullRequestMaterial `json:",omitempty"`
+	DetectedOn           time.Time
+}
+
+// CommitEventPublisher publishes the source changes found by the sensor to an event bus for other services to react to
+// them. The events are saved to an outbox and published from it by a cron, so that they are published at least once
+type CommitEventPublisher interface {
+	// Enqueue saves the events of the material updates to the outbox, it does nothing when COMMIT_EVENT_BUS is not set
+	Enqueue(gitMaterial *sql.GitMaterial, materials []*CiPipelineMaterialBean) error
+	// NewOutboxEvents returns the outbox rows of the events of the material updates, for the caller to save them in the
+	// transaction of the updates. There are none when COMMIT_EVENT_BUS is not set
+	NewOutboxEvents(gitMaterial *sql.GitMaterial, materials []*CiPipelineMaterialBean) ([]*sql.CommitEventOutbox, error)
+	// PublishOutbox publishes the pending events of the outbox in order per material. A failed event is retried before
+	// the later events of its material, those of the other materials are published. An event failing
+	// COMMIT_EVENT_MAX_ATTEMPTS times is dead lettered, so that it no longer holds back its material
+	PublishOutbox()
+	StopCron()
+}
+
+type CommitEventPublisherImpl struct {
+	logger           *zap.SugaredLogger
+	outboxRepository sql.CommitEventOutboxRepository
+	eventBus         EventBus
+	configuration    *internals.Configuration
+	cron             *cron.Cron
+}
+
+func NewCommitEventPublisherImpl(logger *zap.SugaredLogger, outboxRepository sql.CommitEventOutboxRepository,
+	pubSubClient *pubsub.PubSubClientServiceImpl, configuration *internals.Configuration) (*CommitEventPublisherImpl, error) {
+	eventBus, err := NewEventBus(configuration, pubSubClient)
+	if err != nil {
+		logger.Errorw("error in creating commit event bus", "bus", configuration.CommitEventBus, "err", err)
+		return nil, err
+	}
+	cronLogger := &CronLoggerImpl{logger: logger}
+	impl := &CommitEventPublisherImpl{
+		logger:           logger,
+		outboxRepository: outboxRepository,
+		eventBus:         eventBus,
+		configuration:    configuration,
+		cron: cron.New(
+			cron.WithChain(
+				cron.SkipIfStillRunning(cronLogger),
+				cron.Recover(cronLogger))),
+	}
+	if eventBus != nil && configuration.CommitEventPublishIntervalSec > 0 {
+		_, err = impl.cron.AddFunc(fmt.Sprintf("@every %ds", configuration.CommitEventPublishIntervalSec), impl.PublishOutbox)
+		if err != nil {
+			logger.Errorw("error in starting commit event publish cron", "err", err)
+			return nil, err
+		}
+		impl.cron.Start()
+	}
+	return impl, nil
+}
+
+func (impl *CommitEventPublisherImpl) StopCron() {
+	<-impl.cron.Stop().Done()
+}
+
+func (impl *CommitEventPublisherImpl) Enqueue(gitMaterial *sql.GitMaterial, materials []*CiPipelineMaterialBean) error {
+	outboxEvents, err := impl.NewOutboxEvents(gitMaterial, materials)
+	if err != nil {
+		return err
+	}
+	err = impl.outboxRepository.Save(outboxEvents)
+	if err != nil {
+		impl.logger.Errorw("error in saving commit events to outbox", "gitMaterialId", gitMaterial.Id, "events", len(outboxEvents), "err", err)
+		return err
+	}
+	impl.logger.Debugw("commit events saved to outbox", "gitMaterialId", gitMaterial.Id, "events", len(outboxEvents))
+	return nil
+}
+
+func (impl *CommitEventPublisherImpl) NewOutboxEvents(gitMaterial *sql.GitMaterial, materials []*CiPipelineMaterialBean) ([]*sql.CommitEventOutbox, error) {
+	if impl.eventBus == nil {
+		return nil, nil
+	}
+	topic := gitMaterial.EventTopic
+	if len(topic) == 0 {
+		topic = impl.configuration.CommitEventTopic
+	}
+	now := time.Now()
+	var outboxEvents []*sql.CommitEventOutbox
+	for _, event := range NewCommitEvents(gitMaterial, materials, now) {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			impl.logger.Errorw("error in marshaling commit event", "eventId", event.EventId, "err", err)
+			return nil, err
+		}
+		outboxEvents = append(outboxEvents, &sql.CommitEventOutbox{
+			EventId:              event.EventId,
+			EventType:            event.EventType,
+			Topic:                topic,
+			GitMaterialId:        event.GitMaterialId,
+			CiPipelineMaterialId: event.CiPipelineMaterialId,
+			Payload:              string(payload),
+			CreatedOn:            now,
+			UpdatedOn:            now,
+		})
+	}
+	return outboxEvents, nil
+}
+
+func (impl *CommitEventPublisherImpl) PublishOutbox() {
+	if impl.eventBus == nil {
+		return
+	}
+	outboxEvents, err := impl.outboxRepository.FindPending(impl.configuration.CommitEventBatchSize)
+	if err != nil {
+		impl.logger.Errorw("error in getting pending commit events", "err", err)
+		return
+	}
+	// materials with a failed event, their later events wait for it to be published
+	heldMaterials := make(map[int]bool)
+	for _, outboxEvent := range outboxEvents {
+		if heldMaterials[outboxEvent.GitMaterialId] {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), commitEventBusTimeout)
+		err = impl.eventBus.Publish(ctx, outboxEvent.Topic, outboxEvent.EventId, []byte(outboxEvent.Payload))
+		cancel()
+		if err != nil {
+			middleware.CommitEventPublishCounter.WithLabelValues(outboxEvent.EventType, "failure").Inc()
+			impl.logger.Errorw("error in publishing commit event", "eventId", outboxEvent.EventId, "topic", outboxEvent.Topic, "attempts", outboxEvent.Attempts+1, "err", err)
+			outboxEvent.Attempts = outboxEvent.Attempts + 1
+			outboxEvent.Error = SanitizeText(err.Error())
+			outboxEvent.UpdatedOn = time.Now()
+			maxAttempts := impl.configuration.CommitEventMaxAttempts
+			if maxAttempts > 0 && outboxEvent.Attempts >= maxAttempts {
+				outboxEvent.DeadLettered = true
+				middleware.CommitEventPublishCounter.WithLabelValues(outboxEvent.EventType, "dead_lettered").Inc()
+				impl.logger.Errorw("commit event dead lettered, it is not published again", "eventId", outboxEvent.EventId, "gitMaterialId", outboxEvent.GitMaterialId, "attempts", outboxEvent.Attempts)
+			} else {
+				heldMaterials[outboxEvent.GitMaterialId] = true
+			}
+			if updateErr := impl.outboxRepository.Update(outboxEvent); updateErr != nil {
+				impl.logger.Errorw("error in updating commit event", "eventId", outboxEvent.EventId, "err", updateErr)
+				heldMaterials[outboxEvent.GitMaterialId] = true
+			}
+			continue
+		}
+		middleware.CommitEventPublishCounter.WithLabelValues(outboxEvent.EventType, "success").Inc()
+		// deleting a published event may fail, it is then published again which the consumers are expected to tolerate
+		if err = impl.outboxRepository.Delete(outboxEvent); err != nil {
+			impl.logger.Errorw("error in deleting published commit event", "eventId", outboxEvent.EventId, "err", err)
+			return
+		}
+	}
+}
+
+// NewCommitEvents returns the events of the material updates, one per new commit of a branch, oldest first, one per
+// tag and one per pull request event. Deleted branches have no event
+func NewCommitEvents(gitMaterial *sql.GitMaterial, materials []*CiPipelineMaterialBean, detectedOn time.Time) []*CommitEvent {
+	var events []*CommitEvent
+	for _, material := range materials {
+		if material.BranchDeleted || material.GitCommit == nil {
+			continue
+		}
+		newEvent := func(eventType string, commit *GitCommitBase) *CommitEvent {
+			return &CommitEvent{
+				EventType:            eventType,
+				GitMaterialId:        gitMaterial.Id,
+				Url:                  tracing.SanitizeUrl(gitMaterial.Url),
+				CiPipelineMaterialId: material.Id,
+				Commit:               commit,
+				DetectedOn:           detectedOn,
+			}
+		}
+		switch {
+		case material.PullRequest != nil:
+			event := newEvent(COMMIT_EVENT_TYPE_PULL_REQUEST, material.GitCommit)
+			event.Branch = material.PullRequest.SourceBranch
+			event.PullRequest = material.PullRequest
+			event.EventId = getCommitEventId(event, strconv.FormatInt(material.PullRequest.Number, 10), material.PullRequest.Action, material.PullRequest.SourceCommit)
+			events = append(events, event)
+		case material.Type == sql.SOURCE_TYPE_TAG_ANY:
+			event := newEvent(COMMIT_EVENT_TYPE_TAG, material.GitCommit)
+			if material.GitCommit.Tag != nil {
+				event.Tag = material.GitCommit.Tag.Name
+			}
+			event.EventId = getCommitEventId(event, event.Tag, material.GitCommit.Commit)
+			events = append(events, event)
+		default:
+			commits := material.NewCommits
+			if len(commits) == 0 {
+				commits = []*GitCommitBase{material.GitCommit}
+			}
+			branch := material.Branch
+			if len(branch) == 0 {
+				branch = material.Value
+			}
+			for i := len(commits) - 1; i >= 0; i-- {
+				event := newEvent(COMMIT_EVENT_TYPE_COMMIT, commits[i])
+				event.Branch = branch
+				event.ForcePushed = material.ForcePushed && i == 0
+				event.EventId = getCommitEventId(event, branch, commits[i].Commit)
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+// getCommitEventId derives the id from what the event is about, so that the same change found again, e.g. on a poll
+// retried after a failed update, is deduplicated by the outbox and the consumers
+func getCommitEventId(event *CommitEvent, keys ...string) string {
+	hash := sha1.New()
+	hash.Write([]byte(fmt.Sprintf("%s/%d/%d", event.EventType, event.GitMaterialId, event.CiPipelineMaterialId)))
+	for _, key := range keys {
+		hash.Write([]byte("/" + key))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals"
+	"regexp"
+	"strings"
+)
+
+// SKIP_CI_MARKERS in a commit message skip the auto trigger of the commit
+var SKIP_CI_MARKERS = []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]"}
+
+// CommitFilter decides which new commits found by the watcher trigger ci. Filtered commits are still recorded as the last
+// seen commit and in the commit history of the material
+type CommitFilter struct {
+	IncludeMessageRegex *regexp.Regexp
+	ExcludeMessageRegex *regexp.Regexp
+	IncludeAuthorRegex  *regexp.Regexp
+	ExcludeAuthorRegex  *regexp.Regexp
+	HonorSkipCiMarkers  bool
+}
+
+func NewCommitFilter(configuration *internals.Configuration) (*CommitFilter, error) {
+	filter := &CommitFilter{HonorSkipCiMarkers: configuration.HonorSkipCiMarkers}
+	var err error
+	if filter.IncludeMessageRegex, err = compileOptionalRegex(configuration.CommitIncludeMessageRegex); err != nil {
+		return nil, err
+	}
+	if filter.ExcludeMessageRegex, err = compileOptionalRegex(configuration.CommitExcludeMessageRegex); err != nil {
+		return nil, err
+	}
+	if filter.IncludeAuthorRegex, err = compileOptionalRegex(configuration.CommitIncludeAuthorRegex); err != nil {
+		return nil, err
+	}
+	if filter.ExcludeAuthorRegex, err = compileOptionalRegex(configuration.CommitExcludeAuthorRegex); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// compileOptionalRegex returns nil for an empty expression. Unlike the branch regexes, the expressions are not anchored
+// as they are matched against free text
+func compileOptionalRegex(expr string) (*regexp.Regexp, error) {
+	if len(expr) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// Matches checks if the commit should trigger ci. Exclusions take precedence over inclusions
+func (filter *CommitFilter) Matches(commit *GitCommitBase) bool {
+	if commit == nil {
+		return true
+	}
+	if filter.HonorSkipCiMarkers && HasSkipCiMarker(commit.Message) {
+		return false
+	}
+	if filter.ExcludeMessageRegex != nil && filter.ExcludeMessageRegex.MatchString(commit.Message) {
+		return false
+	}
+	if filter.ExcludeAuthorRegex != nil && filter.ExcludeAuthorRegex.MatchString(commit.Author) {
+		return false
+	}
+	if filter.IncludeMessageRegex != nil && !filter.IncludeMessageRegex.MatchString(commit.Message) {
+		return false
+	}
+	if filter.IncludeAuthorRegex != nil && !filter.IncludeAuthorRegex.MatchString(commit.Author) {
+		return false
+	}
+	return true
+}
+
+func HasSkipCiMarker(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range SKIP_CI_MARKERS {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCommitFilter_Matches(t *testing.T) {
+	filter, err := NewCommitFilter(&internals.Configuration{
+		HonorSkipCiMarkers:        true,
+		CommitExcludeMessageRegex: "^chore\\(release\\)",
+		CommitExcludeAuthorRegex:  "renovate\\[bot\\]",
+	})
+	assert.Nil(t, err)
+	assert.True(t, filter.Matches(&GitCommitBase{Message: "fix: handle empty branch", Author: "dev <dev@example.com>"}))
+	assert.False(t, filter.Matches(&GitCommitBase{Message: "docs: update readme [Skip CI]", Author: "dev <dev@example.com>"}))
+	assert.False(t, filter.Matches(&GitCommitBase{Message: "chore(release): 1.2.0", Author: "dev <dev@example.com>"}))
+	assert.False(t, filter.Matches(&GitCommitBase{Message: "update dependency", Author: "renovate[bot] <bot@renovateapp.com>"}))
+
+	filter, err = NewCommitFilter(&internals.Configuration{CommitIncludeMessageRegex: "#deploy", CommitIncludeAuthorRegex: "@example\\.com"})
+	assert.Nil(t, err)
+	assert.True(t, filter.Matches(&GitCommitBase{Message: "feat: new api #deploy", Author: "dev <dev@example.com>"}))
+	assert.False(t, filter.Matches(&GitCommitBase{Message: "feat: new api", Author: "dev <dev@example.com>"}))
+	assert.False(t, filter.Matches(&GitCommitBase{Message: "feat: new api #deploy", Author: "dev <dev@other.org>"}))
+	// markers are ignored unless enabled
+	assert.True(t, filter.Matches(&GitCommitBase{Message: "[skip ci] #deploy", Author: "dev <dev@example.com>"}))
+
+	_, err = NewCommitFilter(&internals.Configuration{CommitExcludeMessageRegex: "("})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strings"
+)
+
+const (
+	NOTES_REF_PREFIX = "refs/notes/"
+	// NOTES_REF_SPEC fetches the notes refs of the remote as they are, notes are not tracked per remote by git
+	NOTES_REF_SPEC = "+refs/notes/*:refs/notes/*"
+)
+
+// GetCommitNotes returns the notes of the commits keyed by commit and then by the name of the notes ref, refs/notes/commits
+// is reported as commits. Commits without notes are left out
+func (impl *GitManagerBaseImpl) GetCommitNotes(gitCtx GitContext, rootDir string, commitHashes []string) (map[string]map[string]string, error) {
+	if len(commitHashes) == 0 {
+		return nil, nil
+	}
+	impl.logger.Debugw("git", "-C", rootDir, "for-each-ref", "--format=%(refname)", NOTES_REF_PREFIX)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "for-each-ref", "--format=%(refname)", NOTES_REF_PREFIX)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in listing notes refs", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(commitHashes))
+	for _, commitHash := range commitHashes {
+		wanted[commitHash] = true
+	}
+	var noteBlobs, noteCommits, noteRefs []string
+	for _, notesRef := range strings.Fields(output) {
+		notes, err := impl.listNotes(gitCtx, rootDir, notesRef)
+		if err != nil {
+			return nil, err
+		}
+		for commitHash, noteBlob := range notes {
+			if wanted[commitHash] {
+				noteBlobs = append(noteBlobs, noteBlob)
+				noteCommits = append(noteCommits, commitHash)
+				noteRefs = append(noteRefs, strings.TrimPrefix(notesRef, NOTES_REF_PREFIX))
+			}
+		}
+	}
+	if len(noteBlobs) == 0 {
+		return nil, nil
+	}
+	catFileOutput, err := impl.catFile(gitCtx, rootDir, "--batch", noteBlobs)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := parseCatFileBatch(catFileOutput)
+	if err != nil {
+		impl.logger.Errorw("error in parsing notes", "rootDir", rootDir, "err", err)
+		return nil, err
+	}
+	commitNotes := make(map[string]map[string]string)
+	for i, content := range contents {
+		if i >= len(noteCommits) || content == nil {
+			continue
+		}
+		if commitNotes[noteCommits[i]] == nil {
+			commitNotes[noteCommits[i]] = make(map[string]string)
+		}
+		commitNotes[noteCommits[i]][noteRefs[i]] = strings.TrimSpace(string(content))
+	}
+	return commitNotes, nil
+}
+
+// listNotes returns the note blobs of the notes ref keyed by the annotated object, as printed by git notes list
+//
+//	<note blob> <annotated object>
+func (impl *GitManagerBaseImpl) listNotes(gitCtx GitContext, rootDir string, notesRef string) (map[string]string, error) {
+	impl.logger.Debugw("git", "-C", rootDir, "notes", "--ref", notesRef, "list")
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "notes", "--ref", notesRef, "list")
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in listing notes", "rootDir", rootDir, "notesRef", notesRef, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	return parseNotesList(output), nil
+}
+
+func parseNotesList(output string) map[string]string {
+	notes := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		notes[fields[1]] = fields[0]
+	}
+	return notes
+}
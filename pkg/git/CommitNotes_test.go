@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetFetchRefSpecs(t *testing.T) {
+	assert.Nil(t, getFetchRefSpecs(false, false))
+	assert.Equal(t, []string{BRANCH_REF_SPEC, GERRIT_CHANGE_REF_SPEC}, getFetchRefSpecs(true, false))
+	assert.Equal(t, []string{BRANCH_REF_SPEC, NOTES_REF_SPEC}, getFetchRefSpecs(false, true))
+}
+
+func TestParseNotesList(t *testing.T) {
+	notes := parseNotesList("1111 aaaa\n2222 bbbb\n\n")
+	assert.Equal(t, map[string]string{"aaaa": "1111", "bbbb": "2222"}, notes)
+}
+
+func TestGitManagerBaseImpl_GetCommitNotes(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{FetchGitNotes: true})
+	remoteDir, workDir := setupTestRemote(t)
+	head := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	first := runTestGitCmd(t, workDir, "rev-parse", "HEAD~1")
+	runTestGitCmd(t, workDir, "notes", "add", "-m", "deployed to qa", head)
+	runTestGitCmd(t, workDir, "notes", "--ref", "review", "add", "-m", "Reviewed-by: A <a@devtron.ai>", head)
+	runTestGitCmd(t, workDir, "push", "origin", "refs/notes/*")
+
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	runTestGitCmd(t, filepath.Dir(checkoutPath), "init", checkoutPath)
+	runTestGitCmd(t, checkoutPath, "remote", "add", "origin", remoteDir)
+	gitCtx := BuildGitContext(context.Background())
+	_, errMsg, err := impl.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err, errMsg)
+
+	notes, err := impl.GetCommitNotes(gitCtx, checkoutPath, []string{head, first})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		head: {"commits": "deployed to qa", "review": "Reviewed-by: A <a@devtron.ai>"},
+	}, notes)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"regexp"
+	"strings"
+)
+
+const (
+	// COMMIT_POLICY_MODE_ANNOTATE only reports the verdict with the commit, COMMIT_POLICY_MODE_EXCLUDE also keeps the
+	// denied commits from triggering ci and out of the commit listing
+	COMMIT_POLICY_MODE_ANNOTATE = "annotate"
+	COMMIT_POLICY_MODE_EXCLUDE  = "exclude"
+
+	COMMIT_POLICY_RULE_EMAIL_DOMAIN  = "EMAIL_DOMAIN_NOT_ALLOWED"
+	COMMIT_POLICY_RULE_AUTHOR        = "AUTHOR_DENIED"
+	COMMIT_POLICY_RULE_EMPTY_MESSAGE = "EMPTY_MESSAGE"
+	COMMIT_POLICY_RULE_FORCE_PUSH    = "FORCE_PUSHED"
+	COMMIT_POLICY_RULE_BINARY_CHANGE = "BINARY_CHANGES"
+)
+
+// CommitPolicyVerdict is the result of the commit policy for a commit, Violations lists the deny rules it matched
+type CommitPolicyVerdict struct {
+	Allowed    bool
+	Mode       string   `json:",omitempty"` // mode of the policy for a denied commit
+	Violations []string `json:",omitempty"`
+}
+
+// IsExcluded checks if the commit is kept from triggering ci and out of the commit listing
+func (verdict *CommitPolicyVerdict) IsExcluded() bool {
+	return verdict != nil && !verdict.Allowed && verdict.Mode == COMMIT_POLICY_MODE_EXCLUDE
+}
+
+// CommitPolicy evaluates the deny rules for the new commits found by the watcher. Unlike the CommitFilter, the result
+// is kept with the commit so that the reason a commit did not trigger ci can be reported
+type CommitPolicy struct {
+	Mode                string
+	AllowedEmailDomains []string
+	DenyAuthorRegex     *regexp.Regexp
+	DenyEmptyMessage    bool
+	DenyForcePush       bool
+	DenyBinaryChanges   bool // needs the file stats of the commits, see ENABLE_FILE_STATS
+}
+
+func NewCommitPolicy(configuration *internals.Configuration) (*CommitPolicy, error) {
+	policy := &CommitPolicy{
+		Mode:              strings.ToLower(configuration.CommitPolicyMode),
+		DenyEmptyMessage:  configuration.CommitPolicyDenyEmptyMessage,
+		DenyForcePush:     configuration.CommitPolicyDenyForcePush,
+		DenyBinaryChanges: configuration.CommitPolicyDenyBinary,
+	}
+	if len(policy.Mode) == 0 {
+		policy.Mode = COMMIT_POLICY_MODE_ANNOTATE
+	}
+	if policy.Mode != COMMIT_POLICY_MODE_ANNOTATE && policy.Mode != COMMIT_POLICY_MODE_EXCLUDE {
+		return nil, fmt.Errorf("invalid commit policy mode %q, expected %s or %s", configuration.CommitPolicyMode, COMMIT_POLICY_MODE_ANNOTATE, COMMIT_POLICY_MODE_EXCLUDE)
+	}
+	for _, domain := range strings.Split(configuration.CommitPolicyAllowedDomains, ",") {
+		if domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), ".@")); len(domain) > 0 {
+			policy.AllowedEmailDomains = append(policy.AllowedEmailDomains, domain)
+		}
+	}
+	var err error
+	if policy.DenyAuthorRegex, err = compileOptionalRegex(configuration.CommitPolicyDenyAuthorRegex); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (policy *CommitPolicy) isEnabled() bool {
+	return len(policy.AllowedEmailDomains) > 0 || policy.DenyAuthorRegex != nil || policy.DenyEmptyMessage || policy.DenyForcePush || policy.DenyBinaryChanges
+}
+
+// Evaluate returns the verdict for a commit, forcePushed being set for the commits which rewrote the history of the
+// branch. It is nil when no rule is configured
+func (policy *CommitPolicy) Evaluate(commit *GitCommitBase, forcePushed bool) *CommitPolicyVerdict {
+	if policy == nil || commit == nil || !policy.isEnabled() {
+		return nil
+	}
+	var violations []string
+	if len(policy.AllowedEmailDomains) > 0 && !policy.isEmailDomainAllowed(getCommitAuthorEmail(commit)) {
+		violations = append(violations, COMMIT_POLICY_RULE_EMAIL_DOMAIN)
+	}
+	if policy.DenyAuthorRegex != nil && policy.DenyAuthorRegex.MatchString(commit.Author) {
+		violations = append(violations, COMMIT_POLICY_RULE_AUTHOR)
+	}
+	if policy.DenyEmptyMessage && len(strings.TrimSpace(commit.Message)) == 0 {
+		violations = append(violations, COMMIT_POLICY_RULE_EMPTY_MESSAGE)
+	}
+	if policy.DenyForcePush && forcePushed {
+		violations = append(violations, COMMIT_POLICY_RULE_FORCE_PUSH)
+	}
+	if policy.DenyBinaryChanges && commit.ContainsBinaryChanges {
+		violations = append(violations, COMMIT_POLICY_RULE_BINARY_CHANGE)
+	}
+	if len(violations) == 0 {
+		return &CommitPolicyVerdict{Allowed: true}
+	}
+	return &CommitPolicyVerdict{Allowed: false, Mode: policy.Mode, Violations: violations}
+}
+
+// isEmailDomainAllowed matches the domain of the email with the allowed domains and their sub domains
+func (policy *CommitPolicy) isEmailDomainAllowed(email string) bool {
+	_, domain, found := strings.Cut(strings.ToLower(email), "@")
+	if !found {
+		return false
+	}
+	for _, allowedDomain := range policy.AllowedEmailDomains {
+		if domain == allowedDomain || strings.HasSuffix(domain, "."+allowedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// getCommitAuthorEmail returns the email of the author, read from the "name <email>" author for the commits which
+// don't carry the author detail
+func getCommitAuthorEmail(commit *GitCommitBase) string {
+	if commit.AuthorDetail != nil {
+		return commit.AuthorDetail.Email
+	}
+	_, email, _, found := parseMailmapNameAndEmail(commit.Author)
+	if !found {
+		return ""
+	}
+	return email
+}
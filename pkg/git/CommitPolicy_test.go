@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCommitPolicy_Evaluate(t *testing.T) {
+	policy, err := NewCommitPolicy(&internals.Configuration{})
+	assert.Nil(t, err)
+	assert.Nil(t, policy.Evaluate(&GitCommitBase{Message: "", Author: "dev <dev@other.org>"}, true))
+
+	policy, err = NewCommitPolicy(&internals.Configuration{
+		CommitPolicyMode:             "exclude",
+		CommitPolicyAllowedDomains:   "example.com, @devtron.ai",
+		CommitPolicyDenyAuthorRegex:  "\\[bot\\]",
+		CommitPolicyDenyEmptyMessage: true,
+		CommitPolicyDenyForcePush:    true,
+	})
+	assert.Nil(t, err)
+	verdict := policy.Evaluate(&GitCommitBase{Message: "fix: handle empty branch", Author: "dev <dev@ci.example.com>"}, false)
+	assert.True(t, verdict.Allowed)
+	assert.False(t, verdict.IsExcluded())
+
+	// the author detail takes precedence over the author
+	verdict = policy.Evaluate(&GitCommitBase{Message: "  \n", Author: "dev <dev@devtron.ai>", AuthorDetail: &Author{Email: "dev@notexample.com"}}, true)
+	assert.False(t, verdict.Allowed)
+	assert.True(t, verdict.IsExcluded())
+	assert.Equal(t, []string{COMMIT_POLICY_RULE_EMAIL_DOMAIN, COMMIT_POLICY_RULE_EMPTY_MESSAGE, COMMIT_POLICY_RULE_FORCE_PUSH}, verdict.Violations)
+
+	verdict = policy.Evaluate(&GitCommitBase{Message: "update dependency", Author: "renovate[bot] <bot@devtron.ai>"}, false)
+	assert.Equal(t, []string{COMMIT_POLICY_RULE_AUTHOR}, verdict.Violations)
+
+	policy, err = NewCommitPolicy(&internals.Configuration{CommitPolicyDenyBinary: true})
+	assert.Nil(t, err)
+	verdict = policy.Evaluate(&GitCommitBase{Message: "add build output", ContainsBinaryChanges: true}, false)
+	assert.Equal(t, []string{COMMIT_POLICY_RULE_BINARY_CHANGE}, verdict.Violations)
+
+	policy, err = NewCommitPolicy(&internals.Configuration{CommitPolicyMode: "annotate", CommitPolicyDenyEmptyMessage: true})
+	assert.Nil(t, err)
+	verdict = policy.Evaluate(&GitCommitBase{Author: "dev <dev@other.org>"}, false)
+	assert.False(t, verdict.Allowed)
+	assert.False(t, verdict.IsExcluded())
+
+	_, err = NewCommitPolicy(&internals.Configuration{CommitPolicyMode: "block"})
+	assert.NotNil(t, err)
+	_, err = NewCommitPolicy(&internals.Configuration{CommitPolicyDenyAuthorRegex: "("})
+	assert.NotNil(t, err)
+}
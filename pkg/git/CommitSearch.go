@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrEmptyCommitSearch = errors.New("query or author is required to search commits")
+
+// SearchCommits searches the remote branches and tags of the repo with git log, a commit reachable from many of them
+// is listed once
+func (impl *GitManagerBaseImpl) SearchCommits(gitCtx GitContext, rootDir string, request *CommitSearchRequest) ([]*GitCommitBase, error) {
+	cmdArgs, err := getCommitSearchArgs(rootDir, request)
+	if err != nil {
+		return nil, err
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in searching commits", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	commits := make([]*GitCommitBase, 0)
+	if len(output) == 0 {
+		return commits, nil
+	}
+	formattedCommits, err := parseFormattedLogOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	for _, formattedCommit := range formattedCommits {
+		commit := toGitCommitBase(formattedCommit)
+		commits = append(commits, &commit)
+	}
+	return commits, nil
+}
+
+// getCommitSearchArgs matches the query and author as fixed strings unless a regex is asked for, a commit must match
+// both when both are set
+func getCommitSearchArgs(rootDir string, request *CommitSearchRequest) ([]string, error) {
+	if len(request.Query) == 0 && len(request.Author) == 0 {
+		return nil, ErrEmptyCommitSearch
+	}
+	limit := request.Limit
+	if limit <= 0 || limit > COMMIT_SEARCH_MAX_LIMIT {
+		limit = COMMIT_SEARCH_MAX_LIMIT
+	}
+	cmdArgs := []string{"-C", rootDir, "log", "--remotes", "--tags", "-n", strconv.Itoa(limit), "--date=iso-strict", GITFORMAT, "--regexp-ignore-case"}
+	if !request.Regex {
+		cmdArgs = append(cmdArgs, "--fixed-strings")
+	}
+	if len(request.Query) > 0 {
+		cmdArgs = append(cmdArgs, "--grep="+request.Query)
+	}
+	if len(request.Author) > 0 {
+		cmdArgs = append(cmdArgs, "--author="+request.Author)
+	}
+	if request.Since != nil {
+		cmdArgs = append(cmdArgs, "--since="+request.Since.Format(time.RFC3339))
+	}
+	if request.Until != nil {
+		cmdArgs = append(cmdArgs, "--until="+request.Until.Format(time.RFC3339))
+	}
+	return cmdArgs, nil
+}
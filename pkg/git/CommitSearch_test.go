@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGitManager_SearchCommits(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "fix: retry fetch (ABC-123)")
+	runTestGitCmd(t, workDir, "-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com", "commit", "--allow-empty", "-m", "feat: abc-123 follow up")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	runTestGitCmd(t, workDir, "checkout", "-b", "release")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "chore: release ABC-1234")
+	runTestGitCmd(t, workDir, "tag", "v1.0.0")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "chore: release fix ABC-999")
+	runTestGitCmd(t, workDir, "push", "origin", "v1.0.0", "release~1:refs/heads/release")
+
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	search := func(request *CommitSearchRequest) []string {
+		commits, err := repositoryManager.gitManager.SearchCommits(gitCtx, checkoutPath, request)
+		assert.Nil(t, err)
+		var messages []string
+		for _, commit := range commits {
+			messages = append(messages, commit.Message)
+		}
+		return messages
+	}
+
+	assert.ElementsMatch(t, []string{"chore: release ABC-1234", "feat: abc-123 follow up", "fix: retry fetch (ABC-123)"}, search(&CommitSearchRequest{Query: "ABC-123"}))
+	assert.ElementsMatch(t, []string{"feat: abc-123 follow up", "fix: retry fetch (ABC-123)"}, search(&CommitSearchRequest{Query: "abc-123\\b", Regex: true}))
+	assert.Equal(t, []string{"feat: abc-123 follow up"}, search(&CommitSearchRequest{Query: "ABC-123", Author: "jane@example"}))
+	assert.Len(t, search(&CommitSearchRequest{Query: "ABC", Limit: 1}), 1)
+	// the regex characters of the query are matched as is
+	assert.Empty(t, search(&CommitSearchRequest{Query: "ABC-12."}))
+	since := time.Now().Add(time.Hour)
+	assert.Empty(t, search(&CommitSearchRequest{Query: "ABC-123", Since: &since}))
+
+	_, err = repositoryManager.gitManager.SearchCommits(gitCtx, checkoutPath, &CommitSearchRequest{})
+	assert.ErrorIs(t, err, ErrEmptyCommitSearch)
+}
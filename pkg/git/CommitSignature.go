@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strings"
+)
+
+// SIGNATURE_FORMAT prints the %G? status, key, signer and trust level of the commit signature separated by the unit separator
+const SIGNATURE_FORMAT = "--format=%G?%x1f%GK%x1f%GS%x1f%GT"
+
+// signature status as printed by %G?
+const (
+	SIGNATURE_STATUS_GOOD              = "G"
+	SIGNATURE_STATUS_BAD               = "B"
+	SIGNATURE_STATUS_UNKNOWN_VALIDITY  = "U"
+	SIGNATURE_STATUS_EXPIRED           = "X"
+	SIGNATURE_STATUS_EXPIRED_KEY       = "Y"
+	SIGNATURE_STATUS_REVOKED_KEY       = "R"
+	SIGNATURE_STATUS_CANNOT_BE_CHECKED = "E"
+	SIGNATURE_STATUS_UNSIGNED          = "N"
+)
+
+const SSH_ALLOWED_SIGNERS_FILE_GIT_CONFIG = "gpg.ssh.allowedSignersFile"
+
+type CommitSignature struct {
+	Status     string // one of the %G? statuses
+	Signed     bool
+	Verified   bool   // signature is good and made with a key which is trusted
+	KeyId      string `json:",omitempty"`
+	Signer     string `json:",omitempty"`
+	TrustLevel string `json:",omitempty"` // undefined, never, marginal, fully or ultimate
+}
+
+func (impl *GitManagerBaseImpl) VerifyCommitSignature(gitCtx GitContext, rootDir string, commitHash string) (*CommitSignature, error) {
+	cmdArgs := []string{"-C", rootDir, "show", "-s", SIGNATURE_FORMAT, commitHash}
+	if len(impl.conf.SshAllowedSignersFile) > 0 {
+		// set by git-sensor itself, it is not one of the extra git config keys a material may have
+		cmdArgs = append([]string{"-c", SSH_ALLOWED_SIGNERS_FILE_GIT_CONFIG + "=" + impl.conf.SshAllowedSignersFile}, cmdArgs...)
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	if len(impl.conf.GpgHomeDir) > 0 {
+		cmd.Env = append(cmd.Env, "GNUPGHOME="+impl.conf.GpgHomeDir)
+	}
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		impl.logger.Errorw("error in verifying commit signature", "rootDir", rootDir, "commitHash", commitHash, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	return parseCommitSignature(output), nil
+}
+
+func parseCommitSignature(output string) *CommitSignature {
+	// gpg may print warnings before the formatted line
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Split(lines[len(lines)-1], "\x1f")
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	status := strings.TrimSpace(fields[0])
+	if len(status) == 0 {
+		status = SIGNATURE_STATUS_UNSIGNED
+	}
+	trustLevel := strings.TrimSpace(fields[3])
+	return &CommitSignature{
+		Status:     status,
+		Signed:     status != SIGNATURE_STATUS_UNSIGNED,
+		Verified:   status == SIGNATURE_STATUS_GOOD && trustLevel != "never",
+		KeyId:      strings.TrimSpace(fields[1]),
+		Signer:     strings.TrimSpace(fields[2]),
+		TrustLevel: trustLevel,
+	}
+}
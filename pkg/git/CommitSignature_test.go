@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCommitSignature(t *testing.T) {
+	assert.Equal(t, &CommitSignature{Status: SIGNATURE_STATUS_UNSIGNED, TrustLevel: "undefined"}, parseCommitSignature("N\x1f\x1f\x1fundefined"))
+	assert.Equal(t, &CommitSignature{Status: SIGNATURE_STATUS_UNSIGNED}, parseCommitSignature(""))
+	assert.Equal(t, &CommitSignature{Status: SIGNATURE_STATUS_GOOD, Signed: true, Verified: true, KeyId: "ABCD", Signer: "devtron", TrustLevel: "ultimate"},
+		parseCommitSignature("gpg: some warning\nG\x1fABCD\x1fdevtron\x1fultimate"))
+	assert.Equal(t, &CommitSignature{Status: SIGNATURE_STATUS_BAD, Signed: true, KeyId: "ABCD", TrustLevel: "undefined"},
+		parseCommitSignature("B\x1fABCD\x1f\x1fundefined"))
+}
+
+func TestGitManagerBaseImpl_VerifyCommitSignature(t *testing.T) {
+	_, workDir := setupTestRemote(t)
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "devtron@devtron.ai", "-f", keyFile).CombinedOutput()
+	assert.Nil(t, err, string(out))
+	publicKey, err := os.ReadFile(keyFile + ".pub")
+	assert.Nil(t, err)
+	allowedSignersFile := filepath.Join(keyDir, "allowed_signers")
+	assert.Nil(t, os.WriteFile(allowedSignersFile, append([]byte("devtron@devtron.ai "), publicKey...), 0644))
+	runTestGitCmd(t, workDir, "-c", "gpg.format=ssh", "-c", "user.signingkey="+keyFile, "commit", "-S", "--allow-empty", "-m", "signed")
+
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{SshAllowedSignersFile: allowedSignersFile})
+	gitCtx := BuildGitContext(context.Background())
+
+	signature, err := impl.VerifyCommitSignature(gitCtx, workDir, "HEAD")
+	assert.Nil(t, err)
+	assert.Equal(t, SIGNATURE_STATUS_GOOD, signature.Status)
+	assert.True(t, signature.Verified)
+	assert.Equal(t, "devtron@devtron.ai", signature.Signer)
+
+	signature, err = impl.VerifyCommitSignature(gitCtx, workDir, "HEAD~1")
+	assert.Nil(t, err)
+	assert.False(t, signature.Signed)
+	assert.False(t, signature.Verified)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CommitStatsCache keeps the file stats of the most recently used commits, so that repeated lookups of the same commits
+// don't run git again. The stats of a commit never change, the ttl only bounds how long unused repos are kept. A nil
+// cache caches nothing
+type CommitStatsCache struct {
+	size    int
+	ttl     time.Duration
+	mutex   sync.Mutex
+	order   *list.List // most recently used first
+	entries map[commitStatsKey]*list.Element
+	now     func() time.Time
+}
+
+type commitStatsKey struct {
+	repo   string
+	commit string
+}
+
+type commitStatsEntry struct {
+	key       commitStatsKey
+	stats     FileStats
+	expiresAt time.Time
+}
+
+// NewCommitStatsCache returns nil when size is not positive, which disables the cache
+func NewCommitStatsCache(size int, ttl time.Duration) *CommitStatsCache {
+	if size <= 0 {
+		return nil
+	}
+	return &CommitStatsCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[commitStatsKey]*list.Element),
+		now:     time.Now,
+	}
+}
+
+func (impl *CommitStatsCache) Get(repo string, commit string) (FileStats, bool) {
+	if impl == nil {
+		return nil, false
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	element, ok := impl.entries[commitStatsKey{repo: repo, commit: commit}]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*commitStatsEntry)
+	if impl.ttl > 0 && impl.now().After(entry.expiresAt) {
+		impl.remove(element)
+		return nil, false
+	}
+	impl.order.MoveToFront(element)
+	return entry.stats, true
+}
+
+// Put caches the stats, evicting the least recently used commit when the cache is full
+func (impl *CommitStatsCache) Put(repo string, commit string, stats FileStats) {
+	if impl == nil {
+		return
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	key := commitStatsKey{repo: repo, commit: commit}
+	expiresAt := impl.now().Add(impl.ttl)
+	if element, ok := impl.entries[key]; ok {
+		entry := element.Value.(*commitStatsEntry)
+		entry.stats, entry.expiresAt = stats, expiresAt
+		impl.order.MoveToFront(element)
+		return
+	}
+	impl.entries[key] = impl.order.PushFront(&commitStatsEntry{key: key, stats: stats, expiresAt: expiresAt})
+	for impl.order.Len() > impl.size {
+		impl.remove(impl.order.Back())
+	}
+}
+
+func (impl *CommitStatsCache) remove(element *list.Element) {
+	impl.order.Remove(element)
+	delete(impl.entries, element.Value.(*commitStatsEntry).key)
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCommitStatsCache(t *testing.T) {
+	cache := NewCommitStatsCache(2, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	statsA := FileStats{{Name: "a.go", Addition: 1}}
+	cache.Put("/repo", "a", statsA)
+	cache.Put("/repo", "b", FileStats{})
+	stats, ok := cache.Get("/repo", "a")
+	assert.True(t, ok)
+	assert.Equal(t, statsA, stats)
+	_, ok = cache.Get("/other-repo", "a")
+	assert.False(t, ok)
+
+	// b is the least recently used
+	cache.Put("/repo", "c", FileStats{})
+	_, ok = cache.Get("/repo", "b")
+	assert.False(t, ok)
+	_, ok = cache.Get("/repo", "c")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.Get("/repo", "a")
+	assert.False(t, ok)
+
+	disabled := NewCommitStatsCache(0, time.Minute)
+	disabled.Put("/repo", "a", statsA)
+	_, ok = disabled.Get("/repo", "a")
+	assert.False(t, ok)
+}
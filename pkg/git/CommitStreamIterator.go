@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// COMMIT_STREAM_BUFFER_SIZE is the number of parsed commits kept ahead of the consumer
+const COMMIT_STREAM_BUFFER_SIZE = 100
+
+// CommitCliStreamIterator parses the git log output as it is read, so only the buffered commits are held in memory
+type CommitCliStreamIterator struct {
+	commits chan GitCommit
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+	peeked  GitCommit
+}
+
+// newCommitCliStreamIterator reads the NUL separated (git log -z) output of stdout in the background,
+// wait is called once stdout is exhausted or the iterator is closed
+func newCommitCliStreamIterator(stdout io.ReadCloser, wait func() (string, error)) *CommitCliStreamIterator {
+	ctx, cancel := context.WithCancel(context.Background())
+	itr := &CommitCliStreamIterator{
+		commits: make(chan GitCommit, COMMIT_STREAM_BUFFER_SIZE),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go itr.read(stdout, wait)
+	return itr
+}
+
+func (itr *CommitCliStreamIterator) read(stdout io.ReadCloser, wait func() (string, error)) {
+	defer close(itr.commits)
+	reader := bufio.NewReader(stdout)
+	var readErr error
+	for readErr == nil {
+		var record string
+		record, readErr = reader.ReadString('\x00')
+		record = strings.TrimSuffix(record, "\x00")
+		if len(strings.TrimSpace(record)) == 0 {
+			continue
+		}
+		formattedCommits, err := parseFormattedLogOutput(record)
+		if err != nil {
+			itr.err = err
+			break
+		}
+		for _, formattedCommit := range formattedCommits {
+			select {
+			case itr.commits <- &GitCommitCli{GitCommitBase: toGitCommitBase(formattedCommit)}:
+			case <-itr.ctx.Done():
+				readErr = itr.ctx.Err()
+			}
+		}
+	}
+	// unblock git if the consumer stopped early, then reap the process
+	_ = stdout.Close()
+	errMsg, err := wait()
+	if itr.err != nil || itr.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		if strings.Contains(errMsg, NO_COMMIT_GIT_ERROR_MESSAGE) {
+			itr.err = errors.New(NO_COMMIT_CUSTOM_ERROR_MESSAGE)
+		} else {
+			itr.err = err
+		}
+	} else if readErr != nil && readErr != io.EOF {
+		itr.err = readErr
+	}
+}
+
+// peek blocks till the first commit is read or the command fails, so that errors in starting the log
+// surface while creating the iterator like they do for the other iterators
+func (itr *CommitCliStreamIterator) peek() error {
+	commit, err := itr.Next()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	itr.peeked = commit
+	return nil
+}
+
+func (itr *CommitCliStreamIterator) Next() (GitCommit, error) {
+	if itr.peeked != nil {
+		commit := itr.peeked
+		itr.peeked = nil
+		return commit, nil
+	}
+	commit, ok := <-itr.commits
+	if ok {
+		return commit, nil
+	}
+	// the channel is closed after err is set
+	if itr.err != nil {
+		return nil, itr.err
+	}
+	return nil, io.EOF
+}
+
+func (itr *CommitCliStreamIterator) Close() {
+	itr.cancel()
+	for range itr.commits {
+	}
+}
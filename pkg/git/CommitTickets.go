@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"regexp"
+	"sort"
+)
+
+// CommitTicket is an issue or ticket id referenced in the commit message, Tracker is the name of the pattern it matched
+type CommitTicket struct {
+	Tracker string
+	Id      string
+}
+
+type ticketPattern struct {
+	tracker string
+	regex   *regexp.Regexp
+}
+
+// TicketExtractor finds the ticket ids in the commit messages with the patterns of COMMIT_TICKET_PATTERNS, a json of
+// tracker name to regex (e.g. {"jira": "\\b[A-Z][A-Z0-9]+-[0-9]+\\b", "github": "(?:^|\\s)#([0-9]+)\\b"}). The first
+// capture group of a pattern is taken as the id when it has one, else the whole match
+type TicketExtractor struct {
+	patterns []*ticketPattern
+}
+
+func NewTicketExtractor(configuration *internals.Configuration) (*TicketExtractor, error) {
+	extractor := &TicketExtractor{}
+	if len(configuration.CommitTicketPatterns) == 0 {
+		return extractor, nil
+	}
+	patterns := make(map[string]string)
+	if err := json.Unmarshal([]byte(configuration.CommitTicketPatterns), &patterns); err != nil {
+		return nil, fmt.Errorf("invalid commit ticket patterns: %w", err)
+	}
+	for tracker, expr := range patterns {
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit ticket pattern of %s: %w", tracker, err)
+		}
+		extractor.patterns = append(extractor.patterns, &ticketPattern{tracker: tracker, regex: regex})
+	}
+	// the tickets of a commit are listed in the same order on every poll
+	sort.Slice(extractor.patterns, func(i, j int) bool {
+		return extractor.patterns[i].tracker < extractor.patterns[j].tracker
+	})
+	return extractor, nil
+}
+
+// Extract returns the distinct tickets referenced in the message, in the order of the trackers and then of their first
+// reference
+func (extractor *TicketExtractor) Extract(message string) []*CommitTicket {
+	if extractor == nil {
+		return nil
+	}
+	var tickets []*CommitTicket
+	for _, pattern := range extractor.patterns {
+		seen := make(map[string]bool)
+		for _, match := range pattern.regex.FindAllStringSubmatch(message, -1) {
+			id := match[0]
+			if len(match) > 1 {
+				id = match[1]
+			}
+			if len(id) == 0 || seen[id] {
+				continue
+			}
+			seen[id] = true
+			tickets = append(tickets, &CommitTicket{Tracker: pattern.tracker, Id: id})
+		}
+	}
+	return tickets
+}
+
+// Annotate sets the tickets of the commits from their messages
+func (extractor *TicketExtractor) Annotate(commits ...*GitCommitBase) {
+	for _, commit := range commits {
+		if commit != nil {
+			commit.Tickets = extractor.Extract(commit.Message)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTicketExtractor(t *testing.T) {
+	extractor, err := NewTicketExtractor(&internals.Configuration{
+		CommitTicketPatterns: `{"jira": "\\b[A-Z][A-Z0-9]+-[0-9]+\\b", "github": "(?:^|\\s|\\()#([0-9]+)\\b"}`,
+	})
+	assert.Nil(t, err)
+
+	commit := &GitCommitBase{Message: "fix: retry fetch on timeout (#42)\n\nFixes ABC-123 and OPS-7, see also ABC-123 and #43 but not a#44"}
+	extractor.Annotate(commit)
+	assert.Equal(t, []*CommitTicket{
+		{Tracker: "github", Id: "42"},
+		{Tracker: "github", Id: "43"},
+		{Tracker: "jira", Id: "ABC-123"},
+		{Tracker: "jira", Id: "OPS-7"},
+	}, commit.Tickets)
+	assert.Empty(t, extractor.Extract("chore: bump dependencies"))
+
+	noPatterns, err := NewTicketExtractor(&internals.Configuration{})
+	assert.Nil(t, err)
+	assert.Empty(t, noPatterns.Extract("Fixes ABC-123"))
+	var nilExtractor *TicketExtractor
+	assert.Empty(t, nilExtractor.Extract("Fixes ABC-123"))
+
+	_, err = NewTicketExtractor(&internals.Configuration{CommitTicketPatterns: `{"jira": "[A-Z"}`})
+	assert.NotNil(t, err)
+	_, err = NewTicketExtractor(&internals.Configuration{CommitTicketPatterns: `["jira"]`})
+	assert.NotNil(t, err)
+}
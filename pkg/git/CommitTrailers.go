@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+// parseTrailerLines parses a block of "Key: value" lines (as printed by %(trailers))
+// into a map, keeping the order of values for repeated keys. Continuation lines,
+// i.e. lines starting with whitespace, are folded into the previous value.
+func parseTrailerLines(block string) map[string][]string {
+	var trailers map[string][]string
+	lastKey := ""
+	for _, line := range strings.Split(block, "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lastKey) > 0 {
+			values := trailers[lastKey]
+			values[len(values)-1] = strings.TrimSpace(values[len(values)-1] + " " + strings.TrimSpace(line))
+			continue
+		}
+		match := trailerLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			lastKey = ""
+			continue
+		}
+		if trailers == nil {
+			trailers = make(map[string][]string)
+		}
+		lastKey = match[1]
+		trailers[lastKey] = append(trailers[lastKey], strings.TrimSpace(match[2]))
+	}
+	return trailers
+}
+
+// ParseTrailersFromMessage extracts the trailers from the last paragraph of a commit message.
+// The paragraph is considered a trailer block only if it is not the subject and every line
+// in it is either a trailer or a continuation of one, which mirrors what git prints for %(trailers)
+// for the commit messages we generally see.
+func ParseTrailersFromMessage(message string) map[string][]string {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	lastParagraph := paragraphs[len(paragraphs)-1]
+	for i, line := range strings.Split(lastParagraph, "\n") {
+		isContinuation := i > 0 && len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+		if !isContinuation && !trailerLineRegex.MatchString(line) {
+			return nil
+		}
+	}
+	return parseTrailerLines(lastParagraph)
+}
+
+// trailer keys parsed into identities, matched case insensitively
+const (
+	TRAILER_SIGNED_OFF_BY  = "Signed-off-by"
+	TRAILER_CO_AUTHORED_BY = "Co-authored-by"
+	TRAILER_REVIEWED_BY    = "Reviewed-by"
+)
+
+// TrailerIdentity is the "name <email>" value of an attribution trailer, Email is empty when the value has no address
+type TrailerIdentity struct {
+	Name  string
+	Email string `json:",omitempty"`
+}
+
+// ParseTrailerIdentity splits a "name <email>" trailer value, values without an address are taken as the name
+func ParseTrailerIdentity(value string) *TrailerIdentity {
+	value = strings.TrimSpace(value)
+	start := strings.LastIndex(value, "<")
+	end := strings.LastIndex(value, ">")
+	if start < 0 || end < start {
+		return &TrailerIdentity{Name: value}
+	}
+	return &TrailerIdentity{
+		Name:  strings.TrimSpace(value[:start]),
+		Email: strings.TrimSpace(value[start+1 : end]),
+	}
+}
+
+// getTrailerIdentities returns the identities of all the values of the trailer key, in the order of the message
+func getTrailerIdentities(trailers map[string][]string, key string) []*TrailerIdentity {
+	var keys []string
+	for trailerKey := range trailers {
+		if strings.EqualFold(trailerKey, key) {
+			keys = append(keys, trailerKey)
+		}
+	}
+	// keys differing only in case are rare, sorted to keep the order stable
+	sort.Strings(keys)
+	var identities []*TrailerIdentity
+	for _, trailerKey := range keys {
+		for _, value := range trailers[trailerKey] {
+			if len(strings.TrimSpace(value)) == 0 {
+				continue
+			}
+			identities = append(identities, ParseTrailerIdentity(value))
+		}
+	}
+	return identities
+}
+
+// withTrailerIdentities sets the sign-off, co-author and reviewer fields of the commit from its trailers
+func (commit GitCommitBase) withTrailerIdentities() GitCommitBase {
+	commit.SignedOffBy = getTrailerIdentities(commit.Trailers, TRAILER_SIGNED_OFF_BY)
+	commit.CoAuthoredBy = getTrailerIdentities(commit.Trailers, TRAILER_CO_AUTHORED_BY)
+	commit.ReviewedBy = getTrailerIdentities(commit.Trailers, TRAILER_REVIEWED_BY)
+	return commit
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseTrailersFromMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    map[string][]string
+	}{
+		{
+			name:    "subject only",
+			message: "Fix: handle empty branch",
+			want:    nil,
+		},
+		{
+			name:    "body without trailers",
+			message: "Fix handling of empty branch\n\nThis changes the way we look at refs.",
+			want:    nil,
+		},
+		{
+			name: "repeated keys keep order",
+			message: "Add trailers\n\nSome body\n\n" +
+				"Signed-off-by: A <a@devtron.ai>\nJira: ABC-123\nSigned-off-by: B <b@devtron.ai>\n",
+			want: map[string][]string{
+				"Signed-off-by": {"A <a@devtron.ai>", "B <b@devtron.ai>"},
+				"Jira":          {"ABC-123"},
+			},
+		},
+		{
+			name:    "folded value is joined",
+			message: "Add trailers\n\nCo-authored-by: A\n  <a@devtron.ai>",
+			want: map[string][]string{
+				"Co-authored-by": {"A <a@devtron.ai>"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseTrailersFromMessage(tt.message))
+		})
+	}
+}
+
+func TestParseFormattedLogOutputWithTrailers(t *testing.T) {
+	out := "{" + _dl_ + "commit" + _dl_ + ":" + _dl_ + "abc" + _dl_ + "," +
+		_dl_ + "subject" + _dl_ + ":" + _dl_ + "subject" + _dl_ + "," +
+		_dl_ + "body" + _dl_ + ":" + _dl_ + "Jira: ABC-1\nJira: ABC-2" + _dl_ + "," +
+		_dl_ + "trailers" + _dl_ + ":" + _dl_ + "Jira: ABC-1\nJira: ABC-2\n" + _dl_ + "},"
+
+	commits, err := parseFormattedLogOutput(out)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(commits))
+	assert.Equal(t, map[string][]string{"Jira": {"ABC-1", "ABC-2"}}, parseTrailerLines(commits[0].Trailers))
+	assert.Nil(t, parseTrailerLines(""))
+}
+
+func TestParseTrailerIdentity(t *testing.T) {
+	assert.Equal(t, &TrailerIdentity{Name: "A B", Email: "ab@devtron.ai"}, ParseTrailerIdentity(" A B <ab@devtron.ai> "))
+	assert.Equal(t, &TrailerIdentity{Name: "release-bot"}, ParseTrailerIdentity("release-bot"))
+}
+
+func TestGitCommitBase_withTrailerIdentities(t *testing.T) {
+	message := "Add trailers\n\nSigned-off-by: A <a@devtron.ai>\nsigned-off-by: B <b@devtron.ai>\n" +
+		"Co-authored-by: C <c@devtron.ai>\nReviewed-by: D"
+	commit := GitCommitBase{Message: message, Trailers: ParseTrailersFromMessage(message)}.withTrailerIdentities()
+	assert.Equal(t, []*TrailerIdentity{{Name: "A", Email: "a@devtron.ai"}, {Name: "B", Email: "b@devtron.ai"}}, commit.SignedOffBy)
+	assert.Equal(t, []*TrailerIdentity{{Name: "C", Email: "c@devtron.ai"}}, commit.CoAuthoredBy)
+	assert.Equal(t, []*TrailerIdentity{{Name: "D"}}, commit.ReviewedBy)
+
+	commit = GitCommitBase{Message: "no trailers"}.withTrailerIdentities()
+	assert.Nil(t, commit.SignedOffBy)
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"golang.org/x/mod/semver"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	VERSION_BUMP_MAJOR = "major"
+	VERSION_BUMP_MINOR = "minor"
+	VERSION_BUMP_PATCH = "patch"
+	VERSION_BUMP_NONE  = "none"
+)
+
+var (
+	conventionalCommitRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	breakingChangeRegex     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// ConventionalCommit is the type, scope and description parsed from a commit subject following the conventional
+// commits spec, Breaking is set by a ! after the type or scope or by a BREAKING CHANGE footer
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// ParseConventionalCommit parses the subject of the commit message, nil is returned when the subject does not follow
+// the spec or its type is not one of the known types (feat, fix, chore...)
+func ParseConventionalCommit(message string) *ConventionalCommit {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	match := conventionalCommitRegex.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil || !isConventionalCommitType(strings.ToLower(match[1])) {
+		return nil
+	}
+	return &ConventionalCommit{
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Description: match[4],
+		Breaking:    len(match[3]) > 0 || breakingChangeRegex.MatchString(body),
+	}
+}
+
+func isConventionalCommitType(commitType string) bool {
+	for _, typeTitle := range changeLogTypeTitles {
+		if typeTitle.Type == commitType && commitType != CHANGE_LOG_TYPE_OTHER {
+			return true
+		}
+	}
+	return false
+}
+
+// withConventionalCommit sets the conventional commit fields of the commit parsed from its message
+func (commit GitCommitBase) withConventionalCommit() GitCommitBase {
+	if conventionalCommit := ParseConventionalCommit(commit.Message); conventionalCommit != nil {
+		commit.CommitType = conventionalCommit.Type
+		commit.CommitScope = conventionalCommit.Scope
+		commit.BreakingChange = conventionalCommit.Breaking
+	}
+	return commit
+}
+
+// GetVersionBump returns the semver bump the commits of the change log call for, major for breaking changes, minor for
+// features and patch for anything else
+func GetVersionBump(changeLog *ChangeLog) string {
+	bump := VERSION_BUMP_NONE
+	for _, group := range changeLog.Groups {
+		for _, commit := range group.Commits {
+			switch {
+			case commit.Breaking:
+				return VERSION_BUMP_MAJOR
+			case commit.Type == "feat":
+				bump = VERSION_BUMP_MINOR
+			case bump == VERSION_BUMP_NONE:
+				bump = VERSION_BUMP_PATCH
+			}
+		}
+	}
+	return bump
+}
+
+// BumpVersion applies the bump to the semver version, the v prefix is kept as given and pre-release or build
+// metadata is dropped
+func BumpVersion(version string, bump string) (string, error) {
+	canonical := semver.Canonical(toSemver(version))
+	if len(canonical) == 0 {
+		return "", fmt.Errorf("invalid semver version %q", version)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(canonical, "v"), ".", 3)
+	patch, _, _ := strings.Cut(parts[2], "-")
+	var numbers [3]int
+	for i, part := range []string{parts[0], parts[1], patch} {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return "", fmt.Errorf("invalid semver version %q", version)
+		}
+		numbers[i] = number
+	}
+	switch bump {
+	case VERSION_BUMP_MAJOR:
+		numbers = [3]int{numbers[0] + 1, 0, 0}
+	case VERSION_BUMP_MINOR:
+		numbers = [3]int{numbers[0], numbers[1] + 1, 0}
+	case VERSION_BUMP_PATCH:
+		numbers[2]++
+	case VERSION_BUMP_NONE:
+	default:
+		return "", fmt.Errorf("unknown version bump %q", bump)
+	}
+	next := fmt.Sprintf("%d.%d.%d", numbers[0], numbers[1], numbers[2])
+	if strings.HasPrefix(version, "v") {
+		next = "v" + next
+	}
+	return next, nil
+}
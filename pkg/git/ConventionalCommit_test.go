@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseConventionalCommitMessage(t *testing.T) {
+	assert.Equal(t, &ConventionalCommit{Type: "feat", Scope: "api", Description: "add version bump", Breaking: false},
+		ParseConventionalCommit("feat(api): add version bump\n\nsuggests the next version"))
+	assert.Equal(t, &ConventionalCommit{Type: "fix", Description: "drop v1 routes", Breaking: true},
+		ParseConventionalCommit("Fix: drop v1 routes\n\nBREAKING CHANGE: v1 routes are removed"))
+	assert.Nil(t, ParseConventionalCommit("Merge branch 'main' into feature"))
+	assert.Nil(t, ParseConventionalCommit("wip: not a known type"))
+
+	commit := GitCommitBase{Message: "refactor(git)!: rename managers"}.withConventionalCommit()
+	assert.Equal(t, "refactor", commit.CommitType)
+	assert.Equal(t, "git", commit.CommitScope)
+	assert.True(t, commit.BreakingChange)
+}
+
+func TestGetVersionBump(t *testing.T) {
+	changeLog := &ChangeLog{}
+	assert.Equal(t, VERSION_BUMP_NONE, GetVersionBump(changeLog))
+	changeLog.Groups = []*ChangeLogGroup{{Type: CHANGE_LOG_TYPE_OTHER, Commits: []*ChangeLogCommit{{Type: CHANGE_LOG_TYPE_OTHER}}}}
+	assert.Equal(t, VERSION_BUMP_PATCH, GetVersionBump(changeLog))
+	changeLog.Groups = append(changeLog.Groups, &ChangeLogGroup{Type: "feat", Commits: []*ChangeLogCommit{{Type: "feat"}}})
+	assert.Equal(t, VERSION_BUMP_MINOR, GetVersionBump(changeLog))
+	changeLog.Groups[0].Commits[0].Breaking = true
+	assert.Equal(t, VERSION_BUMP_MAJOR, GetVersionBump(changeLog))
+}
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		bump    string
+		want    string
+	}{
+		{"v1.2.3", VERSION_BUMP_MAJOR, "v2.0.0"},
+		{"1.2.3", VERSION_BUMP_MINOR, "1.3.0"},
+		{"v1.2.3-rc.1+build.5", VERSION_BUMP_PATCH, "v1.2.4"},
+		{"v1.2", VERSION_BUMP_NONE, "v1.2.0"},
+	}
+	for _, tt := range tests {
+		got, err := BumpVersion(tt.version, tt.bump)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got, tt.version)
+	}
+	_, err := BumpVersion("latest", VERSION_BUMP_PATCH)
+	assert.NotNil(t, err)
+	_, err = BumpVersion("v1.0.0", "huge")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,460 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CREDENTIAL_PROVIDER_GIT_PROVIDER uses the credentials stored with the git provider of the material
+	CREDENTIAL_PROVIDER_GIT_PROVIDER = ""
+	// CREDENTIAL_PROVIDER_ENV reads GIT_CREDENTIAL_<REF>_USERNAME, _PASSWORD and _SSH_PRIVATE_KEY
+	CREDENTIAL_PROVIDER_ENV = "env"
+	// CREDENTIAL_PROVIDER_FILE reads the files of the directory <CREDENTIALS_DIR>/<ref>, like a mounted secret
+	CREDENTIAL_PROVIDER_FILE = "file"
+	// CREDENTIAL_PROVIDER_KUBERNETES reads the kubernetes secret <namespace>/<name>, of the namespace of git-sensor when
+	// the ref has no namespace. Only the namespace of git-sensor and the ones of CREDENTIAL_K8S_NAMESPACES can be read
+	CREDENTIAL_PROVIDER_KUBERNETES = "kubernetes"
+	// CREDENTIAL_PROVIDER_VAULT reads the secret at the ref path of the VAULT_KV_MOUNT kv v2 engine
+	CREDENTIAL_PROVIDER_VAULT = "vault"
+
+	// keys of the credentials in the secrets, the ones of the kubernetes basic-auth and ssh-auth secret types
+	CREDENTIAL_KEY_USERNAME        = "username"
+	CREDENTIAL_KEY_PASSWORD        = "password"
+	CREDENTIAL_KEY_SSH_PRIVATE_KEY = "ssh-privatekey"
+
+	CREDENTIAL_ENV_PREFIX = "GIT_CREDENTIAL_"
+
+	KUBERNETES_SERVICE_ACCOUNT_DIR = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+	credentialProviderTimeout = 10 * time.Second
+)
+
+var ErrInvalidCredentialRef = errors.New("invalid credential ref")
+
+var credentialEnvRefRegex = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Credentials are the secrets of a material read from its credential provider. They are only kept in memory, the
+// username and password are passed to git through the askpass env of each command and the ssh key is written to a
+// file of the material as ssh reads keys from files only
+type Credentials struct {
+	UserName      string
+	Password      string
+	SshPrivateKey string
+}
+
+// String keeps the secrets out of the logs
+func (credentials *Credentials) String() string {
+	return fmt.Sprintf("{UserName:%s Password:%t SshPrivateKey:%t}", credentials.UserName, len(credentials.Password) > 0, len(credentials.SshPrivateKey) > 0)
+}
+
+func newCredentials(secret map[string]string) (*Credentials, error) {
+	credentials := &Credentials{
+		UserName:      secret[CREDENTIAL_KEY_USERNAME],
+		Password:      secret[CREDENTIAL_KEY_PASSWORD],
+		SshPrivateKey: secret[CREDENTIAL_KEY_SSH_PRIVATE_KEY],
+	}
+	if len(credentials.Password) == 0 && len(credentials.SshPrivateKey) == 0 {
+		return nil, fmt.Errorf("secret has neither %s nor %s", CREDENTIAL_KEY_PASSWORD, CREDENTIAL_KEY_SSH_PRIVATE_KEY)
+	}
+	return credentials, nil
+}
+
+// CredentialProvider reads the credentials of materials from a secret backend
+type CredentialProvider interface {
+	// GetCredentials returns the credentials of the secret addressed by ref
+	GetCredentials(ctx context.Context, ref string) (*Credentials, error)
+}
+
+// CredentialResolver gives the credentials of the materials from the credential provider selected on each of them, so
+// that the materials of different teams sharing a git provider can use their own secrets
+type CredentialResolver interface {
+	// ResolveGitProvider returns a copy of the git provider with the credentials of the credential provider of the
+	// material, or the git provider itself when the material uses the credentials stored with it. The copy must not be
+	// saved
+	ResolveGitProvider(ctx context.Context, material *sql.GitMaterial, gitProvider *sql.GitProvider) (*sql.GitProvider, error)
+}
+
+type cachedCredentials struct {
+	credentials *Credentials
+	fetchedOn   time.Time
+}
+
+type CredentialResolverImpl struct {
+	logger        *zap.SugaredLogger
+	configuration *internals.Configuration
+	providers     map[string]CredentialProvider
+	// cache keeps the credentials per provider and ref for CREDENTIAL_CACHE_SEC, in memory only
+	cache map[string]*cachedCredentials
+	mutex *sync.Mutex
+}
+
+func NewCredentialResolverImpl(logger *zap.SugaredLogger, configuration *internals.Configuration) *CredentialResolverImpl {
+	return &CredentialResolverImpl{
+		logger:        logger,
+		configuration: configuration,
+		providers: map[string]CredentialProvider{
+			CREDENTIAL_PROVIDER_ENV:        &envCredentialProvider{},
+			CREDENTIAL_PROVIDER_FILE:       &fileCredentialProvider{dir: configuration.CredentialsDir},
+			CREDENTIAL_PROVIDER_KUBERNETES: newKubernetesCredentialProvider(configuration),
+			CREDENTIAL_PROVIDER_VAULT:      newVaultCredentialProvider(configuration),
+		},
+		cache: make(map[string]*cachedCredentials),
+		mutex: &sync.Mutex{},
+	}
+}
+
+// ValidateCredentialProvider checks the credential provider and ref selected on a material
+func ValidateCredentialProvider(material *sql.GitMaterial) error {
+	switch material.CredentialProvider {
+	case CREDENTIAL_PROVIDER_GIT_PROVIDER:
+		return nil
+	case CREDENTIAL_PROVIDER_ENV, CREDENTIAL_PROVIDER_FILE, CREDENTIAL_PROVIDER_KUBERNETES, CREDENTIAL_PROVIDER_VAULT:
+		if len(strings.TrimSpace(material.CredentialRef)) == 0 {
+			return fmt.Errorf("%w: credential ref is needed with the %s credential provider", ErrInvalidCredentialRef, material.CredentialProvider)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported credential provider %q", material.CredentialProvider)
+	}
+}
+
+// GetCredentialScope returns the owner of the ssh key file of the material, the materials using the credentials of
+// their git provider share the key file of the git provider
+func GetCredentialScope(material *sql.GitMaterial) string {
+	if material.CredentialProvider == CREDENTIAL_PROVIDER_GIT_PROVIDER {
+		return ""
+	}
+	return "material-" + strconv.Itoa(material.Id)
+}
+
+func (impl *CredentialResolverImpl) ResolveGitProvider(ctx context.Context, material *sql.GitMaterial, gitProvider *sql.GitProvider) (*sql.GitProvider, error) {
+	if material.CredentialProvider == CREDENTIAL_PROVIDER_GIT_PROVIDER {
+		return gitProvider, nil
+	}
+	if err := ValidateCredentialProvider(material); err != nil {
+		return nil, err
+	}
+	credentials, err := impl.getCredentials(ctx, material.CredentialProvider, material.CredentialRef)
+	if err != nil {
+		impl.logger.Errorw("error in getting credentials of material", "gitMaterialId", material.Id, "credentialProvider", material.CredentialProvider, "credentialRef", material.CredentialRef, "err", err)
+		return nil, err
+	}
+	resolved := &sql.GitProvider{}
+	if gitProvider != nil {
+		*resolved = *gitProvider
+	}
+	resolved.UserName = credentials.UserName
+	resolved.Password = credentials.Password
+	resolved.AccessToken = credentials.Password
+	resolved.SshPrivateKey = credentials.SshPrivateKey
+	if len(credentials.SshPrivateKey) > 0 {
+		resolved.AuthMode = sql.AUTH_MODE_SSH
+	} else {
+		resolved.AuthMode = sql.AUTH_MODE_USERNAME_PASSWORD
+	}
+	return resolved, nil
+}
+
+func (impl *CredentialResolverImpl) getCredentials(ctx context.Context, providerName, ref string) (*Credentials, error) {
+	cacheKey := providerName + ":" + ref
+	impl.mutex.Lock()
+	cached, found := impl.cache[cacheKey]
+	impl.mutex.Unlock()
+	if found && time.Since(cached.fetchedOn) < time.Duration(impl.configuration.CredentialCacheSec)*time.Second {
+		return cached.credentials, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, credentialProviderTimeout)
+	defer cancel()
+	credentials, err := impl.providers[providerName].GetCredentials(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	impl.mutex.Lock()
+	impl.cache[cacheKey] = &cachedCredentials{credentials: credentials, fetchedOn: time.Now()}
+	impl.mutex.Unlock()
+	return credentials, nil
+}
+
+// envCredentialProvider reads the credentials from env variables prefixed with GIT_CREDENTIAL_, so that the other env
+// variables of git-sensor can't be read through a ref
+type envCredentialProvider struct{}
+
+func (impl *envCredentialProvider) GetCredentials(ctx context.Context, ref string) (*Credentials, error) {
+	prefix := CREDENTIAL_ENV_PREFIX + strings.Trim(credentialEnvRefRegex.ReplaceAllString(strings.ToUpper(ref), "_"), "_") + "_"
+	return newCredentials(map[string]string{
+		CREDENTIAL_KEY_USERNAME:        os.Getenv(prefix + "USERNAME"),
+		CREDENTIAL_KEY_PASSWORD:        os.Getenv(prefix + "PASSWORD"),
+		CREDENTIAL_KEY_SSH_PRIVATE_KEY: os.Getenv(prefix + "SSH_PRIVATE_KEY"),
+	})
+}
+
+// fileCredentialProvider reads the credentials from the files of a directory of CREDENTIALS_DIR, each key in its file
+type fileCredentialProvider struct {
+	dir string
+}
+
+func (impl *fileCredentialProvider) GetCredentials(ctx context.Context, ref string) (*Credentials, error) {
+	if len(impl.dir) == 0 {
+		return nil, errors.New("CREDENTIALS_DIR is not configured")
+	}
+	secretDir := filepath.Join(impl.dir, filepath.Clean("/"+ref))
+	if secretDir == filepath.Clean(impl.dir) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCredentialRef, ref)
+	}
+	secret := make(map[string]string)
+	for _, key := range []string{CREDENTIAL_KEY_USERNAME, CREDENTIAL_KEY_PASSWORD, CREDENTIAL_KEY_SSH_PRIVATE_KEY} {
+		content, err := os.ReadFile(filepath.Join(secretDir, key))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		secret[key] = string(content)
+	}
+	secret[CREDENTIAL_KEY_USERNAME] = strings.TrimSpace(secret[CREDENTIAL_KEY_USERNAME])
+	secret[CREDENTIAL_KEY_PASSWORD] = strings.TrimSpace(secret[CREDENTIAL_KEY_PASSWORD])
+	return newCredentials(secret)
+}
+
+// kubernetesCredentialProvider reads kubernetes secrets through the api server with the service account of git-sensor
+type kubernetesCredentialProvider struct {
+	apiUrl            string
+	serviceAccountDir string
+	// allowedNamespaces are the namespaces besides the one of git-sensor whose secrets the refs may address
+	allowedNamespaces []string
+}
+
+func newKubernetesCredentialProvider(configuration *internals.Configuration) *kubernetesCredentialProvider {
+	apiUrl := ""
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); len(host) > 0 && len(port) > 0 {
+		apiUrl = "https://" + net.JoinHostPort(host, port)
+	}
+	var allowedNamespaces []string
+	for _, namespace := range strings.Split(configuration.CredentialK8sNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); len(namespace) > 0 {
+			allowedNamespaces = append(allowedNamespaces, namespace)
+		}
+	}
+	return &kubernetesCredentialProvider{apiUrl: apiUrl, serviceAccountDir: KUBERNETES_SERVICE_ACCOUNT_DIR, allowedNamespaces: allowedNamespaces}
+}
+
+// isAllowedNamespace tells if the secrets of the namespace can be read, the service account of git-sensor may see the
+// secrets of namespaces of other teams which the refs of the materials must not reach
+func (impl *kubernetesCredentialProvider) isAllowedNamespace(namespace, ownNamespace string) bool {
+	return namespace == ownNamespace || slices.Contains(impl.allowedNamespaces, namespace)
+}
+
+func (impl *kubernetesCredentialProvider) GetCredentials(ctx context.Context, ref string) (*Credentials, error) {
+	if len(impl.apiUrl) == 0 {
+		return nil, errors.New("kubernetes credential provider is only available in cluster")
+	}
+	content, err := os.ReadFile(path.Join(impl.serviceAccountDir, "namespace"))
+	if err != nil {
+		return nil, err
+	}
+	ownNamespace := strings.TrimSpace(string(content))
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		namespace, name = ownNamespace, ref
+	}
+	if len(namespace) == 0 || len(name) == 0 || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCredentialRef, ref)
+	}
+	if !impl.isAllowedNamespace(namespace, ownNamespace) {
+		return nil, fmt.Errorf("%w: namespace %q is not allowed, see CREDENTIAL_K8S_NAMESPACES", ErrInvalidCredentialRef, namespace)
+	}
+	// the token is read for each call as it is rotated by the kubelet
+	token, err := os.ReadFile(path.Join(impl.serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	client, err := newCredentialHttpClient(path.Join(impl.serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s", impl.apiUrl, url.PathEscape(namespace), url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	body, err := doCredentialRequest(client, request)
+	if err != nil {
+		return nil, err
+	}
+	k8sSecret := &struct {
+		// values are base64 encoded, which json decodes into []byte
+		Data map[string][]byte `json:"data"`
+	}{}
+	if err = json.Unmarshal(body, k8sSecret); err != nil {
+		return nil, err
+	}
+	secret := make(map[string]string)
+	for key, value := range k8sSecret.Data {
+		secret[key] = string(value)
+	}
+	return newCredentials(secret)
+}
+
+// vaultCredentialProvider reads secrets of a kv v2 engine of vault, authenticating with VAULT_TOKEN or else with the
+// service account of git-sensor through the kubernetes auth method of VAULT_K8S_ROLE
+type vaultCredentialProvider struct {
+	addr              string
+	token             string
+	kubernetesRole    string
+	kvMount           string
+	serviceAccountDir string
+}
+
+func newVaultCredentialProvider(configuration *internals.Configuration) *vaultCredentialProvider {
+	return &vaultCredentialProvider{
+		addr:              strings.TrimSuffix(configuration.VaultAddr, "/"),
+		token:             configuration.VaultToken,
+		kubernetesRole:    configuration.VaultKubernetesRole,
+		kvMount:           strings.Trim(configuration.VaultKvMount, "/"),
+		serviceAccountDir: KUBERNETES_SERVICE_ACCOUNT_DIR,
+	}
+}
+
+func (impl *vaultCredentialProvider) GetCredentials(ctx context.Context, ref string) (*Credentials, error) {
+	if len(impl.addr) == 0 {
+		return nil, errors.New("VAULT_ADDR is not configured")
+	}
+	secretPath := strings.Trim(path.Clean("/"+ref), "/")
+	if len(secretPath) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCredentialRef, ref)
+	}
+	client, err := newCredentialHttpClient("")
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+	token, err := impl.getToken(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", impl.addr, impl.kvMount, secretPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", token)
+	body, err := doCredentialRequest(client, request)
+	if err != nil {
+		return nil, err
+	}
+	vaultSecret := &struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}{}
+	if err = json.Unmarshal(body, vaultSecret); err != nil {
+		return nil, err
+	}
+	return newCredentials(vaultSecret.Data.Data)
+}
+
+func (impl *vaultCredentialProvider) getToken(ctx context.Context, client *http.Client) (string, error) {
+	if len(impl.token) > 0 {
+		return impl.token, nil
+	}
+	if len(impl.kubernetesRole) == 0 {
+		return "", errors.New("neither VAULT_TOKEN nor VAULT_K8S_ROLE is configured")
+	}
+	jwt, err := os.ReadFile(path.Join(impl.serviceAccountDir, "token"))
+	if err != nil {
+		return "", err
+	}
+	loginBody, err := json.Marshal(map[string]string{"role": impl.kubernetesRole, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, impl.addr+"/v1/auth/kubernetes/login", bytes.NewReader(loginBody))
+	if err != nil {
+		return "", err
+	}
+	body, err := doCredentialRequest(client, request)
+	if err != nil {
+		return "", err
+	}
+	login := &struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}{}
+	if err = json.Unmarshal(body, login); err != nil {
+		return "", err
+	}
+	return login.Auth.ClientToken, nil
+}
+
+// newCredentialHttpClient returns a client trusting the ca cert file in addition to the system ones when it is given
+func newCredentialHttpClient(caCertFile string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(caCertFile) > 0 {
+		caCert, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+		certPool.AppendCertsFromPEM(caCert)
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+	}
+	return &http.Client{Transport: transport, Timeout: credentialProviderTimeout}, nil
+}
+
+// doCredentialRequest returns the body of a successful response. The body of a failed one is not part of the error as
+// it may echo the request
+func doCredentialRequest(client *http.Client, request *http.Request) ([]byte, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned %d", request.Method, request.URL.Path, response.StatusCode)
+	}
+	return body, nil
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type countingCredentialProvider struct {
+	calls int
+}
+
+func (impl *countingCredentialProvider) GetCredentials(ctx context.Context, ref string) (*Credentials, error) {
+	impl.calls++
+	return &Credentials{UserName: "user-" + ref, Password: "secret"}, nil
+}
+
+func TestValidateCredentialProvider(t *testing.T) {
+	assert.Nil(t, ValidateCredentialProvider(&sql.GitMaterial{}))
+	assert.Nil(t, ValidateCredentialProvider(&sql.GitMaterial{CredentialProvider: CREDENTIAL_PROVIDER_VAULT, CredentialRef: "team-a/git"}))
+	err := ValidateCredentialProvider(&sql.GitMaterial{CredentialProvider: CREDENTIAL_PROVIDER_ENV, CredentialRef: " "})
+	assert.True(t, errors.Is(err, ErrInvalidCredentialRef))
+	assert.NotNil(t, ValidateCredentialProvider(&sql.GitMaterial{CredentialProvider: "aws", CredentialRef: "git"}))
+}
+
+func TestGetCredentialScope(t *testing.T) {
+	assert.Equal(t, "", GetCredentialScope(&sql.GitMaterial{Id: 4}))
+	assert.Equal(t, "material-4", GetCredentialScope(&sql.GitMaterial{Id: 4, CredentialProvider: CREDENTIAL_PROVIDER_FILE}))
+}
+
+func TestCredentials_String(t *testing.T) {
+	credentials := &Credentials{UserName: "devtron", Password: "secret", SshPrivateKey: "private-key"}
+	assert.Equal(t, "{UserName:devtron Password:true SshPrivateKey:true}", fmt.Sprint(credentials))
+}
+
+func TestCredentialResolverImpl_ResolveGitProvider(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewCredentialResolverImpl(logger, &internals.Configuration{CredentialCacheSec: 60})
+	provider := &countingCredentialProvider{}
+	impl.providers[CREDENTIAL_PROVIDER_ENV] = provider
+	gitProvider := &sql.GitProvider{Id: 1, Name: "shared", UserName: "admin", Password: "admin-secret", AuthMode: sql.AUTH_MODE_ACCESS_TOKEN}
+
+	resolved, err := impl.ResolveGitProvider(context.Background(), &sql.GitMaterial{Id: 2}, gitProvider)
+	assert.Nil(t, err)
+	assert.Same(t, gitProvider, resolved)
+
+	material := &sql.GitMaterial{Id: 3, CredentialProvider: CREDENTIAL_PROVIDER_ENV, CredentialRef: "team-a"}
+	resolved, err = impl.ResolveGitProvider(context.Background(), material, gitProvider)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-team-a", resolved.UserName)
+	assert.Equal(t, "secret", resolved.Password)
+	assert.Equal(t, sql.AUTH_MODE_USERNAME_PASSWORD, resolved.AuthMode)
+	assert.Equal(t, "shared", resolved.Name)
+	// the git provider shared with the other materials keeps its own credentials
+	assert.Equal(t, "admin", gitProvider.UserName)
+	assert.Equal(t, sql.AUTH_MODE_ACCESS_TOKEN, gitProvider.AuthMode)
+
+	_, err = impl.ResolveGitProvider(context.Background(), material, gitProvider)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestEnvCredentialProvider_GetCredentials(t *testing.T) {
+	t.Setenv("GIT_CREDENTIAL_TEAM_A_GIT_USERNAME", "team-a")
+	t.Setenv("GIT_CREDENTIAL_TEAM_A_GIT_PASSWORD", "secret")
+	credentials, err := (&envCredentialProvider{}).GetCredentials(context.Background(), "team-a/git")
+	assert.Nil(t, err)
+	assert.Equal(t, &Credentials{UserName: "team-a", Password: "secret"}, credentials)
+
+	_, err = (&envCredentialProvider{}).GetCredentials(context.Background(), "team-b")
+	assert.NotNil(t, err)
+}
+
+func TestFileCredentialProvider_GetCredentials(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "team-a"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "team-a", CREDENTIAL_KEY_SSH_PRIVATE_KEY), []byte("private-key\n"), 0600))
+	provider := &fileCredentialProvider{dir: dir}
+
+	credentials, err := provider.GetCredentials(context.Background(), "team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, &Credentials{SshPrivateKey: "private-key\n"}, credentials)
+
+	// refs are confined to the credentials dir
+	credentials, err = provider.GetCredentials(context.Background(), "../../team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "private-key\n", credentials.SshPrivateKey)
+	_, err = provider.GetCredentials(context.Background(), "..")
+	assert.True(t, errors.Is(err, ErrInvalidCredentialRef))
+
+	_, err = (&fileCredentialProvider{}).GetCredentials(context.Background(), "team-a")
+	assert.NotNil(t, err)
+}
+
+func TestKubernetesCredentialProvider_GetCredentials(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sa-token", r.Header.Get("Authorization"))
+		if r.URL.Path != "/api/v1/namespaces/devtroncd/secrets/team-a" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// dXNlcg== and c2VjcmV0 are the base64 of user and secret
+		fmt.Fprint(w, `{"data": {"username": "dXNlcg==", "password": "c2VjcmV0"}}`)
+	}))
+	defer server.Close()
+	saDir := t.TempDir()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, os.WriteFile(filepath.Join(saDir, "ca.crt"), caCert, 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(saDir, "token"), []byte("sa-token\n"), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(saDir, "namespace"), []byte("devtroncd"), 0600))
+	provider := &kubernetesCredentialProvider{apiUrl: server.URL, serviceAccountDir: saDir}
+
+	credentials, err := provider.GetCredentials(context.Background(), "team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, &Credentials{UserName: "user", Password: "secret"}, credentials)
+	credentials, err = provider.GetCredentials(context.Background(), "devtroncd/team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", credentials.Password)
+
+	_, err = provider.GetCredentials(context.Background(), "team-b")
+	assert.NotNil(t, err)
+	// secrets of other namespaces are read only when the namespace is allowed
+	_, err = provider.GetCredentials(context.Background(), "kube-system/team-a")
+	assert.True(t, errors.Is(err, ErrInvalidCredentialRef))
+	provider.allowedNamespaces = []string{"kube-system"}
+	_, err = provider.GetCredentials(context.Background(), "kube-system/team-a")
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidCredentialRef))
+	_, err = provider.GetCredentials(context.Background(), "devtroncd/team-a/extra")
+	assert.True(t, errors.Is(err, ErrInvalidCredentialRef))
+}
+
+func TestVaultCredentialProvider_GetCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			login := map[string]string{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&login))
+			assert.Equal(t, map[string]string{"role": "git-sensor", "jwt": "sa-token"}, login)
+			fmt.Fprint(w, `{"auth": {"client_token": "login-token"}}`)
+		case "/v1/secret/data/teams/team-a":
+			if token := r.Header.Get("X-Vault-Token"); token != "root-token" && token != "login-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"data": {"data": {"username": "user", "password": "secret"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newVaultCredentialProvider(&internals.Configuration{VaultAddr: server.URL + "/", VaultToken: "root-token", VaultKvMount: "/secret/"})
+	credentials, err := provider.GetCredentials(context.Background(), "/teams/team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, &Credentials{UserName: "user", Password: "secret"}, credentials)
+
+	saDir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(saDir, "token"), []byte("sa-token"), 0600))
+	provider = newVaultCredentialProvider(&internals.Configuration{VaultAddr: server.URL, VaultKubernetesRole: "git-sensor", VaultKvMount: "secret"})
+	provider.serviceAccountDir = saDir
+	credentials, err = provider.GetCredentials(context.Background(), "teams/team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", credentials.Password)
+
+	_, err = provider.GetCredentials(context.Background(), "teams/team-b")
+	assert.NotNil(t, err)
+	_, err = newVaultCredentialProvider(&internals.Configuration{VaultAddr: server.URL}).GetCredentials(context.Background(), "teams/team-a")
+	assert.NotNil(t, err)
+}
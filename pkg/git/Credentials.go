@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GetRepoIdentity returns the host and path of a https or ssh url without the user info, port and .git suffix, so that
+// the urls of a repo over either protocol or with different credentials compare equal
+func GetRepoIdentity(repoUrl string) string {
+	repoUrl = strings.TrimSpace(repoUrl)
+	var host, repoPath string
+	if parsedUrl, err := url.Parse(repoUrl); err == nil && len(parsedUrl.Host) > 0 {
+		host, repoPath = parsedUrl.Hostname(), parsedUrl.Path
+	} else if at := strings.Index(repoUrl, "@"); at >= 0 && strings.Contains(repoUrl[at:], ":") {
+		// scp like ssh url, git@github.com:org/repo.git
+		host, repoPath, _ = strings.Cut(repoUrl[at+1:], ":")
+	} else {
+		return repoUrl
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	return strings.ToLower(host) + "/" + repoPath
+}
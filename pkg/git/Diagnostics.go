@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaterialDiagnostics tells why a material may not be picking up commits, the remote is checked live and the rest is
+// from the last poll of the material
+type MaterialDiagnostics struct {
+	GitMaterialId        int                  `json:"gitMaterialId"`
+	Url                  string               `json:"url"`
+	ApiMode              bool                 `json:"apiMode"`
+	RemoteReachable      bool                 `json:"remoteReachable"`
+	RemoteError          string               `json:"remoteError,omitempty"`
+	RemoteErrorCode      string               `json:"remoteErrorCode,omitempty"`
+	RemoteErrorHint      string               `json:"remoteErrorHint,omitempty"`
+	Branches             []*BranchDiagnostics `json:"branches"`
+	CheckedOut           bool                 `json:"checkedOut"`
+	CheckoutLocation     string               `json:"checkoutLocation"`
+	CheckoutSizeInBytes  int64                `json:"checkoutSizeInBytes"`
+	LastFetchTime        time.Time            `json:"lastFetchTime"`
+	LastSuccessFetchTime time.Time            `json:"lastSuccessFetchTime"`
+	LastFetchRemote      string               `json:"lastFetchRemote,omitempty"`
+	LastFetchErrorCount  int                  `json:"lastFetchErrorCount"`
+	LastFetchError       string               `json:"lastFetchError,omitempty"`
+	LastFetchErrorCode   string               `json:"lastFetchErrorCode,omitempty"`
+	LastFetchErrorHint   string               `json:"lastFetchErrorHint,omitempty"`
+	GitBinaryPath        string               `json:"gitBinaryPath"`
+	GitVersion           string               `json:"gitVersion,omitempty"`
+	DisabledGitFeatures  []string             `json:"disabledGitFeatures,omitempty"` // not used as the git version is too old
+}
+
+// BranchDiagnostics compares the branch of a pipeline material at the remote with the last commit seen of it
+type BranchDiagnostics struct {
+	PipelineMaterialId int    `json:"pipelineMaterialId"`
+	Branch             string `json:"branch"`
+	Exists             bool   `json:"exists"`
+	RemoteCommit       string `json:"remoteCommit,omitempty"`
+	LastSeenCommit     string `json:"lastSeenCommit,omitempty"`
+}
+
+// SetRemoteError records the failure of reaching the remote with its classification
+func (diagnostics *MaterialDiagnostics) SetRemoteError(err error) {
+	diagnostics.RemoteReachable = err == nil
+	if err == nil {
+		return
+	}
+	diagnostics.RemoteError = err.Error()
+	diagnostics.RemoteErrorCode = GetGitErrorCode(err)
+	diagnostics.RemoteErrorHint = GetGitErrorUserMessage(err)
+}
+
+// SetLastFetchError classifies the error saved on the material by its last poll
+func (diagnostics *MaterialDiagnostics) SetLastFetchError(message string) {
+	err := ParseGitErrorMessage(message)
+	if err == nil {
+		return
+	}
+	diagnostics.LastFetchError = message
+	diagnostics.LastFetchErrorCode = GetGitErrorCode(err)
+	diagnostics.LastFetchErrorHint = GetGitErrorUserMessage(err)
+}
+
+// LsRemote lists the refs of the remote matching the given refs, all of them when none are given. The remote is either
+// a url or a remote of the repo at rootDir, whose ssh and proxy config is used then
+func (impl *GitManagerBaseImpl) LsRemote(gitCtx GitContext, rootDir, remote string, refs []string) (map[string]string, error) {
+	if len(remote) == 0 || strings.HasPrefix(remote, "-") {
+		return nil, fmt.Errorf("invalid remote %q", remote)
+	}
+	var cmdArgs []string
+	if len(rootDir) > 0 {
+		cmdArgs = append(cmdArgs, "-C", rootDir)
+	}
+	cmdArgs = append(append(cmdArgs, "ls-remote", remote), refs...)
+	impl.logger.Debugw("git", "-C", rootDir, "ls-remote", remote, refs)
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in listing remote refs", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	remoteRefs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		// <hash>\t<ref>
+		hash, ref, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if found {
+			remoteRefs[ref] = hash
+		}
+	}
+	return remoteRefs, nil
+}
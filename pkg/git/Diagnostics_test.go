@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryManager_LsRemote(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	remoteDir, workDir := setupTestRemote(t)
+	head := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	gitCtx := BuildGitContext(context.Background())
+
+	refs, err := impl.LsRemote(gitCtx, "", remoteDir, []string{"refs/heads/main", "refs/heads/missing"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"refs/heads/main": head}, refs)
+
+	refs, err = impl.LsRemote(gitCtx, workDir, "origin", []string{"HEAD"})
+	assert.Nil(t, err)
+	assert.Equal(t, head, refs["HEAD"])
+
+	_, err = impl.LsRemote(gitCtx, "", filepath.Join(t.TempDir(), "missing.git"), nil)
+	assert.NotNil(t, err)
+	_, err = impl.LsRemote(gitCtx, "", "--upload-pack=touch", nil)
+	assert.NotNil(t, err)
+
+	diagnostics := &MaterialDiagnostics{}
+	diagnostics.SetRemoteError(err)
+	assert.False(t, diagnostics.RemoteReachable)
+	diagnostics.SetLastFetchError("")
+	assert.Empty(t, diagnostics.LastFetchErrorCode)
+}
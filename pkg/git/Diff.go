@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffHunk is a hunk of the unified diff of a file, each line keeps its ' ', '+', '-' or '\' prefix
+type DiffHunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Section  string   `json:"section,omitempty"` // function or heading after the @@ of the hunk header
+	Lines    []string `json:"lines"`
+}
+
+type FileDiff struct {
+	Path       string         `json:"path"`
+	OldPath    string         `json:"oldPath,omitempty"` // set for renamed files
+	ChangeType FileChangeType `json:"changeType"`
+	Binary     bool           `json:"binary,omitempty"` // binary files have no hunks
+	Addition   int            `json:"addition"`
+	Deletion   int            `json:"deletion"`
+	Hunks      []*DiffHunk    `json:"hunks"`
+}
+
+type CommitRangeDiff struct {
+	FromCommit string      `json:"fromCommit"`
+	ToCommit   string      `json:"toCommit"`
+	Files      []*FileDiff `json:"files"`
+	Truncated  bool        `json:"truncated"` // the diff exceeded DIFF_MAX_SIZE_IN_BYTES, the hunks after the limit are left out
+}
+
+// GetDiffBetweenCommits returns the unified diff between the two commits, limited to the given paths when set.
+// contextLines is the number of unchanged lines around the changes, the default of git when negative
+func (impl *GitManagerBaseImpl) GetDiffBetweenCommits(gitCtx GitContext, rootDir, fromCommit, toCommit string, paths []string, contextLines int) (*CommitRangeDiff, error) {
+	if len(fromCommit) == 0 || len(toCommit) == 0 || strings.HasPrefix(fromCommit, "-") || strings.HasPrefix(toCommit, "-") {
+		return nil, fmt.Errorf("invalid diff request, from: %q to: %q", fromCommit, toCommit)
+	}
+	cmdArgs := []string{"-C", rootDir, "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "--no-textconv", "--src-prefix=a/", "--dst-prefix=b/", "-M"}
+	if contextLines >= 0 {
+		cmdArgs = append(cmdArgs, "--unified="+strconv.Itoa(contextLines))
+	}
+	cmdArgs = append(cmdArgs, fromCommit, toCommit, "--")
+	for _, path := range paths {
+		if path = strings.Trim(path, "/"); len(path) > 0 {
+			cmdArgs = append(cmdArgs, path)
+		}
+	}
+	impl.prefetchDiffBlobs(gitCtx, rootDir, []string{fromCommit, toCommit}, paths)
+	impl.logger.Debugw("git", cmdArgs)
+	stdout, wait, err := impl.StreamCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in starting git diff", "rootDir", rootDir, "err", err)
+		return nil, err
+	}
+	files, truncated, parseErr := parseUnifiedDiff(stdout, impl.conf.DiffMaxSizeInBytes)
+	if truncated || parseErr != nil {
+		// git is stopped instead of reading the rest of the diff, its exit status is of no interest then
+		_ = stdout.Close()
+	}
+	_, err = wait()
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if err != nil && !truncated {
+		return nil, err
+	}
+	return &CommitRangeDiff{FromCommit: fromCommit, ToCommit: toCommit, Files: files, Truncated: truncated}, nil
+}
+
+// parseUnifiedDiff parses the output of git diff until maxSize bytes are read, 0 for no limit. Reports truncated when
+// the output is longer, the file being read at the limit is kept with the hunks read till then
+func parseUnifiedDiff(reader io.Reader, maxSize int64) (files []*FileDiff, truncated bool, err error) {
+	bufferedReader := bufio.NewReader(reader)
+	var file *FileDiff
+	var hunk *DiffHunk
+	var read int64
+	for {
+		line, readErr := bufferedReader.ReadString('\n')
+		if len(line) == 0 && readErr != nil {
+			if readErr == io.EOF {
+				return files, false, nil
+			}
+			return nil, false, readErr
+		}
+		read += int64(len(line))
+		if maxSize > 0 && read > maxSize {
+			return files, true, nil
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = &FileDiff{Path: parseDiffGitHeader(line), ChangeType: FILE_CHANGE_TYPE_MODIFIED}
+			hunk = nil
+			files = append(files, file)
+		case file == nil:
+			return nil, false, fmt.Errorf("unexpected git diff line %q", line)
+		case strings.HasPrefix(line, "@@ "):
+			hunk, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, false, err
+			}
+			file.Hunks = append(file.Hunks, hunk)
+		case hunk != nil:
+			// a line of the hunk, git prefixes every one of them so the headers of the file can't be confused with it
+			hunk.Lines = append(hunk.Lines, line)
+			if strings.HasPrefix(line, "+") {
+				file.Addition++
+			} else if strings.HasPrefix(line, "-") {
+				file.Deletion++
+			}
+		case strings.HasPrefix(line, "new file mode"):
+			file.ChangeType = FILE_CHANGE_TYPE_ADDED
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.ChangeType = FILE_CHANGE_TYPE_DELETED
+		case strings.HasPrefix(line, "rename from "):
+			file.ChangeType = FILE_CHANGE_TYPE_RENAMED
+			file.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			file.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++ b/"):
+			file.Path = strings.TrimSuffix(strings.TrimPrefix(line, "+++ b/"), "\t")
+		case strings.HasPrefix(line, "--- a/") && file.ChangeType == FILE_CHANGE_TYPE_DELETED:
+			file.Path = strings.TrimSuffix(strings.TrimPrefix(line, "--- a/"), "\t")
+		case strings.HasPrefix(line, "Binary files "):
+			file.Binary = true
+		}
+	}
+}
+
+// parseDiffGitHeader returns the path of a diff --git a/<path> b/<path> line, the header is ambiguous for paths with
+// spaces when the file is renamed, but then the rename lines which follow it carry the paths
+func parseDiffGitHeader(line string) string {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if len(paths)%2 == 0 {
+		return paths
+	}
+	return strings.TrimPrefix(paths[len(paths)/2+1:], "b/")
+}
+
+// parseHunkHeader parses @@ -<old start>[,<old lines>] +<new start>[,<new lines>] @@[ <section>], the line counts are 1 when left out
+func parseHunkHeader(line string) (*DiffHunk, error) {
+	fields := strings.SplitN(line, " ", 5)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return nil, fmt.Errorf("unexpected git diff hunk header %q", line)
+	}
+	hunk := &DiffHunk{}
+	var err error
+	if hunk.OldStart, hunk.OldLines, err = parseHunkRange(fields[1][1:]); err != nil {
+		return nil, fmt.Errorf("unexpected git diff hunk header %q", line)
+	}
+	if hunk.NewStart, hunk.NewLines, err = parseHunkRange(fields[2][1:]); err != nil {
+		return nil, fmt.Errorf("unexpected git diff hunk header %q", line)
+	}
+	if len(fields) == 5 {
+		hunk.Section = fields[4]
+	}
+	return hunk, nil
+}
+
+func parseHunkRange(hunkRange string) (start, lines int, err error) {
+	startValue, linesValue, found := strings.Cut(hunkRange, ",")
+	if start, err = strconv.Atoi(startValue); err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return start, 1, nil
+	}
+	lines, err = strconv.Atoi(linesValue)
+	return start, lines, err
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryManager_GetDiffBetweenCommits(t *testing.T) {
+	impl := getTestRepositoryManager(t)
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "old name.txt"), []byte("one\ntwo\nthree\nfour\n"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add files")
+	from := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.go"), []byte("package main\n\nfunc main() { run() }\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0, 1, 2}, 0644))
+	runTestGitCmd(t, workDir, "mv", "old name.txt", "new name.txt")
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "change files")
+	to := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	gitCtx := BuildGitContext(context.Background())
+	diff, err := impl.GetDiffBetweenCommits(gitCtx, workDir, from, to, nil, 0)
+	assert.Nil(t, err)
+	assert.False(t, diff.Truncated)
+	assert.Len(t, diff.Files, 3)
+	app := diff.Files[0]
+	assert.Equal(t, "app.go", app.Path)
+	assert.Equal(t, FILE_CHANGE_TYPE_MODIFIED, app.ChangeType)
+	assert.Equal(t, 1, app.Addition)
+	assert.Equal(t, 1, app.Deletion)
+	assert.Len(t, app.Hunks, 1)
+	assert.Equal(t, 3, app.Hunks[0].OldStart)
+	assert.Equal(t, []string{"-func main() {}", "+func main() { run() }"}, app.Hunks[0].Lines)
+	assert.Equal(t, "logo.png", diff.Files[1].Path)
+	assert.Equal(t, FILE_CHANGE_TYPE_ADDED, diff.Files[1].ChangeType)
+	assert.True(t, diff.Files[1].Binary)
+	assert.Empty(t, diff.Files[1].Hunks)
+	assert.Equal(t, "new name.txt", diff.Files[2].Path)
+	assert.Equal(t, "old name.txt", diff.Files[2].OldPath)
+	assert.Equal(t, FILE_CHANGE_TYPE_RENAMED, diff.Files[2].ChangeType)
+
+	diff, err = impl.GetDiffBetweenCommits(gitCtx, workDir, from, to, []string{"/app.go"}, -1)
+	assert.Nil(t, err)
+	assert.Len(t, diff.Files, 1)
+	assert.Len(t, diff.Files[0].Hunks[0].Lines, 4)
+
+	impl.configuration.DiffMaxSizeInBytes = 200
+	diff, err = impl.GetDiffBetweenCommits(gitCtx, workDir, from, to, nil, -1)
+	assert.Nil(t, err)
+	assert.True(t, diff.Truncated)
+	assert.Less(t, len(diff.Files), 3)
+	assert.Equal(t, "app.go", diff.Files[0].Path)
+
+	_, err = impl.GetDiffBetweenCommits(gitCtx, workDir, "--output=x", to, nil, -1)
+	assert.NotNil(t, err)
+}
+
+func TestParseHunkHeader(t *testing.T) {
+	hunk, err := parseHunkHeader("@@ -5 +6,0 @@ func main() {")
+	assert.Nil(t, err)
+	assert.Equal(t, &DiffHunk{OldStart: 5, OldLines: 1, NewStart: 6, NewLines: 0, Section: "func main() {"}, hunk)
+	_, err = parseHunkHeader("@@ -a,1 +1 @@")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BINARY_DETECTION_BYTES is the length of the prefix searched for a NUL byte, same heuristic as git
+const BINARY_DETECTION_BYTES = 8000
+
+type FileContent struct {
+	Path    string
+	Commit  string
+	Size    int64
+	Binary  bool
+	Content []byte
+}
+
+// GetFileContentAtCommit returns the content of the file at the commit, files larger than FILE_CONTENT_MAX_SIZE_IN_BYTES are rejected
+func (impl *GitManagerBaseImpl) GetFileContentAtCommit(gitCtx GitContext, rootDir string, commitHash string, filePath string) (*FileContent, error) {
+	object := commitHash + ":" + strings.TrimPrefix(filePath, "/")
+	impl.logger.Debugw("git", "-C", rootDir, "cat-file", "-s", object)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "cat-file", "-s", object)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in getting file size", "rootDir", rootDir, "object", object, "errMsg", errMsg, "err", err)
+		return nil, fmt.Errorf("file %s not found at commit %s", filePath, commitHash)
+	}
+	size, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize := impl.conf.FileContentMaxSizeInBytes; maxSize > 0 && size > maxSize {
+		return nil, fmt.Errorf("file %s of size %d bytes exceeds the limit of %d bytes", filePath, size, maxSize)
+	}
+	// the output is read as is since runCommand trims it and mixes stderr in
+	impl.logger.Debugw("git", "-C", rootDir, "cat-file", "blob", object)
+	cmd, cancel = impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "cat-file", "blob", object)
+	defer cancel()
+	cmd.Env = append(cmd.Env, impl.getCommandEnv()...)
+	content, err := cmd.Output()
+	if err != nil {
+		impl.logger.Errorw("error in reading file content", "rootDir", rootDir, "object", object, "err", err)
+		return nil, err
+	}
+	return &FileContent{
+		Path:    filePath,
+		Commit:  commitHash,
+		Size:    size,
+		Binary:  isBinaryContent(content),
+		Content: content,
+	}, nil
+}
+
+func isBinaryContent(content []byte) bool {
+	if len(content) > BINARY_DETECTION_BYTES {
+		content = content[:BINARY_DETECTION_BYTES]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
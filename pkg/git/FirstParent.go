@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// firstParentCommitIter walks a branch following only the first parent of each commit,
+// the same commits git log --first-parent lists
+type firstParentCommitIter struct {
+	store storer.EncodedObjectStorer
+	next  plumbing.Hash
+}
+
+func newFirstParentCommitIter(store storer.EncodedObjectStorer, from plumbing.Hash) object.CommitIter {
+	return &firstParentCommitIter{store: store, next: from}
+}
+
+func (itr *firstParentCommitIter) Next() (*object.Commit, error) {
+	if itr.next.IsZero() {
+		return nil, io.EOF
+	}
+	commit, err := object.GetCommit(itr.store, itr.next)
+	if err != nil {
+		return nil, err
+	}
+	itr.next = plumbing.ZeroHash
+	if len(commit.ParentHashes) > 0 {
+		itr.next = commit.ParentHashes[0]
+	}
+	return commit, nil
+}
+
+func (itr *firstParentCommitIter) ForEach(cb func(*object.Commit) error) error {
+	for {
+		commit, err := itr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = cb(commit); err == storer.ErrStop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (itr *firstParentCommitIter) Close() {
+	itr.next = plumbing.ZeroHash
+}
+
+func getParentHashes(commit *object.Commit) []string {
+	if len(commit.ParentHashes) == 0 {
+		return nil
+	}
+	parents := make([]string, 0, len(commit.ParentHashes))
+	for _, parent := range commit.ParentHashes {
+		parents = append(parents, parent.String())
+	}
+	return parents
+}
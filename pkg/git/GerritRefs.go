@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strings"
+)
+
+const (
+	// GERRIT_CHANGE_REF_PREFIX is the prefix of the refs gerrit keeps for the patch sets of the changes under review,
+	// refs/changes/<last two digits of change>/<change>/<patch set>
+	GERRIT_CHANGE_REF_PREFIX = "refs/changes/"
+	// GERRIT_CHANGE_BRANCH_PREFIX is the prefix of the branch a change ref is fetched as, refs/changes/34/1234/2 is
+	// tracked as the remote branch changes/34/1234/2 of origin
+	GERRIT_CHANGE_BRANCH_PREFIX = "changes/"
+
+	BRANCH_REF_SPEC        = "+refs/heads/*:refs/remotes/origin/*"
+	GERRIT_CHANGE_REF_SPEC = "+refs/changes/*:refs/remotes/origin/changes/*"
+)
+
+// ToGerritChangeBranch returns the branch a gerrit change ref is tracked as, other refs are returned as they are
+func ToGerritChangeBranch(ref string) string {
+	if strings.HasPrefix(ref, GERRIT_CHANGE_REF_PREFIX) {
+		return strings.TrimPrefix(ref, "refs/")
+	}
+	return ref
+}
+
+// getFetchRefSpecs returns the ref specs to fetch with, none when the ones configured for origin are used
+func getFetchRefSpecs(fetchChangeRefs bool, fetchNotes bool) []string {
+	if !fetchChangeRefs && !fetchNotes {
+		return nil
+	}
+	// ref specs given on the command line replace the configured ones, so the branches are fetched explicitly
+	refSpecs := []string{BRANCH_REF_SPEC}
+	if fetchChangeRefs {
+		refSpecs = append(refSpecs, GERRIT_CHANGE_REF_SPEC)
+	}
+	if fetchNotes {
+		refSpecs = append(refSpecs, NOTES_REF_SPEC)
+	}
+	return refSpecs
+}
+
+// GetRemoteRefOfBranch returns the ref at the remote of a remote tracking branch of origin
+func GetRemoteRefOfBranch(branch string, fetchChangeRefs bool) string {
+	if fetchChangeRefs && strings.HasPrefix(branch, GERRIT_CHANGE_BRANCH_PREFIX) {
+		return "refs/" + branch
+	}
+	return BRANCH_REF_PREFIX + branch
+}
@@ -17,6 +17,7 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -24,12 +25,17 @@ import (
 	commonLibGitManager "github.com/devtron-labs/common-lib/git-manager"
 	"github.com/devtron-labs/git-sensor/internals"
 	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
 	"github.com/devtron-labs/git-sensor/util"
 	"go.uber.org/zap"
+	"io"
 	"os"
 	"os/exec"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type GitManager interface {
@@ -40,6 +46,8 @@ type GitManager interface {
 	GetCommitIterator(gitCtx GitContext, repository *GitRepository, iteratorRequest IteratorRequest) (CommitIterator, error)
 	// GetCommitForHash retrieves the commit reference for given tag
 	GetCommitForHash(gitCtx GitContext, checkoutPath, commitHash string) (GitCommit, error)
+	// GetCommitsForHashes retrieves the commits for the given hashes keyed by hash, unknown hashes are left out
+	GetCommitsForHashes(gitCtx GitContext, checkoutPath string, commitHashes []string) (map[string]GitCommit, error)
 	// GetCommitsForTag retrieves the commit reference for given tag
 	GetCommitsForTag(gitCtx GitContext, checkoutPath, tag string) (GitCommit, error)
 	// OpenRepoPlain opens a new git repo at the given path
@@ -62,14 +70,87 @@ type GitManagerBase interface {
 	FetchDiffStatBetweenCommitsNameOnly(gitCtx GitContext, oldHash string, newHash string, rootDir string) (FileStats, error)
 	//  FetchDiffStatBetweenCommitsWithNumstat returns the file stats reponse on executing git action
 	FetchDiffStatBetweenCommitsWithNumstat(gitCtx GitContext, oldHash string, newHash string, rootDir string) (FileStats, error)
+	// MarkLargeFiles flags the files of the stats of the commit which are above the large file threshold
+	MarkLargeFiles(gitCtx GitContext, rootDir string, commitHash string, fileStats FileStats) error
 	// LogMergeBase get the commit diff between using a merge base strategy
 	LogMergeBase(gitCtx GitContext, rootDir, from string, to string) ([]*Commit, error)
+	// IsAncestor checks whether the ancestor commit is reachable from the descendant commit
+	IsAncestor(gitCtx GitContext, rootDir, ancestor, descendant string) (bool, error)
+	// GetMergeBase returns the best common ancestor of the two commits, empty if they have no common history
+	GetMergeBase(gitCtx GitContext, rootDir, commitA, commitB string) (string, error)
+	// GetOrphanedCommits lists the commits reachable from the old head which are not from the new head, newest first
+	GetOrphanedCommits(gitCtx GitContext, rootDir, oldHead, newHead string, limit int) ([]string, error)
+	// LsRemote lists the refs matching the given refs at the remote with their commits
+	LsRemote(gitCtx GitContext, rootDir, remote string, refs []string) (map[string]string, error)
+	// RefExists checks whether the given ref resolves to a commit in the repo
+	RefExists(gitCtx GitContext, rootDir, ref string) (bool, error)
+	// GetTags lists the tags matching the glob or semver constraint pattern, newest first
+	GetTags(gitCtx GitContext, rootDir string, pattern string) ([]*GitTag, error)
+	// ListBranches lists the remote branches as of the last fetch, most recently updated first
+	ListBranches(gitCtx GitContext, rootDir string) ([]*GitBranch, error)
+	// GetFileContentAtCommit returns the content of the file at the commit with binary detection
+	GetFileContentAtCommit(gitCtx GitContext, rootDir string, commitHash string, filePath string) (*FileContent, error)
+	// GetChangedFiles lists the files changed by the commit with their change type and line stats
+	GetChangedFiles(gitCtx GitContext, rootDir string, commitHash string) ([]*FileChange, error)
+	// GetLfsPointersInCommit returns the files changed by the commit which are git lfs pointers
+	GetLfsPointersInCommit(gitCtx GitContext, rootDir string, commitHash string) ([]*LfsPointer, error)
+	// CountObjects returns the object counts and sizes of the object store from git count-objects -v
+	CountObjects(gitCtx GitContext, rootDir string) (*RepoMetrics, error)
+	// GetLargestBlobs returns the largest blobs of the object store with one of their paths, largest first
+	GetLargestBlobs(gitCtx GitContext, rootDir string, limit int) ([]*BlobSize, error)
+	// WriteArchive streams the archive of the commit in the given format to the writer, limited to the paths when set
+	WriteArchive(gitCtx GitContext, rootDir, commitHash, format string, paths []string, writer io.Writer) error
+	// ResolveCommits returns the full commit hash of each revision which names a commit of the repo
+	ResolveCommits(gitCtx GitContext, rootDir string, revisions []string) (map[string]string, error)
+	// GetDiffBetweenCommits returns the unified diff hunks of the files changed between the two commits
+	GetDiffBetweenCommits(gitCtx GitContext, rootDir, fromCommit, toCommit string, paths []string, contextLines int) (*CommitRangeDiff, error)
+	// GetBlame returns the commit, author and date which last changed each line in the range of the file at the ref
+	GetBlame(gitCtx GitContext, rootDir, filePath string, startLine, endLine int, ref string) (*FileBlame, error)
+	// SearchCommits finds the commits of the branches and tags by message and author, newest first
+	SearchCommits(gitCtx GitContext, rootDir string, request *CommitSearchRequest) ([]*GitCommitBase, error)
+	// GenerateChangeLog groups the commits between the two refs by conventional commit type for release notes
+	GenerateChangeLog(gitCtx GitContext, rootDir, fromRef, toRef string) (*ChangeLog, error)
+	// VerifyCommitSignature verifies the gpg or ssh signature of the commit
+	VerifyCommitSignature(gitCtx GitContext, rootDir string, commitHash string) (*CommitSignature, error)
+	// GetCommitNotes returns the git notes of the commits from all the notes refs, keyed by commit and notes ref name
+	GetCommitNotes(gitCtx GitContext, rootDir string, commitHashes []string) (map[string]map[string]string, error)
+	// GetSubmoduleChanges returns the submodules whose commit pointer was changed by the given commit
+	GetSubmoduleChanges(gitCtx GitContext, rootDir string, commitHash string) ([]*SubmoduleChange, error)
+	// FetchCommit fetches the commit by its full hash from origin when the repo does not have it
+	FetchCommit(gitCtx GitContext, rootDir, commitHash string) (errMsg string, err error)
+	// FetchRefs fetches the refs from origin into the same refs, shallow when depth is set
+	FetchRefs(gitCtx GitContext, rootDir string, depth int, refs []string) (errMsg string, err error)
+	// Deepen fetches the given number of commits beyond the shallow boundary of the repo
+	Deepen(gitCtx GitContext, rootDir string, deepenBy int) (response, errMsg string, err error)
+	// AddWorktree adds a worktree of the repo at worktreeDir sharing its objects and refs, detached at the commit
+	AddWorktree(gitCtx GitContext, rootDir, worktreeDir, commit string) error
+	// PruneWorktrees removes the worktrees of the repo whose dirs are gone
+	PruneWorktrees(gitCtx GitContext, rootDir string) error
+	// GetRefsChecksum returns a checksum of the remote branches and tags of the repo with their commits
+	GetRefsChecksum(gitCtx GitContext, rootDir string) (string, error)
+	// GarbageCollect packs the repo if needed, unreachable objects are pruned after the default expiry of gc
+	GarbageCollect(gitCtx GitContext, rootDir string) (response, errMsg string, err error)
 	ExecuteCustomCommand(gitContext GitContext, name string, arg ...string) (response, errMsg string, err error)
+	// StreamCustomCommand starts the command and returns its stdout, wait must be called once stdout is consumed
+	StreamCustomCommand(gitContext GitContext, name string, arg ...string) (stdout io.ReadCloser, wait func() (errMsg string, err error), err error)
+	// GetGitBinary returns the git executable the commands are run with and its version
+	GetGitBinary() *GitBinary
+	// GetWorktreeStores returns the locks of the worktree stores, shared by everything writing to the stores
+	GetWorktreeStores() *WorktreeStores
+	// RemoveLockFilesOfKilledCommands removes the lock files left in the repo by its git commands killed on their
+	// deadline. The caller must hold the lock of the material, and the one of the store for a worktree, so that no git
+	// command runs on the repo
+	RemoveLockFilesOfKilledCommands(rootDir string) ([]string, error)
 }
 type GitManagerBaseImpl struct {
 	logger            *zap.SugaredLogger
 	conf              *internals.Configuration
 	commandTimeoutMap map[string]int
+	// defaultGitConfigArgs are passed to every git command, after the ones of the material so that they take precedence
+	defaultGitConfigArgs []string
+	gitBinary            *GitBinary
+	worktreeStores       *WorktreeStores
+	killedCmdLockFiles   *killedCommandLockFiles
 }
 
 func NewGitManagerBaseImpl(logger *zap.SugaredLogger, config *internals.Configuration) *GitManagerBaseImpl {
@@ -79,7 +160,23 @@ func NewGitManagerBaseImpl(logger *zap.SugaredLogger, config *internals.Configur
 		logger.Errorw("error in parsing config", "config", config, "err", err)
 	}
 
-	return &GitManagerBaseImpl{logger: logger, conf: config, commandTimeoutMap: commandTimeoutMap}
+	gitBinary, err := DetectGitBinary(config.GitBinaryPath)
+	if err != nil {
+		logger.Warnw("error in detecting git binary", "path", gitBinary.Path, "err", err)
+	} else {
+		logger.Infow("detected git binary", "path", gitBinary.Path, "version", gitBinary.GetVersion(), "disabledFeatures", gitBinary.GetDisabledFeatures())
+	}
+
+	return &GitManagerBaseImpl{logger: logger, conf: config, commandTimeoutMap: commandTimeoutMap, defaultGitConfigArgs: getDefaultGitConfigArgs(config), gitBinary: gitBinary,
+		worktreeStores: NewWorktreeStores(), killedCmdLockFiles: newKilledCommandLockFiles()}
+}
+
+func (impl *GitManagerBaseImpl) GetGitBinary() *GitBinary {
+	return impl.gitBinary
+}
+
+func (impl *GitManagerBaseImpl) GetWorktreeStores() *WorktreeStores {
+	return impl.worktreeStores
 }
 
 type GitManagerImpl struct {
@@ -90,13 +187,15 @@ func NewGitManagerImpl(logger *zap.SugaredLogger, configuration *internals.Confi
 
 	baseImpl := NewGitManagerBaseImpl(logger, configuration)
 	if configuration.UseGitCli {
+		logger.Infow("using git cli based git manager")
 		return &GitManagerImpl{
-			GitManager: NewGitCliManagerImpl(baseImpl, logger),
+			GitManager: NewGitCliManagerImpl(baseImpl, logger, configuration),
 		}
 
 	}
+	logger.Infow("using go-git based git manager")
 	return &GitManagerImpl{
-		GitManager: NewGoGitSDKManagerImpl(baseImpl, logger),
+		GitManager: NewGoGitSDKManagerImpl(baseImpl, logger, configuration),
 	}
 }
 
@@ -111,7 +210,7 @@ func parseCmdTimeoutJson(config *internals.Configuration) (map[string]int, error
 
 func (impl *GitManagerBaseImpl) Fetch(gitCtx GitContext, rootDir string) (response, errMsg string, err error) {
 	impl.logger.Debugw("git fetch ", "location", rootDir)
-	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "fetch", "origin", "--tags", "--force")
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", withProgressArg(gitCtx, getFetchCmdArgs(rootDir, gitCtx.FetchDepth, impl.conf.FetchGerritChangeRefs, impl.conf.FetchGitNotes, impl.conf.EnableTagPolling, gitCtx.FetchUrl))...)
 	defer cancel()
 	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
 	if err != nil {
@@ -119,32 +218,135 @@ func (impl *GitManagerBaseImpl) Fetch(gitCtx GitContext, rootDir string) (respon
 		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
 	}
 	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
-	output, errMsg, err := impl.runCommandWithCred(cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
 	if strings.Contains(output, LOCK_REF_MESSAGE) {
 		impl.logger.Info("error in fetch, pruning local refs and retrying", "rootDir", rootDir)
 		// running git remote prune origin and retrying fetch. gitHub issue - https://github.com/devtron-labs/devtron/issues/4605
 		pruneCmd, pruneCmdCancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "remote", "prune", "origin")
-		pruneOutput, pruneMsg, pruneErr := impl.runCommandWithCred(pruneCmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+		pruneOutput, pruneMsg, pruneErr := impl.runCommandWithCred(gitCtx, pruneCmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
 		defer pruneCmdCancel()
 		if pruneErr != nil {
 			impl.logger.Errorw("error in pruning local refs that do not exist at remote")
 			return pruneOutput, pruneMsg, pruneErr
 		}
 
-		retryFetchCmd, retryFetchCancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "fetch", "origin", "--tags", "--force")
+		retryFetchCmd, retryFetchCancel := impl.createCmdWithContext(gitCtx, "git", withProgressArg(gitCtx, getFetchCmdArgs(rootDir, gitCtx.FetchDepth, impl.conf.FetchGerritChangeRefs, impl.conf.FetchGitNotes, impl.conf.EnableTagPolling, gitCtx.FetchUrl))...)
 		defer retryFetchCancel()
 
-		output, errMsg, err = impl.runCommandWithCred(retryFetchCmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+		output, errMsg, err = impl.runCommandWithCred(gitCtx, retryFetchCmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
 	}
 	impl.logger.Debugw("fetch output", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	return output, errMsg, err
 }
 
+var fullCommitHashRegex = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// getFetchCmdArgs prunes remote branches deleted at remote, so that stale refs are not served after a deletion or force
+// push. tags deleted at remote are pruned only when the tags are tracked with ENABLE_TAG_POLLING
+func getFetchCmdArgs(rootDir string, depth int, fetchChangeRefs bool, fetchNotes bool, pruneTags bool, fetchUrl string) []string {
+	remote := "origin"
+	if len(fetchUrl) > 0 {
+		remote = fetchUrl
+	}
+	args := []string{"-C", rootDir, "fetch", remote, "--tags", "--force", "--prune"}
+	if pruneTags {
+		args = append(args, "--prune-tags")
+	}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	refSpecs := getFetchRefSpecs(fetchChangeRefs, fetchNotes)
+	if len(fetchUrl) > 0 && len(refSpecs) == 0 {
+		// a url has no ref specs configured, its branches are fetched as the ones of origin
+		refSpecs = []string{BRANCH_REF_SPEC}
+	}
+	return append(args, refSpecs...)
+}
+
+// withProgressArg makes git report its progress, which it does only on a terminal otherwise, when the job running
+// the command tracks it
+func withProgressArg(gitCtx GitContext, args []string) []string {
+	if gitCtx.Progress == nil {
+		return args
+	}
+	return append(args, "--progress")
+}
+
+func (impl *GitManagerBaseImpl) Deepen(gitCtx GitContext, rootDir string, deepenBy int) (response, errMsg string, err error) {
+	impl.logger.Debugw("git fetch --deepen", "location", rootDir, "deepenBy", deepenBy)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", withProgressArg(gitCtx, []string{"-C", rootDir, "fetch", "origin", "--deepen", strconv.Itoa(deepenBy)})...)
+	defer cancel()
+	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
+	if err != nil {
+		//making it non-blocking
+		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
+	}
+	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	impl.logger.Debugw("deepen output", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	return output, errMsg, err
+}
+
+// FetchCommit fetches the commit by its hash when the repo does not have it, so that commits which are no longer on any
+// branch can be served. Servers which do not allow fetching unadvertised commits are fetched in full instead
+func (impl *GitManagerBaseImpl) FetchCommit(gitCtx GitContext, rootDir, commitHash string) (errMsg string, err error) {
+	if !fullCommitHashRegex.MatchString(commitHash) {
+		return "", fmt.Errorf("invalid commit hash %q, the full hash is needed to fetch a commit", commitHash)
+	}
+	exists, err := impl.RefExists(gitCtx, rootDir, commitHash)
+	if err != nil || exists {
+		return "", err
+	}
+	cmdArgs := []string{"-C", rootDir, "fetch", "origin", "--no-tags", commitHash}
+	if IsShallowRepository(rootDir) && impl.conf.ShallowCloneDepth > 0 {
+		cmdArgs = append(cmdArgs, "--depth", strconv.Itoa(impl.conf.ShallowCloneDepth))
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
+	if err != nil {
+		//making it non-blocking
+		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
+	}
+	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	impl.logger.Debugw("fetch commit output", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		impl.logger.Warnw("fetching commit by hash failed, fetching the repo instead", "rootDir", rootDir, "commitHash", commitHash, "errMsg", errMsg, "err", err)
+		_, errMsg, err = impl.Fetch(gitCtx, rootDir)
+		if err != nil {
+			return errMsg, err
+		}
+	}
+	exists, err = impl.RefExists(gitCtx, rootDir, commitHash)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("commit %s not found at remote", commitHash)
+	}
+	return "", nil
+}
+
+// GarbageCollect leaves the unreachable objects to the default expiry of gc, objects being written by a concurrent
+// fetch and the orphaned commits still looked up by hash must not be pruned
+func (impl *GitManagerBaseImpl) GarbageCollect(gitCtx GitContext, rootDir string) (response, errMsg string, err error) {
+	impl.logger.Debugw("git", "-C", rootDir, "gc", "--auto", "--quiet")
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "gc", "--auto", "--quiet")
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in git gc", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+	}
+	return output, errMsg, err
+}
+
 func (impl *GitManagerBaseImpl) Checkout(gitCtx GitContext, rootDir, branch string) (response, errMsg string, err error) {
 	impl.logger.Debugw("git checkout ", "location", rootDir)
 	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "checkout", branch, "--force")
 	defer cancel()
-	output, errMsg, err := impl.runCommand(cmd)
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
 	impl.logger.Debugw("checkout output", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	return output, errMsg, err
 }
@@ -161,7 +363,7 @@ func (impl *GitManagerBaseImpl) LogMergeBase(gitCtx GitContext, rootDir, from st
 	impl.logger.Debugw("git", cmdArgs)
 	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
 	defer cancel()
-	output, errMsg, err := impl.runCommand(cmd)
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	if err != nil {
 		return nil, err
@@ -174,7 +376,80 @@ func (impl *GitManagerBaseImpl) LogMergeBase(gitCtx GitContext, rootDir, from st
 	return commits, nil
 }
 
-func (impl *GitManagerBaseImpl) runCommandWithCred(cmd *exec.Cmd, userName, password string, tlsPathInfo *commonLibGitManager.TlsPathInfo) (response, errMsg string, err error) {
+func (impl *GitManagerBaseImpl) IsAncestor(gitCtx GitContext, rootDir, ancestor, descendant string) (bool, error) {
+	impl.logger.Debugw("git", "-C", rootDir, "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "merge-base", "--is-ancestor", ancestor, descendant)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		// exit code 1 means the commit is not an ancestor, anything else is a failure
+		var exErr *exec.ExitError
+		if errors.As(err, &exErr) && exErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (impl *GitManagerBaseImpl) GetMergeBase(gitCtx GitContext, rootDir, commitA, commitB string) (string, error) {
+	impl.logger.Debugw("git", "-C", rootDir, "merge-base", commitA, commitB)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "merge-base", commitA, commitB)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		// exit code 1 without output means the commits have no common ancestor
+		var exErr *exec.ExitError
+		if errors.As(err, &exErr) && exErr.ExitCode() == 1 && len(output) == 0 {
+			return "", nil
+		}
+		return "", err
+	}
+	return output, nil
+}
+
+// GetOrphanedCommits relies on the commits of the old head still being in the repo, a fetch keeps them till they are
+// pruned by gc
+func (impl *GitManagerBaseImpl) GetOrphanedCommits(gitCtx GitContext, rootDir, oldHead, newHead string, limit int) ([]string, error) {
+	cmdArgs := []string{"-C", rootDir, "rev-list", oldHead, "^" + newHead}
+	if limit > 0 {
+		cmdArgs = append(cmdArgs, "-n", strconv.Itoa(limit))
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+func (impl *GitManagerBaseImpl) RefExists(gitCtx GitContext, rootDir, ref string) (bool, error) {
+	impl.logger.Debugw("git", "-C", rootDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	if err != nil {
+		var exErr *exec.ExitError
+		if errors.As(err, &exErr) && exErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (impl *GitManagerBaseImpl) runCommandWithCred(gitCtx GitContext, cmd *exec.Cmd, userName, password string, tlsPathInfo *commonLibGitManager.TlsPathInfo) (response, errMsg string, err error) {
+	setCredEnv(cmd, userName, password, tlsPathInfo)
+	return impl.runCommand(gitCtx, cmd)
+}
+
+func setCredEnv(cmd *exec.Cmd, userName, password string, tlsPathInfo *commonLibGitManager.TlsPathInfo) {
 	cmd.Env = append(os.Environ(),
 		fmt.Sprintf("GIT_ASKPASS=%s", GIT_ASK_PASS),
 		fmt.Sprintf("GIT_USERNAME=%s", userName),
@@ -190,36 +465,107 @@ func (impl *GitManagerBaseImpl) runCommandWithCred(cmd *exec.Cmd, userName, pass
 			cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_SSL_CAINFO=%s", tlsPathInfo.CaCertPath))
 		}
 	}
-	return impl.runCommand(cmd)
 }
 
-func (impl *GitManagerBaseImpl) runCommand(cmd *exec.Cmd) (response, errMsg string, err error) {
-	cmd.Env = append(cmd.Env, "HOME=/dev/null")
-	outBytes, err := cmd.CombinedOutput()
-	output := string(outBytes)
-	output = strings.TrimSpace(output)
+func (impl *GitManagerBaseImpl) runCommand(gitCtx GitContext, cmd *exec.Cmd) (response, errMsg string, err error) {
+	start := time.Now()
+	subCommand := getSubCommand(cmd.Args)
+	_, span := tracing.StartSpan(gitCtx, "git "+subCommand, tracing.ATTRIBUTE_COMMAND.String(subCommand))
+	defer func() {
+		util.TriggerGitCommandMetrics(subCommand, start, err)
+		tracing.EndSpan(span, err)
+	}()
+	cmd.Env = append(cmd.Env, impl.getCommandEnv()...)
+	var gitDir string
+	var lockFilesBefore map[string]bool
+	if rootDir := getCommandRootDir(cmd.Args); len(rootDir) > 0 && repoWriteSubCommands[subCommand] {
+		gitDir = GetGitDir(rootDir)
+		lockFilesBefore = listLockFiles(gitDir)
+	}
+	// the output, the errors and the logged args never carry the credentials of the remote
+	secrets := append(getCommandSecrets(cmd), gitCtx.Password)
+	impl.logger.Debugw("running git command", "args", SanitizeArgs(cmd.Args, secrets...))
+	var outBytes []byte
+	if gitCtx.Progress != nil {
+		// the output is shared with the progress of the job as the command runs
+		var output bytes.Buffer
+		cmd.Stdout = io.MultiWriter(&output, newSanitizingWriter(gitCtx.Progress, secrets))
+		cmd.Stderr = cmd.Stdout
+		err = cmd.Run()
+		outBytes = output.Bytes()
+	} else {
+		outBytes, err = cmd.CombinedOutput()
+	}
+	rawOutput := SanitizeText(string(outBytes), secrets...)
+	output := strings.TrimSpace(rawOutput)
 	if err != nil {
-		impl.logger.Errorw("error in git cli operation", "msg", string(outBytes), "err", err)
+		impl.logger.Errorw("error in git cli operation", "args", SanitizeArgs(cmd.Args, secrets...), "msg", rawOutput, "err", SanitizeError(err, secrets...))
 		exErr, ok := err.(*exec.ExitError)
+		err = SanitizeError(ClassifyGitError(output, err), secrets...)
+		if errors.Is(err, ErrTimeout) && len(gitDir) > 0 {
+			if owned := impl.killedCmdLockFiles.record(gitDir, lockFilesBefore); len(owned) > 0 {
+				impl.logger.Warnw("killed git command left lock files, they are removed under the lock of the repo", "gitDir", gitDir, "lockFiles", owned)
+			}
+		}
 		if !ok {
-			return output, string(outBytes), err
+			return output, rawOutput, err
 		}
-		if strings.Contains(output, AUTHENTICATION_FAILED_ERROR) {
-			impl.logger.Errorw("authentication failed", "msg", string(outBytes), "err", err.Error())
-			return output, "authentication failed", errors.New("authentication failed")
+		if errors.Is(err, ErrAuthFailed) {
+			impl.logger.Errorw("authentication failed", "msg", rawOutput, "err", err.Error())
+			return output, "authentication failed", err
 		}
-		errOutput := string(exErr.Stderr)
+		errOutput := SanitizeText(string(exErr.Stderr), secrets...)
 		return output, errOutput, err
 	}
 	return output, "", nil
 }
 
+// getCommandEnv returns the env set for every git command. With GIT_LFS_SKIP_SMUDGE the lfs filter keeps the pointer
+// files as they are instead of downloading the objects, which can hang or fail for repos with lfs hooks
+func (impl *GitManagerBaseImpl) getCommandEnv() []string {
+	env := []string{"HOME=/dev/null"}
+	if impl.conf.GitLfsSkipSmudge {
+		env = append(env, "GIT_LFS_SKIP_SMUDGE=1")
+	}
+	return env
+}
+
+// getSubCommand returns the git sub command of the command line, skipping the global options like -C dir and -c key=value.
+// Other binaries are reported by their name
+func getSubCommand(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	if path.Base(args[0]) != "git" {
+		return path.Base(args[0])
+	}
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "-C" || args[i] == "-c":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return "git"
+}
+
 func (impl *GitManagerBaseImpl) ConfigureSshCommand(gitCtx GitContext, rootDir string, sshPrivateKeyPath string) (response, errMsg string, err error) {
 	impl.logger.Debugw("configuring ssh command on ", "location", rootDir)
-	coreSshCommand := fmt.Sprintf("ssh -i %s -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no", sshPrivateKeyPath)
+	coreSshCommand, err := BuildSshCommand(sshPrivateKeyPath, impl.conf.SshHostKeyChecking, SSH_KNOWN_HOSTS_FILE)
+	if err != nil {
+		return "", err.Error(), err
+	}
+	if impl.conf.SshHostKeyChecking == SSH_HOST_KEY_CHECKING_ACCEPT_NEW || impl.conf.SshHostKeyChecking == SSH_HOST_KEY_CHECKING_STRICT {
+		err = EnsureKnownHosts(SSH_KNOWN_HOSTS_FILE, impl.conf.SshKnownHosts)
+		if err != nil {
+			impl.logger.Errorw("error in writing known hosts file", "file", SSH_KNOWN_HOSTS_FILE, "err", err)
+			return "", err.Error(), err
+		}
+	}
 	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "config", "core.sshCommand", coreSshCommand)
 	defer cancel()
-	output, errMsg, err := impl.runCommand(cmd)
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
 	impl.logger.Debugw("configure ssh command output ", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	return output, errMsg, err
 }
@@ -301,19 +647,22 @@ func GetBranchReference(branch string) (string, string) {
 	if strings.HasPrefix(branch, "refs/heads/") {
 		branch = strings.ReplaceAll(branch, "refs/heads/", "")
 	}
+	branch = ToGerritChangeBranch(branch)
 
 	branchRef := fmt.Sprintf("refs/remotes/origin/%s", branch)
 	return branch, branchRef
 }
 
 func (impl *GitManagerBaseImpl) FetchDiffStatBetweenCommitsNameOnly(gitCtx GitContext, oldHash string, newHash string, rootDir string) (FileStats, error) {
-	impl.logger.Debugw("git", "-C", rootDir, "diff", "--name-only", oldHash, newHash)
+	impl.logger.Debugw("git", "-C", rootDir, "diff", "--raw", "-z", "-M", "-C", oldHash, newHash)
 
 	if newHash == "" {
 		newHash = oldHash
 		oldHash = oldHash + "^"
 	}
-	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "diff", "--name-only", oldHash, newHash)
+	// the raw output carries the old path and similarity of renamed and copied files, which --name-only leaves out
+	impl.prefetchDiffBlobs(gitCtx, rootDir, []string{oldHash, newHash}, nil)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "diff", "--raw", "-z", "-M", "-C", oldHash, newHash)
 
 	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
 	if err != nil {
@@ -326,23 +675,24 @@ func (impl *GitManagerBaseImpl) FetchDiffStatBetweenCommitsNameOnly(gitCtx GitCo
 		commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
 	}()
 
-	output, errMsg, err := impl.runCommandWithCred(cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	if err != nil || len(errMsg) > 0 {
 		impl.logger.Errorw("error in fetching fileStat diff btw commits: ", "oldHash", oldHash, "newHash", newHash, "checkoutPath", rootDir, "errorMsg", errMsg, "err", err)
 		return nil, err
 	}
-	return processFileStatOutputNameOnly(output)
+	return toFileStats(parseChangedFiles(output)), nil
 }
 
 func (impl *GitManagerBaseImpl) FetchDiffStatBetweenCommitsWithNumstat(gitCtx GitContext, oldHash string, newHash string, rootDir string) (FileStats, error) {
-	impl.logger.Debugw("git", "-C", rootDir, "diff", "--numstat", oldHash, newHash)
+	impl.logger.Debugw("git", "-C", rootDir, "diff", "--raw", "--numstat", "-z", "-M", "-C", oldHash, newHash)
 
 	if newHash == "" {
 		newHash = oldHash
 		oldHash = oldHash + "^"
 	}
-	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "diff", "--numstat", oldHash, newHash)
+	impl.prefetchDiffBlobs(gitCtx, rootDir, []string{oldHash, newHash}, nil)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "diff", "--raw", "--numstat", "-z", "-M", "-C", oldHash, newHash)
 	defer cancel()
 	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
 	if err != nil {
@@ -350,37 +700,136 @@ func (impl *GitManagerBaseImpl) FetchDiffStatBetweenCommitsWithNumstat(gitCtx Gi
 		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
 	}
 	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
-	output, errMsg, err := impl.runCommandWithCred(cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	if err != nil || len(errMsg) > 0 {
 		impl.logger.Errorw("error in fetching fileStat diff btw commits: ", "oldHash", oldHash, "newHash", newHash, "checkoutPath", rootDir, "errorMsg", errMsg, "err", err)
 		return nil, err
 	}
-	return processFileStatOutputWithNumstat(output)
+	return toFileStats(parseChangedFiles(output)), nil
 }
 
 func (impl *GitManagerBaseImpl) createCmdWithContext(ctx GitContext, name string, arg ...string) (*exec.Cmd, context.CancelFunc) {
 	newCtx := ctx
 	cancel := func() {}
 
-	//TODO: how to make it generic, currently works because the
-	// git command is placed at index 2 for current implementations
-	timeout := impl.getCommandTimeout(arg[2])
-
+	timeout := impl.getCommandTimeout(ctx, getSubCommand(append([]string{name}, arg...)))
 	if timeout > 0 {
-
-		newCtx, cancel = ctx.WithTimeout(timeout) //context.WithTimeout(ctx.Context, timeout*time.Second)
+		newCtx, cancel = ctx.WithTimeout(timeout)
+	}
+	if name == "git" && len(ctx.ProxyUrl) > 0 {
+		arg = append([]string{"-c", "http.proxy=" + ctx.ProxyUrl}, arg...)
+	}
+	if name == "git" && ctx.InsecureSkipTLS {
+		arg = append([]string{"-c", "http.sslVerify=false"}, arg...)
+	}
+	if name == "git" && len(impl.conf.MailmapFile) > 0 {
+		arg = append([]string{"-c", "mailmap.file=" + impl.conf.MailmapFile}, arg...)
+	}
+	if name == "git" && len(impl.defaultGitConfigArgs) > 0 {
+		arg = append(append([]string{}, impl.defaultGitConfigArgs...), arg...)
+	}
+	// the config of the material comes first, the later -c of the same key wins so the proxy, tls and defaults set by
+	// git-sensor itself are never overridden by it
+	if name == "git" && len(ctx.ExtraGitConfig) > 0 {
+		configArgs := getGitConfigArgs(ctx.ExtraGitConfig, impl.logger)
+		impl.logger.Debugw("git config overrides for command", "command", getSubCommand(append([]string{name}, arg...)), "config", configArgs)
+		arg = append(configArgs, arg...)
+	}
+	newCtx, cancelCmd := newCtx.WithCancel()
+	stopKill := context.AfterFunc(childProcessCtx, cancelCmd)
+	if ChildProcessesKilled() {
+		// the after func runs in its own goroutine, the command must not be started before it
+		cancelCmd()
+	}
+	cancelTimeout := cancel
+	cancel = func() {
+		stopKill()
+		cancelCmd()
+		cancelTimeout()
 	}
 	cmd := exec.CommandContext(newCtx, name, arg...)
+	if name == "git" && impl.gitBinary.IsAvailable() {
+		// the executable is the one detected at startup, the command is still shown as git
+		cmd.Path, cmd.Err = impl.gitBinary.Path, nil
+	} else if name == "git" {
+		// the command fails on run with it, instead of the error of the lookup of the executable
+		cmd.Err = fmt.Errorf("%w, git %s", ErrGitBinaryRequired, getSubCommand(cmd.Args))
+	}
+	setProcessGroupKill(cmd)
 	return cmd, cancel
 }
 
-func (impl *GitManagerBaseImpl) getCommandTimeout(command string) int {
-	timeout := impl.conf.CliCmdTimeoutGlobal
+// getCommandTimeout returns the timeout of the command in CLI_CMD_TIMEOUT_JSON, else the one of its operation class,
+// else the global timeout
+func (impl *GitManagerBaseImpl) getCommandTimeout(gitCtx GitContext, command string) int {
 	if cmdTimeout, ok := impl.commandTimeoutMap[command]; ok {
-		timeout = cmdTimeout
+		return cmdTimeout
+	}
+	var classTimeout int
+	switch getOperationClass(gitCtx, command) {
+	case GIT_OPERATION_CLONE:
+		classTimeout = impl.conf.CliCloneTimeout
+	case GIT_OPERATION_FETCH:
+		classTimeout = impl.conf.CliFetchTimeout
+	case GIT_OPERATION_LOG:
+		classTimeout = impl.conf.CliLogTimeout
+	}
+	if classTimeout > 0 {
+		return classTimeout
+	}
+	return impl.conf.CliCmdTimeoutGlobal
+}
+
+func (impl *GitManagerBaseImpl) RemoveLockFilesOfKilledCommands(rootDir string) ([]string, error) {
+	var removed []string
+	for _, lockPath := range impl.killedCmdLockFiles.take(GetGitDir(rootDir)) {
+		err := os.Remove(lockPath)
+		if err != nil && !os.IsNotExist(err) {
+			impl.logger.Errorw("error in removing lock file of killed git command", "lockFile", lockPath, "err", err)
+			return removed, err
+		}
+		removed = append(removed, lockPath)
+	}
+	if len(removed) > 0 {
+		impl.logger.Infow("removed lock files of killed git commands", "rootDir", rootDir, "lockFiles", removed)
+	}
+	return removed, nil
+}
+
+func (impl *GitManagerBaseImpl) StreamCustomCommand(gitContext GitContext, name string, arg ...string) (stdout io.ReadCloser, wait func() (errMsg string, err error), err error) {
+	cmd, cancel := impl.createCmdWithContext(gitContext, name, arg...)
+	setCredEnv(cmd, gitContext.Username, gitContext.Password, nil)
+	cmd.Env = append(cmd.Env, impl.getCommandEnv()...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	start := time.Now()
+	subCommand := getSubCommand(cmd.Args)
+	_, span := tracing.StartSpan(gitContext, "git "+subCommand, tracing.ATTRIBUTE_COMMAND.String(subCommand))
+	wait = func() (string, error) {
+		defer cancel()
+		waitErr := cmd.Wait()
+		secrets := append(getCommandSecrets(cmd), gitContext.Password)
+		errOutput := SanitizeText(stderr.String(), secrets...)
+		err := SanitizeError(ClassifyGitError(errOutput, waitErr), secrets...)
+		util.TriggerGitCommandMetrics(subCommand, start, err)
+		tracing.EndSpan(span, err)
+		if err != nil {
+			impl.logger.Errorw("error in git cli operation", "args", SanitizeArgs(cmd.Args, secrets...), "msg", errOutput, "err", err)
+		}
+		return errOutput, err
 	}
-	return timeout
+	return stdout, wait, nil
 }
 
 func (impl *GitManagerBaseImpl) ExecuteCustomCommand(gitContext GitContext, name string, arg ...string) (response, errMsg string, err error) {
@@ -392,6 +841,6 @@ func (impl *GitManagerBaseImpl) ExecuteCustomCommand(gitContext GitContext, name
 		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
 	}
 	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
-	output, errMsg, err := impl.runCommandWithCred(cmd, gitContext.Username, gitContext.Password, tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitContext, cmd, gitContext.Username, gitContext.Password, tlsPathInfo)
 	return output, errMsg, err
 }
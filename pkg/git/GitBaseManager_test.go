@@ -0,0 +1,486 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runTestGitCmd runs git in dir with a fixed identity, failing the test on error
+func runTestGitCmd(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=devtron", "-c", "user.email=devtron@devtron.ai", "-C", dir}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	assert.Nil(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// setupTestRemote creates a bare remote with a main branch and a working clone to push from
+func setupTestRemote(t *testing.T) (remoteDir string, workDir string) {
+	baseDir := t.TempDir()
+	remoteDir = filepath.Join(baseDir, "remote.git")
+	workDir = filepath.Join(baseDir, "work")
+	runTestGitCmd(t, baseDir, "init", "--bare", "-b", "main", remoteDir)
+	runTestGitCmd(t, baseDir, "clone", remoteDir, workDir)
+	runTestGitCmd(t, workDir, "checkout", "-b", "main")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "first")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "second")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	return remoteDir, workDir
+}
+
+func getTestRepositoryManager(t *testing.T) *RepositoryManagerImpl {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	conf := &internals.Configuration{UseGitCli: true, GitHistoryCount: 15}
+	return NewRepositoryManagerImpl(logger, conf, NewGitManagerImpl(logger, conf))
+}
+
+func TestRepositoryManager_GetBranchState(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	lastSeenHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	t.Run("fast forward", func(t *testing.T) {
+		runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "third")
+		runTestGitCmd(t, workDir, "push", "origin", "main")
+		_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+		assert.Nil(t, err)
+		deleted, forcePushed, err := repositoryManager.GetBranchState(gitCtx, checkoutPath, "main", lastSeenHash)
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+		assert.False(t, forcePushed)
+	})
+
+	t.Run("force push", func(t *testing.T) {
+		previousHead := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+		runTestGitCmd(t, workDir, "reset", "--hard", "HEAD~2")
+		runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "rewritten")
+		runTestGitCmd(t, workDir, "push", "--force", "origin", "main")
+		_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+		assert.Nil(t, err)
+		deleted, forcePushed, err := repositoryManager.GetBranchState(gitCtx, checkoutPath, "main", lastSeenHash)
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+		assert.True(t, forcePushed)
+		orphanedCommits, err := repositoryManager.GetOrphanedCommits(gitCtx, checkoutPath, "main", previousHead)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{previousHead, lastSeenHash}, orphanedCommits)
+	})
+
+	t.Run("deleted branch", func(t *testing.T) {
+		runTestGitCmd(t, workDir, "push", "origin", "main:feature")
+		_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+		assert.Nil(t, err)
+		deleted, _, err := repositoryManager.GetBranchState(gitCtx, checkoutPath, "feature", "")
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+
+		runTestGitCmd(t, workDir, "push", "origin", "--delete", "feature")
+		_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+		assert.Nil(t, err)
+		deleted, _, err = repositoryManager.GetBranchState(gitCtx, checkoutPath, "refs/heads/feature", "")
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+	})
+}
+
+func TestGitManagerBaseImpl_createCmdWithContext(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{})
+
+	t.Run("extra git config is scoped before the sub command", func(t *testing.T) {
+		gitCtx := BuildGitContext(context.Background()).WithExtraGitConfig(map[string]string{
+			"http.postBuffer":   "524288000",
+			"pack.windowMemory": "100m",
+		})
+		cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", "/tmp/repo", "fetch", "origin")
+		defer cancel()
+		assert.Equal(t, []string{"git", "-c", "http.postBuffer=524288000", "-c", "pack.windowMemory=100m", "-C", "/tmp/repo", "fetch", "origin"}, cmd.Args)
+	})
+
+	t.Run("invalid and command executing keys are skipped", func(t *testing.T) {
+		gitCtx := BuildGitContext(context.Background()).WithExtraGitConfig(map[string]string{
+			"--upload-pack=touch /tmp/x": "",
+			"core.sshCommand":            "touch /tmp/x",
+			"fetch.negotiationAlgorithm": "skipping",
+			"http.extraHeader":           "Authorization: Bearer token\n",
+		})
+		cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", "/tmp/repo", "fetch", "origin")
+		defer cancel()
+		assert.Equal(t, []string{"git", "-c", "fetch.negotiationAlgorithm=skipping", "-C", "/tmp/repo", "fetch", "origin"}, cmd.Args)
+	})
+
+	t.Run("proxy and insecure tls of the remote", func(t *testing.T) {
+		gitCtx := BuildGitContext(context.Background()).WithProxy("http://proxy:3128").WithInsecureSkipTLS(true)
+		cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", "/tmp/repo", "fetch", "origin")
+		defer cancel()
+		assert.Equal(t, []string{"git", "-c", "http.sslVerify=false", "-c", "http.proxy=http://proxy:3128", "-C", "/tmp/repo", "fetch", "origin"}, cmd.Args)
+	})
+
+	t.Run("no extra git config", func(t *testing.T) {
+		cmd, cancel := impl.createCmdWithContext(BuildGitContext(context.Background()), "git", "-C", "/tmp/repo", "fetch", "origin")
+		defer cancel()
+		assert.Equal(t, []string{"git", "-C", "/tmp/repo", "fetch", "origin"}, cmd.Args)
+	})
+
+	t.Run("git config of git-sensor comes after the one of the material", func(t *testing.T) {
+		impl := NewGitManagerBaseImpl(logger, &internals.Configuration{DeterministicGitConfig: true, GitSafeDirectory: "*"})
+		gitCtx := BuildGitContext(context.Background()).WithExtraGitConfig(map[string]string{"core.autocrlf": "input", "core.compression": "9"}).
+			WithProxy("http://proxy:3128")
+		cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", "/tmp/repo", "fetch", "origin")
+		defer cancel()
+		assert.Equal(t, []string{"git", "-c", "core.compression=9", "-c", "core.autocrlf=false", "-c", "core.longpaths=true", "-c", "safe.directory=*",
+			"-c", "http.proxy=http://proxy:3128", "-C", "/tmp/repo", "fetch", "origin"}, cmd.Args)
+		assert.Equal(t, "fetch", getSubCommand(cmd.Args))
+	})
+}
+
+func TestValidateGitConfig(t *testing.T) {
+	assert.Nil(t, ValidateGitConfig(map[string]string{"core.compression": "9", "http.postBuffer": "524288000"}))
+	assert.True(t, errors.Is(ValidateGitConfig(map[string]string{"core.sshCommand": "touch /tmp/x"}), ErrInvalidGitConfig))
+	assert.True(t, errors.Is(ValidateGitConfig(map[string]string{"remote.origin.uploadpack": "touch /tmp/x"}), ErrInvalidGitConfig))
+	assert.True(t, errors.Is(ValidateGitConfig(map[string]string{"http.lowSpeedTime": "6\n0"}), ErrInvalidGitConfig))
+}
+
+func TestGitCliManager_ShallowFetchAndDeepen(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, remoteDir, "config", "uploadpack.allowFilter", "true")
+	for i := 0; i < 4; i++ {
+		runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "commit")
+	}
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	conf := &internals.Configuration{UseGitCli: true, ShallowCloneDepth: 2, ShallowDeepenBy: 2, PartialCloneFilter: "blob:none"}
+	gitManager := NewGitManagerImpl(logger, conf)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+
+	assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, "file://"+remoteDir, true))
+	assert.Equal(t, "blob:none", runTestGitCmd(t, checkoutPath, "config", "remote.origin.partialclonefilter"))
+	_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	assert.True(t, IsShallowRepository(checkoutPath))
+	assert.Equal(t, "2", runTestGitCmd(t, checkoutPath, "rev-list", "--count", "origin/main"))
+
+	// later fetches keep the shallow boundary
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "new commit")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "3", runTestGitCmd(t, checkoutPath, "rev-list", "--count", "origin/main"))
+
+	// a failure which is not of the shallow boundary, like a missing branch, doesn't deepen the repo
+	cliManager := gitManager.GitManager.(*GitCliManagerImpl)
+	_, err = cliManager.GetCommits(gitCtx, "refs/remotes/origin/missing", "missing", checkoutPath, 5, "", "")
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrShallowRangeUnavailable))
+	assert.Equal(t, "3", runTestGitCmd(t, checkoutPath, "rev-list", "--count", "origin/main"))
+
+	repository, err := gitManager.OpenRepoPlain(checkoutPath)
+	assert.Nil(t, err)
+	iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 5})
+	assert.Nil(t, err)
+	count := 0
+	for commit, err := iterator.Next(); err == nil && commit != nil; commit, err = iterator.Next() {
+		count++
+	}
+	assert.Equal(t, 5, count)
+
+	// from^ is the root commit, which is beyond the shallow boundary
+	secondCommit := runTestGitCmd(t, workDir, "rev-parse", "HEAD~5")
+	iterator, err = gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 15, FromCommitHash: secondCommit})
+	assert.Nil(t, err)
+	count = 0
+	for commit, err := iterator.Next(); err == nil && commit != nil; commit, err = iterator.Next() {
+		count++
+	}
+	assert.Equal(t, 6, count)
+}
+
+func TestIsValidGitConfigKey(t *testing.T) {
+	assert.True(t, IsValidGitConfigKey("http.postBuffer"))
+	assert.True(t, IsValidGitConfigKey("http.postbuffer"))
+	assert.True(t, IsValidGitConfigKey("fetch.negotiationAlgorithm"))
+	assert.False(t, IsValidGitConfigKey("gpg.ssh.allowedSignersFile"))
+	assert.False(t, IsValidGitConfigKey("gpg.program"))
+	assert.False(t, IsValidGitConfigKey("core.sshCommand"))
+	assert.False(t, IsValidGitConfigKey("remote.origin.uploadpack"))
+	assert.False(t, IsValidGitConfigKey("core.gitProxy"))
+	assert.False(t, IsValidGitConfigKey("credential.https://github.com.helper"))
+	assert.False(t, IsValidGitConfigKey("protocol.ext.allow"))
+	assert.False(t, IsValidGitConfigKey("core.alternateRefsCommand"))
+	assert.False(t, IsValidGitConfigKey("uploadpack.packObjectsHook"))
+	assert.False(t, IsValidGitConfigKey("url.https://evil.com/.insteadOf"))
+	assert.False(t, IsValidGitConfigKey("http.proxy"))
+	assert.False(t, IsValidGitConfigKey("http.sslVerify"))
+	assert.False(t, IsValidGitConfigKey("http.https://github.com.postBuffer"))
+}
+
+func TestRepositoryManager_ListBranches(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, workDir, "push", "origin", "main:release/1.0", "main~1:refs/heads/feature")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	branches, err := repositoryManager.ListBranches(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	branchHeads := make(map[string]string)
+	for _, branch := range branches {
+		branchHeads[branch.Name] = branch.Commit
+	}
+	assert.Equal(t, map[string]string{
+		"main":        runTestGitCmd(t, workDir, "rev-parse", "main"),
+		"release/1.0": runTestGitCmd(t, workDir, "rev-parse", "main"),
+		"feature":     runTestGitCmd(t, workDir, "rev-parse", "main~1"),
+	}, branchHeads)
+}
+
+func TestRepositoryManager_GetFileContentAtCommit(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "Dockerfile"), []byte("FROM alpine\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "large.txt"), []byte(strings.Repeat("a", 64)), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add files")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	headHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	repositoryManager := getTestRepositoryManager(t)
+	repositoryManager.configuration.FileContentMaxSizeInBytes = 32
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	fileContent, err := repositoryManager.GetFileContentAtCommit(gitCtx, checkoutPath, headHash, "/Dockerfile")
+	assert.Nil(t, err)
+	assert.Equal(t, "FROM alpine\n", string(fileContent.Content))
+	assert.False(t, fileContent.Binary)
+
+	fileContent, err = repositoryManager.GetFileContentAtCommit(gitCtx, checkoutPath, headHash, "logo.png")
+	assert.Nil(t, err)
+	assert.True(t, fileContent.Binary)
+	assert.Equal(t, int64(6), fileContent.Size)
+
+	_, err = repositoryManager.GetFileContentAtCommit(gitCtx, checkoutPath, headHash, "large.txt")
+	assert.NotNil(t, err)
+	_, err = repositoryManager.GetFileContentAtCommit(gitCtx, checkoutPath, headHash+"~1", "Dockerfile")
+	assert.NotNil(t, err)
+}
+
+func TestGitManagerBaseImpl_WithoutGitBinary(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{})
+	impl.gitBinary = &GitBinary{Path: "git"}
+	_, _, err = impl.GarbageCollect(BuildGitContext(context.Background()), t.TempDir())
+	assert.ErrorIs(t, err, ErrGitBinaryRequired)
+	_, _, err = impl.ExecuteCustomCommand(BuildGitContext(context.Background()), "true")
+	assert.Nil(t, err)
+}
+
+func TestGetFetchCmdArgs(t *testing.T) {
+	assert.Equal(t, []string{"-C", "/tmp/repo", "fetch", "origin", "--tags", "--force", "--prune"},
+		getFetchCmdArgs("/tmp/repo", 0, false, false, false, ""))
+	// tags deleted at remote are pruned only when they are tracked
+	assert.Equal(t, []string{"-C", "/tmp/repo", "fetch", "origin", "--tags", "--force", "--prune", "--prune-tags", "--depth", "5"},
+		getFetchCmdArgs("/tmp/repo", 5, false, false, true, ""))
+}
+
+func TestGetSubCommand(t *testing.T) {
+	assert.Equal(t, "fetch", getSubCommand([]string{"git", "-c", "http.proxy=http://proxy:3128", "-C", "/tmp/repo", "fetch", "origin", "--prune"}))
+	assert.Equal(t, "log", getSubCommand([]string{"/usr/bin/git", "--no-pager", "log", "-n", "1"}))
+	assert.Equal(t, "ssh-keygen", getSubCommand([]string{"/usr/bin/ssh-keygen", "-l"}))
+	assert.Equal(t, "git", getSubCommand([]string{"git", "-C", "/tmp/repo"}))
+}
+
+func TestGitManagerBaseImpl_getCommandTimeout(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{CliCmdTimeoutGlobal: 60, CliCmdTimeoutJson: `{"ls-remote": 5}`,
+		CliCloneTimeout: 600, CliFetchTimeout: 120})
+	gitCtx := BuildGitContext(context.Background())
+	assert.Equal(t, 120, impl.getCommandTimeout(gitCtx, "fetch"))
+	assert.Equal(t, 600, impl.getCommandTimeout(gitCtx.WithClone(), "fetch"))
+	assert.Equal(t, 5, impl.getCommandTimeout(gitCtx, "ls-remote"))
+	assert.Equal(t, 60, impl.getCommandTimeout(gitCtx, "log"))
+	assert.Equal(t, 60, impl.getCommandTimeout(gitCtx, "rev-parse"))
+}
+
+func TestGitManagerBaseImpl_runCommandKillsProcessGroup(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{CliCmdTimeoutGlobal: 1})
+	gitCtx := BuildGitContext(context.Background())
+	// the background sleep keeps the output pipe open until the whole group is killed
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "sh", "-c", "sleep 30 & wait")
+	defer cancel()
+	start := time.Now()
+	_, _, err = impl.runCommand(gitCtx, cmd)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Less(t, time.Since(start), PROCESS_WAIT_DELAY)
+}
+
+func TestGitManagerBaseImpl_KillChildProcesses(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{CliCmdTimeoutGlobal: 30})
+	tests := []struct {
+		name       string
+		script     string
+		killBefore bool
+		killAfter  time.Duration // kill while the command runs, not killed when zero
+		wantErr    bool
+	}{
+		{name: "command not killed", script: "echo done"},
+		{name: "running command is killed with its group", script: "sleep 30 & wait", killAfter: 200 * time.Millisecond, wantErr: true},
+		{name: "command started after the kill is not run", script: "echo done", killBefore: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			childProcessCtx, killChildProcesses = context.WithCancel(context.Background())
+			t.Cleanup(func() {
+				childProcessCtx, killChildProcesses = context.WithCancel(context.Background())
+			})
+			if tt.killBefore {
+				KillChildProcesses()
+			}
+			gitCtx := BuildGitContext(context.Background())
+			cmd, cancel := impl.createCmdWithContext(gitCtx, "sh", "-c", tt.script)
+			defer cancel()
+			if tt.killAfter > 0 {
+				time.AfterFunc(tt.killAfter, KillChildProcesses)
+			}
+			start := time.Now()
+			output, _, err := impl.runCommand(gitCtx, cmd)
+			assert.Less(t, time.Since(start), PROCESS_WAIT_DELAY)
+			assert.Equal(t, tt.killBefore || tt.killAfter > 0, ChildProcessesKilled())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "done", strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestGitManagerBaseImpl_RemoveLockFilesOfKilledCommands(t *testing.T) {
+	_, workDir := setupTestRemote(t)
+	gitDir := filepath.Join(workDir, ".git")
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{CliCmdTimeoutGlobal: 1})
+	gitCtx := BuildGitContext(context.Background())
+	// a lock file of another command is not the one of the killed fetch
+	otherLockFile := filepath.Join(gitDir, "index.lock")
+	assert.Nil(t, os.WriteFile(otherLockFile, nil, 0644))
+	killedLockFile := filepath.Join(gitDir, "refs", "heads", "killed.lock")
+	sshCommand := fmt.Sprintf("sh -c 'touch %s; sleep 30'", killedLockFile)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", workDir, "-c", "core.sshCommand="+sshCommand, "fetch", "ssh://git@localhost/repo.git")
+	defer cancel()
+	_, _, err = impl.runCommand(gitCtx, cmd)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.FileExists(t, killedLockFile)
+
+	removed, err := impl.RemoveLockFilesOfKilledCommands(workDir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{killedLockFile}, removed)
+	assert.NoFileExists(t, killedLockFile)
+	assert.FileExists(t, otherLockFile)
+	removed, err = impl.RemoveLockFilesOfKilledCommands(workDir)
+	assert.Nil(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestRepositoryManager_RecoverCheckout(t *testing.T) {
+	if _, err := os.Stat(GIT_BASE_DIR); err != nil {
+		t.Skip("free space of the git base dir is checked before cloning")
+	}
+	remoteDir, _ := setupTestRemote(t)
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(checkoutPath, ".git", "HEAD"), []byte("garbage"), 0644))
+	_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.True(t, errors.Is(err, ErrRepoCorrupted))
+
+	assert.Nil(t, repositoryManager.RecoverCheckout(gitCtx, 0, checkoutPath, remoteDir, "", ""))
+	_, _, err = repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	assert.DirExists(t, checkoutPath+QUARANTINE_DIR_SUFFIX)
+	assert.NoDirExists(t, checkoutPath+RECOVERY_DIR_SUFFIX)
+}
+
+func TestRepositoryManager_FetchCommit(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	// a pull request head which is no longer on any branch of the remote
+	runTestGitCmd(t, workDir, "checkout", "-b", "feature")
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "feature")
+	prHead := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	runTestGitCmd(t, workDir, "push", "origin", "feature")
+	runTestGitCmd(t, workDir, "push", "origin", "--delete", "feature")
+	runTestGitCmd(t, remoteDir, "config", "uploadpack.allowAnySHA1InWant", "true")
+
+	_, err = repositoryManager.GetCommitMetadata(gitCtx, checkoutPath, prHead)
+	assert.NotNil(t, err)
+	assert.Nil(t, repositoryManager.FetchCommit(gitCtx, checkoutPath, prHead))
+	commit, err := repositoryManager.GetCommitMetadata(gitCtx, checkoutPath, prHead)
+	assert.Nil(t, err)
+	assert.Equal(t, "feature", strings.TrimSpace(commit.Message))
+
+	// commits already in the checkout are not fetched again
+	assert.Nil(t, repositoryManager.FetchCommit(gitCtx, checkoutPath, prHead))
+
+	assert.NotNil(t, repositoryManager.FetchCommit(gitCtx, checkoutPath, prHead[:7]))
+	assert.NotNil(t, repositoryManager.FetchCommit(gitCtx, checkoutPath, strings.Repeat("a", 40)))
+}
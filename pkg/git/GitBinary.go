@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	GIT_FEATURE_PARTIAL_CLONE = "partial-clone"
+)
+
+// gitFeatureMinVersions are the git versions the features need, they are disabled with older versions
+var gitFeatureMinVersions = map[string]*GitVersion{
+	GIT_FEATURE_PARTIAL_CLONE: {Major: 2, Minor: 22},
+}
+
+// ErrGitBinaryRequired is returned by the operations run with the git cli when there is no git binary in the container
+var ErrGitBinaryRequired = errors.New("git binary is required for this operation")
+
+var gitVersionRegex = regexp.MustCompile(`^(?:git version )?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+type GitVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseGitVersion parses the output of git version, like git version 2.39.5 or git version 2.37.1 (Apple Git-137.1),
+// or a bare version like 2.20
+func ParseGitVersion(version string) (*GitVersion, error) {
+	matches := gitVersionRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, fmt.Errorf("unable to parse git version %q", version)
+	}
+	gitVersion := &GitVersion{}
+	gitVersion.Major, _ = strconv.Atoi(matches[1])
+	gitVersion.Minor, _ = strconv.Atoi(matches[2])
+	if len(matches[3]) > 0 {
+		gitVersion.Patch, _ = strconv.Atoi(matches[3])
+	}
+	return gitVersion, nil
+}
+
+func (version *GitVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+}
+
+func (version *GitVersion) IsBefore(other *GitVersion) bool {
+	if version.Major != other.Major {
+		return version.Major < other.Major
+	}
+	if version.Minor != other.Minor {
+		return version.Minor < other.Minor
+	}
+	return version.Patch < other.Patch
+}
+
+// GitBinary is the git executable the cli commands are run with, it is detected once at startup. Version is nil when
+// git is not found
+type GitBinary struct {
+	Path    string
+	Version *GitVersion
+}
+
+// DetectGitBinary resolves the git executable at GIT_BINARY_PATH, or the one in PATH when not set, and reads its version
+func DetectGitBinary(binaryPath string) (*GitBinary, error) {
+	if len(binaryPath) == 0 {
+		binaryPath = "git"
+	}
+	gitBinary := &GitBinary{Path: binaryPath}
+	resolvedPath, err := exec.LookPath(binaryPath)
+	if err != nil {
+		return gitBinary, err
+	}
+	gitBinary.Path = resolvedPath
+	output, err := exec.Command(resolvedPath, "version").Output()
+	if err != nil {
+		return gitBinary, err
+	}
+	gitBinary.Version, err = ParseGitVersion(string(output))
+	return gitBinary, err
+}
+
+func (gitBinary *GitBinary) IsAvailable() bool {
+	return gitBinary.Version != nil
+}
+
+func (gitBinary *GitBinary) GetVersion() string {
+	if gitBinary.Version == nil {
+		return ""
+	}
+	return gitBinary.Version.String()
+}
+
+// Supports tells whether the version of git is recent enough for the feature
+func (gitBinary *GitBinary) Supports(feature string) bool {
+	minVersion, found := gitFeatureMinVersions[feature]
+	return !found || gitBinary.Version == nil || !gitBinary.Version.IsBefore(minVersion)
+}
+
+// GetDisabledFeatures returns the features which are not used as the version of git is too old for them
+func (gitBinary *GitBinary) GetDisabledFeatures() []string {
+	var features []string
+	for feature := range gitFeatureMinVersions {
+		if !gitBinary.Supports(feature) {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// CheckMinVersion fails when the version of git is older than MIN_GIT_VERSION
+func (gitBinary *GitBinary) CheckMinVersion(minVersion string) error {
+	if len(minVersion) == 0 || gitBinary.Version == nil {
+		return nil
+	}
+	requiredVersion, err := ParseGitVersion(minVersion)
+	if err != nil {
+		return fmt.Errorf("invalid MIN_GIT_VERSION: %w", err)
+	}
+	if gitBinary.Version.IsBefore(requiredVersion) {
+		return fmt.Errorf("git %s at %s is older than the minimum supported version %s", gitBinary.Version, gitBinary.Path, requiredVersion)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	tests := map[string]*GitVersion{
+		"git version 2.39.5\n":                 {Major: 2, Minor: 39, Patch: 5},
+		"git version 2.37.1 (Apple Git-137.1)": {Major: 2, Minor: 37, Patch: 1},
+		"git version 2.45.2.windows.1":         {Major: 2, Minor: 45, Patch: 2},
+		"2.20":                                 {Major: 2, Minor: 20},
+	}
+	for version, want := range tests {
+		gitVersion, err := ParseGitVersion(version)
+		assert.Nil(t, err, version)
+		assert.Equal(t, want, gitVersion, version)
+	}
+	_, err := ParseGitVersion("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestGitBinary(t *testing.T) {
+	gitBinary := &GitBinary{Path: "git", Version: &GitVersion{Major: 2, Minor: 30, Patch: 1}}
+	assert.True(t, gitBinary.Supports(GIT_FEATURE_PARTIAL_CLONE))
+	assert.Empty(t, gitBinary.GetDisabledFeatures())
+	assert.Nil(t, gitBinary.CheckMinVersion("2.20.0"))
+	assert.Nil(t, gitBinary.CheckMinVersion("2.30.1"))
+	assert.NotNil(t, gitBinary.CheckMinVersion("2.30.2"))
+	assert.NotNil(t, gitBinary.CheckMinVersion("latest"))
+}
+
+func TestDetectGitBinary(t *testing.T) {
+	gitBinary, err := DetectGitBinary("")
+	assert.Nil(t, err)
+	assert.True(t, gitBinary.IsAvailable())
+	assert.True(t, filepath.IsAbs(gitBinary.Path))
+
+	// a wrapper script at GIT_BINARY_PATH is used in place of git
+	wrapper := filepath.Join(t.TempDir(), "git-wrapper")
+	assert.Nil(t, os.WriteFile(wrapper, []byte("#!/bin/sh\necho 'git version 2.19.0'\n"), 0755))
+	gitBinary, err = DetectGitBinary(wrapper)
+	assert.Nil(t, err)
+	assert.Equal(t, wrapper, gitBinary.Path)
+	assert.Equal(t, "2.19.0", gitBinary.GetVersion())
+	assert.Equal(t, []string{GIT_FEATURE_PARTIAL_CLONE}, gitBinary.GetDisabledFeatures())
+	assert.NotNil(t, gitBinary.CheckMinVersion("2.20.0"))
+
+	gitBinary, err = DetectGitBinary(filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+	assert.False(t, gitBinary.IsAvailable())
+}
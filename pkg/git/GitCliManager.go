@@ -18,6 +18,9 @@ package git
 
 import (
 	"errors"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
 	"go.uber.org/zap"
 	"gopkg.in/src-d/go-billy.v4/osfs"
 	"os"
@@ -29,13 +32,15 @@ import (
 type GitCliManagerImpl struct {
 	GitManagerBase
 	logger *zap.SugaredLogger
+	conf   *internals.Configuration
 }
 
-func NewGitCliManagerImpl(baseManager GitManagerBase, logger *zap.SugaredLogger) *GitCliManagerImpl {
+func NewGitCliManagerImpl(baseManager GitManagerBase, logger *zap.SugaredLogger, conf *internals.Configuration) *GitCliManagerImpl {
 
 	return &GitCliManagerImpl{
 		GitManagerBase: baseManager,
 		logger:         logger,
+		conf:           conf,
 	}
 }
 
@@ -45,6 +50,12 @@ const (
 	LOCK_REF_MESSAGE            = "cannot lock ref"
 )
 
+// errUnknownRevision is the failure of git log to resolve a revision of the range, in a shallow repo the revision may
+// be beyond the boundary
+var errUnknownRevision = errors.New("unknown revision")
+
+var unknownRevisionMessages = []string{"unknown revision", "bad revision", "bad object", "invalid object name"}
+
 func (impl *GitCliManagerImpl) Init(gitCtx GitContext, rootDir string, remoteUrl string, isBare bool) error {
 	//-----------------
 
@@ -53,12 +64,51 @@ func (impl *GitCliManagerImpl) Init(gitCtx GitContext, rootDir string, remoteUrl
 		return err
 	}
 
-	err = impl.GitInit(gitCtx, rootDir)
+	err = impl.GitInit(gitCtx, rootDir, isBare && impl.conf.UseBareRepo)
+	if err != nil {
+		return err
+	}
+	err = impl.GitCreateRemote(gitCtx, rootDir, remoteUrl)
 	if err != nil {
 		return err
 	}
-	return impl.GitCreateRemote(gitCtx, rootDir, remoteUrl)
+	if len(impl.conf.PartialCloneFilter) > 0 && impl.GitManagerBase.GetGitBinary().Supports(GIT_FEATURE_PARTIAL_CLONE) {
+		err = impl.configurePartialClone(gitCtx, rootDir, impl.conf.PartialCloneFilter)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// configurePartialClone marks origin as a promisor remote so that every fetch applies the filter
+// and the filtered out objects are fetched lazily when needed
+func (impl *GitCliManagerImpl) configurePartialClone(gitCtx GitContext, rootDir string, filter string) error {
+	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "config", "remote.origin.promisor", "true")
+	if err != nil {
+		impl.logger.Errorw("error in configuring promisor remote", "rootDir", rootDir, "opt", output, "errMsg", errMsg, "err", err)
+		return err
+	}
+	output, errMsg, err = impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "config", "remote.origin.partialclonefilter", filter)
+	if err != nil {
+		impl.logger.Errorw("error in configuring partial clone filter", "rootDir", rootDir, "filter", filter, "opt", output, "errMsg", errMsg, "err", err)
+		return err
+	}
+	return nil
+}
+
+// Fetch limits the first fetch of a repo to SHALLOW_CLONE_DEPTH commits, later fetches of a shallow repo
+// only bring in the new commits and keep the shallow boundary as is
+func (impl *GitCliManagerImpl) Fetch(gitCtx GitContext, rootDir string) (response, errMsg string, err error) {
+	if impl.conf.ShallowCloneDepth > 0 && !IsShallowRepository(rootDir) && !impl.hasRefs(gitCtx, rootDir) {
+		gitCtx = gitCtx.WithFetchDepth(impl.conf.ShallowCloneDepth)
+	}
+	return impl.GitManagerBase.Fetch(gitCtx, rootDir)
+}
+
+func (impl *GitCliManagerImpl) hasRefs(gitCtx GitContext, rootDir string) bool {
+	output, _, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "for-each-ref", "--count=1")
+	return err != nil || len(strings.TrimSpace(output)) > 0
 }
 
 func (impl *GitCliManagerImpl) OpenRepoPlain(checkoutPath string) (*GitRepository, error) {
@@ -73,7 +123,8 @@ func (impl *GitCliManagerImpl) OpenRepoPlain(checkoutPath string) (*GitRepositor
 }
 
 func (impl *GitCliManagerImpl) GetCommitsForTag(gitCtx GitContext, checkoutPath, tag string) (GitCommit, error) {
-	return impl.GitShow(gitCtx, checkoutPath, tag)
+	// peel annotated tags, git show would otherwise print the tag object before the commit
+	return impl.GitShow(gitCtx, checkoutPath, "refs/tags/"+tag+"^{commit}")
 }
 
 func (impl *GitCliManagerImpl) GetCommitForHash(gitCtx GitContext, checkoutPath, commitHash string) (GitCommit, error) {
@@ -81,8 +132,12 @@ func (impl *GitCliManagerImpl) GetCommitForHash(gitCtx GitContext, checkoutPath,
 	return impl.GitShow(gitCtx, checkoutPath, commitHash)
 }
 func (impl *GitCliManagerImpl) GetCommitIterator(gitCtx GitContext, repository *GitRepository, iteratorRequest IteratorRequest) (CommitIterator, error) {
+	// shallow repos need the whole result to decide whether to deepen, so they are not streamed
+	if impl.conf.UseStreamingGitLog && !IsShallowRepository(repository.rootDir) {
+		return impl.getCommitStreamIterator(gitCtx, repository.rootDir, iteratorRequest)
+	}
 
-	commits, err := impl.GetCommits(gitCtx, iteratorRequest.BranchRef, iteratorRequest.Branch, repository.rootDir, iteratorRequest.CommitCount, iteratorRequest.FromCommitHash, iteratorRequest.ToCommitHash)
+	commits, err := impl.getCommitsForRequest(gitCtx, repository.rootDir, iteratorRequest)
 	if err != nil {
 		impl.logger.Errorw("error in fetching commits for", "err", err, "path", repository.rootDir)
 		return nil, err
@@ -103,25 +158,86 @@ func openGitRepo(path string) error {
 	}
 	return nil
 }
-func (impl *GitCliManagerImpl) GitInit(gitCtx GitContext, rootDir string) error {
-	impl.logger.Debugw("git", "-C", rootDir, "init")
-	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "init")
+
+// GitInit creates a bare repo when isBare is set, polling only needs the objects and refs and never the working tree
+func (impl *GitCliManagerImpl) GitInit(gitCtx GitContext, rootDir string, isBare bool) error {
+	args := []string{"-C", rootDir, "init"}
+	if isBare {
+		args = append(args, "--bare")
+	}
+	impl.logger.Debugw("git", args)
+	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", args...)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	return err
 }
 
 func (impl *GitCliManagerImpl) GitCreateRemote(gitCtx GitContext, rootDir string, url string) error {
-	impl.logger.Debugw("git", "-C", rootDir, "remote", "add", "origin", url)
+	impl.logger.Debugw("git", "-C", rootDir, "remote", "add", "origin", tracing.SanitizeUrl(url))
 	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "remote", "add", "origin", url)
-	impl.logger.Debugw("url", url, "opt", output, "errMsg", errMsg, "error", err)
+	impl.logger.Debugw("url", tracing.SanitizeUrl(url), "opt", output, "errMsg", errMsg, "error", err)
 	return err
 }
 
 func (impl *GitCliManagerImpl) GetCommits(gitCtx GitContext, branchRef string, branch string, rootDir string, numCommits int, from string, to string) ([]GitCommit, error) {
-	baseCmdArgs := []string{"-C", rootDir, "log"}
-	rangeCmdArgs := []string{branchRef}
-	extraCmdArgs := []string{"-n", strconv.Itoa(numCommits), "--date=iso-strict", GITFORMAT}
-	cmdArgs := impl.getCommandForLogRange(branchRef, from, to, rangeCmdArgs, baseCmdArgs, extraCmdArgs)
+	return impl.getCommitsForRequest(gitCtx, rootDir, IteratorRequest{
+		BranchRef:      branchRef,
+		Branch:         branch,
+		CommitCount:    numCommits,
+		FromCommitHash: from,
+		ToCommitHash:   to,
+	})
+}
+
+// getCommitsForRequest deepens a shallow repo until the requested commits are available or the full history is fetched
+func (impl *GitCliManagerImpl) getCommitsForRequest(gitCtx GitContext, rootDir string, iteratorRequest IteratorRequest) ([]GitCommit, error) {
+	commits, err := impl.getCommits(gitCtx, rootDir, iteratorRequest)
+	for attempt := 0; attempt < MAX_DEEPEN_ATTEMPTS && IsShallowRepository(rootDir) && impl.isBeyondShallowBoundary(commits, err, iteratorRequest); attempt++ {
+		impl.logger.Infow("requested commits are beyond shallow boundary, deepening repo", "rootDir", rootDir, "branch", iteratorRequest.Branch, "from", iteratorRequest.FromCommitHash, "attempt", attempt)
+		unlockStore := impl.GitManagerBase.GetWorktreeStores().LockStoreOf(rootDir)
+		_, errMsg, deepenErr := impl.GitManagerBase.Deepen(gitCtx, rootDir, impl.conf.ShallowDeepenBy)
+		unlockStore()
+		if deepenErr != nil {
+			impl.logger.Errorw("error in deepening shallow repo", "rootDir", rootDir, "errMsg", errMsg, "err", deepenErr)
+			break
+		}
+		commits, err = impl.getCommits(gitCtx, rootDir, iteratorRequest)
+	}
+	if errors.Is(err, errUnknownRevision) && IsShallowRepository(rootDir) {
+		return commits, &GitError{Kind: ErrShallowRangeUnavailable, Err: err}
+	}
+	return commits, err
+}
+
+func (impl *GitCliManagerImpl) isBeyondShallowBoundary(commits []GitCommit, err error, iteratorRequest IteratorRequest) bool {
+	if err != nil {
+		// from^ is an unknown revision when from is the boundary commit or is beyond it, other failures, like a missing
+		// branch, are not fixed by deepening
+		return errors.Is(err, errUnknownRevision) && (len(iteratorRequest.FromCommitHash) > 0 || len(iteratorRequest.ToCommitHash) > 0)
+	}
+	// with a path filter or time window fewer commits are expected, so only the error above is a reliable signal
+	if len(commits) >= iteratorRequest.CommitCount || iteratorRequest.IsPathFiltered() || iteratorRequest.IsTimeWindowed() {
+		return false
+	}
+	// with a partial clone missing commits are fetched lazily, the walk then stops at the boundary without an error
+	from := iteratorRequest.FromCommitHash
+	for _, commit := range commits {
+		if len(from) > 0 && strings.HasPrefix(commit.GetCommit().Commit, from) {
+			return false
+		}
+	}
+	return true
+}
+
+func (impl *GitCliManagerImpl) getCommits(gitCtx GitContext, rootDir string, iteratorRequest IteratorRequest) ([]GitCommit, error) {
+	baseCmdArgs := append(append([]string{"-C", rootDir}, getMailmapBlobArgs(rootDir, iteratorRequest.BranchRef)...), "log")
+	rangeCmdArgs := []string{iteratorRequest.BranchRef}
+	extraCmdArgs := []string{"-n", strconv.Itoa(iteratorRequest.CommitCount), "--date=iso-strict", GITFORMAT}
+	if iteratorRequest.FirstParent {
+		extraCmdArgs = append(extraCmdArgs, "--first-parent")
+	}
+	extraCmdArgs = append(extraCmdArgs, iteratorRequest.getTimeWindowArgs()...)
+	pathspecArgs := getPathspecArgs(iteratorRequest.IncludePaths, iteratorRequest.ExcludePaths)
+	cmdArgs := impl.getCommandForLogRange(iteratorRequest.BranchRef, iteratorRequest.FromCommitHash, iteratorRequest.ToCommitHash, rangeCmdArgs, baseCmdArgs, extraCmdArgs, pathspecArgs)
 	impl.logger.Debugw("git", cmdArgs)
 	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
@@ -129,6 +245,9 @@ func (impl *GitCliManagerImpl) GetCommits(gitCtx GitContext, branchRef string, b
 		if strings.Contains(output, NO_COMMIT_GIT_ERROR_MESSAGE) {
 			return nil, errors.New(NO_COMMIT_CUSTOM_ERROR_MESSAGE)
 		}
+		if isUnknownRevisionOutput(output) {
+			return nil, fmt.Errorf("%w: %w", errUnknownRevision, err)
+		}
 		return nil, err
 	}
 	commits, err := impl.processGitLogOutput(output)
@@ -138,7 +257,43 @@ func (impl *GitCliManagerImpl) GetCommits(gitCtx GitContext, branchRef string, b
 	return commits, nil
 }
 
-func (impl *GitCliManagerImpl) getCommandForLogRange(branchRef string, from string, to string, rangeCmdArgs []string, baseCmdArgs []string, extraCmdArgs []string) []string {
+func isUnknownRevisionOutput(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range unknownRevisionMessages {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+func (impl *GitCliManagerImpl) getCommitStreamIterator(gitCtx GitContext, rootDir string, iteratorRequest IteratorRequest) (CommitIterator, error) {
+	baseCmdArgs := append(append([]string{"-C", rootDir}, getMailmapBlobArgs(rootDir, iteratorRequest.BranchRef)...), "log")
+	rangeCmdArgs := []string{iteratorRequest.BranchRef}
+	extraCmdArgs := []string{"-z", "-n", strconv.Itoa(iteratorRequest.CommitCount), "--date=iso-strict", GITFORMAT}
+	if iteratorRequest.FirstParent {
+		extraCmdArgs = append(extraCmdArgs, "--first-parent")
+	}
+	extraCmdArgs = append(extraCmdArgs, iteratorRequest.getTimeWindowArgs()...)
+	pathspecArgs := getPathspecArgs(iteratorRequest.IncludePaths, iteratorRequest.ExcludePaths)
+	cmdArgs := impl.getCommandForLogRange(iteratorRequest.BranchRef, iteratorRequest.FromCommitHash, iteratorRequest.ToCommitHash, rangeCmdArgs, baseCmdArgs, extraCmdArgs, pathspecArgs)
+	impl.logger.Debugw("git", cmdArgs)
+	stdout, wait, err := impl.GitManagerBase.StreamCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in starting git log", "rootDir", rootDir, "err", err)
+		return nil, err
+	}
+	itr := newCommitCliStreamIterator(stdout, wait)
+	if err = itr.peek(); err != nil {
+		impl.logger.Errorw("error in fetching commits for", "err", err, "path", rootDir)
+		return nil, err
+	}
+	return itr, nil
+}
+
+// getCommandForLogRange puts the options before --end-of-options, so that a revision from a request is never taken as
+// an option of git log
+func (impl *GitCliManagerImpl) getCommandForLogRange(branchRef string, from string, to string, rangeCmdArgs []string, baseCmdArgs []string, extraCmdArgs []string, pathspecArgs []string) []string {
 	if from != "" && to != "" {
 		rangeCmdArgs = []string{from + "^.." + to}
 	} else if from != "" {
@@ -146,12 +301,14 @@ func (impl *GitCliManagerImpl) getCommandForLogRange(branchRef string, from stri
 	} else if to != "" {
 		rangeCmdArgs = []string{to}
 	}
-	return append(baseCmdArgs, append(rangeCmdArgs, extraCmdArgs...)...)
+	cmdArgs := append(append(baseCmdArgs, extraCmdArgs...), "--end-of-options")
+	return append(append(cmdArgs, rangeCmdArgs...), pathspecArgs...)
 }
 
 func (impl *GitCliManagerImpl) GitShow(gitCtx GitContext, rootDir string, hash string) (GitCommit, error) {
-	impl.logger.Debugw("git", "-C", rootDir, "show", hash, "--date=iso-strict", GITFORMAT, "-s")
-	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "show", hash, "--date=iso-strict", GITFORMAT, "-s")
+	cmdArgs := append(append([]string{"-C", rootDir}, getMailmapBlobArgs(rootDir, hash)...), "show", hash, "--date=iso-strict", GITFORMAT, "-s")
+	impl.logger.Debugw("git", cmdArgs)
+	output, errMsg, err := impl.GitManagerBase.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
 	impl.logger.Debugw("root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
 	if err != nil {
 		return nil, err
@@ -166,7 +323,8 @@ func (impl *GitCliManagerImpl) GitShow(gitCtx GitContext, rootDir string, hash s
 
 func (impl *GitCliManagerImpl) GetCommitStats(gitCtx GitContext, commit GitCommit, checkoutPath string) (FileStats, error) {
 	gitCommit := commit.GetCommit()
-	return impl.FetchDiffStatBetweenCommitsNameOnly(gitCtx, gitCommit.Commit, "", checkoutPath)
+	// numstat marks the binary files with - for the line counts, which the name only diff can't tell
+	return impl.FetchDiffStatBetweenCommitsWithNumstat(gitCtx, gitCommit.Commit, "", checkoutPath)
 }
 
 func (impl *GitCliManagerImpl) processGitLogOutput(out string) ([]GitCommit, error) {
@@ -181,23 +339,29 @@ func (impl *GitCliManagerImpl) processGitLogOutput(out string) ([]GitCommit, err
 	}
 
 	for _, formattedCommit := range gitCommitFormattedList {
-
-		subject := strings.TrimSpace(formattedCommit.Subject)
-		body := strings.TrimSpace(formattedCommit.Body)
-		message := subject
-		if len(body) > 0 {
-			message = strings.Join([]string{subject, body}, "\n")
-		}
-
-		cm := GitCommitBase{
-			Commit:  formattedCommit.Commit,
-			Author:  formattedCommit.Commiter.Name + " <" + formattedCommit.Commiter.Email + ">",
-			Date:    formattedCommit.Commiter.Date,
-			Message: message,
-		}
 		gitCommits = append(gitCommits, &GitCommitCli{
-			GitCommitBase: cm,
+			GitCommitBase: toGitCommitBase(formattedCommit),
 		})
 	}
 	return gitCommits, nil
 }
+
+func toGitCommitBase(formattedCommit GitCommitFormat) GitCommitBase {
+	subject := strings.TrimSpace(formattedCommit.Subject)
+	body := strings.TrimSpace(formattedCommit.Body)
+	message := subject
+	if len(body) > 0 {
+		message = strings.Join([]string{subject, body}, "\n")
+	}
+	return GitCommitBase{
+		Commit:   formattedCommit.Commit,
+		Parents:  strings.Fields(formattedCommit.Parent),
+		Author:   formattedCommit.Commiter.Name + " <" + formattedCommit.Commiter.Email + ">",
+		Date:     formattedCommit.Commiter.Date,
+		Message:  message,
+		Trailers: parseTrailerLines(formattedCommit.Trailers),
+		// names and emails of the format are mailmapped by git
+		AuthorDetail:    &Author{Name: formattedCommit.Author.Name, Email: formattedCommit.Author.Email, Date: formattedCommit.Author.Date},
+		CommitterDetail: &Committer{Name: formattedCommit.Commiter.Name, Email: formattedCommit.Commiter.Email, Date: formattedCommit.Commiter.Date},
+	}.withConventionalCommit().withTrailerIdentities()
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"github.com/devtron-labs/common-lib/utils"
 	"github.com/devtron-labs/git-sensor/internals"
+	"reflect"
 	"testing"
 )
 
@@ -51,5 +52,28 @@ func TestGitCliManagerImpl_processGitLogOutput(t *testing.T) {
 
 }
 
+func TestGitCliManagerImpl_getCommandForLogRange(t *testing.T) {
+	impl := &GitCliManagerImpl{}
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected []string
+	}{
+		{name: "branch", expected: []string{"log", "-n", "15", "--end-of-options", "origin/main", "--", "src"}},
+		{name: "from and to", from: "a1", to: "b2", expected: []string{"log", "-n", "15", "--end-of-options", "a1^..b2", "--", "src"}},
+		{name: "from", from: "--output=/tmp/log", expected: []string{"log", "-n", "15", "--end-of-options", "--output=/tmp/log^..origin/main", "--", "src"}},
+		{name: "to", to: "-p", expected: []string{"log", "-n", "15", "--end-of-options", "-p", "--", "src"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := impl.getCommandForLogRange("origin/main", tt.from, tt.to, []string{"origin/main"}, []string{"log"}, []string{"-n", "15"}, []string{"--", "src"})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("getCommandForLogRange() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 // const input = "{\"commit\":\"98130668d78f45dc963559039d9a06231edda280\",\"parent\":\"a0628f4f99d956e07a615525358867353c5c3d12 5b689cb7c9fbbe66e3a5c5e3c8f99baf601c6247\",\"refs\":\"origin/main\",\"subject\":\"Merge pull request #20 from devtron-labs/version-upgrade-nats-metrics-oss\",\"body\":\"chore: version upgrade nats metrics oss\",\"author\":{\"name\":\"Gireesh Naidu\",\"email\":\"111440205+gireesh-devtron@users.noreply.github.com\",\"date\":\"2023-12-27T17:51:04+05:30\"},\"commiter\":{\"name\":\"GitHub\",\"email\":\"noreply@github.com\",\"date\":\"2023-12-27T17:51:04+05:30\"}},\n{\"commit\":\"5b689cb7c9fbbe66e3a5c5e3c8f99baf601c6247\",\"parent\":\"b06c43a6c0f904bd3873ad975716becf1ae2a841\",\"refs\":\"origin/version-upgrade-nats-metrics-oss\",\"subject\":\"bump common-lib\",\"body\":\"\",\"author\":{\"name\":\"Gireesh Naidu\",\"email\":\"gireesh@devtron.ai\",\"date\":\"2023-12-26T12:36:19+05:30\"},\"commiter\":{\"name\":\"Gireesh Naidu\",\"email\":\"gireesh@devtron.ai\",\"date\":\"2023-12-26T12:36:19+05:30\"}},\n{\"commit\":\"b06c43a6c0f904bd3873ad975716becf1ae2a841\",\"parent\":\"640be3e2ab7fb94adacf228cfbda1326ee9b70ca\",\"refs\":\"\",\"subject\":\"bump common-lib\",\"body\":\"\",\"author\":{\"name\":\"Gireesh Naidu\",\"email\":\"gireesh@devtron.ai\",\"date\":\"2023-12-22T14:06:33+05:30\"},\"commiter\":{\"name\":\"Gireesh Naidu\",\"email\":\"gireesh@devtron.ai\",\"date\":\"2023-12-22T14:06:33+05:30\"}},\n{\"commit\":\"640be3e2ab7fb94adacf228cfbda1326ee9b70ca\",\"parent\":\"05b3ce9a8ec375045e4b1ab27782104c5be90a00\",\"refs\":\"origin/version-upgrade\",\"subject\":\"issue fixed for wire and make file\",\"body\":\"\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T14:08:31+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T14:08:31+05:30\"}},\n{\"commit\":\"05b3ce9a8ec375045e4b1ab27782104c5be90a00\",\"parent\":\"1015fda7d7562ebe555b4192463cedc9e3bb4bdd\",\"refs\":\"\",\"subject\":\"version upgraded for golang\",\"body\":\"\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T12:55:25+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T12:55:25+05:30\"}},\n{\"commit\":\"1015fda7d7562ebe555b4192463cedc9e3bb4bdd\",\"parent\":\"a0628f4f99d956e07a615525358867353c5c3d12\",\"refs\":\"\",\"subject\":\"version upgraded for lens\",\"body\":\"\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T12:13:58+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T12:13:58+05:30\"}},\n{\"commit\":\"a0628f4f99d956e07a615525358867353c5c3d12\",\"parent\":\"22fa85c73b7a311a4676d6a4e6e1df0b4be2dd4d\",\"refs\":\"\",\"subject\":\"Revert \"version upgrade grpc and net dependencies.\"\",\"body\":\"This reverts commit ca3b53d806f6ec9358e9e0d6d10007e9d8a86045.\n\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:58:28+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:58:28+05:30\"}},\n{\"commit\":\"22fa85c73b7a311a4676d6a4e6e1df0b4be2dd4d\",\"parent\":\"df8d14277f5b4169694cc620ef15ddf2c15e8eab\",\"refs\":\"\",\"subject\":\"Revert \"otel grpc version upgraded.\"\",\"body\":\"This reverts commit df8d14277f5b4169694cc620ef15ddf2c15e8eab.\n\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:58:25+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:58:25+05:30\"}},\n{\"commit\":\"df8d14277f5b4169694cc620ef15ddf2c15e8eab\",\"parent\":\"ca3b53d806f6ec9358e9e0d6d10007e9d8a86045\",\"refs\":\"\",\"subject\":\"otel grpc version upgraded.\",\"body\":\"\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:19:23+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T11:19:23+05:30\"}},\n{\"commit\":\"ca3b53d806f6ec9358e9e0d6d10007e9d8a86045\",\"parent\":\"3d6e488a1555735c41bbad8c5df8c07032f46b27\",\"refs\":\"\",\"subject\":\"version upgrade grpc and net dependencies.\",\"body\":\"\",\"author\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T10:57:08+05:30\"},\"commiter\":{\"name\":\"adi6859\",\"email\":\"aditya.ar1909@gmail.com\",\"date\":\"2023-12-08T10:57:08+05:30\"}},\n{\"commit\":\"3d6e488a1555735c41bbad8c5df8c07032f46b27\",\"parent\":\"8803028b6375b03d64cf6048e1e082bbeb16822d 5e29265eda111faf88fcef459c3bd74bfaf12ffb\",\"refs\":\"\",\"subject\":\"Merge pull request #17 from devtron-labs/config.md\",\"body\":\"docs: Create config.md\",\"author\":{\"name\":\"Pawan Kumar\",\"email\":\"85476803+pawan-59@users.noreply.github.com\",\"date\":\"2023-10-31T12:02:50+05:30\"},\"commiter\":{\"name\":\"GitHub\",\"email\":\"noreply@github.com\",\"date\":\"2023-10-31T12:02:50+05:30\"}},\n{\"commit\":\"5e29265eda111faf88fcef459c3bd74bfaf12ffb\",\"parent\":\"8803028b6375b03d64cf6048e1e082bbeb16822d\",\"refs\":\"origin/config.md\",\"subject\":\"Create config.md\",\"body\":\"\",\"author\":{\"name\":\"Badal Kumar\",\"email\":\"130441461+badal773@users.noreply.github.com\",\"date\":\"2023-10-30T15:58:03+05:30\"},\"commiter\":{\"name\":\"GitHub\",\"email\":\"noreply@github.com\",\"date\":\"2023-10-30T15:58:03+05:30\"}},\n{\"commit\":\"8803028b6375b03d64cf6048e1e082bbeb16822d\",\"parent\":\"c5c40d94a31d9c02c53c3f96342a6fea303bde8c 34435d2671f3b6901d1bdfae63d6c83db36ae97a\",\"refs\":\"\",\"subject\":\"Merge pull request #16 from devtron-labs/makefile-change\",\"body\":\"fix: makefile correction\",\"author\":{\"name\":\"Vikram\",\"email\":\"73224103+vikramdevtron@users.noreply.github.com\",\"date\":\"2023-09-01T19:33:45+05:30\"},\"commiter\":{\"name\":\"GitHub\",\"email\":\"noreply@github.com\",\"date\":\"2023-09-01T19:33:45+05:30\"}},\n{\"commit\":\"34435d2671f3b6901d1bdfae63d6c83db36ae97a\",\"parent\":\"c5c40d94a31d9c02c53c3f96342a6fea303bde8c\",\"refs\":\"origin/makefile-change\",\"subject\":\"makefile correction\",\"body\":\"\",\"author\":{\"name\":\"Prakash Kumar\",\"email\":\"prakash.kumar@devtron.ai\",\"date\":\"2023-09-01T18:18:51+05:30\"},\"commiter\":{\"name\":\"Prakash Kumar\",\"email\":\"prakash.kumar@devtron.ai\",\"date\":\"2023-09-01T18:18:51+05:30\"}},\n{\"commit\":\"c5c40d94a31d9c02c53c3f96342a6fea303bde8c\",\"parent\":\"25bfd796a89992d3dbe739913fd46556440248ff 95f3fc2cc1e8576e19e6d2eabbb186f79d63f851\",\"refs\":\"\",\"subject\":\"Merge pull request #15 from jatin-jangir-0220/main\",\"body\":\"feat: added copy command for sql files in dockerfile\",\"author\":{\"name\":\"Prakarsh\",\"email\":\"71125043+prakarsh-dt@users.noreply.github.com\",\"date\":\"2023-07-24T13:46:20+05:30\"},\"commiter\":{\"name\":\"GitHub\",\"email\":\"noreply@github.com\",\"date\":\"2023-07-24T13:46:20+05:30\"}},"
 const input = "{devtron_delimitercommitdevtron_delimiter:devtron_delimiter98130668d78f45dc963559039d9a06231edda280devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimitera0628f4f99d956e07a615525358867353c5c3d12 5b689cb7c9fbbe66e3a5c5e3c8f99baf601c6247devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterorigin/maindevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterMerge pull request #20 from devtron-labs/version-upgrade-nats-metrics-ossdevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterchore: version upgrade nats metrics ossdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGireesh Naidudevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiter111440205+gireesh-devtron@users.noreply.github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-27T17:51:04+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGitHubdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiternoreply@github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-27T17:51:04+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter5b689cb7c9fbbe66e3a5c5e3c8f99baf601c6247devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiterb06c43a6c0f904bd3873ad975716becf1ae2a841devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterorigin/version-upgrade-nats-metrics-ossdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterbump common-libdevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGireesh Naidudevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimitergireesh@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-26T12:36:19+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGireesh Naidudevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimitergireesh@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-26T12:36:19+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiterb06c43a6c0f904bd3873ad975716becf1ae2a841devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter640be3e2ab7fb94adacf228cfbda1326ee9b70cadevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterbump common-libdevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGireesh Naidudevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimitergireesh@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-22T14:06:33+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGireesh Naidudevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimitergireesh@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-22T14:06:33+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter640be3e2ab7fb94adacf228cfbda1326ee9b70cadevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter05b3ce9a8ec375045e4b1ab27782104c5be90a00devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterorigin/version-upgradedevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterissue fixed for wire and make filedevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T14:08:31+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T14:08:31+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter05b3ce9a8ec375045e4b1ab27782104c5be90a00devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter1015fda7d7562ebe555b4192463cedc9e3bb4bdddevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterversion upgraded for golangdevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T12:55:25+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T12:55:25+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter1015fda7d7562ebe555b4192463cedc9e3bb4bdddevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimitera0628f4f99d956e07a615525358867353c5c3d12devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterversion upgraded for lensdevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T12:13:58+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T12:13:58+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimitera0628f4f99d956e07a615525358867353c5c3d12devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter22fa85c73b7a311a4676d6a4e6e1df0b4be2dd4ddevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterRevert \"version upgrade grpc and net dependencies.\"devtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterThis reverts commit ca3b53d806f6ec9358e9e0d6d10007e9d8a86045.\ndevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:58:28+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:58:28+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter22fa85c73b7a311a4676d6a4e6e1df0b4be2dd4ddevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiterdf8d14277f5b4169694cc620ef15ddf2c15e8eabdevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterRevert \"otel grpc version upgraded.\"devtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterThis reverts commit df8d14277f5b4169694cc620ef15ddf2c15e8eab.\ndevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:58:25+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:58:25+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiterdf8d14277f5b4169694cc620ef15ddf2c15e8eabdevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiterca3b53d806f6ec9358e9e0d6d10007e9d8a86045devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterotel grpc version upgraded.devtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:19:23+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T11:19:23+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiterca3b53d806f6ec9358e9e0d6d10007e9d8a86045devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter3d6e488a1555735c41bbad8c5df8c07032f46b27devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterversion upgrade grpc and net dependencies.devtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T10:57:08+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiteradi6859devtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiteraditya.ar1909@gmail.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-12-08T10:57:08+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter3d6e488a1555735c41bbad8c5df8c07032f46b27devtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter8803028b6375b03d64cf6048e1e082bbeb16822d 5e29265eda111faf88fcef459c3bd74bfaf12ffbdevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterMerge pull request #17 from devtron-labs/config.mddevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdocs: Create config.mddevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterPawan Kumardevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiter85476803+pawan-59@users.noreply.github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-10-31T12:02:50+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGitHubdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiternoreply@github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-10-31T12:02:50+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter5e29265eda111faf88fcef459c3bd74bfaf12ffbdevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter8803028b6375b03d64cf6048e1e082bbeb16822ddevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterorigin/config.mddevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterCreate config.mddevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterBadal Kumardevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiter130441461+badal773@users.noreply.github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-10-30T15:58:03+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGitHubdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiternoreply@github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-10-30T15:58:03+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter8803028b6375b03d64cf6048e1e082bbeb16822ddevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiterc5c40d94a31d9c02c53c3f96342a6fea303bde8c 34435d2671f3b6901d1bdfae63d6c83db36ae97adevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterMerge pull request #16 from devtron-labs/makefile-changedevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterfix: makefile correctiondevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterVikramdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiter73224103+vikramdevtron@users.noreply.github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-09-01T19:33:45+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGitHubdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiternoreply@github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-09-01T19:33:45+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiter34435d2671f3b6901d1bdfae63d6c83db36ae97adevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiterc5c40d94a31d9c02c53c3f96342a6fea303bde8cdevtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterorigin/makefile-changedevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimitermakefile correctiondevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterPrakash Kumardevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiterprakash.kumar@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-09-01T18:18:51+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterPrakash Kumardevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiterprakash.kumar@devtron.aidevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-09-01T18:18:51+05:30devtron_delimiter}},\n{devtron_delimitercommitdevtron_delimiter:devtron_delimiterc5c40d94a31d9c02c53c3f96342a6fea303bde8cdevtron_delimiter,devtron_delimiterparentdevtron_delimiter:devtron_delimiter25bfd796a89992d3dbe739913fd46556440248ff 95f3fc2cc1e8576e19e6d2eabbb186f79d63f851devtron_delimiter,devtron_delimiterrefsdevtron_delimiter:devtron_delimiterdevtron_delimiter,devtron_delimitersubjectdevtron_delimiter:devtron_delimiterMerge pull request #15 from jatin-jangir-0220/maindevtron_delimiter,devtron_delimiterbodydevtron_delimiter:devtron_delimiterfeat: added copy command for sql files in dockerfiledevtron_delimiter,devtron_delimiterauthordevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterPrakarshdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiter71125043+prakarsh-dt@users.noreply.github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-07-24T13:46:20+05:30devtron_delimiter},devtron_delimitercommiterdevtron_delimiter:{devtron_delimiternamedevtron_delimiter:devtron_delimiterGitHubdevtron_delimiter,devtron_delimiteremaildevtron_delimiter:devtron_delimiternoreply@github.comdevtron_delimiter,devtron_delimiterdatedevtron_delimiter:devtron_delimiter2023-07-24T13:46:20+05:30devtron_delimiter}},"
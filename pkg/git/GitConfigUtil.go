@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+const REDACTED_VALUE = "*****"
+
+// DETERMINISTIC_GIT_CONFIG keeps checkouts independent of the global git config of the image, line endings are never
+// converted and long paths are allowed
+var DETERMINISTIC_GIT_CONFIG = []string{"core.autocrlf=false", "core.longpaths=true"}
+
+var ErrInvalidGitConfig = errors.New("invalid git config")
+
+// keys which tune the transfer only, the extra git config of a material is limited to these. Keys running commands,
+// sending the credentials elsewhere or changing the proxy, tls or ssh settings of the remote are never accepted
+var allowedGitConfigKeys = []string{"http.postBuffer", "http.lowSpeedLimit", "http.lowSpeedTime", "core.compression", "pack.windowMemory", "fetch.negotiationAlgorithm"}
+
+// IsValidGitConfigKey checks that the key of a config passed with -c is one of the allowed ones, git keys are case
+// insensitive
+func IsValidGitConfigKey(key string) bool {
+	for _, allowedKey := range allowedGitConfigKeys {
+		if strings.EqualFold(key, allowedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGitConfig checks the extra git config of a material, keys must be allowed and values must be single line
+func ValidateGitConfig(gitConfig map[string]string) error {
+	for key, value := range gitConfig {
+		if !IsValidGitConfigKey(key) {
+			return fmt.Errorf("%w: key %q is not allowed", ErrInvalidGitConfig, key)
+		}
+		if strings.ContainsAny(value, "\n\r\x00") {
+			return fmt.Errorf("%w: value of key %q has a line break", ErrInvalidGitConfig, key)
+		}
+	}
+	return nil
+}
+
+func getDefaultGitConfigArgs(config *internals.Configuration) []string {
+	var configArgs []string
+	if config.DeterministicGitConfig {
+		for _, gitConfig := range DETERMINISTIC_GIT_CONFIG {
+			configArgs = append(configArgs, "-c", gitConfig)
+		}
+	}
+	if len(config.GitSafeDirectory) > 0 {
+		configArgs = append(configArgs, "-c", "safe.directory="+config.GitSafeDirectory)
+	}
+	return configArgs
+}
+
+// getGitConfigArgs returns the -c key=value arguments sorted by key, skipping invalid entries
+func getGitConfigArgs(extraGitConfig map[string]string, logger *zap.SugaredLogger) []string {
+	keys := make([]string, 0, len(extraGitConfig))
+	for key := range extraGitConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	configArgs := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		value := extraGitConfig[key]
+		if !IsValidGitConfigKey(key) || strings.ContainsAny(value, "\n\r\x00") {
+			logger.Warnw("skipping invalid git config", "key", key)
+			continue
+		}
+		configArgs = append(configArgs, "-c", key+"="+value)
+	}
+	return configArgs
+}
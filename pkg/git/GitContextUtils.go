@@ -30,6 +30,23 @@ type GitContext struct {
 	TLSKey                 string
 	TLSCertificate         string
 	TLSVerificationEnabled bool
+	ExtraGitConfig         map[string]string // git config passed as -c key=value, scoped to each git invocation
+	FetchDepth             int               // limits the commits fetched per branch, 0 for no limit
+	ResolveSubmodules      bool              // report submodule pointer changes of each commit
+	FetchSubmoduleCommits  bool              // also fetch the submodules to report their commits between the old and new pointer
+	VerifySignatures       bool              // report the signature verification status of each commit
+	IncludePaths           []string          // only commits touching these paths are listed
+	ExcludePaths           []string          // commits touching only these paths are not listed
+	ProxyUrl               string            // http(s) proxy of the remote, empty to connect directly
+	InsecureSkipTLS        bool              // skip the verification of the server certificate of https remotes
+	IsClone                bool              // the fetch is the first one of the repo, it gets the clone timeout
+	FetchUrl               string            // fetch from this url instead of origin, its refs are stored as the ones of origin
+	FirstParent            bool              // list only the first parent chain of branches, a merge is listed without the commits it merged
+	Progress               *TransferProgress // receives the progress of the git commands of a background job, nil otherwise
+	Since                  time.Time         // only commits committed at or after it are listed, zero for no lower bound
+	Until                  time.Time         // only commits committed at or before it are listed, zero for no upper bound
+	CredentialScope        string            // owner of the ssh key file of the credentials, the git provider when empty
+	RetryPolicy            *RetryPolicy      // retries of the fetches and ls-remotes failing with network errors, the default one when nil
 }
 
 func (gitCtx GitContext) WithCredentials(Username string, Password string) GitContext {
@@ -38,6 +55,18 @@ func (gitCtx GitContext) WithCredentials(Username string, Password string) GitCo
 	return gitCtx
 }
 
+// WithCredentialScope keeps the ssh key of a material with its own credentials apart from the one of its git provider
+func (gitCtx GitContext) WithCredentialScope(credentialScope string) GitContext {
+	gitCtx.CredentialScope = credentialScope
+	return gitCtx
+}
+
+// WithRetryPolicy sets the retries of the remote operations of a material
+func (gitCtx GitContext) WithRetryPolicy(retryPolicy *RetryPolicy) GitContext {
+	gitCtx.RetryPolicy = retryPolicy
+	return gitCtx
+}
+
 func (gitCtx GitContext) WithTLSData(caData string, tlsKey string, tlsCertificate string, tlsVerificationEnabled bool) GitContext {
 	gitCtx.CACert = caData
 	gitCtx.TLSKey = tlsKey
@@ -46,6 +75,69 @@ func (gitCtx GitContext) WithTLSData(caData string, tlsKey string, tlsCertificat
 	return gitCtx
 }
 
+func (gitCtx GitContext) WithInsecureSkipTLS(insecureSkipTLS bool) GitContext {
+	gitCtx.InsecureSkipTLS = insecureSkipTLS
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithExtraGitConfig(extraGitConfig map[string]string) GitContext {
+	gitCtx.ExtraGitConfig = extraGitConfig
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithFetchDepth(fetchDepth int) GitContext {
+	gitCtx.FetchDepth = fetchDepth
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithSubmoduleResolution(resolveSubmodules bool, fetchSubmoduleCommits bool) GitContext {
+	gitCtx.ResolveSubmodules = resolveSubmodules
+	gitCtx.FetchSubmoduleCommits = resolveSubmodules && fetchSubmoduleCommits
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithSignatureVerification(verifySignatures bool) GitContext {
+	gitCtx.VerifySignatures = verifySignatures
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithPathFilter(includePaths []string, excludePaths []string) GitContext {
+	gitCtx.IncludePaths = includePaths
+	gitCtx.ExcludePaths = excludePaths
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithFirstParent(firstParent bool) GitContext {
+	gitCtx.FirstParent = firstParent
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithTimeWindow(since time.Time, until time.Time) GitContext {
+	gitCtx.Since = since
+	gitCtx.Until = until
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithProxy(proxyUrl string) GitContext {
+	gitCtx.ProxyUrl = proxyUrl
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithFetchUrl(fetchUrl string) GitContext {
+	gitCtx.FetchUrl = fetchUrl
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithProgress(progress *TransferProgress) GitContext {
+	gitCtx.Progress = progress
+	return gitCtx
+}
+
+func (gitCtx GitContext) WithClone() GitContext {
+	gitCtx.IsClone = true
+	return gitCtx
+}
+
 func BuildGitContext(ctx context.Context) GitContext {
 	return GitContext{
 		Context: ctx,
@@ -58,6 +150,12 @@ func (gitCtx GitContext) WithTimeout(timeoutSeconds int) (GitContext, context.Ca
 	return gitCtx, cancel
 }
 
+func (gitCtx GitContext) WithCancel() (GitContext, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(gitCtx.Context)
+	gitCtx.Context = ctx
+	return gitCtx, cancel
+}
+
 func (gitCtx GitContext) WithCloningMode(CloningMode string) GitContext {
 	if CloningMode == "" {
 		CloningMode = CloningModeFull
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"strings"
+)
+
+var (
+	ErrAuthFailed              = errors.New("authentication failed")
+	ErrRepoNotFound            = errors.New("repository not found")
+	ErrBranchNotFound          = errors.New("branch not found")
+	ErrShallowRangeUnavailable = errors.New("commit range not available in shallow checkout")
+	ErrTimeout                 = errors.New("git operation timed out")
+	ErrRepoCorrupted           = errors.New("checkout is corrupted")
+	ErrProviderRateLimited     = errors.New("rate limit of the git provider api exceeded")
+	ErrHostCircuitOpen         = errors.New("remote operations of the git host are paused after repeated failures")
+	ErrNetwork                 = errors.New("git host unreachable")
+)
+
+// GIT_ERROR_CODE_UNKNOWN is the code of errors which are not classified
+const GIT_ERROR_CODE_UNKNOWN = "UNKNOWN"
+
+// gitErrorPatterns are matched case insensitively against the output of a failed git command, the first match wins
+var gitErrorPatterns = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrAuthFailed, []string{"authentication failed", "could not read username", "could not read password", "permission denied (publickey", "http basic: access denied", "invalid username or password", "terminal prompts disabled", "the requested url returned error: 401", "the requested url returned error: 403"}},
+	{ErrRepoNotFound, []string{"repository not found", "does not appear to be a git repository", "the requested url returned error: 404", "could not be found or you don't have permission"}},
+	{ErrRepoCorrupted, []string{"not a git repository", "is corrupt", "object file", "index file corrupt", "does not match index", "invalid sha1 pointer", "unable to read sha1 file"}},
+	{ErrBranchNotFound, []string{"couldn't find remote ref", "invalid reference:", "not a valid ref"}},
+	{ErrShallowRangeUnavailable, []string{"shallow update not allowed", "error processing shallow info"}},
+	{ErrTimeout, []string{"connection timed out", "operation timed out"}},
+	{ErrNetwork, []string{"could not resolve host", "temporary failure in name resolution", "failed to connect to", "connection refused", "connection reset", "early eof", "the remote end hung up unexpectedly", "unexpected disconnect", "rpc failed", "gnutls_handshake() failed", "ssl_error_syscall", "the requested url returned error: 500", "the requested url returned error: 502", "the requested url returned error: 503", "the requested url returned error: 504"}},
+}
+
+// GitError is a classified git failure. Kind is one of the Err values above, errors.Is matches both Kind and the
+// underlying error
+type GitError struct {
+	Kind   error
+	Output string
+	Err    error
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind.Error(), e.Err.Error())
+}
+
+func (e *GitError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ClassifyGitError wraps err in a GitError when the failure is known from the output of the command or the go-git
+// error, else err is returned as it is
+func ClassifyGitError(output string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var gitErr *GitError
+	if errors.As(err, &gitErr) {
+		return err
+	}
+	if kind := getGitErrorKind(output, err); kind != nil {
+		return &GitError{Kind: kind, Output: output, Err: err}
+	}
+	return err
+}
+
+func getGitErrorKind(output string, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "signal: killed"):
+		// the command is killed when the context of its timeout expires
+		return ErrTimeout
+	case errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed):
+		return ErrAuthFailed
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return ErrRepoNotFound
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return ErrBranchNotFound
+	}
+	output = strings.ToLower(output)
+	for _, gitErrorPattern := range gitErrorPatterns {
+		for _, pattern := range gitErrorPattern.patterns {
+			if strings.Contains(output, pattern) {
+				return gitErrorPattern.kind
+			}
+		}
+	}
+	return nil
+}
+
+// ParseGitErrorMessage restores the kind of a classified error from its message, like the fetch error saved on a
+// material. The message is classified again from its text when it is not of a GitError
+func ParseGitErrorMessage(message string) error {
+	if len(message) == 0 {
+		return nil
+	}
+	err := errors.New(message)
+	for _, kind := range []error{ErrAuthFailed, ErrRepoNotFound, ErrBranchNotFound, ErrShallowRangeUnavailable, ErrTimeout, ErrRepoCorrupted, ErrProviderRateLimited, ErrHostCircuitOpen, ErrNetwork} {
+		if strings.HasPrefix(message, kind.Error()+":") {
+			return &GitError{Kind: kind, Output: message, Err: err}
+		}
+	}
+	return ClassifyGitError(message, err)
+}
+
+// GetGitErrorCode returns a stable code of the kind of the error for api consumers, GIT_ERROR_CODE_UNKNOWN for unclassified errors
+func GetGitErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return "GIT_AUTH_FAILED"
+	case errors.Is(err, ErrRepoNotFound):
+		return "GIT_REPO_NOT_FOUND"
+	case errors.Is(err, ErrBranchNotFound):
+		return "GIT_BRANCH_NOT_FOUND"
+	case errors.Is(err, ErrShallowRangeUnavailable):
+		return "GIT_SHALLOW_RANGE_UNAVAILABLE"
+	case errors.Is(err, ErrTimeout):
+		return "GIT_TIMEOUT"
+	case errors.Is(err, ErrRepoCorrupted):
+		return "GIT_REPO_CORRUPTED"
+	case errors.Is(err, ErrProviderRateLimited):
+		return "GIT_PROVIDER_RATE_LIMITED"
+	case errors.Is(err, ErrHostCircuitOpen):
+		return "GIT_HOST_CIRCUIT_OPEN"
+	case errors.Is(err, ErrNetwork):
+		return "GIT_NETWORK_ERROR"
+	}
+	return GIT_ERROR_CODE_UNKNOWN
+}
+
+// GetGitErrorUserMessage returns the action to take for a classified error, empty for unclassified errors
+func GetGitErrorUserMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return "git credentials are invalid or lack access to the repository, update the credentials of the git provider"
+	case errors.Is(err, ErrRepoNotFound):
+		return "repository not found, check the url of the material and the access of the credentials to it"
+	case errors.Is(err, ErrBranchNotFound):
+		return "branch not found in the repository, check the branch of the material"
+	case errors.Is(err, ErrShallowRangeUnavailable):
+		return "commits are beyond the history of the shallow checkout, increase the clone depth or use a full clone"
+	case errors.Is(err, ErrTimeout):
+		return "git operation timed out, it is retried on the next poll"
+	case errors.Is(err, ErrRepoCorrupted):
+		return "checkout of the repository is corrupted, it is cloned again on the next poll"
+	case errors.Is(err, ErrProviderRateLimited):
+		return "rate limit of the git provider api is exhausted, commits are polled again once it resets"
+	case errors.Is(err, ErrHostCircuitOpen):
+		return "git host failed repeatedly, its materials are polled again after the cooldown"
+	case errors.Is(err, ErrNetwork):
+		return "git host could not be reached after retries, it is polled again on the next poll"
+	}
+	return ""
+}
+
+// IsRemoteGitError checks if the failure may be of the remote rather than of the checkout, so that another remote of
+// the repo may serve the operation
+func IsRemoteGitError(err error) bool {
+	return !errors.Is(err, ErrRepoCorrupted) && !errors.Is(err, ErrShallowRangeUnavailable) && !errors.Is(err, context.Canceled)
+}
+
+// IsRetryableGitError checks if the operation may succeed when retried as it is. Auth, not found and shallow errors
+// need a change of config and should be alerted instead
+func IsRetryableGitError(err error) bool {
+	return !errors.Is(err, ErrAuthFailed) && !errors.Is(err, ErrRepoNotFound) && !errors.Is(err, ErrBranchNotFound) &&
+		!errors.Is(err, ErrShallowRangeUnavailable)
+}
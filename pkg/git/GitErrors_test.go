@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClassifyGitError(t *testing.T) {
+	exitErr := errors.New("exit status 128")
+	err := ClassifyGitError("fatal: Authentication failed for 'https://github.com/devtron-labs/git-sensor.git/'", exitErr)
+	assert.True(t, errors.Is(err, ErrAuthFailed))
+	assert.True(t, errors.Is(err, exitErr))
+	assert.Equal(t, "GIT_AUTH_FAILED", GetGitErrorCode(err))
+	assert.False(t, IsRetryableGitError(err))
+
+	err = ClassifyGitError("remote: Repository not found.\nfatal: repository 'https://github.com/x/y.git/' not found", exitErr)
+	assert.True(t, errors.Is(err, ErrRepoNotFound))
+	err = ClassifyGitError("fatal: couldn't find remote ref refs/heads/missing", exitErr)
+	assert.True(t, errors.Is(err, ErrBranchNotFound))
+	err = ClassifyGitError("fatal: loose object 0616f89b (stored in ./objects/06/16f89b) is corrupt", exitErr)
+	assert.True(t, errors.Is(err, ErrRepoCorrupted))
+	assert.True(t, IsRetryableGitError(err))
+	err = ClassifyGitError("", errors.New("signal: killed"))
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.True(t, IsRetryableGitError(err))
+	err = ClassifyGitError("", context.DeadlineExceeded)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	err = ClassifyGitError("", transport.ErrAuthenticationRequired)
+	assert.True(t, errors.Is(err, ErrAuthFailed))
+
+	err = ClassifyGitError("fatal: unable to access 'https://github.com/x/y.git/': Could not resolve host: github.com", exitErr)
+	assert.True(t, errors.Is(err, ErrNetwork))
+	assert.Equal(t, "GIT_NETWORK_ERROR", GetGitErrorCode(err))
+	assert.True(t, IsTransientGitError(err))
+	err = ClassifyGitError("error: RPC failed; HTTP 502 curl 22 The requested URL returned error: 502\nfatal: early EOF", exitErr)
+	assert.True(t, errors.Is(err, ErrNetwork))
+	// a connection timeout is a timeout, not retried
+	err = ClassifyGitError("fatal: unable to access 'https://github.com/x/y.git/': Failed to connect to github.com port 443: Connection timed out", exitErr)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.False(t, IsTransientGitError(err))
+
+	err = ClassifyGitError("error: unknown option `depht'", exitErr)
+	assert.Equal(t, exitErr, err)
+	assert.Equal(t, GIT_ERROR_CODE_UNKNOWN, GetGitErrorCode(err))
+	assert.True(t, IsRetryableGitError(err))
+	assert.Nil(t, ClassifyGitError("", nil))
+}
+
+func TestParseGitErrorMessage(t *testing.T) {
+	err := ParseGitErrorMessage(ClassifyGitError("fatal: Authentication failed", errors.New("exit status 128")).Error())
+	assert.True(t, errors.Is(err, ErrAuthFailed))
+	err = ParseGitErrorMessage(ErrTimeout.Error() + ": signal: killed")
+	assert.Equal(t, "GIT_TIMEOUT", GetGitErrorCode(err))
+	err = ParseGitErrorMessage("fatal: couldn't find remote ref refs/heads/missing")
+	assert.True(t, errors.Is(err, ErrBranchNotFound))
+	assert.Equal(t, GIT_ERROR_CODE_UNKNOWN, GetGitErrorCode(ParseGitErrorMessage("exit status 1")))
+	assert.Nil(t, ParseGitErrorMessage(""))
+}
@@ -35,6 +35,7 @@ var GITFORMAT = "--pretty=format:{" +
 	_dl_ + "refs" + _dl_ + ":" + _dl_ + "%D" + _dl_ + "," +
 	_dl_ + "subject" + _dl_ + ":" + _dl_ + "%<(1024,trunc)%s" + _dl_ + "," +
 	_dl_ + "body" + _dl_ + ":" + _dl_ + "%<(1024,trunc)%b" + _dl_ + "," +
+	_dl_ + "trailers" + _dl_ + ":" + _dl_ + "%(trailers:unfold)" + _dl_ + "," +
 	_dl_ + "author" + _dl_ +
 	":{" +
 	_dl_ + "name" + _dl_ + ":" + _dl_ + "%aN" + _dl_ + "," +
@@ -61,6 +62,7 @@ type GitCommitFormat struct {
 	Commiter GitPerson `json:"commiter"`
 	Author   GitPerson `json:"author"`
 	Body     string    `json:"body"`
+	Trailers string    `json:"trailers"`
 }
 
 func parseFormattedLogOutput(out string) ([]GitCommitFormat, error) {
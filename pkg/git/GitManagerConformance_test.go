@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGitManagerConformance runs the same checks against the cli and go-git implementations
+func TestGitManagerConformance(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	withoutGitBinary := NewGoGitSDKManagerImpl(NewGitManagerBaseImpl(logger, &internals.Configuration{EnableTagPolling: true}), logger, &internals.Configuration{EnableTagPolling: true})
+	withoutGitBinary.gitCliAvailable = false
+	managers := map[string]GitManager{
+		"cli":                NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true, EnableTagPolling: true}),
+		"cli bare":           NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true, UseBareRepo: true, EnableTagPolling: true}),
+		"cli streaming":      NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true, UseStreamingGitLog: true, EnableTagPolling: true}),
+		"go-git":             NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: false, EnableTagPolling: true}),
+		"go-git fetch":       NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: false, UseGoGitFetch: true, EnableTagPolling: true}),
+		"go-git without cli": withoutGitBinary,
+	}
+	for name, gitManager := range managers {
+		t.Run(name, func(t *testing.T) {
+			remoteDir, workDir := setupTestRemote(t)
+			assert.Nil(t, os.WriteFile(filepath.Join(workDir, "README.md"), []byte("line1\nline2\n"), 0644))
+			runTestGitCmd(t, workDir, "add", "README.md")
+			runTestGitCmd(t, workDir, "commit", "-m", "add readme\n\nJira: ABC-1")
+			runTestGitCmd(t, workDir, "tag", "v1")
+			runTestGitCmd(t, workDir, "tag", "-a", "v2", "-m", "release v2")
+			runTestGitCmd(t, workDir, "push", "origin", "main", "v1", "v2")
+			headHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+			gitCtx := BuildGitContext(context.Background())
+			checkoutPath := filepath.Join(t.TempDir(), "checkout")
+			assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+			if name == "cli bare" {
+				_, err = os.Stat(filepath.Join(checkoutPath, ".git"))
+				assert.True(t, os.IsNotExist(err))
+			}
+			response, _, err := gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			assert.NotEmpty(t, response)
+
+			repository, err := gitManager.OpenRepoPlain(checkoutPath)
+			assert.Nil(t, err)
+			iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 10})
+			assert.Nil(t, err)
+			var commits []*GitCommitBase
+			for {
+				commit, err := iterator.Next()
+				if err != nil || commit == nil {
+					break
+				}
+				commits = append(commits, commit.GetCommit())
+			}
+			iterator.Close()
+			assert.Equal(t, 3, len(commits))
+			assert.Equal(t, headHash, commits[0].Commit)
+			// message formatting differs between implementations, the subject and trailers don't
+			assert.True(t, strings.HasPrefix(commits[0].Message, "add readme\n"))
+			assert.Equal(t, map[string][]string{"Jira": {"ABC-1"}}, commits[0].Trailers)
+
+			commit, err := gitManager.GetCommitForHash(gitCtx, checkoutPath, headHash)
+			assert.Nil(t, err)
+			assert.Equal(t, headHash, commit.GetCommit().Commit)
+
+			commit, err = gitManager.GetCommitsForTag(gitCtx, checkoutPath, "v1")
+			assert.Nil(t, err)
+			assert.Equal(t, headHash, commit.GetCommit().Commit)
+
+			commit, err = gitManager.GetCommitsForTag(gitCtx, checkoutPath, "v2")
+			assert.Nil(t, err)
+			assert.Equal(t, headHash, commit.GetCommit().Commit)
+
+			exists, err := gitManager.RefExists(gitCtx, checkoutPath, "refs/remotes/origin/main")
+			assert.Nil(t, err)
+			assert.True(t, exists)
+			exists, err = gitManager.RefExists(gitCtx, checkoutPath, "refs/remotes/origin/unknown")
+			assert.Nil(t, err)
+			assert.False(t, exists)
+			remoteRefs, err := gitManager.LsRemote(gitCtx, checkoutPath, "origin", []string{"main", "v2"})
+			assert.Nil(t, err)
+			assert.Equal(t, headHash, remoteRefs["refs/heads/main"])
+			assert.Contains(t, remoteRefs, "refs/tags/v2")
+			assert.NotContains(t, remoteRefs, "refs/tags/v1")
+			isAncestor, err := gitManager.IsAncestor(gitCtx, checkoutPath, commits[2].Commit, "refs/remotes/origin/main")
+			assert.Nil(t, err)
+			assert.True(t, isAncestor)
+			isAncestor, err = gitManager.IsAncestor(gitCtx, checkoutPath, "refs/remotes/origin/main", commits[2].Commit)
+			assert.Nil(t, err)
+			assert.False(t, isAncestor)
+			mergeBase, err := gitManager.GetMergeBase(gitCtx, checkoutPath, commits[1].Commit, "refs/remotes/origin/main")
+			assert.Nil(t, err)
+			assert.Equal(t, commits[1].Commit, mergeBase)
+
+			fileStats, err := gitManager.GetCommitStats(gitCtx, commit, checkoutPath)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(fileStats))
+			assert.Equal(t, "README.md", fileStats[0].Name)
+
+			runTestGitCmd(t, workDir, "push", "origin", "--delete", "v1")
+			_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			_, err = gitManager.GetCommitsForTag(gitCtx, checkoutPath, "v1")
+			assert.NotNil(t, err)
+
+			response, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			assert.Empty(t, response)
+
+			runTestGitCmd(t, workDir, "checkout", "-b", "feature", commits[1].Commit)
+			runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "feature")
+			runTestGitCmd(t, workDir, "checkout", "main")
+			runTestGitCmd(t, workDir, "merge", "--no-ff", "-m", "merge feature", "feature")
+			runTestGitCmd(t, workDir, "push", "origin", "main")
+			_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			repository, err = gitManager.OpenRepoPlain(checkoutPath)
+			assert.Nil(t, err)
+			iterator, err = gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 10, FirstParent: true})
+			assert.Nil(t, err)
+			var firstParentCommits []*GitCommitBase
+			for {
+				commit, err := iterator.Next()
+				if err != nil || commit == nil {
+					break
+				}
+				firstParentCommits = append(firstParentCommits, commit.GetCommit())
+			}
+			iterator.Close()
+			assert.Equal(t, 4, len(firstParentCommits))
+			assert.Equal(t, headHash, firstParentCommits[1].Commit)
+			assert.Equal(t, 2, len(firstParentCommits[0].Parents))
+			assert.Equal(t, headHash, firstParentCommits[0].Parents[0])
+			assert.Equal(t, []string{commits[2].Commit}, firstParentCommits[2].Parents)
+			assert.Empty(t, firstParentCommits[3].Parents)
+		})
+	}
+}
+
+func TestGitManagerConformance_TimeWindow(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	managers := map[string]GitManager{
+		"cli":           NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true}),
+		"cli streaming": NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: true, UseStreamingGitLog: true}),
+		"go-git":        NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: false}),
+	}
+	for name, gitManager := range managers {
+		t.Run(name, func(t *testing.T) {
+			remoteDir, workDir := setupTestRemote(t)
+			// a day apart after the commits of the setup, so that the commit dates increase along the history
+			start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+			for day := 0; day < 3; day++ {
+				date := start.Add(time.Duration(day) * 24 * time.Hour).Format(time.RFC3339)
+				cmd := exec.Command("git", "-c", "user.name=devtron", "-c", "user.email=devtron@devtron.ai", "-C", workDir, "commit", "--allow-empty", "-m", "day"+strconv.Itoa(day))
+				cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date, "GIT_AUTHOR_DATE="+date)
+				out, err := cmd.CombinedOutput()
+				assert.Nil(t, err, string(out))
+			}
+			runTestGitCmd(t, workDir, "push", "origin", "main")
+			gitCtx := BuildGitContext(context.Background())
+			checkoutPath := filepath.Join(t.TempDir(), "checkout")
+			assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+			_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			repository, err := gitManager.OpenRepoPlain(checkoutPath)
+			assert.Nil(t, err)
+
+			getMessages := func(since, until time.Time) []string {
+				iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 10, Since: since, Until: until})
+				assert.Nil(t, err)
+				defer iterator.Close()
+				var messages []string
+				for {
+					commit, err := iterator.Next()
+					if err != nil || commit == nil {
+						return messages
+					}
+					messages = append(messages, strings.TrimSpace(commit.GetCommit().Message))
+				}
+			}
+			day := 24 * time.Hour
+			assert.Equal(t, []string{"day2", "day1"}, getMessages(start.Add(day-time.Hour), time.Time{}))
+			assert.Equal(t, []string{"day1"}, getMessages(start.Add(day-time.Hour), start.Add(day+time.Hour)))
+			assert.Equal(t, []string{"day0", "second", "first"}, getMessages(time.Time{}, start.Add(time.Hour)))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	GIT_OPERATION_CLONE = "clone"
+	GIT_OPERATION_FETCH = "fetch"
+	GIT_OPERATION_LOG   = "log"
+
+	// PROCESS_WAIT_DELAY bounds the wait for the output of a killed command, as its children may still hold the pipes
+	PROCESS_WAIT_DELAY = 5 * time.Second
+)
+
+// GIT_LOCK_FILES are the lock files in the git dir which fail every later command on the repo when left behind
+var GIT_LOCK_FILES = []string{"index.lock", "shallow.lock", "HEAD.lock", "config.lock", "packed-refs.lock", "FETCH_HEAD.lock"}
+
+// childProcessCtx is cancelled by KillChildProcesses, every git command is killed with its process group then
+var childProcessCtx, killChildProcesses = context.WithCancel(context.Background())
+
+// KillChildProcesses kills the git commands which are running and the ones started after it. It is called on shutdown
+// once the running operations had their time to finish, so that no git command keeps writing to a checkout
+func KillChildProcesses() {
+	killChildProcesses()
+}
+
+// ChildProcessesKilled tells if the git commands are being killed for the shutdown
+func ChildProcessesKilled() bool {
+	return childProcessCtx.Err() != nil
+}
+
+// getOperationClass returns the class of the git sub command whose timeout applies, empty for the other commands
+func getOperationClass(gitCtx GitContext, subCommand string) string {
+	switch subCommand {
+	case "clone":
+		return GIT_OPERATION_CLONE
+	case "fetch", "ls-remote":
+		if gitCtx.IsClone {
+			return GIT_OPERATION_CLONE
+		}
+		return GIT_OPERATION_FETCH
+	case "log", "rev-list", "show", "diff":
+		return GIT_OPERATION_LOG
+	}
+	return ""
+}
+
+// setProcessGroupKill starts the command in its own process group and kills the whole group when the context of the
+// command is done, so that the helpers spawned by git like git-remote-https, ssh and index-pack don't outlive it
+func setProcessGroupKill(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = PROCESS_WAIT_DELAY
+}
+
+// getCommandRootDir returns the repo dir a git command runs in with -C, empty when it has none
+func getCommandRootDir(args []string) string {
+	for i := 1; i < len(args)-1; i++ {
+		if args[i] == "-C" {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// repoWriteSubCommands are the sub commands which take lock files of the repo, the lock files they leave behind when
+// they are killed are recorded so that they can be removed under the lock of the repo
+var repoWriteSubCommands = map[string]bool{"fetch": true, "gc": true, "repack": true, "pack-refs": true, "prune": true,
+	"config": true, "remote": true, "worktree": true, "update-ref": true, "checkout": true}
+
+// listLockFiles returns the lock files in the git dir, including the ones of refs
+func listLockFiles(gitDir string) map[string]bool {
+	lockFiles := make(map[string]bool)
+	for _, lockFile := range GIT_LOCK_FILES {
+		lockPath := filepath.Join(gitDir, lockFile)
+		if _, err := os.Lstat(lockPath); err == nil {
+			lockFiles[lockPath] = true
+		}
+	}
+	_ = filepath.WalkDir(filepath.Join(gitDir, "refs"), func(lockPath string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(entry.Name(), ".lock") {
+			lockFiles[lockPath] = true
+		}
+		return nil
+	})
+	return lockFiles
+}
+
+// killedCommandLockFiles keeps the lock files left behind by the git commands killed on their deadline per git dir,
+// till the holder of the lock of the repo removes them
+type killedCommandLockFiles struct {
+	mutex sync.Mutex
+	files map[string][]string
+}
+
+func newKilledCommandLockFiles() *killedCommandLockFiles {
+	return &killedCommandLockFiles{files: make(map[string][]string)}
+}
+
+// record keeps the lock files of the git dir which were not there before the killed command started
+func (lockFiles *killedCommandLockFiles) record(gitDir string, lockFilesBefore map[string]bool) []string {
+	var owned []string
+	for lockPath := range listLockFiles(gitDir) {
+		if !lockFilesBefore[lockPath] {
+			owned = append(owned, lockPath)
+		}
+	}
+	if len(owned) == 0 {
+		return nil
+	}
+	lockFiles.mutex.Lock()
+	defer lockFiles.mutex.Unlock()
+	lockFiles.files[gitDir] = append(lockFiles.files[gitDir], owned...)
+	return owned
+}
+
+func (lockFiles *killedCommandLockFiles) take(gitDir string) []string {
+	lockFiles.mutex.Lock()
+	defer lockFiles.mutex.Unlock()
+	owned := lockFiles.files[gitDir]
+	delete(lockFiles.files, gitDir)
+	return owned
+}
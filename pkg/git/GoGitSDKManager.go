@@ -18,23 +18,250 @@ package git
 
 import (
 	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"go.uber.org/zap"
 	"os"
+	"strings"
+	"time"
 )
 
+// GoGitSDKManagerImpl implements GitManager using go-git. Known divergences from GitCliManagerImpl:
+//   - Author and Date are taken from the commit author, the cli implementation uses the committer
+//   - Message is the raw commit message, the cli implementation joins subject and body with a single new line
+//   - GetCommitStats returns line additions and deletions, the cli implementation returns file names only
+//
+// Fetch uses go-git when USE_GO_GIT_FETCH is enabled or when there is no git binary in the container,
+// ssh remotes and client certificates are not supported by it and need the git cli.
+//
+// Without a git binary only the polling of http(s) materials runs, on go-git alone: Init, Fetch, LsRemote, the commit
+// history, tags, commit stats of full clones and the ref checks. The other operations of GitManagerBase, like gc,
+// diffs, blame, archives, worktrees, shallow and partial clones and the ssh command, still run the git cli and fail
+// with ErrGitBinaryRequired.
 type GoGitSDKManagerImpl struct {
 	GitManagerBase
-	logger *zap.SugaredLogger
+	logger          *zap.SugaredLogger
+	conf            *internals.Configuration
+	gitCliAvailable bool
 }
 
-func NewGoGitSDKManagerImpl(baseManager GitManagerBase, logger *zap.SugaredLogger) *GoGitSDKManagerImpl {
+func NewGoGitSDKManagerImpl(baseManager GitManagerBase, logger *zap.SugaredLogger, conf *internals.Configuration) *GoGitSDKManagerImpl {
+	gitCliAvailable := baseManager.GetGitBinary().IsAvailable()
+	if !gitCliAvailable {
+		logger.Warnw("git binary not found, only the go-git operations are available", "path", baseManager.GetGitBinary().Path)
+	}
 	return &GoGitSDKManagerImpl{
-		GitManagerBase: baseManager,
-		logger:         logger,
+		GitManagerBase:  baseManager,
+		logger:          logger,
+		conf:            conf,
+		gitCliAvailable: gitCliAvailable,
+	}
+}
+
+func (impl *GoGitSDKManagerImpl) Fetch(gitCtx GitContext, rootDir string) (response, errMsg string, err error) {
+	if !impl.conf.UseGoGitFetch && impl.gitCliAvailable {
+		return impl.GitManagerBase.Fetch(gitCtx, rootDir)
+	}
+	r, err := impl.OpenRepoPlain(rootDir)
+	if err != nil {
+		return "", err.Error(), err
+	}
+	remote, err := r.Remote(git.DefaultRemoteName)
+	if err != nil {
+		impl.logger.Errorw("error in getting remote", "rootDir", rootDir, "err", err)
+		return "", err.Error(), err
+	}
+	if len(gitCtx.FetchUrl) > 0 {
+		// the mirror is fetched and listed in place of origin, so that its refs replace the ones of origin
+		remote = git.NewRemote(r.Storer, &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{gitCtx.FetchUrl}})
+	}
+	remoteUrl := remote.Config().URLs[0]
+	if isSshUrl(remoteUrl) || len(gitCtx.TLSKey) > 0 || len(gitCtx.TLSCertificate) > 0 {
+		if !impl.gitCliAvailable {
+			err = fmt.Errorf("%w, fetch of ssh remote or with client certificate", ErrGitBinaryRequired)
+			return "", err.Error(), err
+		}
+		impl.logger.Infow("fetch not supported by go-git for this remote, falling back to git cli", "rootDir", rootDir)
+		return impl.GitManagerBase.Fetch(gitCtx, rootDir)
+	}
+	impl.logger.Debugw("go-git fetch", "location", rootDir)
+	auth := getGoGitAuth(gitCtx)
+	var caBundle []byte
+	if gitCtx.TLSVerificationEnabled && len(gitCtx.CACert) > 0 {
+		caBundle = []byte(gitCtx.CACert)
+	}
+	refSpecs := []config.RefSpec{BRANCH_REF_SPEC, "+refs/tags/*:refs/tags/*"}
+	if impl.conf.FetchGerritChangeRefs {
+		refSpecs = append(refSpecs, GERRIT_CHANGE_REF_SPEC)
+	}
+	if impl.conf.FetchGitNotes {
+		refSpecs = append(refSpecs, NOTES_REF_SPEC)
+	}
+	fetchOptions := &git.FetchOptions{
+		RemoteName:      git.DefaultRemoteName,
+		RefSpecs:        refSpecs,
+		Auth:            auth,
+		Force:           true,
+		CABundle:        caBundle,
+		InsecureSkipTLS: gitCtx.InsecureSkipTLS,
+		ProxyOptions:    transport.ProxyOptions{URL: gitCtx.ProxyUrl},
+	}
+	if gitCtx.Progress != nil {
+		fetchOptions.Progress = gitCtx.Progress
+	}
+	err = remote.FetchContext(gitCtx, fetchOptions)
+	updated := err == nil
+	if err == git.NoErrAlreadyUpToDate {
+		err = nil
+	}
+	if err != nil {
+		// go-git reports the remote url in some of its errors
+		err = SanitizeError(err, gitCtx.Password)
+		impl.logger.Errorw("error in go-git fetch", "rootDir", rootDir, "err", err)
+		return "", err.Error(), err
+	}
+	pruned, err := impl.pruneDeletedRemoteRefs(gitCtx, r, remote, auth, caBundle)
+	if err != nil {
+		impl.logger.Errorw("error in pruning refs deleted at remote", "rootDir", rootDir, "err", err)
+		return "", err.Error(), err
+	}
+	if updated || pruned {
+		return "fetched latest changes from origin", "", nil
+	}
+	return "", "", nil
+}
+
+// pruneDeletedRemoteRefs is the go-git counterpart of fetch --prune, with --prune-tags when ENABLE_TAG_POLLING is set
+func (impl *GoGitSDKManagerImpl) pruneDeletedRemoteRefs(gitCtx GitContext, r *GitRepository, remote *git.Remote, auth transport.AuthMethod, caBundle []byte) (bool, error) {
+	remoteRefs, err := remote.ListContext(gitCtx, &git.ListOptions{
+		Auth:            auth,
+		CABundle:        caBundle,
+		InsecureSkipTLS: gitCtx.InsecureSkipTLS,
+		ProxyOptions:    transport.ProxyOptions{URL: gitCtx.ProxyUrl},
+	})
+	if err != nil {
+		return false, err
+	}
+	remoteRefNames := make(map[plumbing.ReferenceName]bool, len(remoteRefs))
+	for _, ref := range remoteRefs {
+		remoteRefNames[ref.Name()] = true
+	}
+	localRefs, err := r.References()
+	if err != nil {
+		return false, err
+	}
+	var staleRefs []plumbing.ReferenceName
+	err = localRefs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if name.IsRemote() && strings.HasPrefix(name.String(), "refs/remotes/origin/") && name.Short() != "origin/HEAD" {
+			if !remoteRefNames[plumbing.ReferenceName(GetRemoteRefOfBranch(strings.TrimPrefix(name.String(), "refs/remotes/origin/"), impl.conf.FetchGerritChangeRefs))] {
+				staleRefs = append(staleRefs, name)
+			}
+		} else if name.IsTag() && impl.conf.EnableTagPolling && !remoteRefNames[name] {
+			staleRefs = append(staleRefs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, name := range staleRefs {
+		impl.logger.Debugw("pruning ref deleted at remote", "ref", name)
+		if err = r.Storer.RemoveReference(name); err != nil {
+			return false, err
+		}
+	}
+	return len(staleRefs) > 0, nil
+}
+
+func getGoGitAuth(gitCtx GitContext) transport.AuthMethod {
+	if len(gitCtx.Username) == 0 && len(gitCtx.Password) == 0 {
+		return nil
+	}
+	return &http.BasicAuth{Username: gitCtx.Username, Password: gitCtx.Password}
+}
+
+func isSshUrl(url string) bool {
+	return strings.HasPrefix(url, "git@") || strings.HasPrefix(url, "ssh://")
+}
+
+// LsRemote uses go-git when there is no git binary in the container, the refs are matched by their tail like git does
+func (impl *GoGitSDKManagerImpl) LsRemote(gitCtx GitContext, rootDir, remote string, refs []string) (map[string]string, error) {
+	if impl.gitCliAvailable {
+		return impl.GitManagerBase.LsRemote(gitCtx, rootDir, remote, refs)
+	}
+	if len(remote) == 0 || strings.HasPrefix(remote, "-") {
+		return nil, fmt.Errorf("invalid remote %q", remote)
+	}
+	remoteConfig := &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{remote}}
+	if len(rootDir) > 0 {
+		if r, err := impl.OpenRepoPlain(rootDir); err == nil {
+			if namedRemote, err := r.Remote(remote); err == nil {
+				remoteConfig = namedRemote.Config()
+			}
+		}
+	}
+	if isSshUrl(remoteConfig.URLs[0]) {
+		return nil, fmt.Errorf("%w, ls-remote of ssh remote", ErrGitBinaryRequired)
+	}
+	var caBundle []byte
+	if gitCtx.TLSVerificationEnabled && len(gitCtx.CACert) > 0 {
+		caBundle = []byte(gitCtx.CACert)
+	}
+	remoteRefs, err := git.NewRemote(memory.NewStorage(), remoteConfig).ListContext(gitCtx, &git.ListOptions{
+		Auth:            getGoGitAuth(gitCtx),
+		CABundle:        caBundle,
+		InsecureSkipTLS: gitCtx.InsecureSkipTLS,
+		ProxyOptions:    transport.ProxyOptions{URL: gitCtx.ProxyUrl},
+		PeelingOption:   git.AppendPeeled,
+	})
+	if err != nil {
+		err = SanitizeError(ClassifyGitError("", err), gitCtx.Password)
+		impl.logger.Errorw("error in listing remote refs", "rootDir", rootDir, "err", err)
+		return nil, err
+	}
+	hashes := make(map[string]string, len(remoteRefs))
+	for _, ref := range remoteRefs {
+		if ref.Type() == plumbing.HashReference {
+			hashes[ref.Name().String()] = ref.Hash().String()
+		}
+	}
+	result := make(map[string]string)
+	for _, ref := range remoteRefs {
+		name := ref.Name().String()
+		if !matchesLsRemotePattern(name, refs) {
+			continue
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			// HEAD is listed with the hash of the branch it points to
+			if hash, ok := hashes[ref.Target().String()]; ok {
+				result[name] = hash
+			}
+		} else {
+			result[name] = ref.Hash().String()
+		}
+	}
+	return result, nil
+}
+
+// matchesLsRemotePattern checks if the ref is one of the patterns or ends with one of them after a slash, all refs
+// match when there are no patterns
+func matchesLsRemotePattern(ref string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ref == pattern || strings.HasSuffix(ref, "/"+pattern) {
+			return true
+		}
 	}
+	return false
 }
 
 func (impl *GoGitSDKManagerImpl) GetCommitsForTag(gitCtx GitContext, checkoutPath, tag string) (GitCommit, error) {
@@ -49,17 +276,18 @@ func (impl *GoGitSDKManagerImpl) GetCommitsForTag(gitCtx GitContext, checkoutPat
 		impl.logger.Errorw("error in fetching tag", "path", checkoutPath, "tag", tag, "err", err)
 		return nil, err
 	}
-	commit, err := r.CommitObject(plumbing.NewHash(tagRef.Hash().String()))
+	// resolving the revision peels annotated tags to the commit they point to
+	hash, err := r.ResolveRevision(plumbing.Revision(tagRef.Name().String()))
 	if err != nil {
-		impl.logger.Errorw("error in fetching tag", "path", checkoutPath, "hash", tagRef, "err", err)
+		impl.logger.Errorw("error in resolving tag", "path", checkoutPath, "tag", tag, "err", err)
 		return nil, err
 	}
-	cm := GitCommitBase{
-		Author:  commit.Author.String(),
-		Commit:  commit.Hash.String(),
-		Date:    commit.Author.When,
-		Message: commit.Message,
+	commit, err := r.CommitObject(*hash)
+	if err != nil {
+		impl.logger.Errorw("error in fetching tag", "path", checkoutPath, "hash", tagRef, "err", err)
+		return nil, err
 	}
+	cm := newGoGitCommitBase(commit, impl.getMailmap(commit))
 
 	gitCommit := &GitCommitGoGit{
 		GitCommitBase: cm,
@@ -80,12 +308,7 @@ func (impl *GoGitSDKManagerImpl) GetCommitForHash(gitCtx GitContext, checkoutPat
 		impl.logger.Errorw("error in fetching commit", "path", checkoutPath, "hash", commitHash, "err", err)
 		return nil, err
 	}
-	cm := GitCommitBase{
-		Author:  commit.Author.String(),
-		Commit:  commit.Hash.String(),
-		Date:    commit.Author.When,
-		Message: commit.Message,
-	}
+	cm := newGoGitCommitBase(commit, impl.getMailmap(commit))
 
 	gitCommit := &GitCommitGoGit{
 		GitCommitBase: cm,
@@ -102,11 +325,58 @@ func (impl *GoGitSDKManagerImpl) GetCommitIterator(gitCtx GitContext, repository
 	} else if err != nil {
 		return nil, fmt.Errorf("error in getting reference %s branch  %s", err, iteratorRequest.Branch)
 	}
-	itr, err := repository.Log(&git.LogOptions{From: ref.Hash()})
+	var mailmap *Mailmap
+	if head, err := repository.CommitObject(ref.Hash()); err == nil {
+		mailmap = impl.getMailmap(head)
+	}
+	if iteratorRequest.FirstParent {
+		// go-git log has no first parent option, the chain is walked by hand
+		var itr object.CommitIter = newFirstParentCommitIter(repository.Storer, ref.Hash())
+		if pathFilter := getPathFilter(iteratorRequest.IncludePaths, iteratorRequest.ExcludePaths); pathFilter != nil {
+			itr = object.NewCommitPathIterFromIter(pathFilter, itr, true)
+		}
+		if iteratorRequest.IsTimeWindowed() {
+			itr = object.NewCommitLimitIterFromIter(itr, object.LogLimitOptions{Since: getTimeBound(iteratorRequest.Since), Until: getTimeBound(iteratorRequest.Until)})
+		}
+		return &CommitGoGitIterator{CommitIter: itr, mailmap: mailmap}, nil
+	}
+	itr, err := repository.Log(&git.LogOptions{
+		From:       ref.Hash(),
+		PathFilter: getPathFilter(iteratorRequest.IncludePaths, iteratorRequest.ExcludePaths),
+		Since:      getTimeBound(iteratorRequest.Since),
+		Until:      getTimeBound(iteratorRequest.Until),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error in getting iterator %s branch  %s", err, iteratorRequest.Branch)
 	}
-	return &CommitGoGitIterator{itr}, nil
+	return &CommitGoGitIterator{CommitIter: itr, mailmap: mailmap}, nil
+}
+
+// getTimeBound returns the bound of the time window of go-git log, nil for the zero time which doesn't bound it
+func getTimeBound(bound time.Time) *time.Time {
+	if bound.IsZero() {
+		return nil
+	}
+	return &bound
+}
+
+// getMailmap returns the .mailmap committed in the commit, overridden by the one of MAILMAP_FILE
+func (impl *GoGitSDKManagerImpl) getMailmap(commit *object.Commit) *Mailmap {
+	mailmap := NewMailmap()
+	if file, err := commit.File(MAILMAP_FILE_NAME); err == nil {
+		if content, err := file.Contents(); err == nil {
+			mailmap.Parse(content)
+		}
+	}
+	if len(impl.conf.MailmapFile) > 0 {
+		content, err := os.ReadFile(impl.conf.MailmapFile)
+		if err != nil {
+			impl.logger.Warnw("error in reading mailmap file", "file", impl.conf.MailmapFile, "err", err)
+		} else {
+			mailmap.Parse(string(content))
+		}
+	}
+	return mailmap
 }
 
 func (impl *GoGitSDKManagerImpl) OpenRepoPlain(checkoutPath string) (*GitRepository, error) {
@@ -140,13 +410,77 @@ func (impl *GoGitSDKManagerImpl) Init(gitCtx GitContext, rootDir string, remoteU
 
 func (impl *GoGitSDKManagerImpl) GetCommitStats(gitCtx GitContext, commit GitCommit, checkoutPath string) (FileStats, error) {
 	if IsRepoShallowCloned(checkoutPath) {
+		impl.logger.Debugw("commit stats not supported by go-git for shallow clone, falling back to git cli", "checkoutPath", checkoutPath)
 		return impl.GitManagerBase.FetchDiffStatBetweenCommitsNameOnly(gitCtx, commit.GetCommit().Commit, "", checkoutPath)
 	}
 	gitCommit := commit.(*GitCommitGoGit)
+	return getGoGitCommitFileStats(gitCommit.Cm)
+}
 
-	stats, err := gitCommit.Cm.Stats()
+func (impl *GoGitSDKManagerImpl) RefExists(gitCtx GitContext, rootDir, ref string) (bool, error) {
+	r, err := impl.OpenRepoPlain(rootDir)
 	if err != nil {
-		return nil, err
+		return false, err
+	}
+	_, err = r.ResolveRevision(plumbing.Revision(ref))
+	if err == plumbing.ErrReferenceNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (impl *GoGitSDKManagerImpl) IsAncestor(gitCtx GitContext, rootDir, ancestor, descendant string) (bool, error) {
+	r, err := impl.OpenRepoPlain(rootDir)
+	if err != nil {
+		return false, err
+	}
+	ancestorHash, err := r.ResolveRevision(plumbing.Revision(ancestor))
+	if err == plumbing.ErrReferenceNotFound || err == plumbing.ErrObjectNotFound {
+		// same as the cli, a commit which is no longer present can't be an ancestor
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	descendantHash, err := r.ResolveRevision(plumbing.Revision(descendant))
+	if err != nil {
+		return false, err
+	}
+	ancestorCommit, err := r.CommitObject(*ancestorHash)
+	if err == plumbing.ErrObjectNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	descendantCommit, err := r.CommitObject(*descendantHash)
+	if err != nil {
+		return false, err
+	}
+	if ancestorCommit.Hash == descendantCommit.Hash {
+		return true, nil
+	}
+	return ancestorCommit.IsAncestor(descendantCommit)
+}
+
+func (impl *GoGitSDKManagerImpl) GetMergeBase(gitCtx GitContext, rootDir, commitA, commitB string) (string, error) {
+	r, err := impl.OpenRepoPlain(rootDir)
+	if err != nil {
+		return "", err
+	}
+	var commits []*object.Commit
+	for _, revision := range []string{commitA, commitB} {
+		hash, err := r.ResolveRevision(plumbing.Revision(revision))
+		if err != nil {
+			return "", err
+		}
+		commit, err := r.CommitObject(*hash)
+		if err != nil {
+			return "", err
+		}
+		commits = append(commits, commit)
+	}
+	mergeBases, err := commits[0].MergeBase(commits[1])
+	if err != nil || len(mergeBases) == 0 {
+		return "", err
 	}
-	return transformFileStats(stats), err
+	return mergeBases[0].Hash.String(), nil
 }
@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostGuard rate limits the remote operations of each git host and stops them for a cooldown after consecutive
+// failures of the host, so that a throttling or flapping server isn't hit by the polls of all its materials. After
+// the cooldown a single operation probes the host, its success closes the circuit again
+type HostGuard struct {
+	ratePerSec       float64
+	burst            float64
+	failureThreshold int
+	cooldown         time.Duration
+	lock             sync.Mutex
+	hosts            map[string]*hostGuardState
+}
+
+type hostGuardState struct {
+	tokens              float64
+	lastRefill          time.Time
+	consecutiveFailures int
+	openUntil           time.Time
+	probing             bool
+}
+
+// NewHostGuard creates the guard, a ratePerSec of 0 disables rate limiting and a failureThreshold of 0 disables the
+// circuit breaker
+func NewHostGuard(ratePerSec float64, burst int, failureThreshold int, cooldown time.Duration) *HostGuard {
+	if burst < 1 {
+		burst = 1
+	}
+	return &HostGuard{
+		ratePerSec:       ratePerSec,
+		burst:            float64(burst),
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		hosts:            make(map[string]*hostGuardState),
+	}
+}
+
+// Acquire waits for the rate limit of the host, it fails with ErrHostCircuitOpen while the circuit of the host is
+// open. Done must be called with the result of the operation when Acquire succeeds
+func (g *HostGuard) Acquire(ctx context.Context, host string) error {
+	if len(host) == 0 {
+		return nil
+	}
+	g.lock.Lock()
+	state := g.getState(host)
+	now := time.Now()
+	probe := false
+	if g.failureThreshold > 0 && state.consecutiveFailures >= g.failureThreshold {
+		if now.Before(state.openUntil) || state.probing {
+			openUntil := state.openUntil
+			g.lock.Unlock()
+			return &GitError{Kind: ErrHostCircuitOpen, Err: fmt.Errorf("host %s, retried after %s", host, openUntil.Format(time.RFC3339))}
+		}
+		state.probing, probe = true, true
+	}
+	wait := g.reserve(state, now)
+	g.lock.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		g.lock.Lock()
+		state.tokens++
+		if probe {
+			state.probing = false
+		}
+		g.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Done records the result of an operation on the host, it returns true when the failure opened the circuit
+func (g *HostGuard) Done(host string, err error) (opened bool) {
+	if len(host) == 0 || g.failureThreshold <= 0 {
+		return false
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	state := g.getState(host)
+	state.probing = false
+	if !isHostFailure(err) {
+		state.consecutiveFailures = 0
+		return false
+	}
+	state.consecutiveFailures++
+	if state.consecutiveFailures < g.failureThreshold {
+		return false
+	}
+	state.openUntil = time.Now().Add(g.cooldown)
+	return true
+}
+
+func (g *HostGuard) getState(host string) *hostGuardState {
+	state, ok := g.hosts[host]
+	if !ok {
+		state = &hostGuardState{tokens: g.burst, lastRefill: time.Now()}
+		g.hosts[host] = state
+	}
+	return state
+}
+
+// reserve takes a token of the host and returns the wait till it is available, tokens are refilled at ratePerSec up
+// to burst
+func (g *HostGuard) reserve(state *hostGuardState, now time.Time) time.Duration {
+	if g.ratePerSec <= 0 {
+		return 0
+	}
+	state.tokens += now.Sub(state.lastRefill).Seconds() * g.ratePerSec
+	if state.tokens > g.burst {
+		state.tokens = g.burst
+	}
+	state.lastRefill = now
+	state.tokens--
+	if state.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-state.tokens / g.ratePerSec * float64(time.Second))
+}
+
+// isHostFailure checks if the error is of the host being unavailable, errors of the repo like auth or not found
+// failures mean the host is up
+func isHostFailure(err error) bool {
+	return err != nil && IsRemoteGitError(err) && IsRetryableGitError(err) && !errors.Is(err, ErrHostCircuitOpen)
+}
+
+// getRemoteHost returns the host of a git remote, of both urls and the scp like user@host:path syntax of ssh
+func getRemoteHost(remoteUrl string) string {
+	if parsedUrl, err := url.Parse(remoteUrl); err == nil && len(parsedUrl.Host) > 0 {
+		return strings.ToLower(parsedUrl.Hostname())
+	}
+	host := remoteUrl
+	if index := strings.Index(host, ":"); index >= 0 {
+		host = host[:index]
+	}
+	if index := strings.LastIndex(host, "@"); index >= 0 {
+		host = host[index+1:]
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/devtron-labs/git-sensor/internals"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	JOB_TYPE_CLONE   = "CLONE"
+	JOB_TYPE_DEEPEN  = "DEEPEN"
+	JOB_TYPE_ARCHIVE = "ARCHIVE"
+
+	JOB_STATUS_QUEUED    = "QUEUED"
+	JOB_STATUS_RUNNING   = "RUNNING"
+	JOB_STATUS_SUCCEEDED = "SUCCEEDED"
+	JOB_STATUS_FAILED    = "FAILED"
+	JOB_STATUS_CANCELLED = "CANCELLED"
+
+	JOB_RESULTS_DIR = GIT_BASE_DIR + "job-results/"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobFinished    = errors.New("job has already finished")
+	ErrJobHasNoResult = errors.New("job has no result to download")
+	ErrJobQueueFull   = errors.New("too many jobs are queued, retry later")
+)
+
+type Job struct {
+	Id         string      `json:"id"`
+	Type       string      `json:"type"`
+	MaterialId int         `json:"materialId"`
+	Status     string      `json:"status"`
+	Progress   JobProgress `json:"progress"`
+	Error      string      `json:"error,omitempty"`
+	HasResult  bool        `json:"hasResult"`
+	CreatedOn  time.Time   `json:"createdOn"`
+	StartedOn  *time.Time  `json:"startedOn,omitempty"`
+	FinishedOn *time.Time  `json:"finishedOn,omitempty"`
+}
+
+func (job *Job) IsFinished() bool {
+	return job.Status == JOB_STATUS_SUCCEEDED || job.Status == JOB_STATUS_FAILED || job.Status == JOB_STATUS_CANCELLED
+}
+
+// JobFunc runs the operation of a job. The context is cancelled when the job is cancelled and carries the progress of
+// the job, resultPath is the file produced by the job if any
+type JobFunc func(gitCtx GitContext, jobId string) (resultPath string, err error)
+
+type jobEntry struct {
+	job        Job
+	progress   *TransferProgress
+	cancel     context.CancelFunc
+	resultPath string
+}
+
+// JobManager runs the long git operations in the background, so that the requests starting them return the job id
+// right away instead of running into the request timeout
+type JobManager interface {
+	// Submit queues the job, at most MAX_CONCURRENT_JOBS jobs run at a time. it fails with ErrJobQueueFull when
+	// MAX_QUEUED_JOBS jobs are already waiting for a slot
+	Submit(jobType string, materialId int, run JobFunc) (*Job, error)
+	Get(jobId string) (*Job, error)
+	// Cancel stops the job, killing the git command it runs
+	Cancel(jobId string) (*Job, error)
+	// GetResultPath returns the file produced by the succeeded job, removed along with the job after JOB_RETENTION_IN_MIN
+	GetResultPath(jobId string) (string, error)
+}
+
+type JobManagerImpl struct {
+	logger        *zap.SugaredLogger
+	configuration *internals.Configuration
+	mutex         sync.Mutex
+	jobs          map[string]*jobEntry
+	slots         chan struct{}
+	resultsDir    string
+}
+
+func NewJobManagerImpl(logger *zap.SugaredLogger, configuration *internals.Configuration) *JobManagerImpl {
+	impl := &JobManagerImpl{
+		logger:        logger,
+		configuration: configuration,
+		jobs:          make(map[string]*jobEntry),
+		slots:         make(chan struct{}, max(configuration.MaxConcurrentJobs, 1)),
+		resultsDir:    JOB_RESULTS_DIR,
+	}
+	impl.removeStaleResults()
+	return impl
+}
+
+// removeStaleResults removes the results left by the jobs of the previous run, the jobs are kept in memory only so
+// their results can no longer be downloaded
+func (impl *JobManagerImpl) removeStaleResults() {
+	if err := os.RemoveAll(impl.resultsDir); err != nil {
+		impl.logger.Errorw("error in removing stale job results", "dir", impl.resultsDir, "err", err)
+	}
+}
+
+func (impl *JobManagerImpl) Submit(jobType string, materialId int, run JobFunc) (*Job, error) {
+	gitCtx, cancel := BuildGitContext(context.Background()).WithCancel()
+	jobId := newJobId()
+	entry := &jobEntry{
+		job:      Job{Id: jobId, Type: jobType, MaterialId: materialId, Status: JOB_STATUS_QUEUED, CreatedOn: time.Now()},
+		progress: NewTransferProgress(impl.logger, "jobId", jobId),
+		cancel:   cancel,
+	}
+	impl.mutex.Lock()
+	if queued := impl.countQueued(); queued >= max(impl.configuration.MaxQueuedJobs, 1) {
+		impl.mutex.Unlock()
+		cancel()
+		impl.logger.Warnw("job queue is full, rejecting job", "type", jobType, "materialId", materialId, "queued", queued)
+		return nil, ErrJobQueueFull
+	}
+	impl.jobs[entry.job.Id] = entry
+	job := entry.job
+	impl.mutex.Unlock()
+	impl.logger.Infow("job submitted", "jobId", job.Id, "type", jobType, "materialId", materialId)
+	go impl.run(gitCtx.WithProgress(entry.progress), entry, run)
+	return &job, nil
+}
+
+// countQueued returns the number of jobs waiting for a slot, the caller holds the mutex
+func (impl *JobManagerImpl) countQueued() int {
+	queued := 0
+	for _, entry := range impl.jobs {
+		if entry.job.Status == JOB_STATUS_QUEUED {
+			queued++
+		}
+	}
+	return queued
+}
+
+func (impl *JobManagerImpl) run(gitCtx GitContext, entry *jobEntry, run JobFunc) {
+	defer entry.cancel()
+	select {
+	case impl.slots <- struct{}{}:
+		defer func() { <-impl.slots }()
+	case <-gitCtx.Done():
+		impl.finish(entry, "", gitCtx.Err())
+		return
+	}
+	impl.mutex.Lock()
+	startedOn := time.Now()
+	entry.job.Status = JOB_STATUS_RUNNING
+	entry.job.StartedOn = &startedOn
+	impl.mutex.Unlock()
+	resultPath, err := run(gitCtx, entry.job.Id)
+	if err == nil && gitCtx.Err() != nil {
+		err = gitCtx.Err()
+	}
+	impl.finish(entry, resultPath, err)
+}
+
+func (impl *JobManagerImpl) finish(entry *jobEntry, resultPath string, err error) {
+	impl.mutex.Lock()
+	finishedOn := time.Now()
+	entry.job.FinishedOn = &finishedOn
+	entry.job.Progress = entry.progress.Snapshot()
+	switch {
+	case errors.Is(err, context.Canceled):
+		entry.job.Status = JOB_STATUS_CANCELLED
+	case err != nil:
+		entry.job.Status = JOB_STATUS_FAILED
+		entry.job.Error = err.Error()
+	default:
+		entry.job.Status = JOB_STATUS_SUCCEEDED
+		entry.job.HasResult = len(resultPath) > 0
+	}
+	if entry.job.Status != JOB_STATUS_SUCCEEDED && len(resultPath) > 0 {
+		impl.removeResult(resultPath)
+		resultPath = ""
+	}
+	entry.resultPath = resultPath
+	jobId, status := entry.job.Id, entry.job.Status
+	impl.mutex.Unlock()
+	impl.logger.Infow("job finished", "jobId", jobId, "status", status, "err", err)
+	time.AfterFunc(time.Duration(impl.configuration.JobRetentionInMin)*time.Minute, func() {
+		impl.mutex.Lock()
+		delete(impl.jobs, jobId)
+		impl.mutex.Unlock()
+		if len(resultPath) > 0 {
+			impl.removeResult(resultPath)
+		}
+	})
+}
+
+func (impl *JobManagerImpl) removeResult(resultPath string) {
+	if err := os.Remove(resultPath); err != nil && !os.IsNotExist(err) {
+		impl.logger.Errorw("error in removing job result", "resultPath", resultPath, "err", err)
+	}
+}
+
+func (impl *JobManagerImpl) Get(jobId string) (*Job, error) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	entry, ok := impl.jobs[jobId]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	job := entry.job
+	if !job.IsFinished() {
+		job.Progress = entry.progress.Snapshot()
+	}
+	return &job, nil
+}
+
+func (impl *JobManagerImpl) Cancel(jobId string) (*Job, error) {
+	impl.mutex.Lock()
+	entry, ok := impl.jobs[jobId]
+	finished := ok && entry.job.IsFinished()
+	impl.mutex.Unlock()
+	if !ok {
+		return nil, ErrJobNotFound
+	} else if finished {
+		return nil, ErrJobFinished
+	}
+	impl.logger.Infow("cancelling job", "jobId", jobId)
+	entry.cancel()
+	return impl.Get(jobId)
+}
+
+func (impl *JobManagerImpl) GetResultPath(jobId string) (string, error) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	entry, ok := impl.jobs[jobId]
+	if !ok {
+		return "", ErrJobNotFound
+	}
+	if len(entry.resultPath) == 0 {
+		return "", ErrJobHasNoResult
+	}
+	return entry.resultPath, nil
+}
+
+func newJobId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func getTestJobManager(t *testing.T, maxConcurrentJobs int) *JobManagerImpl {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	return NewJobManagerImpl(logger, &internals.Configuration{MaxConcurrentJobs: maxConcurrentJobs, MaxQueuedJobs: 1, JobRetentionInMin: 1})
+}
+
+func waitForJob(t *testing.T, jobManager *JobManagerImpl, jobId string) *Job {
+	var job *Job
+	assert.Eventually(t, func() bool {
+		var err error
+		job, err = jobManager.Get(jobId)
+		return err == nil && job.IsFinished()
+	}, 10*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobManager(t *testing.T) {
+	jobManager := getTestJobManager(t, 1)
+
+	failed, err := jobManager.Submit(JOB_TYPE_DEEPEN, 1, func(gitCtx GitContext, jobId string) (string, error) {
+		return "", errors.New("shallow update not allowed")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, JOB_STATUS_QUEUED, failed.Status)
+	job := waitForJob(t, jobManager, failed.Id)
+	assert.Equal(t, JOB_STATUS_FAILED, job.Status)
+	assert.Equal(t, "shallow update not allowed", job.Error)
+	_, err = jobManager.Cancel(failed.Id)
+	assert.ErrorIs(t, err, ErrJobFinished)
+
+	// the second job waits for the first one to free the only slot
+	started := make(chan bool)
+	running, err := jobManager.Submit(JOB_TYPE_CLONE, 1, func(gitCtx GitContext, jobId string) (string, error) {
+		close(started)
+		<-gitCtx.Done()
+		return "", gitCtx.Err()
+	})
+	assert.Nil(t, err)
+	<-started
+	queued, err := jobManager.Submit(JOB_TYPE_CLONE, 2, func(gitCtx GitContext, jobId string) (string, error) {
+		return "", nil
+	})
+	assert.Nil(t, err)
+	// MAX_QUEUED_JOBS is 1, so no other job can wait for the slot
+	_, err = jobManager.Submit(JOB_TYPE_CLONE, 3, func(gitCtx GitContext, jobId string) (string, error) {
+		return "", nil
+	})
+	assert.ErrorIs(t, err, ErrJobQueueFull)
+	job, err = jobManager.Get(queued.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, JOB_STATUS_QUEUED, job.Status)
+	job, err = jobManager.Get(running.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, JOB_STATUS_RUNNING, job.Status)
+	assert.NotNil(t, job.StartedOn)
+
+	_, err = jobManager.Cancel(running.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, JOB_STATUS_CANCELLED, waitForJob(t, jobManager, running.Id).Status)
+	job = waitForJob(t, jobManager, queued.Id)
+	assert.Equal(t, JOB_STATUS_SUCCEEDED, job.Status)
+	assert.False(t, job.HasResult)
+	_, err = jobManager.GetResultPath(queued.Id)
+	assert.ErrorIs(t, err, ErrJobHasNoResult)
+
+	_, err = jobManager.Get("unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestJobManager_FetchProgress(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "third")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	repositoryManager := getTestRepositoryManager(t)
+	assert.Nil(t, repositoryManager.gitManager.Init(BuildGitContext(context.Background()), checkoutPath, "file://"+remoteDir, true))
+	jobManager := getTestJobManager(t, 1)
+
+	submitted, err := jobManager.Submit(JOB_TYPE_CLONE, 1, func(gitCtx GitContext, jobId string) (string, error) {
+		_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+		return "", err
+	})
+	assert.Nil(t, err)
+	job := waitForJob(t, jobManager, submitted.Id)
+	assert.Equal(t, JOB_STATUS_SUCCEEDED, job.Status)
+	// the three commits and their empty tree
+	assert.Equal(t, 4, job.Progress.TotalObjects)
+	assert.Equal(t, 100, job.Progress.Percent)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LFS_POINTER_MAX_SIZE is the size limit of pointer files in the git lfs spec, larger blobs are not parsed
+	LFS_POINTER_MAX_SIZE = 1024
+	LFS_POINTER_VERSION  = "version https://git-lfs.github.com/spec/v1"
+	LFS_OID_PREFIX       = "sha256:"
+)
+
+// LfsPointer is a changed file of a commit whose content is stored outside git in lfs, Oid and Size are of that content
+type LfsPointer struct {
+	Path string
+	Oid  string
+	Size int64
+}
+
+// GetLfsPointersInCommit returns the files added or modified by the commit which are lfs pointers. Only the blobs small
+// enough to be pointers are read
+func (impl *GitManagerBaseImpl) GetLfsPointersInCommit(gitCtx GitContext, rootDir string, commitHash string) ([]*LfsPointer, error) {
+	changes, err := impl.GetChangedFiles(gitCtx, rootDir, commitHash)
+	if err != nil {
+		return nil, err
+	}
+	var objects []string
+	var paths []string
+	for _, change := range changes {
+		if change.ChangeType == FILE_CHANGE_TYPE_DELETED || strings.ContainsAny(change.Path, "\n") {
+			continue
+		}
+		objects = append(objects, commitHash+":"+change.Path)
+		paths = append(paths, change.Path)
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	sizes, err := impl.catFile(gitCtx, rootDir, "--batch-check=%(objecttype) %(objectsize)", objects)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []string
+	var candidatePaths []string
+	for i, line := range strings.Split(strings.TrimSuffix(string(sizes), "\n"), "\n") {
+		fields := strings.Fields(line)
+		if i >= len(objects) || len(fields) != 2 || fields[0] != "blob" {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[1], 10, 64); err == nil && size < LFS_POINTER_MAX_SIZE {
+			candidates = append(candidates, objects[i])
+			candidatePaths = append(candidatePaths, paths[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	contents, err := impl.catFile(gitCtx, rootDir, "--batch", candidates)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := parseCatFileBatch(contents)
+	if err != nil {
+		return nil, err
+	}
+	var pointers []*LfsPointer
+	for i, blob := range blobs {
+		if i >= len(candidatePaths) {
+			break
+		}
+		if oid, size, ok := ParseLfsPointer(blob); ok {
+			pointers = append(pointers, &LfsPointer{Path: candidatePaths[i], Oid: oid, Size: size})
+		}
+	}
+	return pointers, nil
+}
+
+// catFile runs cat-file in batch mode with one object per line on stdin, the output is read as is since runCommand trims
+// it and mixes stderr in
+func (impl *GitManagerBaseImpl) catFile(gitCtx GitContext, rootDir string, batchOption string, objects []string) ([]byte, error) {
+	impl.logger.Debugw("git", "-C", rootDir, "cat-file", batchOption, "objects", len(objects))
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "cat-file", batchOption)
+	defer cancel()
+	cmd.Env = append(cmd.Env, impl.getCommandEnv()...)
+	cmd.Stdin = strings.NewReader(strings.Join(objects, "\n") + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		impl.logger.Errorw("error in reading objects", "rootDir", rootDir, "batchOption", batchOption, "err", err)
+		return nil, err
+	}
+	return output, nil
+}
+
+// parseCatFileBatch returns the contents of the objects in the output of cat-file --batch, nil for missing objects
+//
+//	<oid> <type> <size>\n<content>\n or <object> missing\n
+func parseCatFileBatch(output []byte) ([][]byte, error) {
+	reader := bufio.NewReader(bytes.NewReader(output))
+	var contents [][]byte
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return contents, nil
+		} else if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			contents = append(contents, nil)
+			continue
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cat-file header %q", header)
+		}
+		content := make([]byte, size+1)
+		if _, err = io.ReadFull(reader, content); err != nil {
+			return nil, err
+		}
+		contents = append(contents, content[:size])
+	}
+}
+
+// ParseLfsPointer parses the oid and size of the lfs object from the content of a pointer file
+//
+//	version https://git-lfs.github.com/spec/v1
+//	oid sha256:<hex>
+//	size <bytes>
+func ParseLfsPointer(content []byte) (oid string, size int64, ok bool) {
+	if len(content) >= LFS_POINTER_MAX_SIZE {
+		return "", 0, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) < 3 || lines[0] != LFS_POINTER_VERSION {
+		return "", 0, false
+	}
+	for _, line := range lines[1:] {
+		key, value, found := strings.Cut(line, " ")
+		if !found {
+			return "", 0, false
+		}
+		switch key {
+		case "oid":
+			oid = strings.TrimPrefix(value, LFS_OID_PREFIX)
+		case "size":
+			parsedSize, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", 0, false
+			}
+			size = parsedSize
+		}
+	}
+	return oid, size, len(oid) > 0 && size >= 0
+}
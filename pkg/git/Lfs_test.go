@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testLfsPointer = `version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`
+
+func TestParseLfsPointer(t *testing.T) {
+	oid, size, ok := ParseLfsPointer([]byte(testLfsPointer))
+	assert.True(t, ok)
+	assert.Equal(t, "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", oid)
+	assert.Equal(t, int64(12345), size)
+	_, _, ok = ParseLfsPointer([]byte("version https://git-lfs.github.com/spec/v1\nsize 10\n"))
+	assert.False(t, ok)
+	_, _, ok = ParseLfsPointer([]byte("package main\n"))
+	assert.False(t, ok)
+}
+
+func TestGitManagerBaseImpl_GetLfsPointersInCommit(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{GitLfsSkipSmudge: true})
+	_, workDir := setupTestRemote(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "model.bin"), []byte(testLfsPointer), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "main.go"), []byte("package main\n"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add model")
+
+	pointers, err := impl.GetLfsPointersInCommit(BuildGitContext(context.Background()), workDir, runTestGitCmd(t, workDir, "rev-parse", "HEAD"))
+	assert.Nil(t, err)
+	assert.Equal(t, []*LfsPointer{{Path: "model.bin", Oid: "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", Size: 12345}}, pointers)
+}
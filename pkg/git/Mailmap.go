@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"strings"
+)
+
+const MAILMAP_FILE_NAME = ".mailmap"
+
+type mailmapIdentity struct {
+	name  string
+	email string
+}
+
+type mailmapEntry struct {
+	mailmapIdentity
+	byName map[string]mailmapIdentity // identities of entries matching the commit name too, keyed by lower case name
+}
+
+// Mailmap maps the names and emails recorded in commits to canonical ones, see gitmailmap(5)
+type Mailmap struct {
+	entries map[string]*mailmapEntry // keyed by lower case commit email
+}
+
+func NewMailmap() *Mailmap {
+	return &Mailmap{entries: make(map[string]*mailmapEntry)}
+}
+
+// Parse adds the entries of a mailmap file, entries added later override the earlier ones for the same commit identity
+func (m *Mailmap) Parse(content string) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		properName, properEmail, rest, ok := parseMailmapNameAndEmail(line)
+		if !ok {
+			continue
+		}
+		commitName, commitEmail, _, ok := parseMailmapNameAndEmail(rest)
+		if !ok {
+			// Proper Name <commit@email>
+			commitName, commitEmail, properEmail = "", properEmail, ""
+		}
+		m.add(mailmapIdentity{name: properName, email: properEmail}, commitName, commitEmail)
+	}
+}
+
+func (m *Mailmap) add(proper mailmapIdentity, commitName, commitEmail string) {
+	key := strings.ToLower(commitEmail)
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &mailmapEntry{byName: make(map[string]mailmapIdentity)}
+		m.entries[key] = entry
+	}
+	if len(commitName) == 0 {
+		if len(proper.name) > 0 {
+			entry.name = proper.name
+		}
+		if len(proper.email) > 0 {
+			entry.email = proper.email
+		}
+		return
+	}
+	entry.byName[strings.ToLower(commitName)] = proper
+}
+
+// Resolve returns the canonical name and email of a commit identity, the identity itself when it isn't mapped
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+	entry, ok := m.entries[strings.ToLower(email)]
+	if !ok {
+		return name, email
+	}
+	proper, ok := entry.byName[strings.ToLower(name)]
+	if !ok {
+		proper = entry.mailmapIdentity
+	}
+	if len(proper.name) > 0 {
+		name = proper.name
+	}
+	if len(proper.email) > 0 {
+		email = proper.email
+	}
+	return name, email
+}
+
+// parseMailmapNameAndEmail parses the leading `Name <email>` of a mailmap line, the name being optional
+func parseMailmapNameAndEmail(line string) (name, email, rest string, ok bool) {
+	start := strings.Index(line, "<")
+	if start < 0 {
+		return "", "", "", false
+	}
+	end := strings.Index(line[start:], ">")
+	if end < 0 {
+		return "", "", "", false
+	}
+	end += start
+	return strings.TrimSpace(line[:start]), line[start+1 : end], line[end+1:], true
+}
+
+// getMailmapBlobArgs makes git read the .mailmap committed at rev, checkouts have no working tree to read it from. A
+// partial clone skips it, reading the blob would make every log fetch it from the remote
+func getMailmapBlobArgs(rootDir string, rev string) []string {
+	if IsPartialClone(rootDir) {
+		return nil
+	}
+	return []string{"-c", "mailmap.blob=" + rev + ":" + MAILMAP_FILE_NAME}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	mailmap := NewMailmap()
+	mailmap.Parse(`# canonical identities
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@old.example.com>
+Jane Doe <jane@example.com> <JANE@laptop.local>
+John Roe <john@example.com> jroe <shared@example.com>
+malformed line
+`)
+	name, email := mailmap.Resolve("jane", "jane@example.com")
+	assert.Equal(t, "Jane Doe", name)
+	assert.Equal(t, "jane@example.com", email)
+	name, email = mailmap.Resolve("jane", "jane@old.example.com")
+	assert.Equal(t, "jane", name)
+	assert.Equal(t, "jane@example.com", email)
+	name, email = mailmap.Resolve("root", "jane@laptop.local")
+	assert.Equal(t, "Jane Doe", name)
+	assert.Equal(t, "jane@example.com", email)
+	name, email = mailmap.Resolve("JRoe", "shared@example.com")
+	assert.Equal(t, "John Roe", name)
+	assert.Equal(t, "john@example.com", email)
+	name, email = mailmap.Resolve("someone", "shared@example.com")
+	assert.Equal(t, "someone", name)
+	assert.Equal(t, "shared@example.com", email)
+
+	// later entries override the earlier ones
+	mailmap.Parse("Jane D <jane@example.com>")
+	name, _ = mailmap.Resolve("jane", "jane@example.com")
+	assert.Equal(t, "Jane D", name)
+	var noMailmap *Mailmap
+	name, email = noMailmap.Resolve("jane", "jane@example.com")
+	assert.Equal(t, "jane", name)
+	assert.Equal(t, "jane@example.com", email)
+}
+
+func TestGitManager_CommitsAreMailmapped(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	operatorMailmap := filepath.Join(t.TempDir(), "mailmap")
+	assert.Nil(t, os.WriteFile(operatorMailmap, []byte("Release Bot <bot@example.com> <ci@devtron.ai>\n"), 0644))
+	for name, useGitCli := range map[string]bool{"cli": true, "go-git": false} {
+		t.Run(name, func(t *testing.T) {
+			gitManager := NewGitManagerImpl(logger, &internals.Configuration{UseGitCli: useGitCli, MailmapFile: operatorMailmap})
+			remoteDir, workDir := setupTestRemote(t)
+			assert.Nil(t, os.WriteFile(filepath.Join(workDir, MAILMAP_FILE_NAME), []byte("Devtron Team <team@devtron.ai> <devtron@devtron.ai>\n"), 0644))
+			runTestGitCmd(t, workDir, "add", MAILMAP_FILE_NAME)
+			runTestGitCmd(t, workDir, "commit", "-m", "add mailmap")
+			runTestGitCmd(t, workDir, "-c", "user.email=ci@devtron.ai", "commit", "--allow-empty", "--author", "Jane <jane@example.com>", "-m", "release")
+			runTestGitCmd(t, workDir, "push", "origin", "main")
+
+			gitCtx := BuildGitContext(context.Background())
+			checkoutPath := filepath.Join(t.TempDir(), "checkout")
+			assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+			_, _, err := gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			repository, err := gitManager.OpenRepoPlain(checkoutPath)
+			assert.Nil(t, err)
+			iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 2})
+			assert.Nil(t, err)
+			release, err := iterator.Next()
+			assert.Nil(t, err)
+			addMailmap, err := iterator.Next()
+			assert.Nil(t, err)
+			iterator.Close()
+			// Author keeps its meaning of each manager, the details tell the author and committer apart
+			if useGitCli {
+				assert.Equal(t, "Release Bot <bot@example.com>", release.GetCommit().Author)
+			} else {
+				assert.Equal(t, "Jane <jane@example.com>", release.GetCommit().Author)
+			}
+			assert.Equal(t, "Jane", release.GetCommit().AuthorDetail.Name)
+			assert.Equal(t, "Release Bot", release.GetCommit().CommitterDetail.Name)
+			assert.Equal(t, "Devtron Team <team@devtron.ai>", addMailmap.GetCommit().Author)
+			assert.Equal(t, "team@devtron.ai", addMailmap.GetCommit().AuthorDetail.Email)
+		})
+	}
+}
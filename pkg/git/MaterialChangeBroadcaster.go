@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"go.uber.org/zap"
+	"sync"
+)
+
+const MATERIAL_CHANGE_SUBSCRIPTION_BUFFER_SIZE = 100
+
+// MaterialChangeSubscription receives the updates of a pipeline material until it is unsubscribed. Changes is closed
+// with Lagged set when the subscriber doesn't keep up, it then has to subscribe again and resume from the last commit it has seen
+type MaterialChangeSubscription struct {
+	PipelineMaterialId int
+	Changes            chan *CiPipelineMaterialBean
+	Lagged             bool
+}
+
+// MaterialChangeBroadcaster fans out the material updates found by the watcher to the subscribers of the material
+type MaterialChangeBroadcaster interface {
+	Subscribe(pipelineMaterialId int) *MaterialChangeSubscription
+	Unsubscribe(subscription *MaterialChangeSubscription)
+	Publish(material *CiPipelineMaterialBean)
+}
+
+type MaterialChangeBroadcasterImpl struct {
+	logger        *zap.SugaredLogger
+	mutex         sync.Mutex
+	subscriptions map[int]map[*MaterialChangeSubscription]bool
+}
+
+func NewMaterialChangeBroadcasterImpl(logger *zap.SugaredLogger) *MaterialChangeBroadcasterImpl {
+	return &MaterialChangeBroadcasterImpl{
+		logger:        logger,
+		subscriptions: make(map[int]map[*MaterialChangeSubscription]bool),
+	}
+}
+
+func (impl *MaterialChangeBroadcasterImpl) Subscribe(pipelineMaterialId int) *MaterialChangeSubscription {
+	subscription := &MaterialChangeSubscription{
+		PipelineMaterialId: pipelineMaterialId,
+		Changes:            make(chan *CiPipelineMaterialBean, MATERIAL_CHANGE_SUBSCRIPTION_BUFFER_SIZE),
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if impl.subscriptions[pipelineMaterialId] == nil {
+		impl.subscriptions[pipelineMaterialId] = make(map[*MaterialChangeSubscription]bool)
+	}
+	impl.subscriptions[pipelineMaterialId][subscription] = true
+	return subscription
+}
+
+func (impl *MaterialChangeBroadcasterImpl) Unsubscribe(subscription *MaterialChangeSubscription) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.remove(subscription)
+}
+
+func (impl *MaterialChangeBroadcasterImpl) Publish(material *CiPipelineMaterialBean) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	for subscription := range impl.subscriptions[material.Id] {
+		select {
+		case subscription.Changes <- material:
+		default:
+			// a slow subscriber must not block the watcher
+			impl.logger.Warnw("material change subscriber lagging, closing subscription", "pipelineMaterialId", material.Id)
+			subscription.Lagged = true
+			impl.remove(subscription)
+		}
+	}
+}
+
+func (impl *MaterialChangeBroadcasterImpl) remove(subscription *MaterialChangeSubscription) {
+	subscriptions := impl.subscriptions[subscription.PipelineMaterialId]
+	if !subscriptions[subscription] {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(impl.subscriptions, subscription.PipelineMaterialId)
+	}
+	close(subscription.Changes)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMaterialChangeBroadcaster(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	broadcaster := NewMaterialChangeBroadcasterImpl(logger)
+	subscription := broadcaster.Subscribe(1)
+	other := broadcaster.Subscribe(2)
+
+	broadcaster.Publish(&CiPipelineMaterialBean{Id: 1, GitCommit: &GitCommitBase{Commit: "a"}})
+	change := <-subscription.Changes
+	assert.Equal(t, "a", change.GitCommit.Commit)
+	assert.Equal(t, 0, len(other.Changes))
+
+	// a subscriber not reading its changes is closed instead of blocking the publisher
+	for i := 0; i <= MATERIAL_CHANGE_SUBSCRIPTION_BUFFER_SIZE; i++ {
+		broadcaster.Publish(&CiPipelineMaterialBean{Id: 2})
+	}
+	for range other.Changes {
+	}
+	assert.True(t, other.Lagged)
+	broadcaster.Unsubscribe(other)
+
+	broadcaster.Unsubscribe(subscription)
+	_, ok := <-subscription.Changes
+	assert.False(t, ok)
+	assert.False(t, subscription.Lagged)
+	assert.Empty(t, broadcaster.subscriptions)
+}
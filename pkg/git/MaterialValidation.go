@@ -0,0 +1,270 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	commonLibGitManager "github.com/devtron-labs/common-lib/git-manager"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/util"
+	"golang.org/x/mod/semver"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// MATERIAL_VALIDATION_MAX_MATCHED_REFS is the number of matching refs listed in the validation, all are counted
+	MATERIAL_VALIDATION_MAX_MATCHED_REFS = 50
+	// MATERIAL_VALIDATION_MAX_FETCHED_REFS is the number of matching refs fetched to find the latest commit, the
+	// highest tag versions and the last branches by name are fetched when more match
+	MATERIAL_VALIDATION_MAX_FETCHED_REFS = 10
+
+	MATERIAL_VALIDATION_DIR_PREFIX = "material-validation-"
+
+	PEELED_TAG_SUFFIX = "^{}"
+)
+
+// MaterialValidationRequest is a material to check before it is saved, with the credentials of a saved git provider
+// or of GitProvider when it isn't saved yet
+type MaterialValidationRequest struct {
+	Url                string           `json:"url"`
+	GitProviderId      int              `json:"gitProviderId"`
+	GitProvider        *sql.GitProvider `json:"gitProvider,omitempty"`
+	CredentialProvider string           `json:"credentialProvider,omitempty"`
+	CredentialRef      string           `json:"credentialRef,omitempty"`
+	// SourceType and Value are the ones of the pipeline material, a branch, a tag pattern or a branch regex
+	SourceType    sql.SourceType `json:"sourceType"`
+	Value         string         `json:"value"`
+	FilterPattern []string       `json:"filterPattern,omitempty"`
+}
+
+// MaterialValidation is the result of a dry run of a material, Valid is set when the remote is reachable with the
+// credentials, the value matches at least one ref and the filters parse
+type MaterialValidation struct {
+	Valid           bool           `json:"valid"`
+	RemoteReachable bool           `json:"remoteReachable"`
+	RemoteError     string         `json:"remoteError,omitempty"`
+	RemoteErrorCode string         `json:"remoteErrorCode,omitempty"`
+	RemoteErrorHint string         `json:"remoteErrorHint,omitempty"`
+	MatchedRefs     []string       `json:"matchedRefs,omitempty"` // at most MATERIAL_VALIDATION_MAX_MATCHED_REFS
+	MatchedRefCount int            `json:"matchedRefCount"`
+	Errors          []string       `json:"errors,omitempty"`
+	LatestCommit    *GitCommitBase `json:"latestCommit,omitempty"` // the commit the material would start from
+}
+
+// SetRemoteError records the failure of reaching the remote with its classification
+func (validation *MaterialValidation) SetRemoteError(err error) {
+	validation.RemoteReachable = err == nil
+	if err == nil {
+		return
+	}
+	validation.RemoteError = err.Error()
+	validation.RemoteErrorCode = GetGitErrorCode(err)
+	validation.RemoteErrorHint = GetGitErrorUserMessage(err)
+}
+
+// ValidateFilterPattern returns the errors of the path filters of a material, parsed the way the poll and the ci
+// trigger apply them
+func ValidateFilterPattern(filterPattern []string) []string {
+	var errs []string
+	for _, pattern := range filterPattern {
+		trimmed := strings.TrimSpace(pattern)
+		if len(strings.TrimPrefix(trimmed, EXCLUDE_PATH_IDENTIFIER)) == 0 {
+			errs = append(errs, fmt.Sprintf("empty path filter %q", pattern))
+			continue
+		}
+		glob := strings.TrimPrefix(strings.TrimPrefix(trimmed, EXCLUDE_PATH_IDENTIFIER), "/")
+		if _, err := regexp.Compile(globToRegex(glob)); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid path filter %q: %v", pattern, err))
+			continue
+		}
+		if _, err := regexp.Compile(util.GetPathRegex(trimmed)); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid path filter %q: %v", pattern, err))
+		}
+	}
+	return errs
+}
+
+// MatchMaterialRefs returns the refs of the remote the pipeline material would track, sorted so that the ones to
+// prefer come last. Materials of the other source types don't track refs, they have no matches and no error
+func MatchMaterialRefs(sourceType sql.SourceType, value string, remoteRefs map[string]string, fetchChangeRefs bool) ([]string, error) {
+	var matched []string
+	switch sourceType {
+	case sql.SOURCE_TYPE_BRANCH_FIXED, "":
+		branch, _ := GetBranchReference(value)
+		if len(branch) == 0 {
+			return nil, fmt.Errorf("branch is required")
+		}
+		ref := GetRemoteRefOfBranch(branch, fetchChangeRefs)
+		if _, ok := remoteRefs[ref]; ok {
+			matched = append(matched, ref)
+		}
+	case sql.SOURCE_TYPE_BRANCH_REGEX:
+		branchRegex, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch regex %q: %v", value, err)
+		}
+		for ref := range remoteRefs {
+			if branch, found := strings.CutPrefix(ref, BRANCH_REF_PREFIX); found && branchRegex.MatchString(branch) {
+				matched = append(matched, ref)
+			}
+		}
+		sort.Strings(matched)
+	case sql.SOURCE_TYPE_TAG_ANY:
+		matcher, err := GetTagMatcher(value)
+		if err != nil {
+			return nil, err
+		}
+		for ref := range remoteRefs {
+			if tag, found := strings.CutPrefix(ref, TAG_REF_PREFIX); found && !strings.HasSuffix(tag, PEELED_TAG_SUFFIX) && matcher(tag) {
+				matched = append(matched, ref)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool {
+			versionI, versionJ := toSemver(strings.TrimPrefix(matched[i], TAG_REF_PREFIX)), toSemver(strings.TrimPrefix(matched[j], TAG_REF_PREFIX))
+			if semver.IsValid(versionI) && semver.IsValid(versionJ) && semver.Compare(versionI, versionJ) != 0 {
+				return semver.Compare(versionI, versionJ) < 0
+			}
+			if semver.IsValid(versionI) != semver.IsValid(versionJ) {
+				return semver.IsValid(versionJ)
+			}
+			return matched[i] < matched[j]
+		})
+	}
+	return matched, nil
+}
+
+// getRefCommit returns the commit of a ref listed by ls-remote, the peeled one for annotated tags
+func getRefCommit(remoteRefs map[string]string, ref string) string {
+	if commit, ok := remoteRefs[ref+PEELED_TAG_SUFFIX]; ok {
+		return commit
+	}
+	return remoteRefs[ref]
+}
+
+// FetchRefs fetches the refs from origin into the same refs, shallow when depth is set
+func (impl *GitManagerBaseImpl) FetchRefs(gitCtx GitContext, rootDir string, depth int, refs []string) (errMsg string, err error) {
+	cmdArgs := []string{"-C", rootDir, "fetch", "origin", "--no-tags"}
+	if depth > 0 {
+		cmdArgs = append(cmdArgs, "--depth", fmt.Sprint(depth))
+	}
+	for _, ref := range refs {
+		cmdArgs = append(cmdArgs, "+"+ref+":"+ref)
+	}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
+	if err != nil {
+		//making it non-blocking
+		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
+	}
+	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
+	output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+	impl.logger.Debugw("fetch refs output", "root", rootDir, "opt", output, "errMsg", errMsg, "error", err)
+	return errMsg, err
+}
+
+// ValidateMaterial does what adding the material and its first poll would, in a temporary repo which is removed after.
+// The credentials are taken from gitCtx, sshPrivateKey is used when the material is accessed over ssh
+func (impl *RepositoryManagerImpl) ValidateMaterial(gitCtx GitContext, request *MaterialValidationRequest, sshPrivateKey string) (*MaterialValidation, error) {
+	validation := &MaterialValidation{}
+	validation.Errors = append(validation.Errors, ValidateFilterPattern(request.FilterPattern)...)
+	rootDir, err := os.MkdirTemp("", MATERIAL_VALIDATION_DIR_PREFIX)
+	if err != nil {
+		impl.logger.Errorw("error in creating material validation dir", "err", err)
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(rootDir); err != nil {
+			impl.logger.Errorw("error in removing material validation dir", "rootDir", rootDir, "err", err)
+		}
+	}()
+	if err = impl.gitManager.Init(gitCtx, rootDir, request.Url, true); err != nil {
+		impl.logger.Errorw("error in initialising material validation repo", "url", SanitizeText(request.Url), "err", err)
+		return nil, err
+	}
+	if len(sshPrivateKey) > 0 {
+		sshPrivateKeyPath := filepath.Join(rootDir, SSH_PRIVATE_KEY_FILE_NAME)
+		if err = os.WriteFile(sshPrivateKeyPath, []byte(sshPrivateKey), 0600); err != nil {
+			return nil, err
+		}
+		if _, errMsg, err := impl.gitManager.ConfigureSshCommand(gitCtx, rootDir, sshPrivateKeyPath); err != nil {
+			impl.logger.Errorw("error in configuring ssh command for material validation", "errMsg", errMsg, "err", err)
+			return nil, err
+		}
+	}
+
+	remoteRefs, err := impl.LsRemote(gitCtx, rootDir, "origin", nil)
+	validation.SetRemoteError(err)
+	if err != nil {
+		validation.Errors = append(validation.Errors, "remote is not reachable: "+validation.RemoteError)
+		return validation, nil
+	}
+	matchedRefs, err := MatchMaterialRefs(request.SourceType, request.Value, remoteRefs, impl.configuration.FetchGerritChangeRefs)
+	if err != nil {
+		validation.Errors = append(validation.Errors, err.Error())
+		return validation, nil
+	}
+	validation.MatchedRefCount = len(matchedRefs)
+	validation.MatchedRefs = matchedRefs[:min(len(matchedRefs), MATERIAL_VALIDATION_MAX_MATCHED_REFS)]
+	isRefMaterial := request.SourceType == sql.SOURCE_TYPE_BRANCH_FIXED || request.SourceType == sql.SOURCE_TYPE_BRANCH_REGEX ||
+		request.SourceType == sql.SOURCE_TYPE_TAG_ANY || len(request.SourceType) == 0
+	if isRefMaterial && len(matchedRefs) == 0 {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("no ref at remote matches %q", request.Value))
+	}
+	if len(matchedRefs) > 0 {
+		validation.LatestCommit, err = impl.getLatestCommitOfRefs(gitCtx, rootDir, remoteRefs, matchedRefs[max(0, len(matchedRefs)-MATERIAL_VALIDATION_MAX_FETCHED_REFS):])
+		if err != nil {
+			validation.Errors = append(validation.Errors, "error in fetching the latest commit: "+SanitizeText(err.Error()))
+		}
+	}
+	validation.Valid = len(validation.Errors) == 0
+	return validation, nil
+}
+
+// getLatestCommitOfRefs fetches the last commit of each of the refs and returns the newest of them
+func (impl *RepositoryManagerImpl) getLatestCommitOfRefs(gitCtx GitContext, rootDir string, remoteRefs map[string]string, refs []string) (*GitCommitBase, error) {
+	var errMsg string
+	err := impl.retry(gitCtx, "fetchRefs", func() (err error) {
+		errMsg, err = impl.gitManager.FetchRefs(gitCtx, rootDir, 1, refs)
+		return err
+	})
+	if err != nil {
+		impl.logger.Errorw("error in fetching refs for material validation", "refs", refs, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	var latestCommit *GitCommitBase
+	for _, ref := range refs {
+		commit, err := impl.GetCommitMetadata(gitCtx, rootDir, getRefCommit(remoteRefs, ref))
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(ref, BRANCH_REF_PREFIX) {
+			commit.Branch = strings.TrimPrefix(ref, BRANCH_REF_PREFIX)
+		} else if tag, found := strings.CutPrefix(ref, TAG_REF_PREFIX); found {
+			commit.Tag = &GitTag{Name: tag, Commit: commit.Commit}
+		}
+		if latestCommit == nil || !commit.Date.Before(latestCommit.Date) {
+			latestCommit = commit
+		}
+	}
+	return latestCommit, nil
+}
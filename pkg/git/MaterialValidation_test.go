@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchMaterialRefs(t *testing.T) {
+	remoteRefs := map[string]string{
+		"HEAD":                   "c1",
+		"refs/heads/main":        "c1",
+		"refs/heads/release-1.0": "c2",
+		"refs/heads/release-1.1": "c3",
+		"refs/tags/v1.2.0":       "t1",
+		"refs/tags/v1.2.0^{}":    "c2",
+		"refs/tags/v1.10.0":      "c3",
+		"refs/tags/nightly":      "c1",
+	}
+
+	matched, err := MatchMaterialRefs(sql.SOURCE_TYPE_BRANCH_FIXED, "main", remoteRefs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"refs/heads/main"}, matched)
+
+	matched, err = MatchMaterialRefs(sql.SOURCE_TYPE_BRANCH_FIXED, "develop", remoteRefs, false)
+	assert.Nil(t, err)
+	assert.Empty(t, matched)
+
+	matched, err = MatchMaterialRefs(sql.SOURCE_TYPE_BRANCH_REGEX, "release-.*", remoteRefs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"refs/heads/release-1.0", "refs/heads/release-1.1"}, matched)
+
+	_, err = MatchMaterialRefs(sql.SOURCE_TYPE_BRANCH_REGEX, "release-(", remoteRefs, false)
+	assert.NotNil(t, err)
+
+	// the peeled refs are not listed and the highest versions come last
+	matched, err = MatchMaterialRefs(sql.SOURCE_TYPE_TAG_ANY, "v*", remoteRefs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"refs/tags/v1.2.0", "refs/tags/v1.10.0"}, matched)
+	assert.Equal(t, "c2", getRefCommit(remoteRefs, "refs/tags/v1.2.0"))
+
+	matched, err = MatchMaterialRefs(sql.SOURCE_TYPE_WEBHOOK, "", remoteRefs, false)
+	assert.Nil(t, err)
+	assert.Empty(t, matched)
+}
+
+func TestValidateFilterPattern(t *testing.T) {
+	assert.Empty(t, ValidateFilterPattern([]string{"src/**", "!docs/*.md"}))
+	errs := ValidateFilterPattern([]string{"src/**", "!", "src/[a"})
+	assert.Len(t, errs, 2)
+	assert.True(t, strings.Contains(errs[0], "empty path filter"))
+}
+
+func TestRepositoryManager_ValidateMaterial(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, workDir, "tag", "-a", "v1.0.0", "-m", "v1.0.0", "HEAD~1")
+	runTestGitCmd(t, workDir, "tag", "v1.1.0")
+	runTestGitCmd(t, workDir, "push", "origin", "--tags")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	headCommit := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	tempDirsBefore, _ := filepath.Glob(filepath.Join(os.TempDir(), MATERIAL_VALIDATION_DIR_PREFIX+"*"))
+
+	t.Run("branch", func(t *testing.T) {
+		validation, err := repositoryManager.ValidateMaterial(gitCtx, &MaterialValidationRequest{Url: remoteDir, SourceType: sql.SOURCE_TYPE_BRANCH_FIXED, Value: "main", FilterPattern: []string{"src/**"}}, "")
+		assert.Nil(t, err)
+		assert.True(t, validation.Valid, validation.Errors)
+		assert.True(t, validation.RemoteReachable)
+		assert.Equal(t, []string{"refs/heads/main"}, validation.MatchedRefs)
+		assert.Equal(t, headCommit, validation.LatestCommit.Commit)
+		assert.Equal(t, "main", validation.LatestCommit.Branch)
+	})
+
+	t.Run("tag pattern", func(t *testing.T) {
+		validation, err := repositoryManager.ValidateMaterial(gitCtx, &MaterialValidationRequest{Url: remoteDir, SourceType: sql.SOURCE_TYPE_TAG_ANY, Value: "v*"}, "")
+		assert.Nil(t, err)
+		assert.True(t, validation.Valid, validation.Errors)
+		assert.Equal(t, 2, validation.MatchedRefCount)
+		assert.Equal(t, headCommit, validation.LatestCommit.Commit)
+		assert.Equal(t, "v1.1.0", validation.LatestCommit.Tag.Name)
+	})
+
+	t.Run("no matching branch and bad filter", func(t *testing.T) {
+		validation, err := repositoryManager.ValidateMaterial(gitCtx, &MaterialValidationRequest{Url: remoteDir, SourceType: sql.SOURCE_TYPE_BRANCH_FIXED, Value: "develop", FilterPattern: []string{"src/[a"}}, "")
+		assert.Nil(t, err)
+		assert.False(t, validation.Valid)
+		assert.True(t, validation.RemoteReachable)
+		assert.Len(t, validation.Errors, 2)
+		assert.Nil(t, validation.LatestCommit)
+	})
+
+	t.Run("unreachable remote", func(t *testing.T) {
+		validation, err := repositoryManager.ValidateMaterial(gitCtx, &MaterialValidationRequest{Url: filepath.Join(t.TempDir(), "missing.git"), SourceType: sql.SOURCE_TYPE_BRANCH_FIXED, Value: "main"}, "")
+		assert.Nil(t, err)
+		assert.False(t, validation.Valid)
+		assert.False(t, validation.RemoteReachable)
+		assert.NotEmpty(t, validation.RemoteError)
+	})
+
+	tempDirsAfter, _ := filepath.Glob(filepath.Join(os.TempDir(), MATERIAL_VALIDATION_DIR_PREFIX+"*"))
+	assert.Equal(t, len(tempDirsBefore), len(tempDirsAfter))
+}
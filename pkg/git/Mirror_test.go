@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryManager_FetchWithMirrors(t *testing.T) {
+	if _, err := os.Stat(GIT_BASE_DIR); err != nil {
+		t.Skip("free space of the git base dir is checked before fetching")
+	}
+	remoteDir, workDir := setupTestRemote(t)
+	primaryUrl := filepath.Join(t.TempDir(), "down.git")
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, primaryUrl, true))
+
+	_, _, fetchedFrom, err := repositoryManager.FetchWithMirrors(gitCtx, primaryUrl, []string{remoteDir}, checkoutPath)
+	assert.Nil(t, err)
+	assert.Equal(t, remoteDir, fetchedFrom)
+	// the branches of the mirror are stored as the ones of origin
+	head := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	assert.Equal(t, head, runTestGitCmd(t, checkoutPath, "rev-parse", "refs/remotes/origin/main"))
+	assert.Equal(t, primaryUrl, runTestGitCmd(t, checkoutPath, "remote", "get-url", "origin"))
+
+	_, _, fetchedFrom, err = repositoryManager.FetchWithMirrors(gitCtx, primaryUrl, nil, checkoutPath)
+	assert.NotNil(t, err)
+	assert.Empty(t, fetchedFrom)
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"path/filepath"
+	"strings"
+
+	commonLibGitManager "github.com/devtron-labs/common-lib/git-manager"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// PARTIAL_CLONE_FETCH_BATCH_SIZE is the number of missing blobs asked for in a single fetch
+const PARTIAL_CLONE_FETCH_BATCH_SIZE = 1000
+
+// IsPartialClone checks if objects of the repo at rootDir may be missing, i.e. it was fetched with a filter
+func IsPartialClone(rootDir string) bool {
+	promisorPacks, err := filepath.Glob(filepath.Join(GetGitDir(rootDir), "objects", "pack", "*.promisor"))
+	return err == nil && len(promisorPacks) > 0
+}
+
+// prefetchDiffBlobs fetches the blobs of the files changed between revs which are missing in a partial clone, in
+// batches and with the credentials of the material. Left alone, git fetches them lazily one diff at a time and
+// without the credentials. revs are passed to diff-tree as is, a single commit is compared with its first parent
+func (impl *GitManagerBaseImpl) prefetchDiffBlobs(gitCtx GitContext, rootDir string, revs []string, paths []string) {
+	if !IsPartialClone(rootDir) {
+		return
+	}
+	missingBlobs, err := impl.getMissingDiffBlobs(gitCtx, rootDir, revs, paths)
+	if err != nil {
+		// making it non-blocking, git still fetches the blobs lazily
+		impl.logger.Errorw("error in listing missing blobs of partial clone", "rootDir", rootDir, "revs", revs, "err", err)
+		return
+	}
+	if len(missingBlobs) == 0 {
+		return
+	}
+	impl.logger.Infow("fetching missing blobs of partial clone", "rootDir", rootDir, "revs", revs, "count", len(missingBlobs))
+	tlsPathInfo, err := commonLibGitManager.CreateFilesForTlsData(commonLibGitManager.BuildTlsData(gitCtx.TLSKey, gitCtx.TLSCertificate, gitCtx.CACert, gitCtx.TLSVerificationEnabled), TLS_FILES_DIR)
+	if err != nil {
+		//making it non-blocking
+		impl.logger.Errorw("error encountered in createFilesForTlsData", "err", err)
+	}
+	defer commonLibGitManager.DeleteTlsFiles(tlsPathInfo)
+	for start := 0; start < len(missingBlobs); start += PARTIAL_CLONE_FETCH_BATCH_SIZE {
+		end := min(start+PARTIAL_CLONE_FETCH_BATCH_SIZE, len(missingBlobs))
+		cmd, cancel := impl.createCmdWithContext(gitCtx, "git", getBlobFetchCmdArgs(rootDir, missingBlobs[start:end])...)
+		output, errMsg, err := impl.runCommandWithCred(gitCtx, cmd, gitCtx.Username, gitCtx.Password, tlsPathInfo)
+		cancel()
+		if err != nil {
+			impl.logger.Errorw("error in fetching missing blobs of partial clone", "rootDir", rootDir, "opt", output, "errMsg", errMsg, "err", err)
+			return
+		}
+	}
+}
+
+// getMissingDiffBlobs lists the blobs on both sides of the files changed between revs which are not in the object store
+func (impl *GitManagerBaseImpl) getMissingDiffBlobs(gitCtx GitContext, rootDir string, revs []string, paths []string) ([]string, error) {
+	// renames are not detected as that needs the blobs, the trees are enough to list the changed files
+	cmdArgs := append([]string{"-C", rootDir, "diff-tree", "-r", "--root", "-m", "--first-parent", "--raw", "-z", "--no-renames", "--no-abbrev"}, revs...)
+	cmdArgs = append(cmdArgs, "--")
+	for _, path := range paths {
+		if path = strings.Trim(path, "/"); len(path) > 0 {
+			cmdArgs = append(cmdArgs, path)
+		}
+	}
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in listing changed files", "rootDir", rootDir, "revs", revs, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	blobs := parseRawDiffBlobs(output)
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+	// the object store is read directly, asking git for a missing object fetches it
+	repository, err := git.PlainOpen(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	missingBlobs := make([]string, 0)
+	for _, blob := range blobs {
+		if repository.Storer.HasEncodedObject(plumbing.NewHash(blob)) != nil {
+			missingBlobs = append(missingBlobs, blob)
+		}
+	}
+	return missingBlobs, nil
+}
+
+// parseRawDiffBlobs returns the unique blob hashes of the NUL separated --raw output of diff-tree, leaving out the
+// zero hash of added and deleted files and the commits of submodules
+func parseRawDiffBlobs(output string) []string {
+	blobs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, token := range strings.Split(output, "\x00") {
+		if !strings.HasPrefix(token, ":") {
+			continue
+		}
+		// :oldMode newMode oldHash newHash status
+		fields := strings.Fields(token[1:])
+		if len(fields) < 4 {
+			continue
+		}
+		for i := 0; i < 2; i++ {
+			mode, hash := fields[i], fields[i+2]
+			if mode == SUBMODULE_FILE_MODE || hash == ZERO_HASH || seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			blobs = append(blobs, hash)
+		}
+	}
+	return blobs
+}
+
+// getBlobFetchCmdArgs fetches the blobs from origin the way git fetches a missing object lazily, without negotiation,
+// refs or tags
+func getBlobFetchCmdArgs(rootDir string, blobs []string) []string {
+	args := []string{"-C", rootDir, "-c", "fetch.negotiationAlgorithm=noop", "fetch", "origin", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no", "--filter=blob:none"}
+	return append(args, blobs...)
+}
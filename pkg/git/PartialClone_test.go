@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitCliManager_PartialCloneDiff(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, remoteDir, "config", "uploadpack.allowFilter", "true")
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.yaml"), []byte("replicas: 1\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "README.md"), []byte("readme\n"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add files")
+	fromCommit := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "app.yaml"), []byte("replicas: 2\n"), 0644))
+	runTestGitCmd(t, workDir, "mv", "README.md", "docs.md")
+	runTestGitCmd(t, workDir, "commit", "-am", "scale up")
+	toCommit := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	conf := &internals.Configuration{UseGitCli: true, PartialCloneFilter: "blob:none"}
+	baseImpl := NewGitManagerBaseImpl(logger, conf)
+	gitManager := NewGitCliManagerImpl(baseImpl, logger, conf)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, "file://"+remoteDir, true))
+	_, _, err = gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+	assert.True(t, IsPartialClone(checkoutPath))
+
+	// log parsing does not need the blobs
+	repository, err := gitManager.OpenRepoPlain(checkoutPath)
+	assert.Nil(t, err)
+	iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{BranchRef: "refs/remotes/origin/main", Branch: "main", CommitCount: 5})
+	assert.Nil(t, err)
+	commit, err := iterator.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, toCommit, commit.GetCommit().Commit)
+	missingBlobs, err := baseImpl.getMissingDiffBlobs(gitCtx, checkoutPath, []string{fromCommit, toCommit}, nil)
+	assert.Nil(t, err)
+	assert.Len(t, missingBlobs, 3)
+
+	fileStats, err := gitManager.FetchDiffStatBetweenCommitsWithNumstat(gitCtx, fromCommit, toCommit, checkoutPath)
+	assert.Nil(t, err)
+	assert.Len(t, fileStats, 2)
+	missingBlobs, err = baseImpl.getMissingDiffBlobs(gitCtx, checkoutPath, []string{fromCommit, toCommit}, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, missingBlobs)
+
+	diff, err := baseImpl.GetDiffBetweenCommits(gitCtx, checkoutPath, fromCommit, toCommit, []string{"app.yaml"}, 3)
+	assert.Nil(t, err)
+	assert.Len(t, diff.Files, 1)
+}
+
+func TestParseRawDiffBlobs(t *testing.T) {
+	output := ":100644 100644 1111111111111111111111111111111111111111 2222222222222222222222222222222222222222 M\x00app.yaml\x00" +
+		":000000 100644 0000000000000000000000000000000000000000 3333333333333333333333333333333333333333 A\x00new.yaml\x00" +
+		":160000 160000 4444444444444444444444444444444444444444 5555555555555555555555555555555555555555 M\x00lib\x00" +
+		":100644 000000 2222222222222222222222222222222222222222 0000000000000000000000000000000000000000 D\x00old.yaml\x00"
+	assert.Equal(t, []string{"1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222",
+		"3333333333333333333333333333333333333333"}, parseRawDiffBlobs(output))
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+const EXCLUDE_PATH_IDENTIFIER = "!"
+
+// GetPathFilterFromPattern splits the filter pattern of a git material into include and exclude globs,
+// exclude globs are the ones prefixed with !
+func GetPathFilterFromPattern(filterPattern []string) (includePaths []string, excludePaths []string) {
+	for _, pattern := range filterPattern {
+		pattern = strings.TrimSpace(pattern)
+		if excludePath, found := strings.CutPrefix(pattern, EXCLUDE_PATH_IDENTIFIER); found {
+			excludePaths = append(excludePaths, strings.TrimPrefix(excludePath, "/"))
+		} else if len(pattern) > 0 {
+			includePaths = append(includePaths, strings.TrimPrefix(pattern, "/"))
+		}
+	}
+	return includePaths, excludePaths
+}
+
+// getPathspecArgs returns the git log arguments limiting the commits to the ones touching the include paths
+// and not only the exclude paths
+func getPathspecArgs(includePaths []string, excludePaths []string) []string {
+	if len(includePaths) == 0 && len(excludePaths) == 0 {
+		return nil
+	}
+	args := []string{"--"}
+	for _, includePath := range includePaths {
+		args = append(args, ":(glob)"+includePath)
+	}
+	for _, excludePath := range excludePaths {
+		args = append(args, ":(glob,exclude)"+excludePath)
+	}
+	return args
+}
+
+// getPathFilter is the go-git equivalent of getPathspecArgs, it is called for each file changed in a commit
+// and the commit is kept if it returns true for any of them
+func getPathFilter(includePaths []string, excludePaths []string) func(string) bool {
+	if len(includePaths) == 0 && len(excludePaths) == 0 {
+		return nil
+	}
+	includeRegexes, excludeRegexes := globsToRegexes(includePaths), globsToRegexes(excludePaths)
+	return func(path string) bool {
+		return (len(includeRegexes) == 0 || matchesAny(includeRegexes, path)) && !matchesAny(excludeRegexes, path)
+	}
+}
+
+func globsToRegexes(globs []string) []*regexp.Regexp {
+	regexes := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		regexes = append(regexes, regexp.MustCompile(globToRegex(glob)))
+	}
+	return regexes
+}
+
+func matchesAny(regexes []*regexp.Regexp, path string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegex follows the glob pathspec magic of git, * and ? don't match /, ** matches across directories
+// and a pattern matching a directory matches everything under it
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		case glob[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		}
+	}
+	sb.WriteString("(/.*)?$")
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetPathFilter(t *testing.T) {
+	filter := getPathFilter([]string{"services/api", "libs/**/*.go"}, []string{"services/api/docs/**", "*.md"})
+	assert.True(t, filter("services/api/main.go"))
+	assert.True(t, filter("libs/common/util/strings.go"))
+	assert.False(t, filter("libs/common/README"))
+	assert.False(t, filter("services/api/docs/index.html"))
+	assert.False(t, filter("services/web/main.go"))
+	assert.False(t, filter("services/apiv2/main.go"))
+	assert.Nil(t, getPathFilter(nil, nil))
+
+	includePaths, excludePaths := GetPathFilterFromPattern([]string{"/services/api/**", "!services/api/docs/**"})
+	assert.Equal(t, []string{"services/api/**"}, includePaths)
+	assert.Equal(t, []string{"services/api/docs/**"}, excludePaths)
+}
+
+func TestGitManager_PathFilteredCommits(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	commitFile := func(file string, message string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(workDir, file)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(workDir, file), []byte(message), 0644))
+		runTestGitCmd(t, workDir, "add", file)
+		runTestGitCmd(t, workDir, "commit", "-m", message)
+	}
+	commitFile("services/api/main.go", "api change")
+	commitFile("services/web/main.go", "web change")
+	commitFile("services/api/docs/README.md", "api docs change")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	for name, conf := range map[string]*internals.Configuration{"cli": {UseGitCli: true}, "go-git": {}} {
+		t.Run(name, func(t *testing.T) {
+			gitManager := NewGitManagerImpl(logger, conf)
+			gitCtx := BuildGitContext(context.Background())
+			checkoutPath := filepath.Join(t.TempDir(), "checkout")
+			assert.Nil(t, gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+			_, _, err := gitManager.Fetch(gitCtx, checkoutPath)
+			assert.Nil(t, err)
+			repository, err := gitManager.OpenRepoPlain(checkoutPath)
+			assert.Nil(t, err)
+
+			iterator, err := gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{
+				BranchRef:    "refs/remotes/origin/main",
+				Branch:       "main",
+				CommitCount:  10,
+				IncludePaths: []string{"services/api"},
+				ExcludePaths: []string{"**/*.md"},
+			})
+			assert.Nil(t, err)
+			var messages []string
+			for commit, err := iterator.Next(); err == nil && commit != nil; commit, err = iterator.Next() {
+				messages = append(messages, strings.TrimSpace(commit.GetCommit().Message))
+			}
+			assert.Equal(t, []string{"api change"}, messages)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MAX_POLL_BACKOFF_EXPONENT caps the doubling of the interval, the max backoff config caps the resulting duration
+const MAX_POLL_BACKOFF_EXPONENT = 10
+
+// PollScheduler decides when each material is polled next. A material is polled at its own interval, or the global
+// poll duration when it has none, doubled for each consecutive fetch error, and delayed by a random jitter so that the
+// materials of the same provider are not fetched at the same time
+type PollScheduler struct {
+	defaultInterval time.Duration
+	maxBackoff      time.Duration
+	jitterPercent   int
+	mutex           sync.Mutex
+	nextPollTime    map[int]time.Time
+	random          *rand.Rand
+}
+
+func NewPollScheduler(pollConfig *PollConfig) *PollScheduler {
+	return &PollScheduler{
+		defaultInterval: time.Duration(pollConfig.PollDuration) * time.Minute,
+		maxBackoff:      time.Duration(pollConfig.PollMaxBackoffInMin) * time.Minute,
+		jitterPercent:   pollConfig.PollJitterPercent,
+		nextPollTime:    make(map[int]time.Time),
+		random:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// IsDue checks if the material should be polled at now. A material seen for the first time is scheduled from its
+// last fetch time, with the jitter spreading out the materials which are all overdue after a restart
+func (impl *PollScheduler) IsDue(material *sql.GitMaterial, now time.Time) bool {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	next, ok := impl.nextPollTime[material.Id]
+	if !ok {
+		next = material.LastFetchTime.Add(impl.interval(material))
+		if next.Before(now) {
+			next = now
+		}
+		next = next.Add(impl.jitter(material))
+		impl.nextPollTime[material.Id] = next
+	}
+	return !now.Before(next)
+}
+
+// Reschedule sets the next poll of the material from now. It is called when the material is queued, so that it is not
+// due again while its poll is queued or running, and once more when the poll ends
+func (impl *PollScheduler) Reschedule(material *sql.GitMaterial, now time.Time) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.nextPollTime[material.Id] = now.Add(impl.interval(material)).Add(impl.jitter(material))
+}
+
+// Forget drops the schedule of materials not in activeIds, i.e. deleted or deactivated ones
+func (impl *PollScheduler) Forget(activeIds map[int]bool) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	for id := range impl.nextPollTime {
+		if !activeIds[id] {
+			delete(impl.nextPollTime, id)
+		}
+	}
+}
+
+func (impl *PollScheduler) interval(material *sql.GitMaterial) time.Duration {
+	interval := impl.defaultInterval
+	if material.PollIntervalInMin > 0 {
+		interval = time.Duration(material.PollIntervalInMin) * time.Minute
+	}
+	if material.LastFetchErrorCount == 0 {
+		return interval
+	}
+	exponent := material.LastFetchErrorCount
+	if exponent > MAX_POLL_BACKOFF_EXPONENT {
+		exponent = MAX_POLL_BACKOFF_EXPONENT
+	}
+	backoff := interval * time.Duration(1<<exponent)
+	if impl.maxBackoff > 0 && backoff > impl.maxBackoff {
+		backoff = impl.maxBackoff
+	}
+	if backoff < interval {
+		return interval
+	}
+	return backoff
+}
+
+func (impl *PollScheduler) jitter(material *sql.GitMaterial) time.Duration {
+	maxJitter := int64(impl.interval(material)) * int64(impl.jitterPercent) / 100
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(impl.random.Int63n(maxJitter))
+}
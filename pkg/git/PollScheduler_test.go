@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPollScheduler(t *testing.T) {
+	scheduler := NewPollScheduler(&PollConfig{PollDuration: 2, PollMaxBackoffInMin: 30, PollJitterPercent: 10})
+	now := time.Now()
+
+	material := &sql.GitMaterial{Id: 1, LastFetchTime: now.Add(-time.Minute)}
+	assert.Equal(t, 2*time.Minute, scheduler.interval(material))
+	assert.False(t, scheduler.IsDue(material, now))
+	assert.True(t, scheduler.IsDue(material, now.Add(time.Minute+12*time.Second)))
+
+	custom := &sql.GitMaterial{Id: 2, PollIntervalInMin: 10}
+	assert.Equal(t, 10*time.Minute, scheduler.interval(custom))
+	// never fetched, due within the jitter
+	scheduler.IsDue(custom, now)
+	assert.True(t, scheduler.IsDue(custom, now.Add(time.Minute)))
+	scheduler.Reschedule(custom, now)
+	assert.False(t, scheduler.IsDue(custom, now.Add(9*time.Minute)))
+	assert.True(t, scheduler.IsDue(custom, now.Add(11*time.Minute)))
+
+	custom.LastFetchErrorCount = 1
+	assert.Equal(t, 20*time.Minute, scheduler.interval(custom))
+	custom.LastFetchErrorCount = 50
+	assert.Equal(t, 30*time.Minute, scheduler.interval(custom))
+	scheduler.Reschedule(custom, now)
+	assert.False(t, scheduler.IsDue(custom, now.Add(29*time.Minute)))
+
+	scheduler.Forget(map[int]bool{2: true})
+	_, ok := scheduler.nextPollTime[1]
+	assert.False(t, ok)
+}
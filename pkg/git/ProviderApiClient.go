@@ -0,0 +1,528 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	PROVIDER_GITHUB    = "github"
+	PROVIDER_GITLAB    = "gitlab"
+	PROVIDER_BITBUCKET = "bitbucket"
+
+	providerApiPageSize       = 100
+	providerApiMaxAttempts    = 3
+	providerApiErrorBodyLimit = 512
+)
+
+// ProviderApiClient lists the commits of the materials in api mode through the rest api of their git provider, so
+// that huge repos don't have to be cloned and fetched to find new commits
+type ProviderApiClient interface {
+	// ListCommits returns the commits of the branch newest first, up to lastSeenHash excluded or count commits
+	ListCommits(gitCtx GitContext, material *sql.GitMaterial, branch, lastSeenHash string, count int) ([]*GitCommitBase, error)
+	// ListProtectedBranches returns the names of the branches protected at the git provider, gitlab and bitbucket
+	// protect branches by wildcard patterns which are returned as is. The list is cached per repo for
+	// PROTECTED_BRANCH_CACHE_SEC
+	ListProtectedBranches(gitCtx GitContext, material *sql.GitMaterial) ([]string, error)
+}
+
+type ProviderApiClientImpl struct {
+	logger        *zap.SugaredLogger
+	configuration *internals.Configuration
+	// rateLimitedUntil keeps the reset time of the rate limit per api host, no call is made to the host until then
+	rateLimitedUntil map[string]time.Time
+	// protectedBranches keeps the protected branches per repo url, protection rules change rarely and are needed on
+	// every poll of the materials triggering only for protected branches
+	protectedBranches map[string]*protectedBranchesEntry
+	mutex             *sync.Mutex
+}
+
+type protectedBranchesEntry struct {
+	branches  []string
+	fetchedOn time.Time
+}
+
+func NewProviderApiClientImpl(logger *zap.SugaredLogger, configuration *internals.Configuration) *ProviderApiClientImpl {
+	return &ProviderApiClientImpl{
+		logger:            logger,
+		configuration:     configuration,
+		rateLimitedUntil:  make(map[string]time.Time),
+		protectedBranches: make(map[string]*protectedBranchesEntry),
+		mutex:             &sync.Mutex{},
+	}
+}
+
+// ProviderRepo is the repo of a material as addressed by the provider api, Path is owner/name or the full path of a
+// gitlab project
+type ProviderRepo struct {
+	Provider string
+	ApiUrl   string
+	Path     string
+}
+
+// ParseProviderRepo finds the provider and api url of the repo from its http or ssh url. Self hosted instances are
+// recognised by the provider name in their host, bitbucket server is not supported
+func ParseProviderRepo(repoUrl string) (*ProviderRepo, error) {
+	scheme, host, repoPath := "https", "", ""
+	if parsedUrl, err := url.Parse(repoUrl); err == nil && len(parsedUrl.Scheme) > 0 && len(parsedUrl.Host) > 0 {
+		host, repoPath = parsedUrl.Host, parsedUrl.Path
+		if parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https" {
+			scheme = parsedUrl.Scheme
+		} else {
+			// ssh port is not the port of the api
+			host = parsedUrl.Hostname()
+		}
+	} else if userHost, scpPath, found := strings.Cut(repoUrl, ":"); found && !strings.Contains(userHost, "/") {
+		// scp like ssh url, git@github.com:owner/repo.git
+		_, host, _ = strings.Cut(userHost, "@")
+		if len(host) == 0 {
+			host = userHost
+		}
+		repoPath = scpPath
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if len(host) == 0 || !strings.Contains(repoPath, "/") {
+		return nil, fmt.Errorf("unable to find the repo of url %q", repoUrl)
+	}
+	hostName := strings.ToLower(host)
+	repo := &ProviderRepo{Path: repoPath}
+	switch {
+	case strings.Contains(hostName, PROVIDER_GITHUB):
+		repo.Provider = PROVIDER_GITHUB
+		repo.ApiUrl = scheme + "://" + host + "/api/v3"
+		if hostName == "github.com" {
+			repo.ApiUrl = "https://api.github.com"
+		}
+	case strings.Contains(hostName, PROVIDER_GITLAB):
+		repo.Provider = PROVIDER_GITLAB
+		repo.ApiUrl = scheme + "://" + host + "/api/v4"
+	case hostName == "bitbucket.org":
+		repo.Provider = PROVIDER_BITBUCKET
+		repo.ApiUrl = "https://api.bitbucket.org/2.0"
+	default:
+		return nil, fmt.Errorf("api mode is not supported for the git provider of %q", repoUrl)
+	}
+	return repo, nil
+}
+
+// commitsUrl returns the url of a page of the commits of the branch, pages are numbered from 1
+func (repo *ProviderRepo) commitsUrl(branch string, page int, pageSize int) string {
+	switch repo.Provider {
+	case PROVIDER_GITHUB:
+		return fmt.Sprintf("%s/repos/%s/commits?sha=%s&per_page=%d&page=%d", repo.ApiUrl, repo.Path, url.QueryEscape(branch), pageSize, page)
+	case PROVIDER_GITLAB:
+		return fmt.Sprintf("%s/projects/%s/repository/commits?ref_name=%s&per_page=%d&page=%d", repo.ApiUrl, url.PathEscape(repo.Path), url.QueryEscape(branch), pageSize, page)
+	default:
+		return fmt.Sprintf("%s/repositories/%s/commits/%s?pagelen=%d&page=%d", repo.ApiUrl, repo.Path, url.PathEscape(branch), pageSize, page)
+	}
+}
+
+// protectedBranchesUrl returns the url of a page of the protected branches of the repo, bitbucket cloud has no
+// protected flag and lists the branch restrictions instead
+func (repo *ProviderRepo) protectedBranchesUrl(page int, pageSize int) string {
+	switch repo.Provider {
+	case PROVIDER_GITHUB:
+		return fmt.Sprintf("%s/repos/%s/branches?protected=true&per_page=%d&page=%d", repo.ApiUrl, repo.Path, pageSize, page)
+	case PROVIDER_GITLAB:
+		return fmt.Sprintf("%s/projects/%s/protected_branches?per_page=%d&page=%d", repo.ApiUrl, url.PathEscape(repo.Path), pageSize, page)
+	default:
+		return fmt.Sprintf("%s/repositories/%s/branch-restrictions?pagelen=%d&page=%d", repo.ApiUrl, repo.Path, pageSize, page)
+	}
+}
+
+type githubCommit struct {
+	Sha    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"committer"`
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+type gitlabCommit struct {
+	Id             string    `json:"id"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedDate  time.Time `json:"committed_date"`
+	Message        string    `json:"message"`
+}
+
+type bitbucketCommits struct {
+	Values []struct {
+		Hash    string    `json:"hash"`
+		Date    time.Time `json:"date"`
+		Message string    `json:"message"`
+		Author  struct {
+			Raw string `json:"raw"`
+		} `json:"author"`
+	} `json:"values"`
+}
+
+// parseProviderCommits converts a page of commits of the provider api to commits as read from git log
+func parseProviderCommits(provider string, body []byte) ([]*GitCommitBase, error) {
+	var commits []*GitCommitBase
+	switch provider {
+	case PROVIDER_GITHUB:
+		var page []*githubCommit
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page {
+			commits = append(commits, newProviderCommit(c.Sha, c.Commit.Committer.Name+" <"+c.Commit.Committer.Email+">", c.Commit.Committer.Date, c.Commit.Message))
+		}
+	case PROVIDER_GITLAB:
+		var page []*gitlabCommit
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page {
+			commits = append(commits, newProviderCommit(c.Id, c.CommitterName+" <"+c.CommitterEmail+">", c.CommittedDate, c.Message))
+		}
+	default:
+		page := &bitbucketCommits{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return nil, err
+		}
+		for _, c := range page.Values {
+			commits = append(commits, newProviderCommit(c.Hash, c.Author.Raw, c.Date, c.Message))
+		}
+	}
+	return commits, nil
+}
+
+type bitbucketBranchRestrictions struct {
+	Values []struct {
+		BranchMatchKind string `json:"branch_match_kind"`
+		Pattern         string `json:"pattern"`
+	} `json:"values"`
+}
+
+// parseProtectedBranches returns the names or patterns of a page of protected branches of the provider api, and the
+// count of entries in the page
+func parseProtectedBranches(provider string, body []byte) ([]string, int, error) {
+	var branches []string
+	if provider == PROVIDER_BITBUCKET {
+		page := &bitbucketBranchRestrictions{}
+		if err := json.Unmarshal(body, page); err != nil {
+			return nil, 0, err
+		}
+		for _, restriction := range page.Values {
+			// restrictions on branch types of the branching model can't be matched to branch names
+			if restriction.BranchMatchKind == "glob" && len(restriction.Pattern) > 0 {
+				branches = append(branches, restriction.Pattern)
+			}
+		}
+		return branches, len(page.Values), nil
+	}
+	// github and gitlab both list the branches with their name
+	var page []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, 0, err
+	}
+	for _, branch := range page {
+		branches = append(branches, branch.Name)
+	}
+	return branches, len(page), nil
+}
+
+// IsBranchProtected tells whether the branch matches any of the protected branch names or wildcard patterns
+func IsBranchProtected(protectedBranches []string, branch string) bool {
+	for _, protectedBranch := range protectedBranches {
+		if protectedBranch == branch {
+			return true
+		}
+		if matched, err := path.Match(protectedBranch, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func newProviderCommit(hash, author string, date time.Time, message string) *GitCommitBase {
+	message = strings.TrimSpace(message)
+	commit := GitCommitBase{
+		Commit:   hash,
+		Author:   author,
+		Date:     date,
+		Message:  message,
+		Trailers: ParseTrailersFromMessage(message),
+	}.withConventionalCommit().withTrailerIdentities()
+	return &commit
+}
+
+func (impl *ProviderApiClientImpl) ListCommits(gitCtx GitContext, material *sql.GitMaterial, branch, lastSeenHash string, count int) ([]*GitCommitBase, error) {
+	repo, err := ParseProviderRepo(material.Url)
+	if err != nil {
+		return nil, err
+	}
+	return impl.listCommits(gitCtx, repo, material.GitProvider, branch, lastSeenHash, count)
+}
+
+func (impl *ProviderApiClientImpl) listCommits(gitCtx GitContext, repo *ProviderRepo, provider *sql.GitProvider, branch, lastSeenHash string, count int) ([]*GitCommitBase, error) {
+	client := impl.newHttpClient(gitCtx)
+	defer client.CloseIdleConnections()
+	pageSize := count
+	if pageSize <= 0 || pageSize > providerApiPageSize {
+		pageSize = providerApiPageSize
+	}
+	var commits []*GitCommitBase
+	for page := 1; count <= 0 || len(commits) < count; page++ {
+		body, err := impl.get(gitCtx, client, repo, provider, repo.commitsUrl(branch, page, pageSize))
+		if err != nil {
+			return nil, err
+		}
+		pageCommits, err := parseProviderCommits(repo.Provider, body)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range pageCommits {
+			if commit.Commit == lastSeenHash || (count > 0 && len(commits) == count) {
+				return commits, nil
+			}
+			commits = append(commits, commit)
+		}
+		if len(pageCommits) < pageSize {
+			break
+		}
+	}
+	return commits, nil
+}
+
+func (impl *ProviderApiClientImpl) ListProtectedBranches(gitCtx GitContext, material *sql.GitMaterial) ([]string, error) {
+	repo, err := ParseProviderRepo(material.Url)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := repo.ApiUrl + "/" + repo.Path
+	if branches, found := impl.getCachedProtectedBranches(cacheKey); found {
+		return branches, nil
+	}
+	branches, err := impl.listProtectedBranches(gitCtx, repo, material.GitProvider)
+	if err != nil {
+		return nil, err
+	}
+	impl.mutex.Lock()
+	impl.protectedBranches[cacheKey] = &protectedBranchesEntry{branches: branches, fetchedOn: time.Now()}
+	impl.mutex.Unlock()
+	return branches, nil
+}
+
+func (impl *ProviderApiClientImpl) getCachedProtectedBranches(cacheKey string) ([]string, bool) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	entry, found := impl.protectedBranches[cacheKey]
+	if !found || time.Since(entry.fetchedOn) >= time.Duration(impl.configuration.ProtectedBranchCacheSec)*time.Second {
+		return nil, false
+	}
+	return entry.branches, true
+}
+
+func (impl *ProviderApiClientImpl) listProtectedBranches(gitCtx GitContext, repo *ProviderRepo, provider *sql.GitProvider) ([]string, error) {
+	client := impl.newHttpClient(gitCtx)
+	defer client.CloseIdleConnections()
+	var branches []string
+	for page := 1; ; page++ {
+		body, err := impl.get(gitCtx, client, repo, provider, repo.protectedBranchesUrl(page, providerApiPageSize))
+		if err != nil {
+			return nil, err
+		}
+		pageBranches, pageSize, err := parseProtectedBranches(repo.Provider, body)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, pageBranches...)
+		if pageSize < providerApiPageSize {
+			return branches, nil
+		}
+	}
+}
+
+func (impl *ProviderApiClientImpl) newHttpClient(gitCtx GitContext) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(gitCtx.ProxyUrl) > 0 {
+		if proxyUrl, err := url.Parse(gitCtx.ProxyUrl); err == nil {
+			transport.Proxy = http.ProxyURL(proxyUrl)
+		}
+	}
+	if gitCtx.InsecureSkipTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{Transport: transport, Timeout: time.Duration(impl.configuration.ProviderApiTimeoutInSec) * time.Second}
+}
+
+// get calls the api, waiting for the rate limit to reset and retrying when the reset is within PROVIDER_API_MAX_WAIT_SEC
+func (impl *ProviderApiClientImpl) get(gitCtx GitContext, client *http.Client, repo *ProviderRepo, provider *sql.GitProvider, apiUrl string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		if err := impl.waitForRateLimitReset(gitCtx, repo.ApiUrl); err != nil {
+			return nil, err
+		}
+		request, err := http.NewRequestWithContext(gitCtx, http.MethodGet, apiUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		setProviderApiAuth(request, repo.Provider, gitCtx, provider)
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, ClassifyGitError("", err)
+		}
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reset, limited := getRateLimitReset(response)
+		if limited && reset.IsZero() {
+			reset = time.Now().Add(time.Minute)
+		}
+		if !reset.IsZero() {
+			// an exhausted limit is remembered even for a successful call, so that the next calls wait instead of failing
+			impl.setRateLimitedUntil(repo.ApiUrl, reset)
+		}
+		if response.StatusCode == http.StatusOK {
+			return body, nil
+		}
+		output := string(body)
+		if len(output) > providerApiErrorBodyLimit {
+			output = output[:providerApiErrorBodyLimit]
+		}
+		err = fmt.Errorf("%s returned %d", strings.Split(apiUrl, "?")[0], response.StatusCode)
+		if !limited {
+			return nil, newProviderApiError(response.StatusCode, output, err)
+		}
+		if attempt >= providerApiMaxAttempts {
+			return nil, &GitError{Kind: ErrProviderRateLimited, Output: output, Err: err}
+		}
+	}
+}
+
+// waitForRateLimitReset waits till the rate limit of the api resets, failing with ErrProviderRateLimited if that is
+// later than PROVIDER_API_MAX_WAIT_SEC
+func (impl *ProviderApiClientImpl) waitForRateLimitReset(gitCtx GitContext, apiUrl string) error {
+	until := impl.getRateLimitedUntil(apiUrl)
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > time.Duration(impl.configuration.ProviderApiMaxWaitSec)*time.Second {
+		return &GitError{Kind: ErrProviderRateLimited, Err: fmt.Errorf("rate limit of %s resets at %s", apiUrl, until.Format(time.RFC3339))}
+	}
+	impl.logger.Infow("rate limit of provider api exhausted, waiting for reset", "apiUrl", apiUrl, "wait", wait)
+	select {
+	case <-gitCtx.Done():
+		return gitCtx.Err()
+	case <-time.After(wait):
+	}
+	impl.setRateLimitedUntil(apiUrl, time.Time{})
+	return nil
+}
+
+func (impl *ProviderApiClientImpl) getRateLimitedUntil(apiUrl string) time.Time {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	return impl.rateLimitedUntil[apiUrl]
+}
+
+func (impl *ProviderApiClientImpl) setRateLimitedUntil(apiUrl string, until time.Time) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if until.IsZero() {
+		delete(impl.rateLimitedUntil, apiUrl)
+	} else {
+		impl.rateLimitedUntil[apiUrl] = until
+	}
+}
+
+// setProviderApiAuth sets the token of the git provider, the access token or the password of username password auth
+// which is generally a personal access token for these providers. No auth is set for public repos
+func setProviderApiAuth(request *http.Request, providerType string, gitCtx GitContext, provider *sql.GitProvider) {
+	token := gitCtx.Password
+	if len(token) == 0 && provider != nil {
+		token = provider.AccessToken
+	}
+	if len(token) == 0 {
+		return
+	}
+	switch {
+	case providerType == PROVIDER_GITLAB:
+		request.Header.Set("PRIVATE-TOKEN", token)
+	case providerType == PROVIDER_BITBUCKET && len(gitCtx.Username) > 0:
+		// bitbucket app passwords are used with the username
+		request.SetBasicAuth(gitCtx.Username, token)
+	default:
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// getRateLimitReset returns when the rate limit resets if it is exhausted, and whether the response was rejected for it.
+// Github sends x-ratelimit-*, gitlab ratelimit-* and all of them may send retry-after
+func getRateLimitReset(response *http.Response) (time.Time, bool) {
+	limited := response.StatusCode == http.StatusTooManyRequests
+	remaining := response.Header.Get("X-RateLimit-Remaining")
+	if len(remaining) == 0 {
+		remaining = response.Header.Get("RateLimit-Remaining")
+	}
+	exhausted := remaining == "0"
+	if response.StatusCode == http.StatusForbidden && exhausted {
+		limited = true
+	}
+	if retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && (limited || response.StatusCode == http.StatusForbidden) {
+		return time.Now().Add(time.Duration(retryAfter) * time.Second), true
+	}
+	if !limited && !exhausted {
+		return time.Time{}, false
+	}
+	reset := response.Header.Get("X-RateLimit-Reset")
+	if len(reset) == 0 {
+		reset = response.Header.Get("RateLimit-Reset")
+	}
+	if resetEpoch, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		return time.Unix(resetEpoch, 0), limited
+	}
+	return time.Time{}, limited
+}
+
+func newProviderApiError(statusCode int, output string, err error) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &GitError{Kind: ErrAuthFailed, Output: output, Err: err}
+	case http.StatusNotFound:
+		return &GitError{Kind: ErrRepoNotFound, Output: output, Err: err}
+	case http.StatusUnprocessableEntity:
+		// github answers with no commit found for the sha
+		return &GitError{Kind: ErrBranchNotFound, Output: output, Err: err}
+	}
+	return fmt.Errorf("%w: %s", err, output)
+}
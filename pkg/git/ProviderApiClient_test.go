@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseProviderRepo(t *testing.T) {
+	tests := []struct {
+		url  string
+		want *ProviderRepo
+	}{
+		{"https://github.com/devtron-labs/git-sensor.git", &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: "https://api.github.com", Path: "devtron-labs/git-sensor"}},
+		{"git@github.com:devtron-labs/git-sensor.git", &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: "https://api.github.com", Path: "devtron-labs/git-sensor"}},
+		{"https://github.example.com/org/repo", &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: "https://github.example.com/api/v3", Path: "org/repo"}},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", &ProviderRepo{Provider: PROVIDER_GITLAB, ApiUrl: "https://gitlab.example.com/api/v4", Path: "group/sub/repo"}},
+		{"https://user@bitbucket.org/workspace/repo.git", &ProviderRepo{Provider: PROVIDER_BITBUCKET, ApiUrl: "https://api.bitbucket.org/2.0", Path: "workspace/repo"}},
+	}
+	for _, tt := range tests {
+		repo, err := ParseProviderRepo(tt.url)
+		assert.Nil(t, err, tt.url)
+		assert.Equal(t, tt.want, repo, tt.url)
+	}
+	_, err := ParseProviderRepo("https://git.example.com/org/repo.git")
+	assert.NotNil(t, err)
+}
+
+func TestProviderApiClientImpl_ListCommits(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"sha": "c3", "commit": {"message": "feat: third", "committer": {"name": "devtron", "email": "devtron@devtron.ai", "date": "2024-05-03T10:00:00Z"}}},
+			{"sha": "c2", "commit": {"message": "fix: second", "committer": {"name": "devtron", "email": "devtron@devtron.ai", "date": "2024-05-02T10:00:00Z"}}},
+			{"sha": "c1", "commit": {"message": "first", "committer": {"name": "devtron", "email": "devtron@devtron.ai", "date": "2024-05-01T10:00:00Z"}}}
+		]`)
+	}))
+	defer server.Close()
+	impl := NewProviderApiClientImpl(logger, &internals.Configuration{ProviderApiTimeoutInSec: 5, ProviderApiMaxWaitSec: 5})
+	repo := &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: server.URL, Path: "org/repo"}
+	gitCtx := BuildGitContext(context.Background()).WithCredentials("", "token")
+
+	commits, err := impl.listCommits(gitCtx, repo, &sql.GitProvider{}, "main", "c1", 15)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, commits, 2)
+	assert.Equal(t, "c3", commits[0].Commit)
+	assert.Equal(t, "devtron <devtron@devtron.ai>", commits[0].Author)
+	assert.Equal(t, "feat", commits[0].CommitType)
+	assert.Equal(t, "c2", commits[1].Commit)
+
+	commits, err = impl.listCommits(gitCtx, repo, &sql.GitProvider{}, "main", "", 2)
+	assert.Nil(t, err)
+	assert.Len(t, commits, 2)
+}
+
+func TestProviderApiClientImpl_RateLimited(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	impl := NewProviderApiClientImpl(logger, &internals.Configuration{ProviderApiTimeoutInSec: 5, ProviderApiMaxWaitSec: 5})
+	repo := &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: server.URL, Path: "org/repo"}
+
+	_, err = impl.listCommits(BuildGitContext(context.Background()), repo, &sql.GitProvider{}, "main", "", 15)
+	assert.True(t, errors.Is(err, ErrProviderRateLimited))
+	assert.Equal(t, "GIT_PROVIDER_RATE_LIMITED", GetGitErrorCode(err))
+}
+
+func TestProviderApiClientImpl_ListProtectedBranches(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/repos/org/repo/branches":
+			assert.Equal(t, "true", r.URL.Query().Get("protected"))
+			fmt.Fprint(w, `[{"name": "main", "protected": true}, {"name": "release/1.0", "protected": true}]`)
+		case "/repositories/workspace/repo/branch-restrictions":
+			fmt.Fprint(w, `{"values": [
+				{"kind": "push", "branch_match_kind": "glob", "pattern": "release/*"},
+				{"kind": "push", "branch_match_kind": "branching_model", "branch_type": "production"}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	impl := NewProviderApiClientImpl(logger, &internals.Configuration{ProviderApiTimeoutInSec: 5, ProviderApiMaxWaitSec: 5})
+	gitCtx := BuildGitContext(context.Background())
+
+	branches, err := impl.listProtectedBranches(gitCtx, &ProviderRepo{Provider: PROVIDER_GITHUB, ApiUrl: server.URL, Path: "org/repo"}, &sql.GitProvider{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main", "release/1.0"}, branches)
+
+	branches, err = impl.listProtectedBranches(gitCtx, &ProviderRepo{Provider: PROVIDER_BITBUCKET, ApiUrl: server.URL, Path: "workspace/repo"}, &sql.GitProvider{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"release/*"}, branches)
+	assert.True(t, IsBranchProtected(branches, "release/2.0"))
+	assert.False(t, IsBranchProtected(branches, "release/2.0/hotfix"))
+	assert.False(t, IsBranchProtected(branches, "main"))
+
+	_, err = impl.listProtectedBranches(gitCtx, &ProviderRepo{Provider: PROVIDER_GITLAB, ApiUrl: server.URL, Path: "group/repo"}, &sql.GitProvider{})
+	assert.True(t, errors.Is(err, ErrRepoNotFound))
+	assert.Equal(t, 3, requests)
+}
+
+func TestProviderApiClientImpl_ListProtectedBranchesCached(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewProviderApiClientImpl(logger, &internals.Configuration{ProtectedBranchCacheSec: 300})
+	impl.protectedBranches["https://api.github.com/org/repo"] = &protectedBranchesEntry{branches: []string{"main"}, fetchedOn: time.Now()}
+
+	branches, err := impl.ListProtectedBranches(BuildGitContext(context.Background()), &sql.GitMaterial{Url: "https://github.com/org/repo.git"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"main"}, branches)
+}
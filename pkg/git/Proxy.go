@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ResolveProxy returns the proxy for the remote, the material proxy overrides the global one. No proxy is used for ssh
+// remotes and for hosts matching noProxy, a comma separated list of hosts and domain suffixes like NO_PROXY
+func ResolveProxy(remoteUrl string, materialProxy string, globalProxy string, noProxy string) string {
+	proxy := materialProxy
+	if len(proxy) == 0 {
+		proxy = globalProxy
+	}
+	if len(proxy) == 0 {
+		return ""
+	}
+	parsedUrl, err := url.Parse(remoteUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") {
+		return ""
+	}
+	if IsNoProxyHost(parsedUrl.Hostname(), noProxy) {
+		return ""
+	}
+	return proxy
+}
+
+// IsNoProxyHost checks if host matches an entry of noProxy. An entry matches the host itself and its subdomains,
+// a leading dot or *. is ignored and * matches all hosts
+func IsNoProxyHost(host string, noProxy string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if len(entry) == 0 {
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResolveProxy(t *testing.T) {
+	global := "http://proxy.internal:3128"
+	noProxy := "localhost, .corp.example.com,gitlab.internal:443"
+	assert.Equal(t, global, ResolveProxy("https://github.com/devtron-labs/git-sensor.git", "", global, noProxy))
+	assert.Equal(t, "http://other:8080", ResolveProxy("https://github.com/devtron-labs/git-sensor.git", "http://other:8080", global, noProxy))
+	assert.Equal(t, "", ResolveProxy("https://gitlab.internal/group/repo.git", "", global, noProxy))
+	assert.Equal(t, "", ResolveProxy("https://git.corp.example.com/repo.git", "http://other:8080", global, noProxy))
+	assert.Equal(t, "", ResolveProxy("git@github.com:devtron-labs/git-sensor.git", "", global, noProxy))
+	assert.Equal(t, "", ResolveProxy("https://github.com/devtron-labs/git-sensor.git", "", "", noProxy))
+	assert.Equal(t, "", ResolveProxy("https://github.com/devtron-labs/git-sensor.git", "", global, "*"))
+}
+
+func TestIsNoProxyHost(t *testing.T) {
+	assert.True(t, IsNoProxyHost("corp.example.com", "*.corp.example.com"))
+	assert.True(t, IsNoProxyHost("GitLab.Corp.Example.com", "corp.example.com"))
+	assert.False(t, IsNoProxyHost("notcorp.example.com", "corp.example.com"))
+	assert.False(t, IsNoProxyHost("github.com", ""))
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	PR_ACTION_OPENED       = "opened"
+	PR_ACTION_SYNCHRONIZED = "synchronized"
+	PR_ACTION_MERGED       = "merged"
+)
+
+// PullRequestEvent is the provider independent form of a pull request or merge request webhook
+type PullRequestEvent struct {
+	Provider string
+	RepoUrls []string
+	PullRequestMaterial
+}
+
+// PullRequestMaterial is sent to ci for materials of type SOURCE_TYPE_PULL_REQUEST
+type PullRequestMaterial struct {
+	Number       int64    `json:"number"`
+	Title        string   `json:"title"`
+	Action       string   `json:"action"`
+	SourceBranch string   `json:"sourceBranch"`
+	SourceCommit string   `json:"sourceCommit"`
+	TargetBranch string   `json:"targetBranch"`
+	Author       string   `json:"author"`
+	Url          string   `json:"url"`
+	Labels       []string `json:"labels,omitempty"`
+	Draft        bool     `json:"draft"`
+}
+
+// PullRequestFilter decides which pull request events are sent to ci, an empty regex matches every branch and
+// when labels are set the pull request needs at least one of them
+type PullRequestFilter struct {
+	SourceBranchRegex *regexp.Regexp
+	TargetBranchRegex *regexp.Regexp
+	Labels            []string
+	AllowDraft        bool
+}
+
+func NewPullRequestFilter(configuration *internals.Configuration) (*PullRequestFilter, error) {
+	filter := &PullRequestFilter{AllowDraft: configuration.PrWebhookAllowDraft}
+	var err error
+	if len(configuration.PrWebhookSourceBranchRegex) > 0 {
+		filter.SourceBranchRegex, err = regexp.Compile("^(?:" + configuration.PrWebhookSourceBranchRegex + ")$")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(configuration.PrWebhookTargetBranchRegex) > 0 {
+		filter.TargetBranchRegex, err = regexp.Compile("^(?:" + configuration.PrWebhookTargetBranchRegex + ")$")
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range strings.Split(configuration.PrWebhookLabels, ",") {
+		if label = strings.TrimSpace(label); len(label) > 0 {
+			filter.Labels = append(filter.Labels, label)
+		}
+	}
+	return filter, nil
+}
+
+func (filter *PullRequestFilter) Matches(event *PullRequestEvent) bool {
+	if event.Draft && !filter.AllowDraft {
+		return false
+	}
+	if filter.SourceBranchRegex != nil && !filter.SourceBranchRegex.MatchString(event.SourceBranch) {
+		return false
+	}
+	if filter.TargetBranchRegex != nil && !filter.TargetBranchRegex.MatchString(event.TargetBranch) {
+		return false
+	}
+	if len(filter.Labels) == 0 {
+		return true
+	}
+	for _, label := range event.Labels {
+		if contains(filter.Labels, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePullRequestEvent returns nil for events other than a pull request being opened, updated with new commits or merged
+func ParsePullRequestEvent(provider string, header http.Header, payload []byte) (*PullRequestEvent, error) {
+	if !gjson.ValidBytes(payload) {
+		return nil, fmt.Errorf("invalid webhook payload")
+	}
+	json := gjson.ParseBytes(payload)
+	event := &PullRequestEvent{Provider: provider}
+	switch provider {
+	case WEBHOOK_PROVIDER_GITHUB:
+		if header.Get("X-GitHub-Event") != "pull_request" {
+			return nil, nil
+		}
+		switch json.Get("action").String() {
+		case "opened", "reopened", "ready_for_review":
+			event.Action = PR_ACTION_OPENED
+		case "synchronize":
+			event.Action = PR_ACTION_SYNCHRONIZED
+		case "closed":
+			if !json.Get("pull_request.merged").Bool() {
+				return nil, nil
+			}
+			event.Action = PR_ACTION_MERGED
+		default:
+			return nil, nil
+		}
+		pr := json.Get("pull_request")
+		event.RepoUrls = repoUrlsOf(json, "repository.clone_url", "repository.ssh_url", "repository.html_url")
+		event.Number = pr.Get("number").Int()
+		event.Title = pr.Get("title").String()
+		event.SourceBranch = pr.Get("head.ref").String()
+		event.SourceCommit = pr.Get("head.sha").String()
+		event.TargetBranch = pr.Get("base.ref").String()
+		event.Author = pr.Get("user.login").String()
+		event.Url = pr.Get("html_url").String()
+		event.Draft = pr.Get("draft").Bool()
+		event.Labels = namesOf(pr.Get("labels"), "name")
+	case WEBHOOK_PROVIDER_GITLAB:
+		if header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+			return nil, nil
+		}
+		mr := json.Get("object_attributes")
+		switch mr.Get("action").String() {
+		case "open", "reopen":
+			event.Action = PR_ACTION_OPENED
+		case "update":
+			// updates of the title or description don't have oldrev
+			if !mr.Get("oldrev").Exists() {
+				return nil, nil
+			}
+			event.Action = PR_ACTION_SYNCHRONIZED
+		case "merge":
+			event.Action = PR_ACTION_MERGED
+		default:
+			return nil, nil
+		}
+		event.RepoUrls = repoUrlsOf(json, "project.git_http_url", "project.git_ssh_url", "project.web_url")
+		event.Number = mr.Get("iid").Int()
+		event.Title = mr.Get("title").String()
+		event.SourceBranch = mr.Get("source_branch").String()
+		event.SourceCommit = mr.Get("last_commit.id").String()
+		event.TargetBranch = mr.Get("target_branch").String()
+		event.Author = json.Get("user.username").String()
+		event.Url = mr.Get("url").String()
+		event.Draft = mr.Get("draft").Bool() || mr.Get("work_in_progress").Bool()
+		event.Labels = namesOf(json.Get("labels"), "title")
+	case WEBHOOK_PROVIDER_BITBUCKET:
+		switch header.Get("X-Event-Key") {
+		case "pullrequest:created":
+			event.Action = PR_ACTION_OPENED
+		case "pullrequest:updated":
+			event.Action = PR_ACTION_SYNCHRONIZED
+		case "pullrequest:fulfilled":
+			event.Action = PR_ACTION_MERGED
+		default:
+			return nil, nil
+		}
+		pr := json.Get("pullrequest")
+		event.RepoUrls = repoUrlsOf(json, "repository.links.html.href")
+		if fullName := json.Get("repository.full_name").String(); len(fullName) > 0 {
+			event.RepoUrls = append(event.RepoUrls, fmt.Sprintf("git@bitbucket.org:%s.git", fullName))
+		}
+		event.Number = pr.Get("id").Int()
+		event.Title = pr.Get("title").String()
+		event.SourceBranch = pr.Get("source.branch.name").String()
+		event.SourceCommit = pr.Get("source.commit.hash").String()
+		event.TargetBranch = pr.Get("destination.branch.name").String()
+		event.Author = pr.Get("author.display_name").String()
+		event.Url = pr.Get("links.html.href").String()
+		event.Draft = pr.Get("draft").Bool()
+	case WEBHOOK_PROVIDER_AZURE_DEVOPS:
+		pr := json.Get("resource")
+		switch json.Get("eventType").String() {
+		case "git.pullrequest.created":
+			event.Action = PR_ACTION_OPENED
+		case "git.pullrequest.updated":
+			if pr.Get("status").String() == "completed" {
+				event.Action = PR_ACTION_MERGED
+			} else {
+				event.Action = PR_ACTION_SYNCHRONIZED
+			}
+		default:
+			return nil, nil
+		}
+		event.RepoUrls = azureDevopsRepoUrlsOf(pr.Get("repository"))
+		event.Number = pr.Get("pullRequestId").Int()
+		event.Title = pr.Get("title").String()
+		event.SourceBranch = strings.TrimPrefix(pr.Get("sourceRefName").String(), BRANCH_REF_PREFIX)
+		event.SourceCommit = pr.Get("lastMergeSourceCommit.commitId").String()
+		event.TargetBranch = strings.TrimPrefix(pr.Get("targetRefName").String(), BRANCH_REF_PREFIX)
+		event.Author = pr.Get("createdBy.uniqueName").String()
+		event.Url = pr.Get("_links.web.href").String()
+		event.Draft = pr.Get("isDraft").Bool()
+		event.Labels = namesOf(pr.Get("labels"), "name")
+	case WEBHOOK_PROVIDER_GERRIT:
+		// gerrit changes are built as pushes of their change refs
+		return nil, nil
+	default:
+		return nil, ErrUnsupportedWebhookProvider
+	}
+	if len(event.RepoUrls) == 0 {
+		return nil, fmt.Errorf("repository url not found in webhook payload")
+	}
+	return event, nil
+}
+
+func namesOf(items gjson.Result, field string) []string {
+	var names []string
+	for _, item := range items.Array() {
+		names = append(names, item.Get(field).String())
+	}
+	return names
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestParsePullRequestEvent(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "pull_request")
+	payload := `{"action":"synchronize","repository":{"clone_url":"https://github.com/org/repo.git"},
+		"pull_request":{"number":7,"title":"fix","draft":true,"html_url":"https://github.com/org/repo/pull/7","user":{"login":"dev"},
+		"head":{"ref":"feature","sha":"abc"},"base":{"ref":"main"},"labels":[{"name":"ci"}]}}`
+	event, err := ParsePullRequestEvent(WEBHOOK_PROVIDER_GITHUB, header, []byte(payload))
+	assert.Nil(t, err)
+	assert.Equal(t, PR_ACTION_SYNCHRONIZED, event.Action)
+	assert.Equal(t, int64(7), event.Number)
+	assert.Equal(t, "feature", event.SourceBranch)
+	assert.Equal(t, "abc", event.SourceCommit)
+	assert.Equal(t, "main", event.TargetBranch)
+	assert.Equal(t, []string{"ci"}, event.Labels)
+	assert.True(t, event.Draft)
+
+	event, err = ParsePullRequestEvent(WEBHOOK_PROVIDER_GITHUB, header, []byte(`{"action":"closed","repository":{"clone_url":"https://github.com/org/repo.git"},"pull_request":{"merged":false}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+
+	header = http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	event, err = ParsePullRequestEvent(WEBHOOK_PROVIDER_GITLAB, header, []byte(`{"project":{"git_http_url":"https://gitlab.com/org/repo.git"},
+		"labels":[{"title":"ci"}],"object_attributes":{"action":"merge","iid":3,"source_branch":"feature","target_branch":"main","last_commit":{"id":"abc"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, PR_ACTION_MERGED, event.Action)
+	assert.Equal(t, int64(3), event.Number)
+	assert.Equal(t, []string{"ci"}, event.Labels)
+	event, err = ParsePullRequestEvent(WEBHOOK_PROVIDER_GITLAB, header, []byte(`{"project":{"git_http_url":"https://gitlab.com/org/repo.git"},"object_attributes":{"action":"update"}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, event)
+
+	header = http.Header{}
+	header.Set("X-Event-Key", "pullrequest:created")
+	event, err = ParsePullRequestEvent(WEBHOOK_PROVIDER_BITBUCKET, header, []byte(`{"repository":{"full_name":"org/repo"},
+		"pullrequest":{"id":5,"source":{"branch":{"name":"feature"},"commit":{"hash":"abc"}},"destination":{"branch":{"name":"main"}}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, PR_ACTION_OPENED, event.Action)
+	assert.Equal(t, "main", event.TargetBranch)
+	assert.Equal(t, []string{"git@bitbucket.org:org/repo.git"}, event.RepoUrls)
+
+	event, err = ParsePullRequestEvent(WEBHOOK_PROVIDER_AZURE_DEVOPS, http.Header{}, []byte(`{"eventType":"git.pullrequest.updated","resource":{
+		"pullRequestId":9,"status":"completed","sourceRefName":"refs/heads/feature","targetRefName":"refs/heads/main",
+		"lastMergeSourceCommit":{"commitId":"abc"},"repository":{"remoteUrl":"https://dev.azure.com/org/project/_git/repo"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, PR_ACTION_MERGED, event.Action)
+	assert.Equal(t, "feature", event.SourceBranch)
+	assert.Equal(t, "main", event.TargetBranch)
+}
+
+func TestPullRequestFilter_Matches(t *testing.T) {
+	filter, err := NewPullRequestFilter(&internals.Configuration{PrWebhookTargetBranchRegex: "main|release-.*", PrWebhookLabels: "ci, deploy"})
+	assert.Nil(t, err)
+	event := &PullRequestEvent{PullRequestMaterial: PullRequestMaterial{SourceBranch: "feature", TargetBranch: "release-1", Labels: []string{"deploy"}}}
+	assert.True(t, filter.Matches(event))
+	event.Draft = true
+	assert.False(t, filter.Matches(event))
+	event.Draft = false
+	event.Labels = []string{"docs"}
+	assert.False(t, filter.Matches(event))
+	event.Labels = []string{"ci"}
+	event.TargetBranch = "develop"
+	assert.False(t, filter.Matches(event))
+
+	_, err = NewPullRequestFilter(&internals.Configuration{PrWebhookSourceBranchRegex: "("})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	WEBHOOK_PROVIDER_GITHUB       = "github"
+	WEBHOOK_PROVIDER_GITLAB       = "gitlab"
+	WEBHOOK_PROVIDER_BITBUCKET    = "bitbucket"
+	WEBHOOK_PROVIDER_AZURE_DEVOPS = "azure-devops"
+	WEBHOOK_PROVIDER_GERRIT       = "gerrit"
+
+	BRANCH_REF_PREFIX = "refs/heads/"
+	TAG_REF_PREFIX    = "refs/tags/"
+)
+
+var (
+	ErrWebhookSecretNotConfigured = errors.New("webhook secret is not configured")
+	ErrInvalidWebhookSignature    = errors.New("invalid webhook signature")
+	ErrUnsupportedWebhookProvider = errors.New("unsupported webhook provider")
+)
+
+// PushEvent is the provider independent form of a push or tag push webhook
+type PushEvent struct {
+	Provider string
+	RepoUrls []string
+	Branch   string
+	Tag      string
+	Commit   string
+	Deleted  bool
+}
+
+// IsTrackedBy checks if any of the pipeline materials would pick up the pushed branch or tag
+func (event *PushEvent) IsTrackedBy(ciPipelineMaterials []*sql.CiPipelineMaterial) bool {
+	for _, material := range ciPipelineMaterials {
+		if !material.Active {
+			continue
+		}
+		switch material.Type {
+		case sql.SOURCE_TYPE_BRANCH_FIXED:
+			if len(event.Branch) > 0 && ToGerritChangeBranch(material.Value) == event.Branch {
+				return true
+			}
+		case sql.SOURCE_TYPE_BRANCH_REGEX:
+			branchRegex, err := regexp.Compile("^(?:" + material.Value + ")$")
+			if err == nil && len(event.Branch) > 0 && branchRegex.MatchString(event.Branch) {
+				return true
+			}
+		case sql.SOURCE_TYPE_TAG_ANY:
+			if len(event.Tag) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateWebhookSignature checks the X-Hub-Signature-256 hmac of github and bitbucket and the X-Gitlab-Token of gitlab.
+// Azure devops service hooks and the gerrit webhooks plugin don't sign the payload, they are configured to send the
+// secret either in the X-Webhook-Token header or as the basic auth password
+func ValidateWebhookSignature(provider string, header http.Header, payload []byte, secret string) error {
+	if len(secret) == 0 {
+		return ErrWebhookSecretNotConfigured
+	}
+	switch provider {
+	case WEBHOOK_PROVIDER_GITHUB, WEBHOOK_PROVIDER_BITBUCKET:
+		signature := header.Get("X-Hub-Signature-256")
+		if len(signature) == 0 {
+			// bitbucket cloud sends the sha256 hmac in X-Hub-Signature
+			signature = header.Get("X-Hub-Signature")
+		}
+		if !strings.HasPrefix(signature, "sha256=") {
+			return ErrInvalidWebhookSignature
+		}
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return ErrInvalidWebhookSignature
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		if !hmac.Equal(mac.Sum(nil), expected) {
+			return ErrInvalidWebhookSignature
+		}
+	case WEBHOOK_PROVIDER_GITLAB:
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return ErrInvalidWebhookSignature
+		}
+	case WEBHOOK_PROVIDER_AZURE_DEVOPS, WEBHOOK_PROVIDER_GERRIT:
+		token := header.Get("X-Webhook-Token")
+		if len(token) == 0 {
+			_, token, _ = (&http.Request{Header: header}).BasicAuth()
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return ErrInvalidWebhookSignature
+		}
+	default:
+		return ErrUnsupportedWebhookProvider
+	}
+	return nil
+}
+
+// ParsePushEvents returns an event for each ref updated by the push, nil for events other than push and tag push
+func ParsePushEvents(provider string, header http.Header, payload []byte) ([]*PushEvent, error) {
+	if !gjson.ValidBytes(payload) {
+		return nil, fmt.Errorf("invalid webhook payload")
+	}
+	json := gjson.ParseBytes(payload)
+	var event *PushEvent
+	var events []*PushEvent
+	switch provider {
+	case WEBHOOK_PROVIDER_GITHUB:
+		if header.Get("X-GitHub-Event") != "push" {
+			return nil, nil
+		}
+		event = &PushEvent{
+			RepoUrls: repoUrlsOf(json, "repository.clone_url", "repository.ssh_url", "repository.html_url"),
+			Commit:   json.Get("after").String(),
+			Deleted:  json.Get("deleted").Bool(),
+		}
+		event.setRef(json.Get("ref").String())
+	case WEBHOOK_PROVIDER_GITLAB:
+		eventType := header.Get("X-Gitlab-Event")
+		if eventType != "Push Hook" && eventType != "Tag Push Hook" {
+			return nil, nil
+		}
+		event = &PushEvent{
+			RepoUrls: repoUrlsOf(json, "project.git_http_url", "project.git_ssh_url", "project.web_url"),
+			Commit:   json.Get("after").String(),
+			// gitlab sends the zero hash as after when the ref is deleted
+			Deleted: strings.Trim(json.Get("after").String(), "0") == "",
+		}
+		event.setRef(json.Get("ref").String())
+	case WEBHOOK_PROVIDER_BITBUCKET:
+		if header.Get("X-Event-Key") != "repo:push" {
+			return nil, nil
+		}
+		repoUrls := repoUrlsOf(json, "repository.links.html.href")
+		if fullName := json.Get("repository.full_name").String(); len(fullName) > 0 {
+			repoUrls = append(repoUrls, fmt.Sprintf("git@bitbucket.org:%s.git", fullName))
+		}
+		// a push can update several refs, with a change for each of them
+		for _, change := range json.Get("push.changes").Array() {
+			changeEvent := &PushEvent{
+				RepoUrls: repoUrls,
+				Commit:   change.Get("new.target.hash").String(),
+				Deleted:  change.Get("new").Type == gjson.Null,
+			}
+			ref := change.Get("new")
+			if changeEvent.Deleted {
+				ref = change.Get("old")
+			}
+			switch ref.Get("type").String() {
+			case "tag":
+				changeEvent.Tag = ref.Get("name").String()
+			default:
+				changeEvent.Branch = ref.Get("name").String()
+			}
+			events = append(events, changeEvent)
+		}
+	case WEBHOOK_PROVIDER_AZURE_DEVOPS:
+		if json.Get("eventType").String() != "git.push" {
+			return nil, nil
+		}
+		repoUrls := azureDevopsRepoUrlsOf(json.Get("resource.repository"))
+		for _, refUpdate := range json.Get("resource.refUpdates").Array() {
+			refUpdateEvent := &PushEvent{
+				RepoUrls: repoUrls,
+				Commit:   refUpdate.Get("newObjectId").String(),
+				Deleted:  strings.Trim(refUpdate.Get("newObjectId").String(), "0") == "",
+			}
+			refUpdateEvent.setRef(refUpdate.Get("name").String())
+			events = append(events, refUpdateEvent)
+		}
+	case WEBHOOK_PROVIDER_GERRIT:
+		event = &PushEvent{RepoUrls: gerritRepoUrlsOf(json)}
+		switch json.Get("type").String() {
+		case "patchset-created":
+			// the patch set is built from its change ref, which is fetched as a changes/* branch
+			event.setRef(json.Get("patchSet.ref").String())
+			event.Commit = json.Get("patchSet.revision").String()
+		case "change-merged":
+			event.Branch = json.Get("change.branch").String()
+			event.Commit = json.Get("newRev").String()
+		default:
+			return nil, nil
+		}
+	default:
+		return nil, ErrUnsupportedWebhookProvider
+	}
+	if event != nil {
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	for _, e := range events {
+		if len(e.RepoUrls) == 0 {
+			return nil, fmt.Errorf("repository url not found in webhook payload")
+		}
+		e.Provider = provider
+	}
+	return events, nil
+}
+
+func (event *PushEvent) setRef(ref string) {
+	if strings.HasPrefix(ref, TAG_REF_PREFIX) {
+		event.Tag = strings.TrimPrefix(ref, TAG_REF_PREFIX)
+	} else {
+		event.Branch = ToGerritChangeBranch(strings.TrimPrefix(ref, BRANCH_REF_PREFIX))
+	}
+}
+
+// azureDevopsRepoUrlsOf returns the urls of an azure devops repository, the remote url has the organization as
+// user info which materials are usually saved without
+func azureDevopsRepoUrlsOf(repository gjson.Result) []string {
+	repoUrls := repoUrlsOf(repository, "remoteUrl", "sshUrl", "webUrl")
+	if remoteUrl, err := url.Parse(repository.Get("remoteUrl").String()); err == nil && remoteUrl.User != nil {
+		remoteUrl.User = nil
+		repoUrls = append(repoUrls, remoteUrl.String())
+	}
+	return repoUrls
+}
+
+// gerritRepoUrlsOf returns the urls of the project of a gerrit change, gerrit serves authenticated http clones under /a/
+func gerritRepoUrlsOf(json gjson.Result) []string {
+	project := json.Get("change.project").String()
+	changeUrl, err := url.Parse(json.Get("change.url").String())
+	if len(project) == 0 || err != nil || len(changeUrl.Host) == 0 {
+		return nil
+	}
+	var repoUrls []string
+	for _, path := range []string{"/" + project, "/a/" + project} {
+		repoUrl := changeUrl.Scheme + "://" + changeUrl.Host + path
+		repoUrls = append(repoUrls, repoUrl, repoUrl+".git")
+	}
+	return repoUrls
+}
+
+// repoUrlsOf returns the urls at the given paths, urls without the .git suffix are added with it as well
+// since materials can be saved in either form
+func repoUrlsOf(json gjson.Result, paths ...string) []string {
+	var repoUrls []string
+	for _, p := range paths {
+		repoUrl := json.Get(p).String()
+		if len(repoUrl) == 0 {
+			continue
+		}
+		repoUrls = append(repoUrls, repoUrl)
+		if !strings.HasSuffix(repoUrl, ".git") && strings.HasPrefix(repoUrl, "http") {
+			repoUrls = append(repoUrls, repoUrl+".git")
+		}
+	}
+	return repoUrls
+}
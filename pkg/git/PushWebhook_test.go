@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestValidateWebhookSignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", signature)
+	assert.Nil(t, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITHUB, header, payload, "secret"))
+	assert.Equal(t, ErrInvalidWebhookSignature, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITHUB, header, payload, "other"))
+	assert.Equal(t, ErrInvalidWebhookSignature, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITHUB, http.Header{}, payload, "secret"))
+	assert.Equal(t, ErrWebhookSecretNotConfigured, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITHUB, header, payload, ""))
+
+	header = http.Header{}
+	header.Set("X-Hub-Signature", signature)
+	assert.Nil(t, ValidateWebhookSignature(WEBHOOK_PROVIDER_BITBUCKET, header, payload, "secret"))
+
+	header = http.Header{}
+	header.Set("X-Gitlab-Token", "secret")
+	assert.Nil(t, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITLAB, header, payload, "secret"))
+	assert.Equal(t, ErrInvalidWebhookSignature, ValidateWebhookSignature(WEBHOOK_PROVIDER_GITLAB, header, payload, "other"))
+	assert.Equal(t, ErrUnsupportedWebhookProvider, ValidateWebhookSignature("gitea", header, payload, "secret"))
+
+	header = http.Header{}
+	header.Set("X-Webhook-Token", "secret")
+	assert.Nil(t, ValidateWebhookSignature(WEBHOOK_PROVIDER_AZURE_DEVOPS, header, payload, "secret"))
+	request, _ := http.NewRequest(http.MethodPost, "/", nil)
+	request.SetBasicAuth("gerrit", "secret")
+	assert.Nil(t, ValidateWebhookSignature(WEBHOOK_PROVIDER_GERRIT, request.Header, payload, "secret"))
+	assert.Equal(t, ErrInvalidWebhookSignature, ValidateWebhookSignature(WEBHOOK_PROVIDER_GERRIT, request.Header, payload, "other"))
+}
+
+func TestParsePushEvents(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	events, err := ParsePushEvents(WEBHOOK_PROVIDER_GITHUB, header, []byte(`{"ref":"refs/heads/main","after":"abc","deleted":false,
+		"repository":{"clone_url":"https://github.com/org/repo.git","ssh_url":"git@github.com:org/repo.git","html_url":"https://github.com/org/repo"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "main", events[0].Branch)
+	assert.Equal(t, "abc", events[0].Commit)
+	assert.Equal(t, []string{"https://github.com/org/repo.git", "git@github.com:org/repo.git", "https://github.com/org/repo", "https://github.com/org/repo.git"}, events[0].RepoUrls)
+
+	header.Set("X-GitHub-Event", "issues")
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_GITHUB, header, []byte(`{}`))
+	assert.Nil(t, err)
+	assert.Nil(t, events)
+
+	header = http.Header{}
+	header.Set("X-Gitlab-Event", "Tag Push Hook")
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_GITLAB, header, []byte(`{"ref":"refs/tags/v1","after":"abc",
+		"project":{"git_http_url":"https://gitlab.com/org/repo.git","git_ssh_url":"git@gitlab.com:org/repo.git"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", events[0].Tag)
+	assert.False(t, events[0].Deleted)
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_GITLAB, header, []byte(`{"ref":"refs/tags/v1","after":"0000000000000000000000000000000000000000",
+		"project":{"git_http_url":"https://gitlab.com/org/repo.git"}}`))
+	assert.Nil(t, err)
+	assert.True(t, events[0].Deleted)
+
+	// a push of several refs has a change for each of them
+	header = http.Header{}
+	header.Set("X-Event-Key", "repo:push")
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_BITBUCKET, header, []byte(`{"repository":{"full_name":"org/repo","links":{"html":{"href":"https://bitbucket.org/org/repo"}}},
+		"push":{"changes":[{"new":{"type":"branch","name":"develop","target":{"hash":"abc"}}},
+		{"new":{"type":"tag","name":"v1","target":{"hash":"abc"}}},{"new":null,"old":{"type":"branch","name":"feature"}}]}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, "develop", events[0].Branch)
+	assert.Equal(t, "abc", events[0].Commit)
+	assert.Contains(t, events[0].RepoUrls, "git@bitbucket.org:org/repo.git")
+	assert.Equal(t, "v1", events[1].Tag)
+	assert.Equal(t, "feature", events[2].Branch)
+	assert.True(t, events[2].Deleted)
+
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_AZURE_DEVOPS, http.Header{}, []byte(`{"eventType":"git.push","resource":{
+		"refUpdates":[{"name":"refs/heads/main","newObjectId":"abc"},{"name":"refs/tags/v1","newObjectId":"def"}],
+		"repository":{"remoteUrl":"https://org@dev.azure.com/org/project/_git/repo"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "main", events[0].Branch)
+	assert.Equal(t, "abc", events[0].Commit)
+	assert.Contains(t, events[0].RepoUrls, "https://dev.azure.com/org/project/_git/repo")
+	assert.Equal(t, "v1", events[1].Tag)
+	assert.Equal(t, "def", events[1].Commit)
+
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_GERRIT, http.Header{}, []byte(`{"type":"patchset-created",
+		"change":{"project":"org/repo","branch":"main","url":"https://review.example.com/c/org/repo/+/1234"},
+		"patchSet":{"ref":"refs/changes/34/1234/2","revision":"abc"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "changes/34/1234/2", events[0].Branch)
+	assert.Equal(t, "abc", events[0].Commit)
+	assert.Contains(t, events[0].RepoUrls, "https://review.example.com/a/org/repo")
+	events, err = ParsePushEvents(WEBHOOK_PROVIDER_GERRIT, http.Header{}, []byte(`{"type":"change-merged","newRev":"def",
+		"change":{"project":"org/repo","branch":"main","url":"https://review.example.com/c/org/repo/+/1234"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "main", events[0].Branch)
+	assert.Equal(t, "def", events[0].Commit)
+
+	_, err = ParsePushEvents(WEBHOOK_PROVIDER_GITHUB, http.Header{}, []byte(`not json`))
+	assert.NotNil(t, err)
+}
+
+func TestPushEvent_IsTrackedBy(t *testing.T) {
+	materials := []*sql.CiPipelineMaterial{
+		{Type: sql.SOURCE_TYPE_BRANCH_FIXED, Value: "main", Active: true},
+		{Type: sql.SOURCE_TYPE_BRANCH_REGEX, Value: "release-.*", Active: true},
+		{Type: sql.SOURCE_TYPE_TAG_ANY, Active: false},
+	}
+	assert.True(t, (&PushEvent{Branch: "main"}).IsTrackedBy(materials))
+	assert.True(t, (&PushEvent{Branch: "release-1.0"}).IsTrackedBy(materials))
+	assert.False(t, (&PushEvent{Branch: "feature"}).IsTrackedBy(materials))
+	assert.False(t, (&PushEvent{Tag: "v1"}).IsTrackedBy(materials))
+}
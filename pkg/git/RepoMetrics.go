@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// REPO_METRICS_LARGEST_BLOBS is the number of largest blobs reported when none is asked for
+	REPO_METRICS_LARGEST_BLOBS     = 10
+	REPO_METRICS_MAX_LARGEST_BLOBS = 100
+)
+
+// RepoMetrics is the usage of the object store of a checkout from git count-objects -v, with the size of the checkout on
+// disk and its largest blobs, to find the repos which need a gc or a migration of their large files to lfs
+type RepoMetrics struct {
+	GitMaterialId       int   `json:"gitMaterialId"`
+	CheckoutSizeInBytes int64 `json:"checkoutSizeInBytes"`
+	LooseObjects        int64 `json:"looseObjects"`
+	LooseSizeInBytes    int64 `json:"looseSizeInBytes"`
+	PackedObjects       int64 `json:"packedObjects"`
+	PackCount           int64 `json:"packCount"`
+	PackSizeInBytes     int64 `json:"packSizeInBytes"`
+	// PrunePackable are the loose objects which are also packed, removed by a gc
+	PrunePackable      int64       `json:"prunePackable"`
+	GarbageCount       int64       `json:"garbageCount"`
+	GarbageSizeInBytes int64       `json:"garbageSizeInBytes"`
+	LargestBlobs       []*BlobSize `json:"largestBlobs"`
+}
+
+// BlobSize is a blob of the object store, Path is one of the paths it is committed at. It is empty for the blobs no
+// ref reaches
+type BlobSize struct {
+	Hash string `json:"hash"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// CountObjects returns the object counts and sizes of git count-objects -v, the checkout size and blobs are not set
+func (impl *GitManagerBaseImpl) CountObjects(gitCtx GitContext, rootDir string) (*RepoMetrics, error) {
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "count-objects", "-v")
+	if err != nil {
+		impl.logger.Errorw("error in counting objects", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	return ParseCountObjects(output)
+}
+
+// ParseCountObjects parses the output of git count-objects -v, whose sizes are in KiB
+//
+//	count: 4
+//	size: 16
+//	in-pack: 1200
+//	packs: 1
+//	size-pack: 5400
+//	prune-packable: 0
+//	garbage: 0
+//	size-garbage: 0
+func ParseCountObjects(output string) (*RepoMetrics, error) {
+	metrics := &RepoMetrics{}
+	fields := map[string]*int64{
+		"count":          &metrics.LooseObjects,
+		"size":           &metrics.LooseSizeInBytes,
+		"in-pack":        &metrics.PackedObjects,
+		"packs":          &metrics.PackCount,
+		"size-pack":      &metrics.PackSizeInBytes,
+		"prune-packable": &metrics.PrunePackable,
+		"garbage":        &metrics.GarbageCount,
+		"size-garbage":   &metrics.GarbageSizeInBytes,
+	}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		field, known := fields[strings.TrimSpace(key)]
+		if !found || !known {
+			continue
+		}
+		parsedValue, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count-objects line %q", line)
+		}
+		*field = parsedValue
+	}
+	metrics.LooseSizeInBytes *= 1024
+	metrics.PackSizeInBytes *= 1024
+	metrics.GarbageSizeInBytes *= 1024
+	return metrics, nil
+}
+
+// GetLargestBlobs returns the largest blobs of the object store, largest first. The sizes are read from all the objects
+// and the paths from the history of all the refs, objects missing in partial clones are skipped
+func (impl *GitManagerBaseImpl) GetLargestBlobs(gitCtx GitContext, rootDir string, limit int) ([]*BlobSize, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	stdout, wait, err := impl.StreamCustomCommand(gitCtx, "git", "-C", rootDir, "cat-file", "--batch-all-objects", "--batch-check=%(objecttype) %(objectname) %(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+	var blobs []*BlobSize
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		blobs = addLargestBlob(blobs, &BlobSize{Hash: fields[1], Size: size}, limit)
+	}
+	scanErr := scanner.Err()
+	if errMsg, err := wait(); err != nil {
+		impl.logger.Errorw("error in listing objects", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	} else if scanErr != nil {
+		return nil, scanErr
+	}
+	if len(blobs) == 0 {
+		return blobs, nil
+	}
+	blobsByHash := make(map[string]*BlobSize, len(blobs))
+	for _, blob := range blobs {
+		blobsByHash[blob.Hash] = blob
+	}
+	stdout, wait, err = impl.StreamCustomCommand(gitCtx, "git", "-C", rootDir, "rev-list", "--objects", "--all", "--missing=allow-any")
+	if err != nil {
+		return nil, err
+	}
+	scanner = bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		// <hash> <path> for the trees and blobs
+		hash, objectPath, found := strings.Cut(scanner.Text(), " ")
+		if blob, ok := blobsByHash[hash]; ok && found && len(blob.Path) == 0 {
+			blob.Path = objectPath
+		}
+	}
+	scanErr = scanner.Err()
+	if errMsg, err := wait(); err != nil {
+		impl.logger.Errorw("error in listing objects of the refs", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	} else if scanErr != nil {
+		return nil, scanErr
+	}
+	return blobs, nil
+}
+
+// addLargestBlob adds the blob to the blobs sorted largest first if it is among the limit largest ones
+func addLargestBlob(blobs []*BlobSize, blob *BlobSize, limit int) []*BlobSize {
+	if len(blobs) == limit && blobs[limit-1].Size >= blob.Size {
+		return blobs
+	}
+	index := sort.Search(len(blobs), func(i int) bool {
+		return blobs[i].Size < blob.Size
+	})
+	if len(blobs) < limit {
+		blobs = append(blobs, nil)
+	}
+	copy(blobs[index+1:], blobs[index:])
+	blobs[index] = blob
+	return blobs
+}
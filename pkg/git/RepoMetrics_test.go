@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	metrics, err := ParseCountObjects("count: 4\nsize: 16\nin-pack: 1200\npacks: 2\nsize-pack: 5400\nprune-packable: 1\ngarbage: 0\nsize-garbage: 0")
+	assert.Nil(t, err)
+	assert.Equal(t, &RepoMetrics{LooseObjects: 4, LooseSizeInBytes: 16 * 1024, PackedObjects: 1200, PackCount: 2, PackSizeInBytes: 5400 * 1024, PrunePackable: 1}, metrics)
+	_, err = ParseCountObjects("count: four")
+	assert.NotNil(t, err)
+}
+
+func TestAddLargestBlob(t *testing.T) {
+	var blobs []*BlobSize
+	for _, size := range []int64{5, 1, 9, 7, 3} {
+		blobs = addLargestBlob(blobs, &BlobSize{Size: size}, 3)
+	}
+	var sizes []int64
+	for _, blob := range blobs {
+		sizes = append(sizes, blob.Size)
+	}
+	assert.Equal(t, []int64{9, 7, 5}, sizes)
+}
+
+func TestRepositoryManager_GetRepoMetrics(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	assert.Nil(t, os.MkdirAll(filepath.Join(workDir, "assets"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "assets", "video.bin"), []byte(strings.Repeat("v", 64*1024)), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(workDir, "README.md"), []byte("readme"), 0644))
+	runTestGitCmd(t, workDir, "add", ".")
+	runTestGitCmd(t, workDir, "commit", "-m", "add assets")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	metrics, err := repositoryManager.GetRepoMetrics(gitCtx, checkoutPath, 1)
+	assert.Nil(t, err)
+	assert.True(t, metrics.LooseObjects+metrics.PackedObjects > 0)
+	assert.True(t, metrics.CheckoutSizeInBytes > 0)
+	assert.Len(t, metrics.LargestBlobs, 1)
+	assert.Equal(t, "assets/video.bin", metrics.LargestBlobs[0].Path)
+	assert.Equal(t, int64(64*1024), metrics.LargestBlobs[0].Size)
+	assert.Equal(t, runTestGitCmd(t, workDir, "rev-parse", "HEAD:assets/video.bin"), metrics.LargestBlobs[0].Hash)
+
+	metrics, err = repositoryManager.GetRepoMetrics(gitCtx, checkoutPath, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, metrics.LargestBlobs)
+}
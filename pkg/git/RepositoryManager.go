@@ -17,6 +17,7 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/devtron-labs/git-sensor/internals"
@@ -32,6 +33,8 @@ import (
 
 	"github.com/devtron-labs/git-sensor/internals/middleware"
 	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -39,10 +42,20 @@ type RepositoryManager interface {
 	// Fetch Fetches latest commit for  repo. Creates a new repo if it doesn't already exist
 	// and returns the reference to the repo
 	Fetch(gitCtx GitContext, url string, location string) (updated bool, repo *GitRepository, err error)
+	// FetchWithMirrors fetches from the mirrors in order when the fetch from origin fails with a remote error, fetchedFrom
+	// is the url which served the fetch
+	FetchWithMirrors(gitCtx GitContext, url string, mirrorUrls []string, location string) (updated bool, repo *GitRepository, fetchedFrom string, err error)
 	// Add adds and initializes a new git repo , cleans the directory if not empty and fetches latest commits
 	Add(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error
+	// AddWorktree adds the checkout at location as a worktree of the store shared by the materials of the url, the
+	// store is cloned by the first of them
+	AddWorktree(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error
+	// PruneWorktrees removes the worktrees of the store whose checkouts are gone
+	PruneWorktrees(gitCtx GitContext, store string) error
 	InitRepoAndGetSshPrivateKeyPath(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) (string, error)
 	FetchRepo(gitCtx GitContext, location string) error
+	// RecoverCheckout clones a corrupted checkout again into a fresh dir and swaps it in, the broken checkout is kept in quarantine
+	RecoverCheckout(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error
 	GetCheckoutLocationFromGitUrl(material *sql.GitMaterial, cloningMode string) (location string, httpMatched bool, shMatched bool, err error)
 	GetCheckoutLocation(gitCtx GitContext, material *sql.GitMaterial, url, checkoutPath string) string
 	TrimLastGitCommit(gitCommits []*GitCommitBase, count int) []*GitCommitBase
@@ -52,16 +65,57 @@ type RepositoryManager interface {
 	ChangesSinceByRepository(gitCtx GitContext, repository *GitRepository, branch string, from string, to string, count int, checkoutPath string, openNewGitRepo bool) ([]*GitCommitBase, error)
 	// GetCommitMetadata retrieves the commit metadata for given hash
 	GetCommitMetadata(gitCtx GitContext, checkoutPath, commitHash string) (*GitCommitBase, error)
+	// FetchCommit fetches the commit by its full hash when it is not in the checkout, for commits no longer on any branch
+	FetchCommit(gitCtx GitContext, checkoutPath, commitHash string) error
+	// GetCommitsMetadata retrieves the commit metadata for many hashes at once keyed by hash, unknown hashes are left out
+	GetCommitsMetadata(gitCtx GitContext, checkoutPath string, commitHashes []string) (map[string]*GitCommitBase, error)
 	// GetCommitForTag retrieves the commit metadata for given tag
 	GetCommitForTag(gitCtx GitContext, checkoutPath, tag string) (*GitCommitBase, error)
+	// UpdateCredentials points origin of the checkout at the url and configures the ssh key of the git provider, or
+	// removes the ssh command for other auth modes, so that rotated credentials apply without cloning again
+	UpdateCredentials(gitCtx GitContext, location string, gitProviderId int, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error
 	// CreateSshFileIfNotExistsAndConfigureSshCommand creates ssh file with creds and configures it at the location
 	CreateSshFileIfNotExistsAndConfigureSshCommand(gitCtx GitContext, location string, gitProviderId int, sshPrivateKeyContent string) (string, error)
+	// GetBranchState reports if the branch was deleted at remote or if the last seen commit is no longer reachable from its head (force push)
+	GetBranchState(gitCtx GitContext, checkoutPath, branch, lastSeenHash string) (deleted bool, forcePushed bool, err error)
+	// GetOrphanedCommits lists the commits up to the last seen commit which a force push dropped from the branch, newest first
+	GetOrphanedCommits(gitCtx GitContext, checkoutPath, branch, lastSeenHash string) ([]string, error)
+	// GetTags lists the tags matching the glob or semver constraint pattern, newest first
+	GetTags(gitCtx GitContext, checkoutPath, pattern string) ([]*GitTag, error)
+	// ListBranches lists the remote branches as of the last fetch, most recently updated first
+	ListBranches(gitCtx GitContext, checkoutPath string) ([]*GitBranch, error)
+	// GetFileContentAtCommit returns the content of the file at the commit
+	GetFileContentAtCommit(gitCtx GitContext, checkoutPath, commitHash, filePath string) (*FileContent, error)
+	// GetLfsPointersInCommit returns the files changed by the commit which are git lfs pointers
+	GetLfsPointersInCommit(gitCtx GitContext, checkoutPath, commitHash string) ([]*LfsPointer, error)
+	// GetRepoMetrics returns the object counts and sizes of the checkout, its size on disk and its largest blobs
+	GetRepoMetrics(gitCtx GitContext, checkoutPath string, largestBlobs int) (*RepoMetrics, error)
+	// LsRemote lists the refs matching the given refs at the remote, checking that it is reachable with the credentials
+	LsRemote(gitCtx GitContext, checkoutPath, remote string, refs []string) (map[string]string, error)
+	// ValidateMaterial checks a material in a temporary repo without adding it, see MaterialValidation
+	ValidateMaterial(gitCtx GitContext, request *MaterialValidationRequest, sshPrivateKey string) (*MaterialValidation, error)
+	// WriteArchive streams the archive of the commit to the writer
+	WriteArchive(gitCtx GitContext, checkoutPath, commitHash, format string, paths []string, writer io.Writer) error
+	// GetDiffBetweenCommits returns the unified diff between the two commits, size capped by DIFF_MAX_SIZE_IN_BYTES
+	GetDiffBetweenCommits(gitCtx GitContext, checkoutPath, fromCommit, toCommit string, paths []string, contextLines int) (*CommitRangeDiff, error)
+	// GetBlame returns the last commit which changed each line in the range of the file at the ref
+	GetBlame(gitCtx GitContext, checkoutPath, filePath string, startLine, endLine int, ref string) (*FileBlame, error)
+	// GenerateChangeLog returns the commits between the two refs grouped for release notes with the merged pull requests and authors
+	GenerateChangeLog(gitCtx GitContext, checkoutPath, fromRef, toRef string) (*ChangeLog, error)
+	// GetMergeBase returns the best common ancestor of the two commits, empty if they have no common history
+	GetMergeBase(gitCtx GitContext, checkoutPath, commitA, commitB string) (string, error)
+	// IsAncestor checks whether the ancestor commit is reachable from the descendant commit
+	IsAncestor(gitCtx GitContext, checkoutPath, ancestor, descendant string) (bool, error)
 }
 
 type RepositoryManagerImpl struct {
-	logger        *zap.SugaredLogger
-	gitManager    GitManager
-	configuration *internals.Configuration
+	logger           *zap.SugaredLogger
+	gitManager       GitManager
+	configuration    *internals.Configuration
+	commitStatsCache *CommitStatsCache
+	hostGuard        *HostGuard
+	worktreeStores   *WorktreeStores
+	retryPolicy      *RetryPolicy
 }
 
 func NewRepositoryManagerImpl(
@@ -69,7 +123,11 @@ func NewRepositoryManagerImpl(
 	configuration *internals.Configuration,
 	gitManager GitManager,
 ) *RepositoryManagerImpl {
-	return &RepositoryManagerImpl{logger: logger, configuration: configuration, gitManager: gitManager}
+	commitStatsCache := NewCommitStatsCache(configuration.CommitStatsCacheSize, time.Duration(configuration.CommitStatsCacheTtlInMin)*time.Minute)
+	hostGuard := NewHostGuard(float64(configuration.HostRateLimitPerMin)/60, configuration.HostRateLimitBurst, configuration.HostCircuitFailureThreshold,
+		time.Duration(configuration.HostCircuitCooldownInSec)*time.Second)
+	return &RepositoryManagerImpl{logger: logger, configuration: configuration, gitManager: gitManager, commitStatsCache: commitStatsCache, hostGuard: hostGuard,
+		worktreeStores: gitManager.GetWorktreeStores(), retryPolicy: GetRetryPolicy(configuration, nil)}
 }
 
 func (impl *RepositoryManagerImpl) IsSpaceAvailableOnDisk() bool {
@@ -99,7 +157,7 @@ func (impl *RepositoryManagerImpl) GetCheckoutLocationFromGitUrl(material *sql.G
 		return checkoutPath, httpsMatched, sshMatched, nil
 	}
 
-	return "", httpsMatched, sshMatched, fmt.Errorf("unsupported format url %s", material.Url)
+	return "", httpsMatched, sshMatched, fmt.Errorf("unsupported format url %s", tracing.SanitizeUrl(material.Url))
 }
 
 func (impl *RepositoryManagerImpl) GetCheckoutLocation(gitCtx GitContext, material *sql.GitMaterial, url, checkoutPath string) string {
@@ -107,11 +165,33 @@ func (impl *RepositoryManagerImpl) GetCheckoutLocation(gitCtx GitContext, materi
 }
 
 func (impl *RepositoryManagerImpl) Add(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error {
+	if impl.configuration.SharedWorktreeCheckouts && impl.configuration.UseGitCli {
+		return impl.AddWorktree(gitCtx, gitProviderId, location, url, authMode, sshPrivateKeyContent)
+	}
+	return impl.addClone(gitCtx, gitProviderId, location, url, authMode, sshPrivateKeyContent)
+}
+
+func (impl *RepositoryManagerImpl) addClone(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error {
 	_, err := impl.InitRepoAndGetSshPrivateKeyPath(gitCtx, gitProviderId, location, url, authMode, sshPrivateKeyContent)
 	if err != nil {
 		return err
 	}
-	return impl.FetchRepo(gitCtx, location)
+	err = impl.FetchRepo(impl.withCloneProgress(gitCtx, location), location)
+	if errors.Is(err, ErrTimeout) {
+		// the partial clone of a killed fetch is not resumed, it is cloned again on the next add
+		if cleanErr := os.RemoveAll(location); cleanErr != nil {
+			impl.logger.Errorw("error in removing partial clone", "location", location, "err", cleanErr)
+		}
+	}
+	return err
+}
+
+// withCloneProgress marks the fetch as the clone of the repo and logs its progress, unless a job already tracks it
+func (impl *RepositoryManagerImpl) withCloneProgress(gitCtx GitContext, location string) GitContext {
+	if gitCtx.Progress == nil {
+		gitCtx = gitCtx.WithProgress(NewTransferProgress(impl.logger, "location", location))
+	}
+	return gitCtx.WithClone()
 }
 
 func (impl *RepositoryManagerImpl) InitRepoAndGetSshPrivateKeyPath(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) (string, error) {
@@ -138,7 +218,7 @@ func (impl *RepositoryManagerImpl) InitRepoAndGetSshPrivateKeyPath(gitCtx GitCon
 }
 
 func (impl *RepositoryManagerImpl) CleanupAndInitRepo(gitCtx GitContext, location string, url string) error {
-	err := os.RemoveAll(location)
+	err := impl.removeCheckout(gitCtx, location)
 	if err != nil {
 		impl.logger.Errorw("error in cleaning checkout path", "err", err)
 		return err
@@ -156,7 +236,12 @@ func (impl *RepositoryManagerImpl) CleanupAndInitRepo(gitCtx GitContext, locatio
 }
 
 func (impl *RepositoryManagerImpl) FetchRepo(gitCtx GitContext, location string) error {
-	opt, errorMsg, err := impl.gitManager.Fetch(gitCtx, location)
+	var opt, errorMsg string
+	err := impl.retry(gitCtx, "clone", func() (err error) {
+		impl.removeLockFilesOfKilledCommands(location)
+		opt, errorMsg, err = impl.gitManager.Fetch(gitCtx, location)
+		return err
+	})
 	if err != nil {
 		impl.logger.Errorw("error in fetching repo", "errorMsg", errorMsg, "err", err)
 		return err
@@ -165,49 +250,253 @@ func (impl *RepositoryManagerImpl) FetchRepo(gitCtx GitContext, location string)
 	return nil
 }
 
+const (
+	RECOVERY_DIR_SUFFIX   = ".recovering"
+	QUARANTINE_DIR_SUFFIX = ".quarantined"
+)
+
+// RecoverCheckout clones the repo into a fresh dir next to the corrupted checkout and swaps the two once the clone is
+// complete, so a failed clone leaves the checkout as it was. The broken checkout is kept for inspection until the next
+// recovery of the same location
+func (impl *RepositoryManagerImpl) RecoverCheckout(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error {
+	var err error
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("recover", start, err)
+	}()
+	if len(GetWorktreeStore(location)) > 0 {
+		// moving a worktree breaks its link with the store, it is added again in place as its objects are in the store
+		impl.logger.Infow("recovering corrupted worktree", "location", location)
+		err = impl.AddWorktree(gitCtx, gitProviderId, location, url, authMode, sshPrivateKeyContent)
+		return err
+	}
+	freshLocation := location + RECOVERY_DIR_SUFFIX
+	quarantineLocation := location + QUARANTINE_DIR_SUFFIX
+	impl.logger.Infow("recovering corrupted checkout", "location", location, "freshLocation", freshLocation)
+	err = impl.Add(gitCtx, gitProviderId, freshLocation, url, authMode, sshPrivateKeyContent)
+	if err != nil {
+		impl.logger.Errorw("error in cloning repo to recover corrupted checkout", "location", location, "err", err)
+		_ = os.RemoveAll(freshLocation)
+		return err
+	}
+	if err = os.RemoveAll(quarantineLocation); err != nil {
+		impl.logger.Errorw("error in removing previous quarantined checkout", "quarantineLocation", quarantineLocation, "err", err)
+		return err
+	}
+	if err = os.Rename(location, quarantineLocation); err != nil && !os.IsNotExist(err) {
+		impl.logger.Errorw("error in quarantining corrupted checkout", "location", location, "err", err)
+		return err
+	}
+	if err = os.Rename(freshLocation, location); err != nil {
+		impl.logger.Errorw("error in swapping recovered checkout", "location", location, "err", err)
+		return err
+	}
+	impl.logger.Infow("recovered corrupted checkout", "location", location, "quarantineLocation", quarantineLocation)
+	return nil
+}
+
 func (impl *RepositoryManagerImpl) Clean(dir string) error {
 	var err error
 	start := time.Now()
 	defer func() {
 		util.TriggerGitOperationMetrics("clean", start, err)
 	}()
-	err = os.RemoveAll(dir)
+	err = impl.removeCheckout(BuildGitContext(context.Background()), dir)
+	return err
+}
+
+func (impl *RepositoryManagerImpl) AddWorktree(gitCtx GitContext, gitProviderId int, location, url string, authMode sql.AuthMode, sshPrivateKeyContent string) error {
+	var err error
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("addWorktree", start, err)
+	}()
+	store := getWorktreeStoreLocation(gitProviderId, gitCtx.CredentialScope, url)
+	unlock := impl.worktreeStores.Lock(store)
+	defer unlock()
+	if _, statErr := os.Stat(store); os.IsNotExist(statErr) {
+		_, err = impl.InitRepoAndGetSshPrivateKeyPath(gitCtx, gitProviderId, store, url, authMode, sshPrivateKeyContent)
+		if err != nil {
+			return err
+		}
+	}
+	err = impl.FetchRepo(impl.withCloneProgress(gitCtx, store), store)
+	if err != nil {
+		return err
+	}
+	branches, err := impl.gitManager.ListBranches(gitCtx, store)
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		// a worktree is added at a commit, an empty repo gets a clone of its own
+		impl.logger.Infow("repo has no branches, cloning it instead of adding a worktree", "location", location, "store", store)
+		err = impl.addClone(gitCtx, gitProviderId, location, url, authMode, sshPrivateKeyContent)
+		return err
+	}
+	previousStore := GetWorktreeStore(location)
+	err = os.RemoveAll(location)
+	if err != nil {
+		impl.logger.Errorw("error in cleaning checkout path", "location", location, "err", err)
+		return err
+	}
+	if len(previousStore) > 0 && previousStore != store {
+		err = impl.PruneWorktrees(gitCtx, previousStore)
+		if err != nil {
+			return err
+		}
+	}
+	// a worktree left behind at the location is pruned, the store is already locked
+	err = impl.gitManager.PruneWorktrees(gitCtx, store)
+	if err != nil {
+		return err
+	}
+	err = impl.gitManager.AddWorktree(gitCtx, store, location, branches[0].Commit)
 	return err
 }
 
+func (impl *RepositoryManagerImpl) PruneWorktrees(gitCtx GitContext, store string) error {
+	var err error
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("pruneWorktrees", start, err)
+	}()
+	unlock := impl.worktreeStores.Lock(store)
+	defer unlock()
+	err = impl.gitManager.PruneWorktrees(gitCtx, store)
+	return err
+}
+
+// removeCheckout removes the checkout dir, and the worktree from its store when it is a worktree
+func (impl *RepositoryManagerImpl) removeCheckout(gitCtx GitContext, location string) error {
+	store := GetWorktreeStore(location)
+	err := os.RemoveAll(location)
+	if err != nil {
+		return err
+	}
+	if len(store) == 0 {
+		return nil
+	}
+	return impl.PruneWorktrees(gitCtx, store)
+}
+
+// haveWorktreeRefsChanged checks if the refs seen by the worktree at location changed since its last fetch, false for
+// other checkouts
+func (impl *RepositoryManagerImpl) haveWorktreeRefsChanged(gitCtx GitContext, location string) bool {
+	if len(GetWorktreeStore(location)) == 0 {
+		return false
+	}
+	checksum, err := impl.gitManager.GetRefsChecksum(gitCtx, location)
+	if err != nil {
+		return false
+	}
+	return impl.worktreeStores.SwapRefsChecksum(location, checksum)
+}
+
 func (impl *RepositoryManagerImpl) Fetch(gitCtx GitContext, url string, location string) (updated bool, repo *GitRepository, err error) {
+	updated, repo, _, err = impl.FetchWithMirrors(gitCtx, url, nil, location)
+	return updated, repo, err
+}
+
+func (impl *RepositoryManagerImpl) FetchWithMirrors(gitCtx GitContext, url string, mirrorUrls []string, location string) (updated bool, repo *GitRepository, fetchedFrom string, err error) {
 	start := time.Now()
+	var span trace.Span
+	gitCtx.Context, span = tracing.StartSpan(gitCtx, "fetch", tracing.ATTRIBUTE_REPO_URL.String(tracing.SanitizeUrl(url)))
 	defer func() {
 		util.TriggerGitOperationMetrics("fetch", start, err)
+		tracing.EndSpan(span, err)
 	}()
 	middleware.GitMaterialPollCounter.WithLabelValues().Inc()
 	if !impl.IsSpaceAvailableOnDisk() {
 		err = errors.New("git-sensor PVC - disk full, please increase space")
-		return false, nil, err
+		return false, nil, "", err
 	}
 	r, err := impl.openNewRepo(gitCtx, location, url)
 	if err != nil {
-		return false, r, err
+		return false, r, "", err
 	}
-	res, errorMsg, err := impl.gitManager.Fetch(gitCtx, location)
+	fetchedFrom = url
+	res, errorMsg, err := impl.fetchWithRetry(gitCtx, location, url)
+	for _, mirrorUrl := range mirrorUrls {
+		if err == nil || !IsRemoteGitError(err) || gitCtx.Err() != nil {
+			break
+		}
+		impl.logger.Warnw("fetch failed, falling back to mirror", "url", tracing.SanitizeUrl(fetchedFrom), "mirror", tracing.SanitizeUrl(mirrorUrl), "err", err)
+		fetchedFrom = mirrorUrl
+		mirrorCtx := gitCtx.WithFetchUrl(mirrorUrl)
+		// the credentials of the material are for its own host, they are never sent to a mirror on another host
+		if getRemoteHost(mirrorUrl) != getRemoteHost(url) {
+			mirrorCtx = mirrorCtx.WithCredentials("", "")
+		}
+		res, errorMsg, err = impl.fetchWithRetry(mirrorCtx, location, mirrorUrl)
+	}
+	// the worktrees of a store see the commits fetched through the others, their own fetch then has no output
+	refsChanged := err == nil && impl.haveWorktreeRefsChanged(gitCtx, location)
 
-	if err == nil && len(res) > 0 {
-		impl.logger.Infow("repository updated", "location", url)
+	if err == nil && (len(res) > 0 || refsChanged) {
+		impl.logger.Infow("repository updated", "location", url, "fetchedFrom", tracing.SanitizeUrl(fetchedFrom))
 		//updated
 		middleware.GitPullDuration.WithLabelValues("true", "true").Observe(time.Since(start).Seconds())
-		return true, r, nil
+		return true, r, fetchedFrom, nil
 	} else if err == nil && len(res) == 0 {
-		impl.logger.Debugw("no update for ", "path", url)
+		impl.logger.Debugw("no update for ", "path", url, "fetchedFrom", tracing.SanitizeUrl(fetchedFrom))
 		middleware.GitPullDuration.WithLabelValues("true", "false").Observe(time.Since(start).Seconds())
-		return false, r, nil
+		return false, r, fetchedFrom, nil
 	} else {
 		impl.logger.Errorw("error in updating repository", "err", err, "location", url, "error msg", errorMsg)
 		middleware.GitPullDuration.WithLabelValues("false", "false").Observe(time.Since(start).Seconds())
-		return false, r, err
+		return false, r, "", err
 	}
 
 }
 
+// fetchWithRetry retries the fetch from remoteUrl while it fails with transient errors, each attempt is guarded
+func (impl *RepositoryManagerImpl) fetchWithRetry(gitCtx GitContext, location string, remoteUrl string) (response, errMsg string, err error) {
+	err = impl.retry(gitCtx, "fetch", func() (err error) {
+		response, errMsg, err = impl.fetchGuarded(gitCtx, location, remoteUrl)
+		return err
+	})
+	return response, errMsg, err
+}
+
+// retry runs the remote operation with the retry policy of the material in the context, else with the default one
+func (impl *RepositoryManagerImpl) retry(gitCtx GitContext, operation string, remoteOperation func() error) error {
+	retryPolicy := gitCtx.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = impl.retryPolicy
+	}
+	return retryPolicy.Do(gitCtx, remoteOperation, func(attempt int, backoff time.Duration, err error) {
+		impl.logger.Warnw("retrying git operation after transient error", "operation", operation, "attempt", attempt, "backoff", backoff, "err", err)
+		middleware.GitRetryCounter.WithLabelValues(operation).Inc()
+	})
+}
+
+// fetchGuarded fetches from remoteUrl within the rate limit and circuit breaker of its host
+func (impl *RepositoryManagerImpl) fetchGuarded(gitCtx GitContext, location string, remoteUrl string) (response, errMsg string, err error) {
+	host := getRemoteHost(remoteUrl)
+	err = impl.hostGuard.Acquire(gitCtx, host)
+	if err != nil {
+		return "", "", err
+	}
+	unlockStore := impl.worktreeStores.LockStoreOf(location)
+	defer unlockStore()
+	impl.removeLockFilesOfKilledCommands(location)
+	response, errMsg, err = impl.gitManager.Fetch(gitCtx, location)
+	if opened := impl.hostGuard.Done(host, err); opened {
+		impl.logger.Warnw("pausing fetches of git host after repeated failures", "host", host, "cooldownInSec", impl.configuration.HostCircuitCooldownInSec, "err", err)
+	}
+	return response, errMsg, err
+}
+
+// removeLockFilesOfKilledCommands removes the lock files left by the killed git commands of the repo at location before
+// the next command on it, the caller must hold the lock of the material and the one of its store
+func (impl *RepositoryManagerImpl) removeLockFilesOfKilledCommands(location string) {
+	_, err := impl.gitManager.RemoveLockFilesOfKilledCommands(location)
+	if err != nil {
+		impl.logger.Errorw("error in removing lock files of killed git commands", "location", location, "err", err)
+	}
+}
+
 func (impl *RepositoryManagerImpl) GetCommitForTag(gitCtx GitContext, checkoutPath, tag string) (*GitCommitBase, error) {
 	var err error
 	start := time.Now()
@@ -232,8 +521,51 @@ func (impl *RepositoryManagerImpl) GetCommitMetadata(gitCtx GitContext, checkout
 	if err != nil {
 		return nil, err
 	}
+	commit := gitCommit.GetCommit()
+	if gitCtx.ResolveSubmodules {
+		submodules, err := impl.gitManager.GetSubmoduleChanges(gitCtx, checkoutPath, commit.Commit)
+		if err != nil {
+			impl.logger.Errorw("error in getting submodule changes", "commit", commit.Commit, "err", err)
+		}
+		commit.Submodules = submodules
+	}
+	return commit, nil
+}
 
-	return gitCommit.GetCommit(), nil
+func (impl *RepositoryManagerImpl) FetchCommit(gitCtx GitContext, checkoutPath, commitHash string) (err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("fetchCommit", start, err)
+	}()
+	unlockStore := impl.worktreeStores.LockStoreOf(checkoutPath)
+	defer unlockStore()
+	var errMsg string
+	err = impl.retry(gitCtx, "fetchCommit", func() (err error) {
+		impl.removeLockFilesOfKilledCommands(checkoutPath)
+		errMsg, err = impl.gitManager.FetchCommit(gitCtx, checkoutPath, commitHash)
+		return err
+	})
+	if err != nil {
+		impl.logger.Errorw("error in fetching commit", "checkoutPath", checkoutPath, "commitHash", commitHash, "errMsg", errMsg, "err", err)
+	}
+	return err
+}
+
+func (impl *RepositoryManagerImpl) GetCommitsMetadata(gitCtx GitContext, checkoutPath string, commitHashes []string) (commits map[string]*GitCommitBase, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getCommitsMetadata", start, err)
+	}()
+	gitCommits, err := impl.gitManager.GetCommitsForHashes(gitCtx, checkoutPath, commitHashes)
+	if err != nil {
+		impl.logger.Errorw("error in getting commits for hashes", "checkoutPath", checkoutPath, "hashes", len(commitHashes), "err", err)
+		return nil, err
+	}
+	commits = make(map[string]*GitCommitBase, len(gitCommits))
+	for commitHash, gitCommit := range gitCommits {
+		commits[commitHash] = gitCommit.GetCommit()
+	}
+	return commits, nil
 }
 
 // from -> old commit
@@ -255,23 +587,33 @@ func (impl *RepositoryManagerImpl) ChangesSinceByRepository(gitCtx GitContext, r
 		}
 	}
 
+	branch, branchRef := GetBranchReference(branch)
 	start := time.Now()
+	var span trace.Span
+	gitCtx.Context, span = tracing.StartSpan(gitCtx, "changesSinceByRepository", tracing.ATTRIBUTE_BRANCH.String(branch))
+	var gitCommits []*GitCommitBase
 	defer func() {
 		util.TriggerGitOperationMetrics("changesSinceByRepository", start, err)
+		span.SetAttributes(tracing.ATTRIBUTE_COMMIT_COUNT.Int(len(gitCommits)))
+		tracing.EndSpan(span, err)
 	}()
-	branch, branchRef := GetBranchReference(branch)
 	itr, err := impl.gitManager.GetCommitIterator(gitCtx, repository, IteratorRequest{
 		BranchRef:      branchRef,
 		Branch:         branch,
 		CommitCount:    count,
 		FromCommitHash: from,
 		ToCommitHash:   to,
+		IncludePaths:   gitCtx.IncludePaths,
+		ExcludePaths:   gitCtx.ExcludePaths,
+		FirstParent:    gitCtx.FirstParent,
+		Since:          gitCtx.Since,
+		Until:          gitCtx.Until,
 	})
 	if err != nil {
 		impl.logger.Errorw("error in getting iterator", "branch", branch, "err", err)
 		return nil, err
 	}
-	var gitCommits []*GitCommitBase
+	defer itr.Close()
 	itrCounter := 0
 	commitToFind := len(to) == 0 //no commit mentioned
 	breakLoop := false
@@ -312,6 +654,27 @@ func (impl *RepositoryManagerImpl) ChangesSinceByRepository(gitCtx GitContext, r
 			if !gitCommit.IsMessageValidUTF8() {
 				gitCommit.FixInvalidUTF8Message()
 			}
+			if gitCtx.ResolveSubmodules {
+				submodules, err := impl.gitManager.GetSubmoduleChanges(gitCtx, checkoutPath, gitCommit.Commit)
+				if err != nil {
+					impl.logger.Errorw("error in getting submodule changes", "commit", gitCommit.Commit, "err", err)
+				}
+				gitCommit.Submodules = submodules
+			}
+			if impl.configuration.EnableChangedFiles {
+				changedFiles, err := impl.gitManager.GetChangedFiles(gitCtx, checkoutPath, gitCommit.Commit)
+				if err != nil {
+					impl.logger.Errorw("error in getting changed files", "commit", gitCommit.Commit, "err", err)
+				}
+				gitCommit.ChangedFiles = changedFiles
+			}
+			if gitCtx.VerifySignatures {
+				signature, err := impl.gitManager.VerifyCommitSignature(gitCtx, checkoutPath, gitCommit.Commit)
+				if err != nil {
+					impl.logger.Errorw("error in verifying commit signature", "commit", gitCommit.Commit, "err", err)
+				}
+				gitCommit.Signature = signature
+			}
 			impl.logger.Debugw("commit dto for repo ", "repo", repository, commit)
 			gitCommits = append(gitCommits, gitCommit)
 			itrCounter = itrCounter + 1
@@ -329,7 +692,7 @@ func (impl *RepositoryManagerImpl) ChangesSinceByRepository(gitCtx GitContext, r
 					//TODO: needed this in case of go-git mode where we are executing cli command
 					statsCheckoutPath = checkoutPath
 				}
-				stats, err := impl.gitManager.GetCommitStats(gitCtx, commit, statsCheckoutPath)
+				stats, err := impl.getCommitStats(gitCtx, commit, statsCheckoutPath)
 				if err != nil {
 					impl.logger.Errorw("error in  fetching stats", "err", err)
 				}
@@ -337,9 +700,242 @@ func (impl *RepositoryManagerImpl) ChangesSinceByRepository(gitCtx GitContext, r
 			}
 		}()
 	}
+	if impl.configuration.FetchGitNotes {
+		impl.setCommitNotes(gitCtx, checkoutPath, gitCommits)
+	}
 	return gitCommits, err
 }
 
+// setCommitNotes sets the notes of the commits, the commits are returned without notes when they can't be read
+func (impl *RepositoryManagerImpl) setCommitNotes(gitCtx GitContext, checkoutPath string, gitCommits []*GitCommitBase) {
+	commitHashes := make([]string, 0, len(gitCommits))
+	for _, gitCommit := range gitCommits {
+		commitHashes = append(commitHashes, gitCommit.Commit)
+	}
+	commitNotes, err := impl.gitManager.GetCommitNotes(gitCtx, checkoutPath, commitHashes)
+	if err != nil {
+		impl.logger.Errorw("error in getting commit notes", "checkoutPath", checkoutPath, "err", err)
+		return
+	}
+	for _, gitCommit := range gitCommits {
+		gitCommit.Notes = commitNotes[gitCommit.Commit]
+	}
+}
+
+// getCommitStats returns the file stats of the commit from the cache, running git only for the commits not seen before
+func (impl *RepositoryManagerImpl) getCommitStats(gitCtx GitContext, commit GitCommit, checkoutPath string) (FileStats, error) {
+	commitHash := commit.GetCommit().Commit
+	if stats, ok := impl.commitStatsCache.Get(checkoutPath, commitHash); ok {
+		middleware.CommitStatsCacheCounter.WithLabelValues("hit").Inc()
+		return stats, nil
+	}
+	middleware.CommitStatsCacheCounter.WithLabelValues("miss").Inc()
+	stats, err := impl.gitManager.GetCommitStats(gitCtx, commit, checkoutPath)
+	if err == nil {
+		if markErr := impl.gitManager.MarkLargeFiles(gitCtx, checkoutPath, commitHash, stats); markErr != nil {
+			impl.logger.Errorw("error in marking large files of commit", "commitHash", commitHash, "err", markErr)
+		}
+		impl.commitStatsCache.Put(checkoutPath, commitHash, stats)
+	}
+	return stats, err
+}
+
+func (impl *RepositoryManagerImpl) GetBranchState(gitCtx GitContext, checkoutPath, branch, lastSeenHash string) (deleted bool, forcePushed bool, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getBranchState", start, err)
+	}()
+	_, branchRef := GetBranchReference(branch)
+	exists, err := impl.gitManager.RefExists(gitCtx, checkoutPath, branchRef)
+	if err != nil {
+		impl.logger.Errorw("error in checking branch ref", "checkoutPath", checkoutPath, "branchRef", branchRef, "err", err)
+		return false, false, err
+	}
+	if !exists {
+		return true, false, nil
+	}
+	if len(lastSeenHash) == 0 {
+		return false, false, nil
+	}
+	isAncestor, err := impl.gitManager.IsAncestor(gitCtx, checkoutPath, lastSeenHash, branchRef)
+	if err != nil {
+		impl.logger.Errorw("error in checking ancestry of last seen commit", "checkoutPath", checkoutPath, "lastSeenHash", lastSeenHash, "branchRef", branchRef, "err", err)
+		return false, false, err
+	}
+	return false, !isAncestor, nil
+}
+
+func (impl *RepositoryManagerImpl) GetOrphanedCommits(gitCtx GitContext, checkoutPath, branch, lastSeenHash string) (orphanedCommits []string, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getOrphanedCommits", start, err)
+	}()
+	_, branchRef := GetBranchReference(branch)
+	orphanedCommits, err = impl.gitManager.GetOrphanedCommits(gitCtx, checkoutPath, lastSeenHash, branchRef, impl.configuration.GitHistoryCount)
+	if err != nil {
+		impl.logger.Errorw("error in getting orphaned commits", "checkoutPath", checkoutPath, "lastSeenHash", lastSeenHash, "branchRef", branchRef, "err", err)
+	}
+	return orphanedCommits, err
+}
+
+func (impl *RepositoryManagerImpl) GetTags(gitCtx GitContext, checkoutPath, pattern string) (tags []*GitTag, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getTags", start, err)
+	}()
+	tags, err = impl.gitManager.GetTags(gitCtx, checkoutPath, pattern)
+	if err != nil {
+		impl.logger.Errorw("error in getting tags", "checkoutPath", checkoutPath, "pattern", pattern, "err", err)
+	}
+	return tags, err
+}
+
+func (impl *RepositoryManagerImpl) ListBranches(gitCtx GitContext, checkoutPath string) (branches []*GitBranch, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("listBranches", start, err)
+	}()
+	branches, err = impl.gitManager.ListBranches(gitCtx, checkoutPath)
+	if err != nil {
+		impl.logger.Errorw("error in listing branches", "checkoutPath", checkoutPath, "err", err)
+	}
+	return branches, err
+}
+
+func (impl *RepositoryManagerImpl) GetFileContentAtCommit(gitCtx GitContext, checkoutPath, commitHash, filePath string) (fileContent *FileContent, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getFileContentAtCommit", start, err)
+	}()
+	fileContent, err = impl.gitManager.GetFileContentAtCommit(gitCtx, checkoutPath, commitHash, filePath)
+	if err != nil {
+		impl.logger.Errorw("error in getting file content", "checkoutPath", checkoutPath, "commitHash", commitHash, "filePath", filePath, "err", err)
+	}
+	return fileContent, err
+}
+
+func (impl *RepositoryManagerImpl) GetLfsPointersInCommit(gitCtx GitContext, checkoutPath, commitHash string) (pointers []*LfsPointer, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getLfsPointersInCommit", start, err)
+	}()
+	pointers, err = impl.gitManager.GetLfsPointersInCommit(gitCtx, checkoutPath, commitHash)
+	if err != nil {
+		impl.logger.Errorw("error in getting lfs pointers", "checkoutPath", checkoutPath, "commitHash", commitHash, "err", err)
+	}
+	return pointers, err
+}
+
+func (impl *RepositoryManagerImpl) GetRepoMetrics(gitCtx GitContext, checkoutPath string, largestBlobs int) (metrics *RepoMetrics, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getRepoMetrics", start, err)
+	}()
+	metrics, err = impl.gitManager.CountObjects(gitCtx, checkoutPath)
+	if err != nil {
+		impl.logger.Errorw("error in counting objects", "checkoutPath", checkoutPath, "err", err)
+		return nil, err
+	}
+	metrics.CheckoutSizeInBytes, err = GetDirSize(checkoutPath)
+	if err != nil {
+		impl.logger.Errorw("error in getting checkout size", "checkoutPath", checkoutPath, "err", err)
+		return nil, err
+	}
+	metrics.LargestBlobs, err = impl.gitManager.GetLargestBlobs(gitCtx, checkoutPath, largestBlobs)
+	if err != nil {
+		impl.logger.Errorw("error in getting largest blobs", "checkoutPath", checkoutPath, "err", err)
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (impl *RepositoryManagerImpl) LsRemote(gitCtx GitContext, checkoutPath, remote string, refs []string) (remoteRefs map[string]string, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("lsRemote", start, err)
+	}()
+	err = impl.retry(gitCtx, "lsRemote", func() (err error) {
+		remoteRefs, err = impl.gitManager.LsRemote(gitCtx, checkoutPath, remote, refs)
+		return err
+	})
+	if err != nil {
+		impl.logger.Errorw("error in listing remote refs", "checkoutPath", checkoutPath, "remote", tracing.SanitizeUrl(remote), "err", err)
+	}
+	return remoteRefs, err
+}
+
+func (impl *RepositoryManagerImpl) WriteArchive(gitCtx GitContext, checkoutPath, commitHash, format string, paths []string, writer io.Writer) (err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("writeArchive", start, err)
+	}()
+	err = impl.gitManager.WriteArchive(gitCtx, checkoutPath, commitHash, format, paths, writer)
+	if err != nil {
+		impl.logger.Errorw("error in writing archive", "checkoutPath", checkoutPath, "commitHash", commitHash, "format", format, "paths", paths, "err", err)
+	}
+	return err
+}
+
+func (impl *RepositoryManagerImpl) GetDiffBetweenCommits(gitCtx GitContext, checkoutPath, fromCommit, toCommit string, paths []string, contextLines int) (diff *CommitRangeDiff, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getDiffBetweenCommits", start, err)
+	}()
+	diff, err = impl.gitManager.GetDiffBetweenCommits(gitCtx, checkoutPath, fromCommit, toCommit, paths, contextLines)
+	if err != nil {
+		impl.logger.Errorw("error in getting diff between commits", "checkoutPath", checkoutPath, "fromCommit", fromCommit, "toCommit", toCommit, "paths", paths, "err", err)
+	}
+	return diff, err
+}
+
+func (impl *RepositoryManagerImpl) GetBlame(gitCtx GitContext, checkoutPath, filePath string, startLine, endLine int, ref string) (fileBlame *FileBlame, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getBlame", start, err)
+	}()
+	fileBlame, err = impl.gitManager.GetBlame(gitCtx, checkoutPath, filePath, startLine, endLine, ref)
+	if err != nil {
+		impl.logger.Errorw("error in getting blame", "checkoutPath", checkoutPath, "filePath", filePath, "startLine", startLine, "endLine", endLine, "ref", ref, "err", err)
+	}
+	return fileBlame, err
+}
+
+func (impl *RepositoryManagerImpl) GenerateChangeLog(gitCtx GitContext, checkoutPath, fromRef, toRef string) (changeLog *ChangeLog, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("generateChangeLog", start, err)
+	}()
+	changeLog, err = impl.gitManager.GenerateChangeLog(gitCtx, checkoutPath, fromRef, toRef)
+	if err != nil {
+		impl.logger.Errorw("error in generating change log", "checkoutPath", checkoutPath, "fromRef", fromRef, "toRef", toRef, "err", err)
+	}
+	return changeLog, err
+}
+
+func (impl *RepositoryManagerImpl) GetMergeBase(gitCtx GitContext, checkoutPath, commitA, commitB string) (mergeBase string, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("getMergeBase", start, err)
+	}()
+	mergeBase, err = impl.gitManager.GetMergeBase(gitCtx, checkoutPath, commitA, commitB)
+	if err != nil {
+		impl.logger.Errorw("error in getting merge base", "checkoutPath", checkoutPath, "commitA", commitA, "commitB", commitB, "err", err)
+	}
+	return mergeBase, err
+}
+
+func (impl *RepositoryManagerImpl) IsAncestor(gitCtx GitContext, checkoutPath, ancestor, descendant string) (isAncestor bool, err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("isAncestor", start, err)
+	}()
+	isAncestor, err = impl.gitManager.IsAncestor(gitCtx, checkoutPath, ancestor, descendant)
+	if err != nil {
+		impl.logger.Errorw("error in checking commit ancestry", "checkoutPath", checkoutPath, "ancestor", ancestor, "descendant", descendant, "err", err)
+	}
+	return isAncestor, err
+}
+
 func (impl *RepositoryManagerImpl) TrimLastGitCommit(gitCommits []*GitCommitBase, count int) []*GitCommitBase {
 	if len(gitCommits) > count {
 		gitCommits = gitCommits[:len(gitCommits)-1]
@@ -355,7 +951,7 @@ func (impl *RepositoryManagerImpl) CreateSshFileIfNotExistsAndConfigureSshComman
 	defer func() {
 		util.TriggerGitOperationMetrics("createSshFileIfNotExistsAndConfigureSshCommand", start, err)
 	}()
-	sshPrivateKeyPath, err = GetOrCreateSshPrivateKeyOnDisk(gitProviderId, sshPrivateKeyContent)
+	sshPrivateKeyPath, err = GetOrCreateSshPrivateKeyOnDisk(getSshKeyOwner(gitCtx, gitProviderId), sshPrivateKeyContent)
 	if err != nil {
 		impl.logger.Errorw("error in creating ssh private key", "err", err)
 		return sshPrivateKeyPath, err
@@ -371,6 +967,45 @@ func (impl *RepositoryManagerImpl) CreateSshFileIfNotExistsAndConfigureSshComman
 	return sshPrivateKeyPath, nil
 }
 
+// getSshKeyOwner returns the directory name of the ssh key file, the one of the git provider unless the credentials are
+// scoped to a material
+func getSshKeyOwner(gitCtx GitContext, gitProviderId int) string {
+	if len(gitCtx.CredentialScope) > 0 {
+		return gitCtx.CredentialScope
+	}
+	return strconv.Itoa(gitProviderId)
+}
+
+func (impl *RepositoryManagerImpl) UpdateCredentials(gitCtx GitContext, location string, gitProviderId int, url string, authMode sql.AuthMode, sshPrivateKeyContent string) (err error) {
+	start := time.Now()
+	defer func() {
+		util.TriggerGitOperationMetrics("updateCredentials", start, err)
+	}()
+	// the remote and the ssh command of a worktree are the ones of its store
+	unlockStore := impl.worktreeStores.LockStoreOf(location)
+	defer unlockStore()
+	_, errMsg, err := impl.gitManager.ExecuteCustomCommand(gitCtx, "git", "-C", location, "remote", "set-url", "origin", url)
+	if err != nil {
+		impl.logger.Errorw("error in updating remote url", "location", location, "errMsg", errMsg, "err", err)
+		return err
+	}
+	if authMode == sql.AUTH_MODE_SSH {
+		// the key file is written again since the one on disk may be of the key before the rotation
+		err = CreateOrUpdateSshPrivateKeyOnDisk(getSshKeyOwner(gitCtx, gitProviderId), sshPrivateKeyContent)
+		if err != nil {
+			impl.logger.Errorw("error in updating ssh private key", "gitProviderId", gitProviderId, "err", err)
+			return err
+		}
+		_, err = impl.CreateSshFileIfNotExistsAndConfigureSshCommand(gitCtx, location, gitProviderId, sshPrivateKeyContent)
+		return err
+	}
+	// the credentials of the other auth modes are passed to each command through askpass, only a stale ssh command of
+	// an earlier ssh provider is removed. unset fails when it is not set, which is fine
+	_, errMsg, unsetErr := impl.gitManager.ExecuteCustomCommand(gitCtx, "git", "-C", location, "config", "--unset-all", "core.sshCommand")
+	impl.logger.Debugw("unset ssh command", "location", location, "errMsg", errMsg, "err", unsetErr)
+	return nil
+}
+
 func (impl *RepositoryManagerImpl) openNewRepo(gitCtx GitContext, location string, url string) (*GitRepository, error) {
 
 	r, err := impl.gitManager.OpenRepoPlain(location)
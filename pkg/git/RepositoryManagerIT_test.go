@@ -56,8 +56,8 @@ func getRepoManagerImpl(t *testing.T) *RepositoryManagerImpl {
 		GoGitTimeout:            10,
 	}
 	base := NewGitManagerBaseImpl(logger, conf)
-	_ = NewGitCliManagerImpl(base, logger)
-	_ = NewGoGitSDKManagerImpl(base, logger)
+	_ = NewGitCliManagerImpl(base, logger, conf)
+	_ = NewGoGitSDKManagerImpl(base, logger, conf)
 
 	gitUtil := NewGitManagerImpl(logger, conf)
 	repositoryManagerImpl := NewRepositoryManagerImpl(logger, conf, gitUtil)
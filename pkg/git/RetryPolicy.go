@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetryPolicy retries the remote operations which fail with a transient network error, waiting an exponential backoff
+// between the attempts. Fetch, ls-remote and the fetch of a clone leave the refs as they were when they fail, so they
+// are retried as they are
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Deadline is the time since the first attempt after which no retry is started, 0 for no limit
+	Deadline time.Duration
+}
+
+// the overrides of a material are capped, so that a material can't hold its repo lock and a poll worker for long
+const (
+	MAX_MATERIAL_RETRY_ATTEMPTS = 10
+	MAX_MATERIAL_RETRY_BACKOFF  = 5 * time.Minute
+)
+
+// GetRetryPolicy returns the retry policy of the GIT_RETRY_* config with the overrides of the material
+func GetRetryPolicy(configuration *internals.Configuration, material *sql.GitMaterial) *RetryPolicy {
+	policy := &RetryPolicy{
+		MaxAttempts:    configuration.GitRetryMaxAttempts,
+		InitialBackoff: time.Duration(configuration.GitRetryBackoffInMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(configuration.GitRetryMaxBackoffInSec) * time.Second,
+		Deadline:       time.Duration(configuration.GitRetryDeadlineInSec) * time.Second,
+	}
+	if material != nil && material.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = min(material.RetryMaxAttempts, MAX_MATERIAL_RETRY_ATTEMPTS)
+	}
+	if material != nil && material.RetryMaxBackoffInSec > 0 {
+		policy.MaxBackoff = min(time.Duration(material.RetryMaxBackoffInSec)*time.Second, MAX_MATERIAL_RETRY_BACKOFF)
+	}
+	return policy
+}
+
+// GetBackoff returns the wait before the retry following the given failed attempt, counted from 1. The backoff is
+// doubled for each attempt up to MaxBackoff, and the half of it is randomized so that the materials of a host which
+// failed together don't retry together
+func (policy *RetryPolicy) GetBackoff(attempt int) time.Duration {
+	backoff := policy.InitialBackoff
+	for i := 1; i < attempt && (policy.MaxBackoff <= 0 || backoff < policy.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// Do runs the operation till it succeeds, fails with an error which is not transient, the attempts are exhausted or the
+// retry would end past the deadline. onRetry is called before each retry with the error of the failed attempt
+func (policy *RetryPolicy) Do(ctx context.Context, operation func() error, onRetry func(attempt int, backoff time.Duration, err error)) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt >= policy.MaxAttempts || !IsTransientGitError(err) || ctx.Err() != nil {
+			return err
+		}
+		backoff := policy.GetBackoff(attempt)
+		if policy.Deadline > 0 && time.Since(start)+backoff >= policy.Deadline {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(attempt, backoff, err)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// IsTransientGitError checks if the failure is of the network between git-sensor and the git host, which a retry after
+// a backoff may not hit. Timeouts, of the command or of the connection, are not retried as each attempt would hold the
+// repo for the whole timeout
+func IsTransientGitError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrNetwork) {
+		return true
+	}
+	// go-git reports the failures of its transport as they are
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout() && !errors.Is(err, ErrTimeout)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetRetryPolicy(t *testing.T) {
+	configuration := &internals.Configuration{GitRetryMaxAttempts: 3, GitRetryBackoffInMs: 500, GitRetryMaxBackoffInSec: 30}
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 3, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}, GetRetryPolicy(configuration, nil))
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 3, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}, GetRetryPolicy(configuration, &sql.GitMaterial{}))
+	policy := GetRetryPolicy(configuration, &sql.GitMaterial{RetryMaxAttempts: 5, RetryMaxBackoffInSec: 2})
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 2 * time.Second}, policy)
+	// the overrides of the material are capped
+	policy = GetRetryPolicy(configuration, &sql.GitMaterial{RetryMaxAttempts: 1000, RetryMaxBackoffInSec: 86400})
+	assert.Equal(t, MAX_MATERIAL_RETRY_ATTEMPTS, policy.MaxAttempts)
+	assert.Equal(t, MAX_MATERIAL_RETRY_BACKOFF, policy.MaxBackoff)
+}
+
+func TestRetryPolicy_GetBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 60: 5 * time.Second} {
+		backoff := policy.GetBackoff(attempt)
+		assert.True(t, backoff >= want/2 && backoff <= want, "attempt %d backoff %s", attempt, backoff)
+	}
+	assert.Equal(t, time.Duration(0), (&RetryPolicy{MaxAttempts: 3}).GetBackoff(1))
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	networkErr := &GitError{Kind: ErrNetwork, Err: errors.New("exit status 128")}
+
+	t.Run("transient errors are retried till the attempts are exhausted", func(t *testing.T) {
+		attempts, retries := 0, 0
+		err := policy.Do(context.Background(), func() error {
+			attempts++
+			return networkErr
+		}, func(attempt int, backoff time.Duration, err error) {
+			retries++
+			assert.Equal(t, attempts, attempt)
+		})
+		assert.Equal(t, networkErr, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 2, retries)
+	})
+
+	t.Run("success after a transient error", func(t *testing.T) {
+		attempts := 0
+		err := policy.Do(context.Background(), func() error {
+			attempts++
+			if attempts == 1 {
+				return &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+			}
+			return nil
+		}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		for _, permanentErr := range []error{
+			&GitError{Kind: ErrAuthFailed, Err: errors.New("exit status 128")},
+			&GitError{Kind: ErrTimeout, Err: context.DeadlineExceeded},
+			ClassifyGitError("ssh: connect to host github.com port 22: Connection timed out", errors.New("exit status 128")),
+			&GitError{Kind: ErrHostCircuitOpen, Err: errors.New("host github.com")},
+			errors.New("exit status 1"),
+		} {
+			attempts := 0
+			err := policy.Do(context.Background(), func() error {
+				attempts++
+				return permanentErr
+			}, nil)
+			assert.Equal(t, permanentErr, err)
+			assert.Equal(t, 1, attempts, permanentErr.Error())
+		}
+	})
+
+	t.Run("no retry past the deadline", func(t *testing.T) {
+		attempts := 0
+		err := (&RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: time.Second, Deadline: 100 * time.Millisecond}).Do(context.Background(), func() error {
+			attempts++
+			return networkErr
+		}, nil)
+		assert.Equal(t, networkErr, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("cancelled context stops the retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := (&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}).Do(ctx, func() error {
+			attempts++
+			cancel()
+			return networkErr
+		}, nil)
+		assert.Equal(t, networkErr, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestRepositoryManager_LsRemoteRetriesNetworkErrors(t *testing.T) {
+	logger, logs := getTestLogger()
+	conf := &internals.Configuration{UseGitCli: true}
+	repositoryManager := NewRepositoryManagerImpl(logger, conf, NewGitManagerImpl(logger, conf))
+	gitCtx := BuildGitContext(context.Background()).WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	_, err := repositoryManager.LsRemote(gitCtx, "", "https://127.0.0.1:1/org/repo.git", []string{"HEAD"})
+	assert.True(t, errors.Is(err, ErrNetwork), err)
+	assert.Equal(t, 1, strings.Count(logs.String(), "retrying git operation"))
+
+	// a missing repo fails at once
+	logs.Reset()
+	_, err = repositoryManager.LsRemote(gitCtx, "", filepath.Join(t.TempDir(), "missing.git"), []string{"HEAD"})
+	assert.NotNil(t, err)
+	assert.False(t, IsTransientGitError(err))
+	assert.NotContains(t, logs.String(), "retrying git operation")
+}
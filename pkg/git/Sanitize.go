@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// minSecretLength is the length from which the secrets are replaced in text, shorter ones would mangle the text
+// around them
+const minSecretLength = 4
+
+// the userinfo of http urls in text, like user:token@ of the remote urls echoed by git in its errors
+var urlUserInfoRegex = regexp.MustCompile(`(?i)(https?://)[^/\s@'"]+@`)
+
+// the askpass env of the credentials, as it is set on the git commands
+var credentialEnvRegex = regexp.MustCompile(`\b(GIT_PASSWORD)=\S*`)
+
+// SanitizeText redacts the userinfo of the urls, the askpass password and the given secrets in text, like the output
+// of a git command
+func SanitizeText(text string, secrets ...string) string {
+	text = urlUserInfoRegex.ReplaceAllString(text, "${1}"+REDACTED_VALUE+"@")
+	text = credentialEnvRegex.ReplaceAllString(text, "${1}="+REDACTED_VALUE)
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			text = strings.ReplaceAll(text, secret, REDACTED_VALUE)
+		}
+	}
+	return text
+}
+
+// SanitizeArgs returns the arguments of a command with the credentials of SanitizeText redacted, the extra git config of
+// a material is limited to the keys of allowedGitConfigKeys which hold no credentials
+func SanitizeArgs(args []string, secrets ...string) []string {
+	sanitizedArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		sanitizedArgs = append(sanitizedArgs, SanitizeText(arg, secrets...))
+	}
+	return sanitizedArgs
+}
+
+// sanitizedError is an error whose message had credentials, errors.Is and errors.As still match the errors it wraps
+type sanitizedError struct {
+	message string
+	err     error
+}
+
+func (e *sanitizedError) Error() string {
+	return e.message
+}
+
+func (e *sanitizedError) Unwrap() error {
+	return e.err
+}
+
+// SanitizeError returns err with the credentials of SanitizeText redacted from its message and output, err itself when
+// it has none
+func SanitizeError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	if gitErr, ok := err.(*GitError); ok {
+		// kept a GitError so that the kind of the failure is still reported
+		return &GitError{Kind: gitErr.Kind, Output: SanitizeText(gitErr.Output, secrets...), Err: SanitizeError(gitErr.Err, secrets...)}
+	}
+	message := err.Error()
+	sanitizedMessage := SanitizeText(message, secrets...)
+	if sanitizedMessage == message {
+		return err
+	}
+	return &sanitizedError{message: sanitizedMessage, err: err}
+}
+
+// getCommandSecrets returns the credentials passed to a command through its env
+func getCommandSecrets(cmd *exec.Cmd) []string {
+	var secrets []string
+	for _, env := range cmd.Env {
+		if password, found := strings.CutPrefix(env, "GIT_PASSWORD="); found && len(password) > 0 {
+			secrets = append(secrets, password)
+		}
+	}
+	return secrets
+}
+
+// sanitizingWriter redacts the credentials of SanitizeText from each write, git writes its progress a line at a time
+type sanitizingWriter struct {
+	writer  io.Writer
+	secrets []string
+}
+
+func newSanitizingWriter(writer io.Writer, secrets []string) io.Writer {
+	return &sanitizingWriter{writer: writer, secrets: secrets}
+}
+
+func (w *sanitizingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.writer, SanitizeText(string(p), w.secrets...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
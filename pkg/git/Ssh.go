@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	// SSH_HOST_KEY_CHECKING_NO skips host key verification
+	SSH_HOST_KEY_CHECKING_NO = "no"
+	// SSH_HOST_KEY_CHECKING_ACCEPT_NEW adds the keys of unknown hosts to the known hosts file and rejects changed keys
+	SSH_HOST_KEY_CHECKING_ACCEPT_NEW = "accept-new"
+	// SSH_HOST_KEY_CHECKING_STRICT only connects to hosts in the known hosts file
+	SSH_HOST_KEY_CHECKING_STRICT = "strict"
+
+	SSH_KNOWN_HOSTS_FILE = SSH_PRIVATE_KEY_DIR + "known_hosts"
+)
+
+var knownHostsMutex sync.Mutex
+
+// BuildSshCommand returns the ssh command git uses for a remote, with the host key verification of the mode
+func BuildSshCommand(sshPrivateKeyPath string, hostKeyChecking string, knownHostsFile string) (string, error) {
+	switch hostKeyChecking {
+	case "", SSH_HOST_KEY_CHECKING_NO:
+		return fmt.Sprintf("ssh -i %s -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no", sshPrivateKeyPath), nil
+	case SSH_HOST_KEY_CHECKING_ACCEPT_NEW:
+		return fmt.Sprintf("ssh -i %s -o UserKnownHostsFile=%s -o StrictHostKeyChecking=accept-new", sshPrivateKeyPath, knownHostsFile), nil
+	case SSH_HOST_KEY_CHECKING_STRICT:
+		return fmt.Sprintf("ssh -i %s -o UserKnownHostsFile=%s -o StrictHostKeyChecking=yes", sshPrivateKeyPath, knownHostsFile), nil
+	default:
+		return "", fmt.Errorf("unsupported ssh host key checking mode %s", hostKeyChecking)
+	}
+}
+
+// EnsureKnownHosts adds the configured known hosts entries missing from the known hosts file. Entries added by
+// ssh in accept-new mode are kept
+func EnsureKnownHosts(knownHostsFile string, knownHosts string) error {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+	err := os.MkdirAll(path.Dir(knownHostsFile), 0700)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(knownHostsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, line := range strings.Split(knownHosts, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 && !strings.HasPrefix(line, "#") && !present[line] {
+			missing = append(missing, line)
+			present[line] = true
+		}
+	}
+	if len(missing) == 0 && existing != nil {
+		return nil
+	}
+	file, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		missing = append([]string{""}, missing...)
+	}
+	for _, line := range missing {
+		if _, err = file.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
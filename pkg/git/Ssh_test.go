@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildSshCommand(t *testing.T) {
+	command, err := BuildSshCommand("/keys/1", "", "/keys/known_hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, "ssh -i /keys/1 -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no", command)
+	command, err = BuildSshCommand("/keys/1", SSH_HOST_KEY_CHECKING_ACCEPT_NEW, "/keys/known_hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, "ssh -i /keys/1 -o UserKnownHostsFile=/keys/known_hosts -o StrictHostKeyChecking=accept-new", command)
+	command, err = BuildSshCommand("/keys/1", SSH_HOST_KEY_CHECKING_STRICT, "/keys/known_hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, "ssh -i /keys/1 -o UserKnownHostsFile=/keys/known_hosts -o StrictHostKeyChecking=yes", command)
+	_, err = BuildSshCommand("/keys/1", "ask", "/keys/known_hosts")
+	assert.NotNil(t, err)
+}
+
+func TestEnsureKnownHosts(t *testing.T) {
+	knownHostsFile := filepath.Join(t.TempDir(), "ssh", "known_hosts")
+	assert.Nil(t, EnsureKnownHosts(knownHostsFile, "github.com ssh-ed25519 AAAA\n# comment\n"))
+	info, err := os.Stat(knownHostsFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// entries learnt by ssh are kept and configured entries are not duplicated
+	file, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.Nil(t, err)
+	_, err = file.WriteString("gitlab.com ssh-ed25519 BBBB")
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	assert.Nil(t, EnsureKnownHosts(knownHostsFile, "github.com ssh-ed25519 AAAA\nbitbucket.org ssh-rsa CCCC"))
+	content, err := os.ReadFile(knownHostsFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "github.com ssh-ed25519 AAAA\ngitlab.com ssh-ed25519 BBBB\nbitbucket.org ssh-rsa CCCC\n", string(content))
+}
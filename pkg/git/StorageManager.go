@@ -0,0 +1,448 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	RECLAIM_REASON_GC       = "gc"
+	RECLAIM_REASON_EVICTION = "eviction"
+	RECLAIM_REASON_ORPHAN   = "orphan"
+)
+
+type StorageManager interface {
+	// Reconcile runs gc on the checkouts, removes the checkouts of deleted materials and evicts the least recently
+	// polled checkouts till the disk usage is under the quota. evicted checkouts are cloned again on the next fetch
+	Reconcile()
+	// MeasureUsage updates the size metrics of the checkouts and the worktree stores without reclaiming any disk
+	MeasureUsage()
+	StopCron()
+}
+
+type StorageManagerImpl struct {
+	logger        *zap.SugaredLogger
+	materialRepo  sql.MaterialRepository
+	gitManager    GitManager
+	locker        *internals.RepositoryLocker
+	configuration *internals.Configuration
+	cron          *cron.Cron
+	baseDir       string
+	storeDir      string
+	jobResultsDir string
+}
+
+func NewStorageManagerImpl(logger *zap.SugaredLogger, materialRepo sql.MaterialRepository, gitManager GitManager,
+	locker *internals.RepositoryLocker, configuration *internals.Configuration) (*StorageManagerImpl, error) {
+	cronLogger := &CronLoggerImpl{logger: logger}
+	impl := &StorageManagerImpl{
+		logger:        logger,
+		materialRepo:  materialRepo,
+		gitManager:    gitManager,
+		locker:        locker,
+		configuration: configuration,
+		cron: cron.New(
+			cron.WithChain(
+				cron.SkipIfStillRunning(cronLogger),
+				cron.Recover(cronLogger))),
+		baseDir:       GIT_BASE_DIR,
+		storeDir:      WORKTREE_STORE_DIR,
+		jobResultsDir: JOB_RESULTS_DIR,
+	}
+	if configuration.StorageReconcileIntervalInMin > 0 {
+		_, err := impl.cron.AddFunc(fmt.Sprintf("@every %dm", configuration.StorageReconcileIntervalInMin), impl.Reconcile)
+		if err != nil {
+			logger.Errorw("error in starting storage reconcile cron", "err", err)
+			return nil, err
+		}
+	}
+	// the sizes are measured on their own schedule so that the metrics are there when the reconcile is disabled
+	if configuration.CheckoutSizeIntervalInMin > 0 {
+		_, err := impl.cron.AddFunc(fmt.Sprintf("@every %dm", configuration.CheckoutSizeIntervalInMin), impl.MeasureUsage)
+		if err != nil {
+			logger.Errorw("error in starting checkout size cron", "err", err)
+			return nil, err
+		}
+	}
+	if len(impl.cron.Entries()) > 0 {
+		impl.cron.Start()
+	}
+	return impl, nil
+}
+
+func (impl *StorageManagerImpl) StopCron() {
+	<-impl.cron.Stop().Done()
+}
+
+type checkoutUsage struct {
+	material *sql.GitMaterial
+	dir      string
+	size     int64
+}
+
+// storeUsage is a worktree store with the checkouts which are its worktrees, they are evicted together as the
+// checkouts have no objects of their own
+type storeUsage struct {
+	dir           string
+	size          int64
+	checkouts     []*checkoutUsage
+	lastFetchTime time.Time
+}
+
+func (impl *StorageManagerImpl) Reconcile() {
+	impl.logger.Infow("starting storage reconcile")
+	materials, err := impl.materialRepo.FindAll()
+	if err != nil {
+		impl.logger.Errorw("error in fetching materials for storage reconcile", "err", err)
+		return
+	}
+	materialsById := make(map[int]*sql.GitMaterial, len(materials))
+	for _, material := range materials {
+		materialsById[material.Id] = material
+	}
+	entries, err := os.ReadDir(impl.baseDir)
+	if err != nil {
+		impl.logger.Errorw("error in reading git base dir", "dir", impl.baseDir, "err", err)
+		return
+	}
+	var checkouts []*checkoutUsage
+	var totalSize int64
+	storeCheckouts := make(map[string][]*checkoutUsage)
+	for _, entry := range entries {
+		// checkouts are kept in a directory named after the material id, skip the rest like ssh keys and the stores
+		materialId, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		checkoutDir := path.Join(impl.baseDir, entry.Name())
+		material, ok := materialsById[materialId]
+		if !ok || material.Deleted {
+			impl.removeCheckout(materialId, checkoutDir, RECLAIM_REASON_ORPHAN)
+			continue
+		}
+		checkout := &checkoutUsage{material: material, dir: checkoutDir}
+		if store := GetWorktreeStore(material.CheckoutLocation); len(store) > 0 {
+			// gc of a worktree is the gc of its store, which is run once for all of its worktrees below
+			checkout.size, _ = GetDirSize(checkoutDir)
+			storeCheckouts[store] = append(storeCheckouts[store], checkout)
+		} else {
+			checkout.size = impl.garbageCollect(checkout)
+			checkouts = append(checkouts, checkout)
+		}
+		middleware.GitCheckoutSize.WithLabelValues(entry.Name()).Set(float64(checkout.size))
+		totalSize += checkout.size
+	}
+	stores := impl.reconcileStores(storeCheckouts)
+	for _, store := range stores {
+		totalSize += store.size
+	}
+	// job results are removed by the job manager after JOB_RETENTION_IN_MIN, they count in the usage but are not evicted
+	if jobResultsSize, err := GetDirSize(impl.jobResultsDir); err == nil {
+		totalSize += jobResultsSize
+	} else if !os.IsNotExist(err) {
+		impl.logger.Errorw("error in getting job results size", "dir", impl.jobResultsDir, "err", err)
+	}
+	quota := impl.configuration.DiskQuotaInMB * 1024 * 1024
+	if quota > 0 && totalSize > quota {
+		impl.logger.Infow("disk quota exceeded, evicting least recently polled checkouts", "usage", totalSize, "quota", quota)
+		totalSize = impl.evict(checkouts, stores, totalSize, quota)
+	}
+	middleware.GitStorageUsage.WithLabelValues().Set(float64(totalSize))
+	impl.logger.Infow("storage reconcile done", "usage", totalSize, "checkouts", len(checkouts), "worktreeStores", len(stores))
+}
+
+func (impl *StorageManagerImpl) MeasureUsage() {
+	entries, err := os.ReadDir(impl.baseDir)
+	if err != nil {
+		impl.logger.Errorw("error in reading git base dir", "dir", impl.baseDir, "err", err)
+		return
+	}
+	var totalSize int64
+	checkouts := 0
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil || !entry.IsDir() {
+			continue
+		}
+		size, err := GetDirSize(path.Join(impl.baseDir, entry.Name()))
+		if err != nil {
+			// removed by a reconcile or an update of the material meanwhile
+			continue
+		}
+		middleware.GitCheckoutSize.WithLabelValues(entry.Name()).Set(float64(size))
+		totalSize += size
+		checkouts++
+	}
+	storeDirs, err := listWorktreeStores(impl.storeDir)
+	if err != nil {
+		impl.logger.Errorw("error in listing worktree stores", "dir", impl.storeDir, "err", err)
+	}
+	for _, storeDir := range storeDirs {
+		size, _ := GetDirSize(storeDir)
+		totalSize += size
+	}
+	if jobResultsSize, err := GetDirSize(impl.jobResultsDir); err == nil {
+		totalSize += jobResultsSize
+	}
+	middleware.GitStorageUsage.WithLabelValues().Set(float64(totalSize))
+	impl.logger.Debugw("storage usage measured", "usage", totalSize, "checkouts", checkouts, "worktreeStores", len(storeDirs))
+}
+
+// reconcileStores runs gc on the worktree stores which have worktrees and removes the ones which are left without any
+func (impl *StorageManagerImpl) reconcileStores(storeCheckouts map[string][]*checkoutUsage) []*storeUsage {
+	storeDirs, err := listWorktreeStores(impl.storeDir)
+	if err != nil {
+		impl.logger.Errorw("error in listing worktree stores", "dir", impl.storeDir, "err", err)
+		return nil
+	}
+	var stores []*storeUsage
+	for _, storeDir := range storeDirs {
+		checkouts := storeCheckouts[storeDir]
+		if len(checkouts) == 0 {
+			impl.removeWorktreeStore(storeDir, RECLAIM_REASON_ORPHAN)
+			continue
+		}
+		store := &storeUsage{dir: storeDir, checkouts: checkouts}
+		for _, checkout := range checkouts {
+			if checkout.material.LastFetchTime.After(store.lastFetchTime) {
+				store.lastFetchTime = checkout.material.LastFetchTime
+			}
+		}
+		store.size = impl.garbageCollectStore(storeDir)
+		stores = append(stores, store)
+	}
+	return stores
+}
+
+// evict removes the least recently polled checkouts and worktree stores till the usage is under the quota, a store
+// is as recently polled as the most recently polled of its worktrees. It returns the usage after the eviction
+func (impl *StorageManagerImpl) evict(checkouts []*checkoutUsage, stores []*storeUsage, totalSize int64, quota int64) int64 {
+	type evictionCandidate struct {
+		lastFetchTime time.Time
+		evict         func() int64
+	}
+	candidates := make([]*evictionCandidate, 0, len(checkouts)+len(stores))
+	for _, checkout := range checkouts {
+		checkout := checkout
+		candidates = append(candidates, &evictionCandidate{lastFetchTime: checkout.material.LastFetchTime, evict: func() int64 {
+			if impl.removeCheckout(checkout.material.Id, checkout.dir, RECLAIM_REASON_EVICTION) {
+				return checkout.size
+			}
+			return 0
+		}})
+	}
+	for _, store := range stores {
+		store := store
+		candidates = append(candidates, &evictionCandidate{lastFetchTime: store.lastFetchTime, evict: func() int64 {
+			var reclaimed int64
+			for _, checkout := range store.checkouts {
+				if impl.removeCheckout(checkout.material.Id, checkout.dir, RECLAIM_REASON_EVICTION) {
+					reclaimed += checkout.size
+				}
+			}
+			if impl.removeWorktreeStore(store.dir, RECLAIM_REASON_EVICTION) {
+				reclaimed += store.size
+			}
+			return reclaimed
+		}})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastFetchTime.Before(candidates[j].lastFetchTime)
+	})
+	for _, candidate := range candidates {
+		if totalSize <= quota {
+			break
+		}
+		totalSize -= candidate.evict()
+	}
+	return totalSize
+}
+
+// garbageCollect runs gc on the repos of the checkout and returns its size after it
+func (impl *StorageManagerImpl) garbageCollect(checkout *checkoutUsage) int64 {
+	repoLock := impl.locker.LeaseLocker(checkout.material.Id)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(checkout.material.Id)
+	}()
+	sizeBefore, err := GetDirSize(checkout.dir)
+	if err != nil {
+		impl.logger.Errorw("error in getting checkout size", "dir", checkout.dir, "err", err)
+		return sizeBefore
+	}
+	if !checkout.material.CheckoutStatus || len(checkout.material.CheckoutLocation) == 0 {
+		return sizeBefore
+	}
+	if !impl.gitManager.GetGitBinary().IsAvailable() {
+		// gc needs the git binary, without it the checkouts are only measured and evicted
+		return sizeBefore
+	}
+	if _, err = impl.gitManager.RemoveLockFilesOfKilledCommands(checkout.material.CheckoutLocation); err != nil {
+		return sizeBefore
+	}
+	_, errMsg, err := impl.gitManager.GarbageCollect(BuildGitContext(context.Background()), checkout.material.CheckoutLocation)
+	if err != nil {
+		impl.logger.Errorw("error in gc of checkout", "materialId", checkout.material.Id, "errMsg", errMsg, "err", err)
+		return sizeBefore
+	}
+	sizeAfter, err := GetDirSize(checkout.dir)
+	if err != nil {
+		return sizeBefore
+	}
+	if sizeBefore > sizeAfter {
+		middleware.GitStorageReclaimedBytes.WithLabelValues(RECLAIM_REASON_GC).Add(float64(sizeBefore - sizeAfter))
+	}
+	return sizeAfter
+}
+
+func (impl *StorageManagerImpl) removeCheckout(materialId int, checkoutDir string, reason string) bool {
+	repoLock := impl.locker.LeaseLocker(materialId)
+	repoLock.Mutex.Lock()
+	defer func() {
+		repoLock.Mutex.Unlock()
+		impl.locker.ReturnLocker(materialId)
+	}()
+	size, _ := GetDirSize(checkoutDir)
+	impl.logger.Infow("removing checkout", "materialId", materialId, "dir", checkoutDir, "size", size, "reason", reason)
+	if err := os.RemoveAll(checkoutDir); err != nil {
+		impl.logger.Errorw("error in removing checkout", "materialId", materialId, "dir", checkoutDir, "err", err)
+		return false
+	}
+	middleware.GitStorageReclaimedBytes.WithLabelValues(reason).Add(float64(size))
+	middleware.GitCheckoutSize.DeleteLabelValues(strconv.Itoa(materialId))
+	return true
+}
+
+// garbageCollectStore prunes the worktrees of the store whose checkouts are gone and runs gc on it under the lock of
+// the store, it returns the size of the store after it
+func (impl *StorageManagerImpl) garbageCollectStore(storeDir string) int64 {
+	unlock := impl.gitManager.GetWorktreeStores().Lock(storeDir)
+	defer unlock()
+	sizeBefore, err := GetDirSize(storeDir)
+	if err != nil {
+		impl.logger.Errorw("error in getting worktree store size", "dir", storeDir, "err", err)
+		return sizeBefore
+	}
+	if _, err = impl.gitManager.RemoveLockFilesOfKilledCommands(storeDir); err != nil {
+		return sizeBefore
+	}
+	gitCtx := BuildGitContext(context.Background())
+	if err = impl.gitManager.PruneWorktrees(gitCtx, storeDir); err != nil {
+		return sizeBefore
+	}
+	_, errMsg, err := impl.gitManager.GarbageCollect(gitCtx, storeDir)
+	if err != nil {
+		impl.logger.Errorw("error in gc of worktree store", "dir", storeDir, "errMsg", errMsg, "err", err)
+		return sizeBefore
+	}
+	sizeAfter, err := GetDirSize(storeDir)
+	if err != nil {
+		return sizeBefore
+	}
+	if sizeBefore > sizeAfter {
+		middleware.GitStorageReclaimedBytes.WithLabelValues(RECLAIM_REASON_GC).Add(float64(sizeBefore - sizeAfter))
+	}
+	return sizeAfter
+}
+
+// removeWorktreeStore removes the store once it has no worktrees left, a worktree added to it since its checkouts
+// were removed keeps it
+func (impl *StorageManagerImpl) removeWorktreeStore(storeDir string, reason string) bool {
+	unlock := impl.gitManager.GetWorktreeStores().Lock(storeDir)
+	defer unlock()
+	if err := impl.gitManager.PruneWorktrees(BuildGitContext(context.Background()), storeDir); err != nil {
+		return false
+	}
+	if worktrees, _ := os.ReadDir(path.Join(GetGitDir(storeDir), "worktrees")); len(worktrees) > 0 {
+		impl.logger.Infow("keeping worktree store with worktrees", "dir", storeDir, "worktrees", len(worktrees))
+		return false
+	}
+	size, _ := GetDirSize(storeDir)
+	impl.logger.Infow("removing worktree store", "dir", storeDir, "size", size, "reason", reason)
+	if err := os.RemoveAll(storeDir); err != nil {
+		impl.logger.Errorw("error in removing worktree store", "dir", storeDir, "err", err)
+		return false
+	}
+	middleware.GitStorageReclaimedBytes.WithLabelValues(reason).Add(float64(size))
+	return true
+}
+
+// listWorktreeStores returns the repos under dir, the stores are nested under the credential scope, the git provider
+// and the path of the url
+func listWorktreeStores(dir string) ([]string, error) {
+	var stores []string
+	err := filepath.WalkDir(dir, func(entryPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && entryPath == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if isGitRepoDir(entryPath) {
+			stores = append(stores, filepath.Clean(entryPath))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return stores, err
+}
+
+// isGitRepoDir checks if dir is a repo with a .git dir or a bare repo
+func isGitRepoDir(dir string) bool {
+	if info, err := os.Stat(path.Join(dir, ".git")); err == nil && info.IsDir() {
+		return true
+	}
+	_, headErr := os.Stat(path.Join(dir, "HEAD"))
+	_, objectsErr := os.Stat(path.Join(dir, "objects"))
+	return headErr == nil && objectsErr == nil
+}
+
+// GetDirSize returns the total size of the regular files under dir
+func GetDirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reconcileMaterialRepository lists the materials of the storage reconcile, the other methods are not used by it
+type reconcileMaterialRepository struct {
+	sql.MaterialRepository
+	materials []*sql.GitMaterial
+}
+
+func (impl *reconcileMaterialRepository) FindAll() ([]*sql.GitMaterial, error) {
+	return impl.materials, nil
+}
+
+func TestStorageManagerImpl_Reconcile(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	now := time.Now()
+	tests := []struct {
+		name        string
+		diskQuota   int64
+		expectedIds []string
+	}{
+		{name: "under the quota", diskQuota: 2, expectedIds: []string{"1", "2"}},
+		{name: "least recently polled evicted above the quota", diskQuota: 1, expectedIds: []string{"2"}},
+		{name: "no quota", diskQuota: 0, expectedIds: []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			// 3 has no material and 4 is of a deleted material, both are removed whatever the usage
+			for _, checkout := range []string{"1", "2", "3", "4"} {
+				assert.Nil(t, os.MkdirAll(filepath.Join(baseDir, checkout), 0755))
+				assert.Nil(t, os.WriteFile(filepath.Join(baseDir, checkout, "pack"), make([]byte, 700*1024), 0644))
+			}
+			// ssh keys and the other files of the base dir are not checkouts
+			assert.Nil(t, os.WriteFile(filepath.Join(baseDir, "ssh-key"), []byte("key"), 0600))
+			materialRepository := &reconcileMaterialRepository{materials: []*sql.GitMaterial{
+				{Id: 1, LastFetchTime: now.Add(-time.Hour)},
+				{Id: 2, LastFetchTime: now},
+				{Id: 4, Deleted: true},
+			}}
+			storageManager := &StorageManagerImpl{logger: logger, materialRepo: materialRepository, locker: internals.NewRepositoryLocker(logger),
+				configuration: &internals.Configuration{DiskQuotaInMB: tt.diskQuota}, baseDir: baseDir}
+
+			storageManager.Reconcile()
+			entries, err := os.ReadDir(baseDir)
+			assert.Nil(t, err)
+			var checkouts []string
+			for _, entry := range entries {
+				if entry.IsDir() {
+					checkouts = append(checkouts, entry.Name())
+				}
+			}
+			assert.Equal(t, tt.expectedIds, checkouts)
+			assert.FileExists(t, filepath.Join(baseDir, "ssh-key"))
+		})
+	}
+}
+
+func TestListWorktreeStores(t *testing.T) {
+	storeDir := filepath.Join(t.TempDir(), "worktree-stores")
+	stores, err := listWorktreeStores(storeDir)
+	assert.Nil(t, err)
+	assert.Empty(t, stores)
+
+	bareStore := filepath.Join(storeDir, "1", "github.com", "devtron-labs", "git-sensor.git")
+	scopedStore := filepath.Join(storeDir, "material-7", "1", "github.com", "devtron-labs", "git-sensor.git")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(bareStore), 0755))
+	assert.Nil(t, os.MkdirAll(scopedStore, 0755))
+	runTestGitCmd(t, filepath.Dir(bareStore), "init", "--bare", bareStore)
+	runTestGitCmd(t, scopedStore, "init")
+	// a dir without a repo is not a store
+	assert.Nil(t, os.MkdirAll(filepath.Join(storeDir, "2", "github.com"), 0755))
+
+	stores, err = listWorktreeStores(storeDir)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{bareStore, scopedStore}, stores)
+}
+
+func TestStorageManagerImpl_MeasureUsage(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	baseDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(baseDir, "9001", ".git"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(baseDir, "9001", ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+	// ssh keys and the other files of the base dir are not checkouts
+	assert.Nil(t, os.WriteFile(filepath.Join(baseDir, "ssh-key"), []byte("key"), 0600))
+	storageManager := &StorageManagerImpl{logger: logger, baseDir: baseDir, storeDir: filepath.Join(baseDir, "worktree-stores"),
+		jobResultsDir: filepath.Join(baseDir, "job-results")}
+
+	storageManager.MeasureUsage()
+	metric := &dto.Metric{}
+	assert.Nil(t, middleware.GitCheckoutSize.WithLabelValues("9001").Write(metric))
+	assert.Equal(t, float64(len("ref: refs/heads/main\n")), metric.GetGauge().GetValue())
+	assert.Nil(t, middleware.GitStorageUsage.WithLabelValues().Write(metric))
+	assert.Equal(t, float64(len("ref: refs/heads/main\n")), metric.GetGauge().GetValue())
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	SUBMODULE_FILE_MODE = "160000"
+	ZERO_HASH           = "0000000000000000000000000000000000000000"
+)
+
+// submoduleUrlSchemes are the schemes of the submodule urls which are fetched, the scp like user@host:path syntax is ssh
+var submoduleUrlSchemes = []string{"https", "ssh"}
+
+// SubmoduleChange is a change of the commit a submodule points to, OldHash is empty for an added
+// submodule and NewHash is empty for a removed one
+type SubmoduleChange struct {
+	Path    string
+	Url     string           `json:",omitempty"`
+	OldHash string           `json:",omitempty"`
+	NewHash string           `json:",omitempty"`
+	Commits []*GitCommitBase `json:",omitempty"` // commits of the submodule in OldHash..NewHash
+}
+
+func (impl *GitManagerBaseImpl) GetSubmoduleChanges(gitCtx GitContext, rootDir string, commitHash string) ([]*SubmoduleChange, error) {
+	cmdArgs := []string{"-C", rootDir, "diff-tree", "-r", "-z", "--no-commit-id", "--root", "-m", "--first-parent", commitHash}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in getting tree diff", "rootDir", rootDir, "commitHash", commitHash, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	changes := parseSubmoduleChanges(output)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	submoduleUrls := impl.getSubmoduleUrls(gitCtx, rootDir, commitHash)
+	for _, change := range changes {
+		change.Url = submoduleUrls[change.Path]
+		if !gitCtx.FetchSubmoduleCommits || len(change.Url) == 0 || len(change.OldHash) == 0 || len(change.NewHash) == 0 {
+			continue
+		}
+		if err = validateSubmoduleUrl(change.Url); err != nil {
+			impl.logger.Warnw("skipping commits of submodule", "rootDir", rootDir, "submodule", change.Path, "err", err)
+			continue
+		}
+		change.Commits, err = impl.getSubmoduleCommits(gitCtx, rootDir, change)
+		if err != nil {
+			// the pointer change is still reported without the commits of the submodule
+			impl.logger.Errorw("error in getting submodule commits", "rootDir", rootDir, "submodule", change.Path, "err", err)
+		}
+	}
+	return changes, nil
+}
+
+// parseSubmoduleChanges picks the gitlink entries from the raw output of diff-tree -z, an entry looks like
+// :160000 160000 <old hash> <new hash> M\0<path>\0 and the path is not quoted
+func parseSubmoduleChanges(output string) []*SubmoduleChange {
+	var changes []*SubmoduleChange
+	entries := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(entries); i++ {
+		meta, found := strings.CutPrefix(entries[i], ":")
+		if !found {
+			continue
+		}
+		i++
+		filePath := entries[i]
+		fields := strings.Fields(meta)
+		if len(fields) < 5 || (fields[0] != SUBMODULE_FILE_MODE && fields[1] != SUBMODULE_FILE_MODE) {
+			continue
+		}
+		change := &SubmoduleChange{Path: filePath}
+		if fields[0] == SUBMODULE_FILE_MODE && fields[2] != ZERO_HASH {
+			change.OldHash = fields[2]
+		}
+		if fields[1] == SUBMODULE_FILE_MODE && fields[3] != ZERO_HASH {
+			change.NewHash = fields[3]
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// getSubmoduleUrls reads the submodule urls from .gitmodules as of the given commit, keyed by submodule path
+func (impl *GitManagerBaseImpl) getSubmoduleUrls(gitCtx GitContext, rootDir string, commitHash string) map[string]string {
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "config", "--blob", commitHash+":.gitmodules", "--get-regexp", `^submodule\..*\.(path|url)$`)
+	defer cancel()
+	output, _, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Debugw("no submodule urls found", "rootDir", rootDir, "commitHash", commitHash, "err", err)
+		return nil
+	}
+	originUrl := impl.getOriginUrl(gitCtx, rootDir)
+	paths, urls := make(map[string]string), make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		if name, ok := strings.CutSuffix(key, ".path"); ok {
+			paths[name] = value
+		} else if name, ok := strings.CutSuffix(key, ".url"); ok {
+			urls[name] = resolveSubmoduleUrl(originUrl, value)
+		}
+	}
+	submoduleUrls := make(map[string]string, len(paths))
+	for name, submodulePath := range paths {
+		submoduleUrls[submodulePath] = urls[name]
+	}
+	return submoduleUrls
+}
+
+func (impl *GitManagerBaseImpl) getOriginUrl(gitCtx GitContext, rootDir string) string {
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", "-C", rootDir, "config", "--get", "remote.origin.url")
+	defer cancel()
+	output, _, _ := impl.runCommand(gitCtx, cmd)
+	return output
+}
+
+// resolveSubmoduleUrl resolves urls like ../lib.git against the url of the super project, same as git submodule does
+func resolveSubmoduleUrl(originUrl string, submoduleUrl string) string {
+	if !strings.HasPrefix(submoduleUrl, "./") && !strings.HasPrefix(submoduleUrl, "../") {
+		return submoduleUrl
+	}
+	if parsedUrl, err := url.Parse(originUrl); err == nil && len(parsedUrl.Scheme) > 0 {
+		parsedUrl.Path = path.Join(parsedUrl.Path, submoduleUrl)
+		return parsedUrl.String()
+	}
+	// scp like syntax, git@host:org/repo.git
+	if host, repoPath, found := strings.Cut(originUrl, ":"); found {
+		return host + ":" + path.Join(repoPath, submoduleUrl)
+	}
+	return path.Join(originUrl, submoduleUrl)
+}
+
+// getSubmoduleCommits fetches the submodule in a bare repo under the modules dir of the super project's git dir
+// and returns the commits in OldHash..NewHash
+func (impl *GitManagerBaseImpl) getSubmoduleCommits(gitCtx GitContext, rootDir string, change *SubmoduleChange) ([]*GitCommitBase, error) {
+	modulesDir := path.Join(GetGitDir(rootDir), "modules")
+	submoduleDir := path.Join(modulesDir, change.Path)
+	if !strings.HasPrefix(submoduleDir, modulesDir+"/") {
+		return nil, fmt.Errorf("submodule path %q is outside of the modules dir", SanitizeText(change.Path))
+	}
+	if _, err := os.Stat(submoduleDir); os.IsNotExist(err) {
+		err = os.MkdirAll(submoduleDir, 0755)
+		if err != nil {
+			return nil, err
+		}
+		_, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", submoduleDir, "init", "--bare")
+		if err != nil {
+			impl.logger.Errorw("error in initialising submodule repo", "submoduleDir", submoduleDir, "errMsg", errMsg, "err", err)
+			return nil, err
+		}
+	}
+	// the url comes from the .gitmodules of the repo, the credentials of the material are only sent to its own host
+	sshCommand := "ssh"
+	originUrl := impl.getOriginUrl(gitCtx, rootDir)
+	if getRemoteHost(change.Url) == getRemoteHost(originUrl) {
+		if output, _, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "config", "--get", "core.sshCommand"); err == nil && len(output) > 0 {
+			sshCommand = output
+		}
+	} else {
+		gitCtx = gitCtx.WithCredentials("", "")
+	}
+	_, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", submoduleDir, "-c", "core.sshCommand="+sshCommand, "fetch", "--no-tags", "--force", "--", change.Url, "+refs/heads/*:refs/heads/*")
+	if err != nil {
+		impl.logger.Errorw("error in fetching submodule", "submoduleDir", submoduleDir, "url", change.Url, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", submoduleDir, "log", change.OldHash+".."+change.NewHash, "-n", strconv.Itoa(impl.conf.GitHistoryCount), "--date=iso-strict", GITFORMAT)
+	if err != nil {
+		impl.logger.Errorw("error in getting submodule log", "submoduleDir", submoduleDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	if len(output) == 0 {
+		return nil, nil
+	}
+	formattedCommits, err := parseFormattedLogOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]*GitCommitBase, 0, len(formattedCommits))
+	for _, formattedCommit := range formattedCommits {
+		commit := toGitCommitBase(formattedCommit)
+		commits = append(commits, &commit)
+	}
+	return commits, nil
+}
+
+// validateSubmoduleUrl checks that the url of a submodule can be fetched, it must not be taken as an option by git and
+// must be of one of the submoduleUrlSchemes
+func validateSubmoduleUrl(submoduleUrl string) error {
+	if strings.HasPrefix(submoduleUrl, "-") {
+		return fmt.Errorf("submodule url %q is not allowed", SanitizeText(submoduleUrl))
+	}
+	scheme := ""
+	if parsedUrl, err := url.Parse(submoduleUrl); err == nil && len(parsedUrl.Scheme) > 1 {
+		scheme = strings.ToLower(parsedUrl.Scheme)
+	} else if host, _, found := strings.Cut(submoduleUrl, ":"); found && !strings.Contains(host, "/") {
+		scheme = "ssh"
+	}
+	for _, allowedScheme := range submoduleUrlSchemes {
+		if scheme == allowedScheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("scheme of submodule url %q is not allowed", SanitizeText(submoduleUrl))
+}
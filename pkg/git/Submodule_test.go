@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSubmoduleUrl(t *testing.T) {
+	assert.Equal(t, "https://github.com/devtron-labs/lib.git", resolveSubmoduleUrl("https://github.com/devtron-labs/app.git", "../lib.git"))
+	assert.Equal(t, "git@github.com:devtron-labs/lib.git", resolveSubmoduleUrl("git@github.com:devtron-labs/app.git", "../lib.git"))
+	assert.Equal(t, "https://gitlab.com/lib.git", resolveSubmoduleUrl("https://github.com/devtron-labs/app.git", "https://gitlab.com/lib.git"))
+}
+
+func TestValidateSubmoduleUrl(t *testing.T) {
+	assert.Nil(t, validateSubmoduleUrl("https://github.com/devtron-labs/lib.git"))
+	assert.Nil(t, validateSubmoduleUrl("ssh://git@github.com/devtron-labs/lib.git"))
+	assert.Nil(t, validateSubmoduleUrl("git@github.com:devtron-labs/lib.git"))
+	assert.NotNil(t, validateSubmoduleUrl("--upload-pack=touch /tmp/x"))
+	assert.NotNil(t, validateSubmoduleUrl("-uhttps://github.com/devtron-labs/lib.git"))
+	assert.NotNil(t, validateSubmoduleUrl("http://github.com/devtron-labs/lib.git"))
+	assert.NotNil(t, validateSubmoduleUrl("ext::sh -c touch% /tmp/x"))
+	assert.NotNil(t, validateSubmoduleUrl("file:///tmp/lib.git"))
+	assert.NotNil(t, validateSubmoduleUrl("/tmp/lib.git"))
+	assert.NotNil(t, validateSubmoduleUrl("../lib.git"))
+}
+
+func TestParseSubmoduleChanges(t *testing.T) {
+	oldHash, newHash := "9f2c6b1e4a5d8c7b3e2f1a0d9c8b7a6f5e4d3c2b", "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b"
+	tests := []struct {
+		name     string
+		output   string
+		expected []*SubmoduleChange
+	}{
+		{name: "no changes", output: ""},
+		{name: "file change", output: ":100644 100644 " + oldHash + " " + newHash + " M\x00README.md\x00"},
+		{name: "pointer change", output: ":160000 160000 " + oldHash + " " + newHash + " M\x00lib\x00",
+			expected: []*SubmoduleChange{{Path: "lib", OldHash: oldHash, NewHash: newHash}}},
+		{name: "added and removed", output: ":000000 160000 " + ZERO_HASH + " " + newHash + " A\x00lib\x00:160000 000000 " + oldHash + " " + ZERO_HASH + " D\x00vendor/old\x00",
+			expected: []*SubmoduleChange{{Path: "lib", NewHash: newHash}, {Path: "vendor/old", OldHash: oldHash}}},
+		{name: "path with a tab and a newline", output: ":160000 160000 " + oldHash + " " + newHash + " M\x00lib\tv1\nx\x00",
+			expected: []*SubmoduleChange{{Path: "lib\tv1\nx", OldHash: oldHash, NewHash: newHash}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseSubmoduleChanges(tt.output))
+		})
+	}
+}
+
+func TestGetSubmoduleCommits_PathOutsideModulesDir(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	impl := NewGitManagerBaseImpl(logger, &internals.Configuration{})
+	rootDir := t.TempDir()
+	for _, submodulePath := range []string{"..", "../../hooks", "lib/../../config"} {
+		_, err = impl.getSubmoduleCommits(BuildGitContext(context.Background()), rootDir, &SubmoduleChange{Path: submodulePath, Url: "https://github.com/devtron-labs/lib.git"})
+		assert.NotNil(t, err, submodulePath)
+	}
+	_, err = os.Stat(filepath.Join(rootDir, ".git"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRepositoryManager_SubmoduleChanges(t *testing.T) {
+	// the test remotes are local, file urls are fetched only in this test
+	defaultSubmoduleUrlSchemes := submoduleUrlSchemes
+	submoduleUrlSchemes = append([]string{"file"}, defaultSubmoduleUrlSchemes...)
+	defer func() {
+		submoduleUrlSchemes = defaultSubmoduleUrlSchemes
+	}()
+	libRemoteDir, libWorkDir := setupTestRemote(t)
+	libRemoteDir = "file://" + libRemoteDir
+	appRemoteDir, appWorkDir := setupTestRemote(t)
+	runTestGitCmd(t, appWorkDir, "-c", "protocol.file.allow=always", "submodule", "add", libRemoteDir, "lib")
+	runTestGitCmd(t, appWorkDir, "commit", "-m", "add lib")
+	runTestGitCmd(t, libWorkDir, "commit", "--allow-empty", "-m", "lib fix")
+	runTestGitCmd(t, libWorkDir, "push", "origin", "main")
+	oldHash := runTestGitCmd(t, appWorkDir, "-C", "lib", "rev-parse", "HEAD")
+	runTestGitCmd(t, appWorkDir, "-C", "lib", "pull", "origin", "main")
+	newHash := runTestGitCmd(t, appWorkDir, "-C", "lib", "rev-parse", "HEAD")
+	runTestGitCmd(t, appWorkDir, "add", "lib")
+	runTestGitCmd(t, appWorkDir, "commit", "-m", "bump lib")
+	runTestGitCmd(t, appWorkDir, "push", "origin", "main")
+
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background()).WithSubmoduleResolution(true, true)
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, appRemoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	commits, err := repositoryManager.ChangesSinceByRepository(gitCtx, nil, "main", "", "", 2, checkoutPath, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(commits))
+	assert.Equal(t, []*SubmoduleChange{{Path: "lib", Url: libRemoteDir, OldHash: oldHash, NewHash: newHash, Commits: commits[0].Submodules[0].Commits}}, commits[0].Submodules)
+	assert.Equal(t, 1, len(commits[0].Submodules[0].Commits))
+	assert.Equal(t, "lib fix", commits[0].Submodules[0].Commits[0].Message)
+	// submodule added, there is no old commit to diff against
+	assert.Equal(t, []*SubmoduleChange{{Path: "lib", Url: libRemoteDir, NewHash: oldHash}}, commits[1].Submodules)
+
+	commit, err := repositoryManager.GetCommitMetadata(gitCtx.WithSubmoduleResolution(true, false), checkoutPath, commits[0].Commit)
+	assert.Nil(t, err)
+	assert.Equal(t, []*SubmoduleChange{{Path: "lib", Url: libRemoteDir, OldHash: oldHash, NewHash: newHash}}, commit.Submodules)
+	commit, err = repositoryManager.GetCommitMetadata(BuildGitContext(context.Background()), checkoutPath, commits[0].Commit)
+	assert.Nil(t, err)
+	assert.Empty(t, commit.Submodules)
+}
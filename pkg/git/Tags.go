@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"fmt"
+	"golang.org/x/mod/semver"
+	"path"
+	"strings"
+	"time"
+)
+
+// TAG_FORMAT prints the name, object, peeled commit (annotated tags only), creation date and subject of each tag
+const TAG_FORMAT = "--format=%(refname:strip=2)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)%1f%(contents:subject)"
+
+// semver constraints start with one of these operators, any other tag pattern is a glob
+const SEMVER_CONSTRAINT_OPERATORS = "<>=!"
+
+type GitTag struct {
+	Name      string
+	Commit    string    // commit the tag points to, peeled for annotated tags
+	Date      time.Time // tagger date for annotated tags, committer date for lightweight tags
+	Annotated bool      `json:",omitempty"`
+	Message   string    `json:",omitempty"`
+}
+
+// GetTags lists the tags matching the pattern, newest first. the pattern is either a glob on the tag name (e.g. v1.*)
+// or a comma separated list of semver constraints (e.g. >=1.2.0, <2.0.0), all tags are returned for an empty pattern
+func (impl *GitManagerBaseImpl) GetTags(gitCtx GitContext, rootDir string, pattern string) ([]*GitTag, error) {
+	matcher, err := GetTagMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs := []string{"-C", rootDir, "for-each-ref", "--sort=-creatordate", TAG_FORMAT, "refs/tags"}
+	impl.logger.Debugw("git", cmdArgs)
+	cmd, cancel := impl.createCmdWithContext(gitCtx, "git", cmdArgs...)
+	defer cancel()
+	output, errMsg, err := impl.runCommand(gitCtx, cmd)
+	if err != nil {
+		impl.logger.Errorw("error in listing tags", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return nil, err
+	}
+	var tags []*GitTag
+	for _, tag := range parseTags(output) {
+		if matcher(tag.Name) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func parseTags(output string) []*GitTag {
+	var tags []*GitTag
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) < 5 {
+			continue
+		}
+		tag := &GitTag{Name: fields[0], Commit: fields[1], Message: fields[4]}
+		if len(fields[2]) > 0 {
+			tag.Commit = fields[2]
+			tag.Annotated = true
+		}
+		tag.Date, _ = time.Parse(time.RFC3339, fields[3])
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// GetTagMatcher returns the matcher for a tag pattern, see GetTags
+func GetTagMatcher(pattern string) (func(tag string) bool, error) {
+	pattern = strings.TrimSpace(pattern)
+	if len(pattern) == 0 {
+		return func(string) bool { return true }, nil
+	}
+	if !strings.ContainsAny(pattern[:1], SEMVER_CONSTRAINT_OPERATORS) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %v", pattern, err)
+		}
+		return func(tag string) bool {
+			matched, _ := path.Match(pattern, tag)
+			return matched
+		}, nil
+	}
+	type constraint struct {
+		operator string
+		version  string
+	}
+	var constraints []constraint
+	for _, term := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ',' || r == ' ' }) {
+		version := strings.TrimLeft(term, SEMVER_CONSTRAINT_OPERATORS)
+		operator := strings.TrimSuffix(term, version)
+		version = toSemver(version)
+		if !semver.IsValid(version) {
+			return nil, fmt.Errorf("invalid version %q in tag constraint %q", term, pattern)
+		}
+		switch operator {
+		case "<", "<=", ">", ">=", "=", "==", "!=":
+		default:
+			return nil, fmt.Errorf("invalid operator %q in tag constraint %q", operator, pattern)
+		}
+		constraints = append(constraints, constraint{operator: operator, version: version})
+	}
+	return func(tag string) bool {
+		version := toSemver(tag)
+		if !semver.IsValid(version) {
+			return false
+		}
+		for _, c := range constraints {
+			result := semver.Compare(version, c.version)
+			satisfied := false
+			switch c.operator {
+			case "<":
+				satisfied = result < 0
+			case "<=":
+				satisfied = result <= 0
+			case ">":
+				satisfied = result > 0
+			case ">=":
+				satisfied = result >= 0
+			case "=", "==":
+				satisfied = result == 0
+			case "!=":
+				satisfied = result != 0
+			}
+			if !satisfied {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// toSemver adds the v prefix expected by the semver package, tags are commonly created both with and without it
+func toSemver(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
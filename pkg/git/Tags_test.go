@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetTagMatcher(t *testing.T) {
+	matcher, err := GetTagMatcher("release-*")
+	assert.Nil(t, err)
+	assert.True(t, matcher("release-1"))
+	assert.False(t, matcher("v1.0.0"))
+
+	matcher, err = GetTagMatcher(">=1.2.0, <2.0.0")
+	assert.Nil(t, err)
+	assert.True(t, matcher("v1.2.0"))
+	assert.True(t, matcher("1.10.3"))
+	assert.False(t, matcher("v2.0.0"))
+	assert.False(t, matcher("v1.1.9"))
+	assert.False(t, matcher("latest"))
+
+	_, err = GetTagMatcher(">=one")
+	assert.NotNil(t, err)
+	_, err = GetTagMatcher("=>1.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestRepositoryManager_GetTags(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	runTestGitCmd(t, workDir, "tag", "v1.0.0", "HEAD~1")
+	runTestGitCmd(t, workDir, "tag", "-a", "v1.1.0", "-m", "release v1.1.0")
+	runTestGitCmd(t, workDir, "tag", "nightly")
+	runTestGitCmd(t, workDir, "push", "origin", "--tags")
+	firstHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD~1")
+	headHash := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, checkoutPath, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, checkoutPath)
+	assert.Nil(t, err)
+
+	tags, err := repositoryManager.GetTags(gitCtx, checkoutPath, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tags))
+
+	tags, err = repositoryManager.GetTags(gitCtx, checkoutPath, ">=1.0.0")
+	assert.Nil(t, err)
+	tagsByName := make(map[string]*GitTag)
+	for _, tag := range tags {
+		tagsByName[tag.Name] = tag
+	}
+	assert.Equal(t, 2, len(tagsByName))
+	assert.Equal(t, firstHash, tagsByName["v1.0.0"].Commit)
+	assert.False(t, tagsByName["v1.0.0"].Annotated)
+	// annotated tags are peeled to their commit
+	assert.Equal(t, headHash, tagsByName["v1.1.0"].Commit)
+	assert.True(t, tagsByName["v1.1.0"].Annotated)
+	assert.Equal(t, "release v1.1.0", tagsByName["v1.1.0"].Message)
+}
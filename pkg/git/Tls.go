@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import "github.com/devtron-labs/git-sensor/internals/sql"
+
+// GetTLSData returns the ca cert, client key, client cert and whether they are used for the remote of the material.
+// The tls data of the material, for servers using a private ca, overrides the one of the git provider
+func GetTLSData(material *sql.GitMaterial, gitProvider *sql.GitProvider) (caCert string, tlsKey string, tlsCert string, tlsVerificationEnabled bool) {
+	if len(material.TlsCaCert) > 0 || (len(material.TlsKey) > 0 && len(material.TlsCert) > 0) {
+		return material.TlsCaCert, material.TlsKey, material.TlsCert, true
+	}
+	if gitProvider == nil {
+		return "", "", "", false
+	}
+	return gitProvider.CaCert, gitProvider.TlsKey, gitProvider.TlsCert, gitProvider.EnableTLSVerification
+}
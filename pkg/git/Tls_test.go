@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetTLSData(t *testing.T) {
+	provider := &sql.GitProvider{CaCert: "provider-ca", TlsKey: "provider-key", TlsCert: "provider-cert", EnableTLSVerification: true}
+	caCert, tlsKey, tlsCert, enabled := GetTLSData(&sql.GitMaterial{}, provider)
+	assert.Equal(t, []string{"provider-ca", "provider-key", "provider-cert"}, []string{caCert, tlsKey, tlsCert})
+	assert.True(t, enabled)
+
+	caCert, tlsKey, tlsCert, enabled = GetTLSData(&sql.GitMaterial{TlsCaCert: "material-ca"}, provider)
+	assert.Equal(t, []string{"material-ca", "", ""}, []string{caCert, tlsKey, tlsCert})
+	assert.True(t, enabled)
+
+	// a client key without its certificate is not used
+	caCert, _, _, _ = GetTLSData(&sql.GitMaterial{TlsKey: "material-key"}, provider)
+	assert.Equal(t, "provider-ca", caCert)
+
+	_, _, _, enabled = GetTLSData(&sql.GitMaterial{}, nil)
+	assert.False(t, enabled)
+}
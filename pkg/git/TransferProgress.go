@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"go.uber.org/zap"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PROGRESS_LOG_INTERVAL is the interval at which the progress of a phase still running is logged
+const PROGRESS_LOG_INTERVAL = 30 * time.Second
+
+// gitProgressRegex matches the progress lines git writes to stderr with --progress, like
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s"
+var gitProgressRegex = regexp.MustCompile(`^(?:remote: )?([A-Za-z ]+):\s+(\d+)% \((\d+)/(\d+)\)(?:, ([\d.]+) (bytes|KiB|MiB|GiB)(?: \| ([\d.]+) (bytes|KiB|MiB|GiB)/s)?)?`)
+
+// gitTotalObjectsRegex matches the summary of the pack sent by the remote, like "remote: Total 4 (delta 1), reused 0"
+var gitTotalObjectsRegex = regexp.MustCompile(`^(?:remote: )?Total (\d+)`)
+
+var byteUnits = map[string]float64{"bytes": 1, "KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30}
+
+// JobProgress is the progress of a transfer. LastProgressOn tells a hung transfer from a slow one, it is the time git
+// last reported progress
+type JobProgress struct {
+	Phase           string     `json:"phase,omitempty"`
+	Percent         int        `json:"percent"`
+	ObjectsReceived int        `json:"objectsReceived"`
+	TotalObjects    int        `json:"totalObjects"`
+	BytesReceived   int64      `json:"bytesReceived"`
+	BytesPerSecond  int64      `json:"bytesPerSecond"`
+	LastProgressOn  *time.Time `json:"lastProgressOn,omitempty"`
+}
+
+// TransferProgress tracks the progress of git commands from their stderr, and the bytes written for the jobs producing
+// a file. The phases are logged as they start and finish, and every PROGRESS_LOG_INTERVAL while running, when it has a
+// logger. It is safe for concurrent use
+type TransferProgress struct {
+	logger        *zap.SugaredLogger
+	keysAndValues []interface{}
+	mutex         sync.Mutex
+	progress      JobProgress
+	pending       []byte
+	lastLoggedOn  time.Time
+}
+
+// NewTransferProgress returns a progress logging with the given keys and values, like the job or checkout it tracks
+func NewTransferProgress(logger *zap.SugaredLogger, keysAndValues ...interface{}) *TransferProgress {
+	return &TransferProgress{logger: logger, keysAndValues: keysAndValues}
+}
+
+// Write parses the progress lines of the output, which git separates with a carriage return while updating a phase
+func (p *TransferProgress) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending = append(p.pending, data...)
+	start := 0
+	for i, b := range p.pending {
+		if b == '\r' || b == '\n' {
+			p.parseLine(string(p.pending[start:i]))
+			start = i + 1
+		}
+	}
+	p.pending = append(p.pending[:0], p.pending[start:]...)
+	return len(data), nil
+}
+
+func (p *TransferProgress) parseLine(line string) {
+	if match := gitTotalObjectsRegex.FindStringSubmatch(line); match != nil {
+		// git shows the progress of receiving only for transfers taking a while
+		p.progress.TotalObjects, _ = strconv.Atoi(match[1])
+		return
+	}
+	match := gitProgressRegex.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	now := time.Now()
+	phaseChanged := p.progress.Phase != match[1]
+	p.progress.Phase = match[1]
+	p.progress.Percent, _ = strconv.Atoi(match[2])
+	p.progress.LastProgressOn = &now
+	if match[1] == "Receiving objects" || match[1] == "Unpacking objects" {
+		// small packs are unpacked as they are received
+		p.progress.ObjectsReceived, _ = strconv.Atoi(match[3])
+		p.progress.TotalObjects, _ = strconv.Atoi(match[4])
+		p.progress.BytesReceived = parseByteSize(match[5], match[6])
+		p.progress.BytesPerSecond = parseByteSize(match[7], match[8])
+	}
+	if p.logger != nil && (phaseChanged || strings.HasSuffix(strings.TrimSpace(line), "done.") || now.Sub(p.lastLoggedOn) >= PROGRESS_LOG_INTERVAL) {
+		p.lastLoggedOn = now
+		p.logger.Infow("git progress", append(p.keysAndValues, "phase", p.progress.Phase, "percent", p.progress.Percent,
+			"objectsReceived", p.progress.ObjectsReceived, "totalObjects", p.progress.TotalObjects,
+			"bytesReceived", p.progress.BytesReceived, "bytesPerSecond", p.progress.BytesPerSecond)...)
+	}
+}
+
+func parseByteSize(size, unit string) int64 {
+	if len(size) == 0 {
+		return 0
+	}
+	value, _ := strconv.ParseFloat(size, 64)
+	return int64(value * byteUnits[unit])
+}
+
+// AddBytes counts the bytes written by a job producing a file
+func (p *TransferProgress) AddBytes(n int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	p.progress.BytesReceived += n
+	p.progress.LastProgressOn = &now
+}
+
+func (p *TransferProgress) Snapshot() JobProgress {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.progress
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	progress *TransferProgress
+}
+
+func (w *progressWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.progress.AddBytes(int64(n))
+	return n, err
+}
+
+// NewProgressWriter counts the bytes written to the writer in the progress, the writer is returned as is without progress
+func NewProgressWriter(writer io.Writer, progress *TransferProgress) io.Writer {
+	if progress == nil {
+		return writer
+	}
+	return &progressWriter{writer: writer, progress: progress}
+}
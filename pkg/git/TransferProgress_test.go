@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"bytes"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransferProgress_Write(t *testing.T) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	progress := NewTransferProgress(logger, "location", "/git-base/1")
+	_, _ = progress.Write([]byte("remote: Counting objects:  50% (5/10)\rremote: Counting objects: 100% (10/10), done.        \n"))
+	snapshot := progress.Snapshot()
+	assert.Equal(t, "Counting objects", snapshot.Phase)
+	assert.Equal(t, 100, snapshot.Percent)
+	assert.NotNil(t, snapshot.LastProgressOn)
+
+	// a line split over two writes is parsed once complete
+	_, _ = progress.Write([]byte("Receiving objects:  45% (450/1000), 1.50 Mi"))
+	assert.Equal(t, 0, progress.Snapshot().ObjectsReceived)
+	_, _ = progress.Write([]byte("B | 512.00 KiB/s\r"))
+	snapshot = progress.Snapshot()
+	assert.Equal(t, "Receiving objects", snapshot.Phase)
+	assert.Equal(t, 45, snapshot.Percent)
+	assert.Equal(t, 450, snapshot.ObjectsReceived)
+	assert.Equal(t, 1000, snapshot.TotalObjects)
+	assert.Equal(t, int64(1572864), snapshot.BytesReceived)
+	assert.Equal(t, int64(524288), snapshot.BytesPerSecond)
+
+	_, _ = progress.Write([]byte("remote: Total 1000 (delta 20), reused 0 (delta 0), pack-reused 0\n"))
+	_, _ = progress.Write([]byte("Resolving deltas: 100% (20/20), done.\n"))
+	snapshot = progress.Snapshot()
+	assert.Equal(t, "Resolving deltas", snapshot.Phase)
+	assert.Equal(t, 450, snapshot.ObjectsReceived)
+	assert.Equal(t, int64(1572864), snapshot.BytesReceived)
+}
+
+func TestNewProgressWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.Equal(t, &buffer, NewProgressWriter(&buffer, nil))
+	progress := &TransferProgress{}
+	_, err := NewProgressWriter(&buffer, progress).Write([]byte("archive"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), progress.Snapshot().BytesReceived)
+	assert.NotNil(t, progress.Snapshot().LastProgressOn)
+}
@@ -18,7 +18,6 @@ package git
 
 import (
 	"fmt"
-	"github.com/devtron-labs/git-sensor/internals/middleware"
 	"github.com/devtron-labs/git-sensor/internals/sql"
 	"io/ioutil"
 	"os"
@@ -43,6 +42,8 @@ const (
 	CloningModeFull           = "FULL"
 	NO_COMMIT_GIT_ERROR_MESSAGE    = "unknown revision or path not in the working tree."
 	NO_COMMIT_CUSTOM_ERROR_MESSAGE = "No Commit Found"
+	BRANCH_DELETED_ERROR_MESSAGE   = "branch deleted at remote"
+	MAX_DEEPEN_ATTEMPTS            = 5
 )
 
 //git@gitlab.com:devtron-client-gitops/wms-user-management.git
@@ -80,8 +81,8 @@ func GetUserNamePassword(gitProvider *sql.GitProvider) (userName, password strin
 	}
 }
 
-func GetOrCreateSshPrivateKeyOnDisk(gitProviderId int, sshPrivateKeyContent string) (privateKeyPath string, err error) {
-	sshPrivateKeyFolderPath := path.Join(SSH_PRIVATE_KEY_DIR, strconv.Itoa(gitProviderId))
+func GetOrCreateSshPrivateKeyOnDisk(keyOwner string, sshPrivateKeyContent string) (privateKeyPath string, err error) {
+	sshPrivateKeyFolderPath := path.Join(SSH_PRIVATE_KEY_DIR, keyOwner)
 	sshPrivateKeyFilePath := path.Join(sshPrivateKeyFolderPath, SSH_PRIVATE_KEY_FILE_NAME)
 
 	// if file exists then return
@@ -90,7 +91,7 @@ func GetOrCreateSshPrivateKeyOnDisk(gitProviderId int, sshPrivateKeyContent stri
 	}
 
 	// create dirs
-	err = os.MkdirAll(sshPrivateKeyFolderPath, 0755)
+	err = os.MkdirAll(sshPrivateKeyFolderPath, 0700)
 	if err != nil {
 		return "", err
 	}
@@ -104,8 +105,8 @@ func GetOrCreateSshPrivateKeyOnDisk(gitProviderId int, sshPrivateKeyContent stri
 	return sshPrivateKeyFilePath, nil
 }
 
-func CreateOrUpdateSshPrivateKeyOnDisk(gitProviderId int, sshPrivateKeyContent string) error {
-	sshPrivateKeyFolderPath := path.Join(SSH_PRIVATE_KEY_DIR, strconv.Itoa(gitProviderId))
+func CreateOrUpdateSshPrivateKeyOnDisk(keyOwner string, sshPrivateKeyContent string) error {
+	sshPrivateKeyFolderPath := path.Join(SSH_PRIVATE_KEY_DIR, keyOwner)
 	sshPrivateKeyFilePath := path.Join(sshPrivateKeyFolderPath, SSH_PRIVATE_KEY_FILE_NAME)
 
 	// if file exists then delete file
@@ -114,7 +115,7 @@ func CreateOrUpdateSshPrivateKeyOnDisk(gitProviderId int, sshPrivateKeyContent s
 	}
 
 	// create dirs
-	err := os.MkdirAll(sshPrivateKeyFolderPath, 0755)
+	err := os.MkdirAll(sshPrivateKeyFolderPath, 0700)
 	if err != nil {
 		return err
 	}
@@ -158,65 +159,25 @@ func DeleteAFileIfExists(path string) error {
 	return nil
 }
 
-// sample commitDiff :=4\t3\tModels/models.go\n2\t2\tRepository/Repository.go\n0\t2\main.go
-func processFileStatOutputWithNumstat(commitDiff string) (FileStats, error) {
-	filestat := FileStats{}
-	lines := strings.Split(strings.TrimSpace(commitDiff), "\n")
-
-	for _, line := range lines {
-		parts := strings.Split(line, "\t")
-
-		if len(parts) != 3 {
-			fmt.Printf("invalid git diff --numstat output, parts: %v\n", parts)
-			middleware.CommitStatParsingErrorCounter.WithLabelValues().Inc()
-			continue
-		}
-
-		if parts[0] == "-" && parts[1] == "-" {
-			// ignoring binary file
-			continue
-		}
-		var isParsingError bool
-		//TODO not ignoring in case of error in below cases because of include/exclude feature where file name is important
-		added, err := strconv.Atoi(parts[0])
-		if err != nil {
-			fmt.Printf("failed to parse number of lines added: %v\n", err)
-			isParsingError = true
-		}
-
-		deleted, err := strconv.Atoi(parts[1])
-		if err != nil {
-			fmt.Printf("failed to parse number of lines deleted: %v\n", err)
-			isParsingError = true
-		}
-		if isParsingError {
-			middleware.CommitStatParsingErrorCounter.WithLabelValues().Inc()
-		}
-
-		filestat = append(filestat, FileStat{
-			Name:     parts[2],
-			Addition: added,
-			Deletion: deleted,
-		})
-	}
-
-	return filestat, nil
+func IsRepoShallowCloned(checkoutPath string) bool {
+	return strings.Contains(checkoutPath, "/.git")
 }
 
-// sample commitDiff :=pkg/bulkAction/BulkUpdateService.go\nscripts/sql/244_alter_resource_release_feature.down.sql\nscripts/sql/244_alter_resource_release_feature.up.sql
-func processFileStatOutputNameOnly(commitDiff string) (FileStats, error) {
-	filestat := FileStats{}
-	lines := strings.Split(strings.TrimSpace(commitDiff), "\n")
-
-	for _, line := range lines {
+// IsShallowRepository checks if the history of the repo at rootDir is truncated, i.e. it was fetched with a depth
+func IsShallowRepository(rootDir string) bool {
+	_, err := os.Stat(path.Join(GetGitDir(rootDir), "shallow"))
+	return err == nil
+}
 
-		filestat = append(filestat, FileStat{
-			Name: line,
-		})
+// GetGitDir returns the directory holding the objects and refs of the repo at rootDir, which is rootDir itself for a bare
+// repo and the one of the store for a worktree
+func GetGitDir(rootDir string) string {
+	if store := GetWorktreeStore(rootDir); len(store) > 0 {
+		rootDir = store
 	}
-
-	return filestat, nil
-}
-func IsRepoShallowCloned(checkoutPath string) bool {
-	return strings.Contains(checkoutPath, "/.git")
+	gitDir := path.Join(rootDir, ".git")
+	if _, err := os.Stat(gitDir); err != nil {
+		return rootDir
+	}
+	return gitDir
 }
@@ -19,6 +19,7 @@ package git
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/caarlos0/env"
 	"github.com/devtron-labs/common-lib/constants"
@@ -27,10 +28,13 @@ import (
 	"github.com/devtron-labs/common-lib/pubsub-lib/model"
 	"github.com/devtron-labs/git-sensor/internals"
 	"github.com/devtron-labs/git-sensor/internals/middleware"
+	"github.com/devtron-labs/git-sensor/internals/queueManager"
 	"github.com/devtron-labs/git-sensor/internals/sql"
-	"github.com/gammazero/workerpool"
+	"github.com/devtron-labs/git-sensor/internals/tracing"
+	"github.com/devtron-labs/git-sensor/util"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"regexp"
 	"runtime/debug"
 	"strings"
 	"time"
@@ -48,26 +52,44 @@ type GitWatcherImpl struct {
 	webhookHandler               WebhookHandler
 	configuration                *internals.Configuration
 	gitManager                   GitManager
+	pollWorker                   *queueManager.LocalWorker
+	materialChangeBroadcaster    MaterialChangeBroadcaster
+	gitCommitRepository          sql.GitCommitRepository
+	pollScheduler                *PollScheduler
+	commitFilter                 *CommitFilter
+	commitPolicy                 *CommitPolicy
+	ticketExtractor              *TicketExtractor
+	providerApiClient            ProviderApiClient
+	credentialResolver           CredentialResolver
+	commitEventPublisher         CommitEventPublisher
 }
 
 const PANIC = "panic"
 
 type GitWatcher interface {
 	PollAndUpdateGitMaterial(material *sql.GitMaterial) (*sql.GitMaterial, error)
+	RunOnWorker(materials []*sql.GitMaterial)
 }
 
 type PollConfig struct {
-	PollDuration int `env:"POLL_DURATION" envDefault:"2"`
-	PollWorker   int `env:"POLL_WORKER" envDefault:"5"`
+	PollDuration        int `env:"POLL_DURATION" envDefault:"2"`
+	PollWorker          int `env:"POLL_WORKER" envDefault:"5"`
+	PollJitterPercent   int `env:"POLL_JITTER_PERCENT" envDefault:"10"`
+	PollMaxBackoffInMin int `env:"POLL_MAX_BACKOFF_IN_MIN" envDefault:"60"`
 }
 
+// POLL_SCHEDULER_TICK is how often the materials due for polling are checked, their intervals are set in minutes
+const POLL_SCHEDULER_TICK = "@every 30s"
+
 func NewGitWatcherImpl(repositoryManager RepositoryManager,
 	materialRepo sql.MaterialRepository,
 	logger *zap.SugaredLogger,
 	ciPipelineMaterialRepository sql.CiPipelineMaterialRepository,
 	locker *internals.RepositoryLocker,
 	pubSubClient *pubsub.PubSubClientServiceImpl, webhookHandler WebhookHandler, configuration *internals.Configuration,
-	gitmanager GitManager,
+	gitmanager GitManager, materialChangeBroadcaster MaterialChangeBroadcaster, gitCommitRepository sql.GitCommitRepository,
+	providerApiClient ProviderApiClient, ticketExtractor *TicketExtractor, credentialResolver CredentialResolver,
+	commitEventPublisher CommitEventPublisher,
 ) (*GitWatcherImpl, error) {
 
 	cfg := &PollConfig{}
@@ -75,6 +97,26 @@ func NewGitWatcherImpl(repositoryManager RepositoryManager,
 	if err != nil {
 		return nil, err
 	}
+	commitFilter, err := NewCommitFilter(configuration)
+	if err != nil {
+		logger.Errorw("invalid commit filter config", "err", err)
+		return nil, err
+	}
+	commitPolicy, err := NewCommitPolicy(configuration)
+	if err != nil {
+		logger.Errorw("invalid commit policy config", "err", err)
+		return nil, err
+	}
+	gitBinary := gitmanager.GetGitBinary()
+	if configuration.UseGitCli && !gitBinary.IsAvailable() {
+		logger.Errorw("git binary not found, it is needed with USE_GIT_CLI", "path", gitBinary.Path)
+		return nil, fmt.Errorf("git binary %q not found", gitBinary.Path)
+	}
+	err = gitBinary.CheckMinVersion(configuration.MinGitVersion)
+	if err != nil {
+		logger.Errorw("unsupported git version", "err", err)
+		return nil, err
+	}
 	cronLogger := &CronLoggerImpl{logger: logger}
 	cron := cron.New(
 		cron.WithChain(
@@ -93,10 +135,20 @@ func NewGitWatcherImpl(repositoryManager RepositoryManager,
 		webhookHandler:               webhookHandler,
 		configuration:                configuration,
 		gitManager:                   gitmanager,
+		pollWorker:                   queueManager.NewLocalWorker(logger, cfg.PollWorker),
+		materialChangeBroadcaster:    materialChangeBroadcaster,
+		gitCommitRepository:          gitCommitRepository,
+		pollScheduler:                NewPollScheduler(cfg),
+		commitFilter:                 commitFilter,
+		commitPolicy:                 commitPolicy,
+		ticketExtractor:              ticketExtractor,
+		providerApiClient:            providerApiClient,
+		credentialResolver:           credentialResolver,
+		commitEventPublisher:         commitEventPublisher,
 	}
 
 	logger.Info()
-	_, err = cron.AddFunc(fmt.Sprintf("@every %dm", cfg.PollDuration), watcher.Watch)
+	_, err = cron.AddFunc(POLL_SCHEDULER_TICK, watcher.Watch)
 	if err != nil {
 		fmt.Println("error in starting cron")
 		return nil, err
@@ -108,6 +160,7 @@ func NewGitWatcherImpl(repositoryManager RepositoryManager,
 }
 func (impl GitWatcherImpl) StopCron() {
 	impl.cron.Stop()
+	impl.pollWorker.Stop()
 }
 
 func (impl GitWatcherImpl) Watch() {
@@ -119,13 +172,23 @@ func (impl GitWatcherImpl) Watch() {
 	}
 	// impl.Publish(materials)
 	middleware.ActiveGitRepoCount.WithLabelValues().Set(float64(len(materials)))
-	impl.RunOnWorker(materials)
+	now := time.Now()
+	activeIds := make(map[int]bool, len(materials))
+	var dueMaterials []*sql.GitMaterial
+	for _, material := range materials {
+		activeIds[material.Id] = true
+		if impl.pollScheduler.IsDue(material, now) {
+			dueMaterials = append(dueMaterials, material)
+		}
+	}
+	impl.pollScheduler.Forget(activeIds)
+	impl.RunOnWorker(dueMaterials)
 	impl.logger.Infow("stop git watch thread")
 }
 
+// RunOnWorker queues the materials for polling, a material still queued from the previous run is not queued again. The
+// next poll of a queued material is scheduled right away, a poll running longer than the tick doesn't make it due again
 func (impl *GitWatcherImpl) RunOnWorker(materials []*sql.GitMaterial) {
-	wp := workerpool.New(impl.pollConfig.PollWorker)
-
 	handlePanic := func() {
 		if err := recover(); err != nil {
 			impl.logger.Error(constants.PanicLogIdentifier, "recovered from panic", "panic", err, "stack", string(debug.Stack()))
@@ -135,19 +198,24 @@ func (impl *GitWatcherImpl) RunOnWorker(materials []*sql.GitMaterial) {
 
 	for _, material := range materials {
 		if len(material.CiPipelineMaterials) == 0 {
-			impl.logger.Infow("no ci pipeline, skipping", "id", material.Id, "url", material.Url)
+			impl.logger.Infow("no ci pipeline, skipping", "id", material.Id, "url", tracing.SanitizeUrl(material.Url))
 			continue
 		}
 		materialMsg := &sql.GitMaterial{Id: material.Id, Url: material.Url}
-		wp.Submit(func() {
+		// scheduled before the submit, so that the end of a fast poll is not overwritten
+		impl.pollScheduler.Reschedule(material, time.Now())
+		queued := impl.pollWorker.Submit(material.Id, func() {
 			defer handlePanic()
 			_, err := impl.pollAndUpdateGitMaterial(materialMsg)
 			if err != nil {
 				impl.logger.Errorw("error in polling git material", "material", materialMsg, "err", err)
 			}
 		})
+		if !queued {
+			impl.logger.Debugw("material already queued for polling, skipping", "id", material.Id)
+		}
 	}
-	wp.StopWait()
+	impl.logger.Infow("materials queued for polling", "queueDepth", impl.pollWorker.QueueDepth())
 }
 
 func (impl GitWatcherImpl) PollAndUpdateGitMaterial(material *sql.GitMaterial) (*sql.GitMaterial, error) {
@@ -168,6 +236,13 @@ func (impl GitWatcherImpl) pollAndUpdateGitMaterial(materialReq *sql.GitMaterial
 		return nil, err
 	}
 	err = impl.pollGitMaterialAndNotify(material)
+	if err != nil && ChildProcessesKilled() {
+		// the poll was cut by the shutdown, the material keeps its fetch status and last seen commits and is polled again
+		// after the restart
+		impl.logger.Warnw("poll of material interrupted by shutdown", "materialId", material.Id, "err", err)
+		return material, err
+	}
+	util.TriggerGitFetchMetrics(material.Id, err)
 	material.LastFetchTime = time.Now()
 	material.FetchStatus = err == nil
 	if err != nil {
@@ -176,7 +251,9 @@ func (impl GitWatcherImpl) pollAndUpdateGitMaterial(materialReq *sql.GitMaterial
 	} else {
 		material.LastFetchErrorCount = 0
 		material.FetchErrorMessage = ""
+		material.LastSuccessFetchTime = material.LastFetchTime
 	}
+	impl.pollScheduler.Reschedule(material, material.LastFetchTime)
 	err = impl.materialRepo.Update(material)
 	if err != nil {
 		impl.logger.Errorw("error in updating fetch status", "material", material, "err", err)
@@ -184,19 +261,48 @@ func (impl GitWatcherImpl) pollAndUpdateGitMaterial(materialReq *sql.GitMaterial
 	return material, err
 }
 
-func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) error {
-	gitProvider := material.GitProvider
+func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) (err error) {
+	gitProvider, err := impl.credentialResolver.ResolveGitProvider(context.Background(), material, material.GitProvider)
+	if err != nil {
+		return err
+	}
 	userName, password, err := GetUserNamePassword(gitProvider)
 	location := material.CheckoutLocation
 	if err != nil {
-		impl.logger.Errorw("error in determining location", "url", material.Url, "err", err)
+		impl.logger.Errorw("error in determining location", "url", tracing.SanitizeUrl(material.Url), "err", err)
 		return err
 	}
-	gitCtx := BuildGitContext(context.Background()).
+	ctx, span := tracing.StartSpan(context.Background(), "pollGitMaterial", tracing.ATTRIBUTE_MATERIAL_ID.Int(material.Id),
+		tracing.ATTRIBUTE_REPO_URL.String(tracing.SanitizeUrl(material.Url)))
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	gitCtx := BuildGitContext(ctx).
 		WithCredentials(userName, password).
-		WithTLSData(gitProvider.CaCert, gitProvider.TlsKey, gitProvider.TlsCert, material.GitProvider.EnableTLSVerification)
+		WithCredentialScope(GetCredentialScope(material)).
+		WithRetryPolicy(GetRetryPolicy(impl.configuration, material)).
+		WithTLSData(GetTLSData(material, gitProvider)).
+		WithExtraGitConfig(material.GitConfig).
+		WithInsecureSkipTLS(material.TlsInsecureSkipVerify).
+		WithSubmoduleResolution(impl.configuration.ResolveSubmoduleChanges, impl.configuration.FetchSubmoduleCommits).
+		WithSignatureVerification(impl.configuration.VerifyCommitSignatures).
+		WithProxy(ResolveProxy(material.Url, material.ProxyUrl, impl.configuration.GitHttpProxy, impl.configuration.GitNoProxy))
+	if impl.configuration.PathFilteredPolling {
+		gitCtx = gitCtx.WithPathFilter(GetPathFilterFromPattern(material.FilterPattern))
+	}
+	gitCtx = gitCtx.WithFirstParent(impl.configuration.FirstParentPolling)
+	if material.ApiMode {
+		// new commits are listed through the provider api, the repo is cloned only when its content is requested
+		return impl.updateMaterialsAndNotify(gitCtx, material, nil)
+	}
 
 	updated, repo, err := impl.FetchAndUpdateMaterial(gitCtx, material, location)
+	recovered := false
+	if errors.Is(err, ErrRepoCorrupted) && impl.configuration.RecoverCorruptedCheckouts {
+		repo, err = impl.recoverCorruptedCheckout(gitCtx, material)
+		// the commits of the fresh clone are not reported as fetched, so the materials are updated from it
+		updated, recovered = err == nil, err == nil
+	}
 	if err != nil {
 		impl.logger.Errorw("error in fetching material details ", "repo", material.Url, "err", err)
 		// there might be the case if ssh private key gets flush from disk, so creating and single retrying in this case
@@ -227,16 +333,90 @@ func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) e
 	if !updated {
 		return nil
 	}
+	err = impl.updateMaterialsAndNotify(gitCtx, material, repo)
+	if errors.Is(err, ErrRepoCorrupted) && impl.configuration.RecoverCorruptedCheckouts && !recovered {
+		repo, err = impl.recoverCorruptedCheckout(gitCtx, material)
+		if err != nil {
+			return err
+		}
+		return impl.updateMaterialsAndNotify(gitCtx, material, repo)
+	}
+	return err
+}
+
+// recoverCorruptedCheckout clones the material again in place of its corrupted checkout and fetches it
+func (impl GitWatcherImpl) recoverCorruptedCheckout(gitCtx GitContext, material *sql.GitMaterial) (*GitRepository, error) {
+	impl.logger.Warnw("checkout of material is corrupted, cloning it again", "materialId", material.Id, "checkoutLocation", material.CheckoutLocation)
+	location, _, _, err := impl.repositoryManager.GetCheckoutLocationFromGitUrl(material, gitCtx.CloningMode)
+	if err != nil {
+		impl.logger.Errorw("error in getting clone location ", "material", material, "err", err)
+		return nil, err
+	}
+	gitProvider, err := impl.credentialResolver.ResolveGitProvider(gitCtx, material, material.GitProvider)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.repositoryManager.RecoverCheckout(gitCtx, gitProvider.Id, location, material.Url, gitProvider.AuthMode, gitProvider.SshPrivateKey)
+	if err != nil {
+		impl.logger.Errorw("error in recovering corrupted checkout", "materialId", material.Id, "location", location, "err", err)
+		return nil, err
+	}
+	_, repo, err := impl.FetchAndUpdateMaterial(gitCtx, material, location)
+	return repo, err
+}
+
+// updateMaterialsAndNotify finds the new commits of the pipeline materials of the fetched material and notifies them. An
+// ErrRepoCorrupted is returned when the checkout could not be read for some of them
+func (impl GitWatcherImpl) updateMaterialsAndNotify(gitCtx GitContext, material *sql.GitMaterial, repo *GitRepository) error {
 	materials, err := impl.ciPipelineMaterialRepository.FindByGitMaterialId(material.Id)
 	if err != nil {
-		impl.logger.Errorw("error in calculating head", "err", err, "url", material.Url)
+		impl.logger.Errorw("error in calculating head", "err", err, "url", tracing.SanitizeUrl(material.Url))
 		return err
 	}
 	var updatedMaterials []*CiPipelineMaterialBean
 	var updatedMaterialsModel []*sql.CiPipelineMaterial
 	var erroredMaterialsModels []*sql.CiPipelineMaterial
+	var corruptedErr error
 	checkoutLocation := material.CheckoutLocation
+	apiMode := material.ApiMode
+	gitMaterial := material
 	for _, material := range materials {
+		if apiMode && material.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
+			impl.logger.Debugw("only branch materials are polled in api mode, skipping", "materialId", material.Id, "type", material.Type)
+			continue
+		}
+		if material.Type == sql.SOURCE_TYPE_TAG_ANY && impl.configuration.EnableTagPolling {
+			mb, err := impl.pollTagMaterial(gitCtx, checkoutLocation, material)
+			if err != nil {
+				material.Errored = true
+				material.ErrorMsg = err.Error()
+				if errors.Is(err, ErrRepoCorrupted) {
+					corruptedErr = err
+				}
+				erroredMaterialsModels = append(erroredMaterialsModels, material)
+			} else if mb != nil {
+				updatedMaterials = append(updatedMaterials, mb)
+				updatedMaterialsModel = append(updatedMaterialsModel, material)
+				middleware.GitMaterialUpdateCounter.WithLabelValues().Inc()
+			}
+			continue
+		}
+		if material.Type == sql.SOURCE_TYPE_BRANCH_REGEX && impl.configuration.EnableBranchRegexPolling {
+			mbs, err := impl.pollBranchRegexMaterial(gitCtx, repo, checkoutLocation, material)
+			if err != nil {
+				material.Errored = true
+				material.ErrorMsg = err.Error()
+				if errors.Is(err, ErrRepoCorrupted) {
+					corruptedErr = err
+				}
+				erroredMaterialsModels = append(erroredMaterialsModels, material)
+			} else if len(mbs) > 0 {
+				updatedMaterials = append(updatedMaterials, mbs...)
+				updatedMaterialsModel = append(updatedMaterialsModel, material)
+				middleware.GitMaterialUpdateCounter.WithLabelValues().Inc()
+			}
+			continue
+		}
 		if material.Type != sql.SOURCE_TYPE_BRANCH_FIXED {
 			continue
 		}
@@ -249,18 +429,50 @@ func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) e
 			lastSeenHash = material.LastSeenHash
 		}
 		fetchCount := impl.configuration.GitHistoryCount
-		commits, err := impl.repositoryManager.ChangesSinceByRepository(gitCtx, repo, material.Value, lastSeenHash, "", fetchCount, checkoutLocation, false)
+		forcePushed := false
+		var forcePush *ForcePush
+		var commits []*GitCommitBase
+		if apiMode {
+			commits, err = impl.providerApiClient.ListCommits(gitCtx, gitMaterial, material.Value, lastSeenHash, fetchCount)
+		} else {
+			var branchDeleted bool
+			branchDeleted, forcePushed, err = impl.repositoryManager.GetBranchState(gitCtx, checkoutLocation, material.Value, lastSeenHash)
+			if err != nil {
+				impl.logger.Errorw("error in getting branch state, continuing with last seen commit", "materialId", material.Id, "branch", material.Value, "err", err)
+			} else if branchDeleted {
+				impl.logger.Infow("branch deleted at remote", "materialId", material.Id, "branch", material.Value)
+				material.Errored = true
+				material.ErrorMsg = BRANCH_DELETED_ERROR_MESSAGE
+				erroredMaterialsModels = append(erroredMaterialsModels, material)
+				continue
+			} else if forcePushed {
+				// last seen commit is unreachable from the new head, so the range from^..to can't be used anymore
+				impl.logger.Infow("force push detected, resetting last seen commit", "materialId", material.Id, "branch", material.Value, "lastSeenHash", lastSeenHash)
+				forcePush = impl.getForcePush(gitCtx, checkoutLocation, material.Value, lastSeenHash)
+				lastSeenHash = ""
+			}
+			commits, err = impl.repositoryManager.ChangesSinceByRepository(gitCtx, repo, material.Value, lastSeenHash, "", fetchCount, checkoutLocation, false)
+		}
 		if err != nil {
 			material.Errored = true
 			material.ErrorMsg = err.Error()
+			if errors.Is(err, ErrRepoCorrupted) {
+				corruptedErr = err
+			}
 			erroredMaterialsModels = append(erroredMaterialsModels, material)
 		} else if len(commits) > 0 {
+			impl.evaluateCommitPolicy(commits, forcePushed)
+			impl.ticketExtractor.Annotate(commits...)
 			latestCommit := commits[0]
 			if latestCommit.GetCommit().Commit != material.LastSeenHash {
 
-				commitsTotal, err := AppendOldCommitsFromHistory(commits, material.CommitHistory, fetchCount)
-				if err != nil {
-					impl.logger.Errorw("error in appending history to new commits", "material", material.GitMaterialId, "err", err)
+				commitsTotal := commits
+				if !forcePushed {
+					// old history is rewritten in case of force push, so not retaining it
+					commitsTotal, err = AppendOldCommitsFromHistory(commits, material.CommitHistory, fetchCount)
+					if err != nil {
+						impl.logger.Errorw("error in appending history to new commits", "material", material.GitMaterialId, "err", err)
+					}
 				}
 
 				// new commit found
@@ -271,8 +483,18 @@ func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) e
 					Type:          material.Type,
 					Active:        material.Active,
 					GitCommit:     latestCommit,
+					ForcePushed:   forcePushed,
+				}
+				if forcePush != nil {
+					latestCommit.ForcePush = forcePush
+					mb.OrphanedCommits = forcePush.OrphanedCommits
+				}
+				if len(lastSeenHash) > 0 {
+					// on the first poll and after a force push the commits include the old history
+					mb.NewCommits = commits
 				}
 				updatedMaterials = append(updatedMaterials, mb)
+				impl.persistCommits(material.Id, commits)
 
 				material.LastSeenHash = latestCommit.Commit
 				material.CommitAuthor = latestCommit.Author
@@ -288,31 +510,287 @@ func (impl GitWatcherImpl) pollGitMaterialAndNotify(material *sql.GitMaterial) e
 		}
 	}
 	if len(updatedMaterialsModel) > 0 {
+		changedMaterials := updatedMaterials
+		if material.ProtectedBranchesOnly {
+			updatedMaterials = impl.filterProtectedBranchUpdates(gitCtx, material, updatedMaterials)
+		}
+		// the events are for all the changes, the filters of the notification decide only what triggers ci
+		var outboxEvents []*sql.CommitEventOutbox
+		outboxEvents, err = impl.commitEventPublisher.NewOutboxEvents(material, updatedMaterials)
+		if err != nil {
+			impl.logger.Errorw("error in creating commit events", "gitMaterialId", material.Id, "err", err)
+		}
 		err = impl.NotifyForMaterialUpdate(updatedMaterials, material)
 		if err != nil {
-			impl.logger.Errorw("error in sending notification for materials", "url", material.Url, "update", updatedMaterialsModel)
+			impl.logger.Errorw("error in sending notification for materials", "url", tracing.SanitizeUrl(material.Url), "update", updatedMaterialsModel)
 		}
-		err = impl.ciPipelineMaterialRepository.Update(updatedMaterialsModel)
+		err = impl.ciPipelineMaterialRepository.UpdateWithCommitEvents(updatedMaterialsModel, outboxEvents)
 		if err != nil {
-			impl.logger.Errorw("error in update db ", "url", material.Url, "update", updatedMaterialsModel)
-			impl.logger.Errorw("error in sending notification for materials", "url", material.Url, "update", updatedMaterialsModel)
+			impl.logger.Errorw("error in update db ", "url", tracing.SanitizeUrl(material.Url), "update", updatedMaterialsModel)
+			impl.logger.Errorw("error in sending notification for materials", "url", tracing.SanitizeUrl(material.Url), "update", updatedMaterialsModel)
+		} else {
+			// watchers resume from the saved commit history, so all the changes are published once it is saved, unfiltered
+			for _, changedMaterial := range changedMaterials {
+				impl.materialChangeBroadcaster.Publish(changedMaterial)
+			}
 		}
 	}
 	if len(erroredMaterialsModels) > 0 {
-		err = impl.ciPipelineMaterialRepository.Update(updatedMaterialsModel)
+		err = impl.ciPipelineMaterialRepository.Update(erroredMaterialsModels)
 		if err != nil {
-			impl.logger.Errorw("error in update db ", "url", material.Url, "update", updatedMaterialsModel)
-			impl.logger.Errorw("error in sending notification for materials", "url", material.Url, "update", updatedMaterialsModel)
+			impl.logger.Errorw("error in update db ", "url", tracing.SanitizeUrl(material.Url), "update", erroredMaterialsModels)
 		}
 	}
-	return nil
+	return corruptedErr
+}
+
+// persistCommits saves the new commits of the material, so that its history can be queried beyond the cached commit history
+func (impl GitWatcherImpl) persistCommits(ciPipelineMaterialId int, commits []*GitCommitBase) {
+	if !impl.configuration.PersistCommitHistory {
+		return
+	}
+	now := time.Now()
+	gitCommits := make([]*sql.GitCommit, 0, len(commits))
+	for _, commit := range commits {
+		commitData, err := json.Marshal(commit)
+		if err != nil {
+			impl.logger.Errorw("error in marshalling commit", "ciPipelineMaterialId", ciPipelineMaterialId, "commit", commit.Commit, "err", err)
+			continue
+		}
+		gitCommits = append(gitCommits, &sql.GitCommit{
+			CiPipelineMaterialId: ciPipelineMaterialId,
+			CommitHash:           commit.Commit,
+			Author:               commit.Author,
+			CommitDate:           commit.Date,
+			CommitData:           string(commitData),
+			CreatedOn:            now,
+		})
+	}
+	err := impl.gitCommitRepository.SaveAll(gitCommits)
+	if err != nil {
+		impl.logger.Errorw("error in persisting commits", "ciPipelineMaterialId", ciPipelineMaterialId, "err", err)
+	}
+}
+
+// pollTagMaterial checks for tags matching the material value which were created or moved since the last poll,
+// the newest of them is returned as the material update. the matching tags are kept in the commit history
+func (impl GitWatcherImpl) pollTagMaterial(gitCtx GitContext, checkoutLocation string, material *sql.CiPipelineMaterial) (*CiPipelineMaterialBean, error) {
+	tags, err := impl.repositoryManager.GetTags(gitCtx, checkoutLocation, material.Value)
+	if err != nil {
+		return nil, err
+	}
+	seenTags := make(map[string]string)
+	if len(material.CommitHistory) > 0 {
+		var oldCommits []*GitCommitBase
+		if err := json.Unmarshal([]byte(material.CommitHistory), &oldCommits); err != nil {
+			impl.logger.Errorw("error in unmarshalling tag history", "materialId", material.Id, "err", err)
+		}
+		for _, oldCommit := range oldCommits {
+			if oldCommit.Tag != nil {
+				seenTags[oldCommit.Tag.Name] = oldCommit.Tag.Commit
+			}
+		}
+	}
+	var latestTag *GitTag
+	for _, tag := range tags {
+		seenCommit, seen := seenTags[tag.Name]
+		isNew := len(material.LastSeenHash) == 0 || (!seen && tag.Date.After(material.CommitDate))
+		isMoved := seen && seenCommit != tag.Commit
+		if isNew || isMoved {
+			// tags are sorted newest first
+			latestTag = tag
+			break
+		}
+	}
+	if latestTag == nil {
+		return nil, nil
+	}
+	commit, err := impl.repositoryManager.GetCommitMetadata(gitCtx, checkoutLocation, latestTag.Commit)
+	if err != nil {
+		return nil, err
+	}
+	commit.Tag = latestTag
+	fetchCount := impl.configuration.GitHistoryCount
+	tagCommits := make([]*GitCommitBase, 0, fetchCount)
+	for _, tag := range tags {
+		if len(tagCommits) == fetchCount {
+			break
+		}
+		if tag == latestTag {
+			tagCommits = append(tagCommits, commit)
+			continue
+		}
+		tagCommits = append(tagCommits, &GitCommitBase{Commit: tag.Commit, Date: tag.Date, Message: tag.Message, Tag: tag})
+	}
+	impl.logger.Infow("new tag found", "materialId", material.Id, "tag", latestTag.Name, "commit", latestTag.Commit)
+	material.LastSeenHash = commit.Commit
+	material.CommitAuthor = commit.Author
+	material.CommitDate = latestTag.Date
+	material.CommitMessage = commit.Message
+	commitJson, _ := json.Marshal(tagCommits)
+	material.CommitHistory = string(commitJson)
+	material.Errored = false
+	material.ErrorMsg = ""
+	return &CiPipelineMaterialBean{
+		Id:            material.Id,
+		Value:         material.Value,
+		GitMaterialId: material.GitMaterialId,
+		Type:          material.Type,
+		Active:        material.Active,
+		GitCommit:     commit,
+	}, nil
+}
+
+// pollBranchRegexMaterial tracks all the branches matching the regex in the material value, an update is returned for
+// each branch which was created, deleted or got new commits since the last poll. the heads of the matching branches
+// are kept in the commit history
+func (impl GitWatcherImpl) pollBranchRegexMaterial(gitCtx GitContext, repo *GitRepository, checkoutLocation string, material *sql.CiPipelineMaterial) ([]*CiPipelineMaterialBean, error) {
+	branchRegex, err := regexp.Compile("^(?:" + material.Value + ")$")
+	if err != nil {
+		impl.logger.Errorw("invalid branch regex", "materialId", material.Id, "regex", material.Value, "err", err)
+		return nil, err
+	}
+	branches, err := impl.repositoryManager.ListBranches(gitCtx, checkoutLocation)
+	if err != nil {
+		return nil, err
+	}
+	var oldHeads []*GitCommitBase
+	if len(material.CommitHistory) > 0 {
+		if err := json.Unmarshal([]byte(material.CommitHistory), &oldHeads); err != nil {
+			impl.logger.Errorw("error in unmarshalling branch heads", "materialId", material.Id, "err", err)
+		}
+	}
+	oldHeadsByBranch := make(map[string]*GitCommitBase)
+	for _, oldHead := range oldHeads {
+		if len(oldHead.Branch) > 0 {
+			oldHeadsByBranch[oldHead.Branch] = oldHead
+		}
+	}
+	newMaterialBean := func(head *GitCommitBase) *CiPipelineMaterialBean {
+		return &CiPipelineMaterialBean{
+			Id:            material.Id,
+			Value:         material.Value,
+			GitMaterialId: material.GitMaterialId,
+			Type:          material.Type,
+			Active:        material.Active,
+			GitCommit:     head,
+			Branch:        head.Branch,
+		}
+	}
+	// the first poll only records the matching branches
+	isFirstPoll := len(material.LastSeenHash) == 0
+	var updates []*CiPipelineMaterialBean
+	var heads []*GitCommitBase
+	matchedBranches := make(map[string]bool)
+	for _, branch := range branches {
+		if !branchRegex.MatchString(branch.Name) {
+			continue
+		}
+		matchedBranches[branch.Name] = true
+		oldHead, seen := oldHeadsByBranch[branch.Name]
+		if seen && oldHead.Commit == branch.Commit {
+			heads = append(heads, oldHead)
+			continue
+		}
+		lastSeenHash := ""
+		forcePushed := false
+		var forcePush *ForcePush
+		if seen {
+			lastSeenHash = oldHead.Commit
+			_, forcePushed, err = impl.repositoryManager.GetBranchState(gitCtx, checkoutLocation, branch.Name, lastSeenHash)
+			if err != nil {
+				impl.logger.Errorw("error in getting branch state, continuing with last seen commit", "materialId", material.Id, "branch", branch.Name, "err", err)
+			} else if forcePushed {
+				forcePush = impl.getForcePush(gitCtx, checkoutLocation, branch.Name, lastSeenHash)
+				lastSeenHash = ""
+			}
+		}
+		commits, err := impl.repositoryManager.ChangesSinceByRepository(gitCtx, repo, branch.Name, lastSeenHash, "", impl.configuration.GitHistoryCount, checkoutLocation, false)
+		if err != nil {
+			impl.logger.Errorw("error in getting new commits of branch", "materialId", material.Id, "branch", branch.Name, "err", err)
+			if seen {
+				heads = append(heads, oldHead)
+			}
+			continue
+		}
+		if len(commits) == 0 {
+			continue
+		}
+		impl.evaluateCommitPolicy(commits, forcePushed)
+		impl.ticketExtractor.Annotate(commits...)
+		impl.persistCommits(material.Id, commits)
+		head := commits[0]
+		head.Branch = branch.Name
+		head.ForcePush = forcePush
+		heads = append(heads, head)
+		if isFirstPoll || (seen && head.Commit == oldHead.Commit) {
+			continue
+		}
+		mb := newMaterialBean(head)
+		mb.ForcePushed = forcePushed
+		if forcePush != nil {
+			mb.OrphanedCommits = forcePush.OrphanedCommits
+		}
+		mb.BranchCreated = !seen
+		updates = append(updates, mb)
+	}
+	for _, oldHead := range oldHeads {
+		if len(oldHead.Branch) > 0 && !matchedBranches[oldHead.Branch] {
+			impl.logger.Infow("branch matching regex deleted", "materialId", material.Id, "branch", oldHead.Branch)
+			mb := newMaterialBean(oldHead)
+			mb.BranchDeleted = true
+			updates = append(updates, mb)
+		}
+	}
+	if isFirstPoll && len(heads) > 0 {
+		updates = append(updates, newMaterialBean(heads[0]))
+	}
+	if len(updates) == 0 {
+		return nil, nil
+	}
+	if len(heads) > 0 {
+		material.LastSeenHash = heads[0].Commit
+		material.CommitAuthor = heads[0].Author
+		material.CommitDate = heads[0].Date
+		material.CommitMessage = heads[0].Message
+	}
+	commitJson, _ := json.Marshal(heads)
+	material.CommitHistory = string(commitJson)
+	material.Errored = false
+	material.ErrorMsg = ""
+	return updates, nil
+}
+
+// filterProtectedBranchUpdates drops the updates of the branches which are not protected at the git provider. Tags
+// are not branches and are kept. No update is kept when the protected branches can't be fetched, so that an outage of
+// the provider api doesn't trigger builds from unprotected branches
+func (impl GitWatcherImpl) filterProtectedBranchUpdates(gitCtx GitContext, gitMaterial *sql.GitMaterial, updates []*CiPipelineMaterialBean) []*CiPipelineMaterialBean {
+	protectedBranches, err := impl.providerApiClient.ListProtectedBranches(gitCtx, gitMaterial)
+	if err != nil {
+		impl.logger.Errorw("error in getting protected branches, skipping the triggers of the material", "gitMaterialId", gitMaterial.Id, "err", err)
+		return nil
+	}
+	var protectedUpdates []*CiPipelineMaterialBean
+	for _, update := range updates {
+		branch := update.Value
+		if update.Type == sql.SOURCE_TYPE_BRANCH_REGEX {
+			branch = update.Branch
+		}
+		if update.Type != sql.SOURCE_TYPE_TAG_ANY && !IsBranchProtected(protectedBranches, branch) {
+			impl.logger.Infow("skip this auto trigger, branch is not protected", "materialId", update.Id, "branch", branch)
+			continue
+		}
+		protectedUpdates = append(protectedUpdates, update)
+	}
+	return protectedUpdates
 }
 
 func (impl GitWatcherImpl) FetchAndUpdateMaterial(gitCtx GitContext, material *sql.GitMaterial, location string) (bool, *GitRepository, error) {
-	updated, repo, err := impl.repositoryManager.Fetch(gitCtx, material.Url, location)
+	updated, repo, fetchedFrom, err := impl.repositoryManager.FetchWithMirrors(gitCtx, material.Url, material.MirrorUrls, location)
 	if err == nil {
 		material.CheckoutLocation = location
 		material.CheckoutStatus = true
+		material.LastFetchRemote = fetchedFrom
 	}
 	return updated, repo, err
 }
@@ -321,11 +799,20 @@ func (impl GitWatcherImpl) NotifyForMaterialUpdate(materials []*CiPipelineMateri
 
 	impl.logger.Warnw("material notification", "materials", materials)
 	for _, material := range materials {
-		excluded := impl.gitManager.PathMatcher(material.GitCommit.FileStats, gitMaterial)
+		// tag pushes and branch deletions are not filtered by the changed paths
+		excluded := material.Type != sql.SOURCE_TYPE_TAG_ANY && !material.BranchDeleted && impl.gitManager.PathMatcher(material.GitCommit.FileStats, gitMaterial)
 		if excluded {
 			impl.logger.Infow("skip this auto trigger", "exclude", excluded)
 			continue
 		}
+		if material.Type != sql.SOURCE_TYPE_TAG_ANY && !material.BranchDeleted && !impl.commitFilter.Matches(material.GitCommit) {
+			impl.logger.Infow("skip this auto trigger, commit filtered", "materialId", material.Id, "commit", material.GitCommit.Commit)
+			continue
+		}
+		if material.Type != sql.SOURCE_TYPE_TAG_ANY && !material.BranchDeleted && material.GitCommit.PolicyVerdict.IsExcluded() {
+			impl.logger.Infow("skip this auto trigger, commit denied by policy", "materialId", material.Id, "commit", material.GitCommit.Commit, "violations", material.GitCommit.PolicyVerdict.Violations)
+			continue
+		}
 		mb, err := json.Marshal(material)
 		if err != nil {
 			impl.logger.Errorw("err in json marshaling", "err", err)
@@ -340,6 +827,25 @@ func (impl GitWatcherImpl) NotifyForMaterialUpdate(materials []*CiPipelineMateri
 	return nil
 }
 
+// getForcePush reports the force push of the branch with the commits it dropped, which are listed on a best effort basis
+func (impl GitWatcherImpl) getForcePush(gitCtx GitContext, checkoutLocation, branch, previousHead string) *ForcePush {
+	orphanedCommits, err := impl.repositoryManager.GetOrphanedCommits(gitCtx, checkoutLocation, branch, previousHead)
+	if err != nil {
+		impl.logger.Warnw("force push reported without the orphaned commits", "branch", branch, "previousHead", previousHead, "err", err)
+	}
+	return &ForcePush{PreviousHead: previousHead, OrphanedCommits: orphanedCommits}
+}
+
+// evaluateCommitPolicy sets the policy verdict of the new commits, all of them came with the force push if forcePushed
+func (impl GitWatcherImpl) evaluateCommitPolicy(commits []*GitCommitBase, forcePushed bool) {
+	for _, commit := range commits {
+		commit.PolicyVerdict = impl.commitPolicy.Evaluate(commit, forcePushed)
+		if commit.PolicyVerdict != nil && !commit.PolicyVerdict.Allowed {
+			impl.logger.Infow("commit denied by policy", "commit", commit.Commit, "violations", commit.PolicyVerdict.Violations)
+		}
+	}
+}
+
 func (impl GitWatcherImpl) SubscribeWebhookEvent() error {
 	callback := func(msg *model.PubSubMsg) {
 		impl.logger.Debugw("received msg", "msg", msg)
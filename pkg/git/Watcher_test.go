@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// recordingGitCommitRepository keeps the saved commits, SaveAll fails while err is set
+type recordingGitCommitRepository struct {
+	sql.GitCommitRepository
+	err   error
+	saved []*sql.GitCommit
+}
+
+func (impl *recordingGitCommitRepository) SaveAll(commits []*sql.GitCommit) error {
+	if impl.err != nil {
+		return impl.err
+	}
+	impl.saved = append(impl.saved, commits...)
+	return nil
+}
+
+func TestGitWatcherImpl_persistCommits(t *testing.T) {
+	logger, _ := utils.NewSugardLogger()
+	commitDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	commits := []*GitCommitBase{
+		{Commit: "c2", Author: "dev <dev@example.com>", Date: commitDate, Message: "second"},
+		{Commit: "c1", Author: "ops <ops@example.com>", Date: commitDate.Add(-time.Hour), Message: "first"},
+	}
+	tests := []struct {
+		name       string
+		persist    bool
+		err        error
+		wantHashes []string
+	}{
+		{name: "history persistence disabled", persist: false},
+		{name: "commits are saved", persist: true, wantHashes: []string{"c2", "c1"}},
+		{name: "save error is only logged", persist: true, err: errors.New("db down")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &recordingGitCommitRepository{err: tt.err}
+			impl := GitWatcherImpl{
+				logger:              logger,
+				configuration:       &internals.Configuration{PersistCommitHistory: tt.persist},
+				gitCommitRepository: repository,
+			}
+			impl.persistCommits(7, commits)
+
+			var hashes []string
+			for _, saved := range repository.saved {
+				hashes = append(hashes, saved.CommitHash)
+				assert.Equal(t, 7, saved.CiPipelineMaterialId)
+				assert.False(t, saved.CreatedOn.IsZero())
+			}
+			assert.Equal(t, tt.wantHashes, hashes)
+			if len(repository.saved) == 0 {
+				return
+			}
+			assert.Equal(t, "dev <dev@example.com>", repository.saved[0].Author)
+			assert.Equal(t, commitDate, repository.saved[0].CommitDate)
+			commit := &GitCommitBase{}
+			assert.NoError(t, json.Unmarshal([]byte(repository.saved[0].CommitData), commit))
+			assert.Equal(t, "second", commit.Message)
+		})
+	}
+}
+
+// failingCredentialResolver fails the resolve of the credentials of the materials, i.e. every poll
+type failingCredentialResolver struct {
+	err error
+}
+
+func (impl failingCredentialResolver) ResolveGitProvider(ctx context.Context, material *sql.GitMaterial, gitProvider *sql.GitProvider) (*sql.GitProvider, error) {
+	return nil, impl.err
+}
+
+// updatedMaterialRepository finds the material and records its updates
+type updatedMaterialRepository struct {
+	sql.MaterialRepository
+	material *sql.GitMaterial
+	updated  []*sql.GitMaterial
+}
+
+func (impl *updatedMaterialRepository) FindById(id int) (*sql.GitMaterial, error) {
+	material := *impl.material
+	return &material, nil
+}
+
+func (impl *updatedMaterialRepository) Update(material *sql.GitMaterial) error {
+	impl.updated = append(impl.updated, material)
+	return nil
+}
+
+func TestGitWatcherImpl_pollAndUpdateGitMaterial(t *testing.T) {
+	logger, _ := utils.NewSugardLogger()
+	pollErr := errors.New("signal: killed")
+	tests := []struct {
+		name                string
+		killed              bool
+		wantUpdated         bool
+		wantFetchStatus     bool
+		wantFetchErrorCount int
+	}{
+		{name: "failed poll is recorded", wantUpdated: true, wantFetchStatus: false, wantFetchErrorCount: 2},
+		{name: "poll interrupted by the shutdown keeps the fetch status", killed: true, wantFetchStatus: true, wantFetchErrorCount: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			childProcessCtx, killChildProcesses = context.WithCancel(context.Background())
+			t.Cleanup(func() {
+				childProcessCtx, killChildProcesses = context.WithCancel(context.Background())
+			})
+			if tt.killed {
+				KillChildProcesses()
+			}
+			materialRepository := &updatedMaterialRepository{material: &sql.GitMaterial{Id: 3, FetchStatus: true, LastFetchErrorCount: 1}}
+			impl := GitWatcherImpl{
+				logger:             logger,
+				materialRepo:       materialRepository,
+				locker:             internals.NewRepositoryLocker(logger),
+				credentialResolver: failingCredentialResolver{err: pollErr},
+				pollScheduler:      NewPollScheduler(&PollConfig{PollDuration: 2}),
+				configuration:      &internals.Configuration{},
+			}
+			material, err := impl.pollAndUpdateGitMaterial(&sql.GitMaterial{Id: 3})
+			assert.Equal(t, tt.wantFetchStatus, material.FetchStatus)
+			assert.Equal(t, tt.wantFetchErrorCount, material.LastFetchErrorCount)
+			if !tt.wantUpdated {
+				// the poll error is returned as is, not recorded as a fetch error of the material
+				assert.ErrorIs(t, err, pollErr)
+				assert.Empty(t, materialRepository.updated)
+				assert.True(t, material.LastFetchTime.IsZero())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, materialRepository.updated, 1)
+			assert.Equal(t, pollErr.Error(), material.FetchErrorMessage)
+		})
+	}
+}
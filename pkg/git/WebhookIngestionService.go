@@ -0,0 +1,370 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	pubsub "github.com/devtron-labs/common-lib/pubsub-lib"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const (
+	WEBHOOK_DELIVERIES_DEFAULT_LIMIT = 20
+	WEBHOOK_DELIVERIES_MAX_LIMIT     = 100
+	WEBHOOK_DELIVERY_PRUNE_SCHEDULE  = "@every 1h"
+)
+
+// WEBHOOK_EVENT_HEADERS are the headers of the providers that the payload is parsed with, only these are saved
+var WEBHOOK_EVENT_HEADERS = []string{"X-GitHub-Event", "X-Gitlab-Event", "X-Event-Key"}
+
+var (
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookIngestionResponse struct {
+	Accepted              bool  `json:"accepted"`
+	MaterialIds           []int `json:"materialIds"`
+	CiPipelineMaterialIds []int `json:"ciPipelineMaterialIds,omitempty"`
+}
+
+type WebhookDeliveryDto struct {
+	Id          int       `json:"id"`
+	Provider    string    `json:"provider"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	MaterialIds []int     `json:"materialIds"`
+	ReplayCount int       `json:"replayCount"`
+	Payload     string    `json:"payload,omitempty"`
+	CreatedOn   time.Time `json:"createdOn"`
+	UpdatedOn   time.Time `json:"updatedOn"`
+}
+
+// WebhookIngestionService handles webhooks sent by the git providers directly. A push triggers a poll of the
+// materials it is for, so that new commits are picked up without waiting for the next poll interval, and a pull request
+// matching the filters is sent to ci for the pull request materials of the repo. Every webhook with a valid signature
+// is saved with the result of its processing so that it can be listed and replayed, until WEBHOOK_RETENTION_IN_DAYS
+type WebhookIngestionService interface {
+	HandleWebhook(provider string, header http.Header, payload []byte) (*WebhookIngestionResponse, error)
+	// GetDeliveries returns the latest webhooks received for the material, for all materials when materialId is 0
+	GetDeliveries(materialId int, limit int) ([]*WebhookDeliveryDto, error)
+	// ReplayDelivery processes a received webhook again, its signature was validated when it was received
+	ReplayDelivery(deliveryId int) (*WebhookIngestionResponse, error)
+	// PruneDeliveries deletes the deliveries older than the retention
+	PruneDeliveries()
+	StopCron()
+}
+
+type WebhookIngestionServiceImpl struct {
+	logger             *zap.SugaredLogger
+	materialRepository sql.MaterialRepository
+	deliveryRepository sql.WebhookDeliveryRepository
+	gitWatcher         GitWatcher
+	pubSubClient       *pubsub.PubSubClientServiceImpl
+	configuration      *internals.Configuration
+	pullRequestFilter  *PullRequestFilter
+	eventPublisher     CommitEventPublisher
+	cron               *cron.Cron
+}
+
+func NewWebhookIngestionServiceImpl(logger *zap.SugaredLogger, materialRepository sql.MaterialRepository,
+	deliveryRepository sql.WebhookDeliveryRepository, gitWatcher GitWatcher, pubSubClient *pubsub.PubSubClientServiceImpl, configuration *internals.Configuration,
+	eventPublisher CommitEventPublisher) (*WebhookIngestionServiceImpl, error) {
+	pullRequestFilter, err := NewPullRequestFilter(configuration)
+	if err != nil {
+		logger.Errorw("error in parsing pull request webhook filter", "err", err)
+		return nil, err
+	}
+	cronLogger := &CronLoggerImpl{logger: logger}
+	impl := &WebhookIngestionServiceImpl{
+		logger:             logger,
+		materialRepository: materialRepository,
+		deliveryRepository: deliveryRepository,
+		gitWatcher:         gitWatcher,
+		pubSubClient:       pubSubClient,
+		configuration:      configuration,
+		pullRequestFilter:  pullRequestFilter,
+		eventPublisher:     eventPublisher,
+		cron: cron.New(
+			cron.WithChain(
+				cron.SkipIfStillRunning(cronLogger),
+				cron.Recover(cronLogger))),
+	}
+	if configuration.WebhookRetentionInDays > 0 {
+		_, err = impl.cron.AddFunc(WEBHOOK_DELIVERY_PRUNE_SCHEDULE, impl.PruneDeliveries)
+		if err != nil {
+			logger.Errorw("error in starting webhook delivery prune cron", "err", err)
+			return nil, err
+		}
+		impl.cron.Start()
+	}
+	return impl, nil
+}
+
+func (impl WebhookIngestionServiceImpl) StopCron() {
+	<-impl.cron.Stop().Done()
+}
+
+func (impl WebhookIngestionServiceImpl) PruneDeliveries() {
+	createdBefore := time.Now().AddDate(0, 0, -impl.configuration.WebhookRetentionInDays)
+	deleted, err := impl.deliveryRepository.DeleteCreatedBefore(createdBefore)
+	if err != nil {
+		impl.logger.Errorw("error in pruning webhook deliveries", "createdBefore", createdBefore, "err", err)
+		return
+	}
+	impl.logger.Infow("pruned webhook deliveries", "createdBefore", createdBefore, "deleted", deleted)
+}
+
+func (impl WebhookIngestionServiceImpl) HandleWebhook(provider string, header http.Header, payload []byte) (*WebhookIngestionResponse, error) {
+	err := ValidateWebhookSignature(provider, header, payload, impl.configuration.WebhookSecret)
+	if err != nil {
+		// the rejected webhooks are not saved, anyone can send them
+		impl.logger.Errorw("error in validating webhook signature", "provider", provider, "err", err)
+		return nil, err
+	}
+	delivery := &sql.WebhookDelivery{
+		Provider: provider,
+		Headers:  getWebhookEventHeaders(header),
+		Payload:  string(payload),
+		Status:   sql.WEBHOOK_DELIVERY_STATUS_RECEIVED,
+	}
+	impl.saveDelivery(delivery)
+	response, err := impl.processWebhook(provider, header, payload)
+	impl.updateDeliveryResult(delivery, response, err)
+	return response, err
+}
+
+func (impl WebhookIngestionServiceImpl) GetDeliveries(materialId int, limit int) ([]*WebhookDeliveryDto, error) {
+	if limit <= 0 {
+		limit = WEBHOOK_DELIVERIES_DEFAULT_LIMIT
+	} else if limit > WEBHOOK_DELIVERIES_MAX_LIMIT {
+		limit = WEBHOOK_DELIVERIES_MAX_LIMIT
+	}
+	deliveries, err := impl.deliveryRepository.FindRecent(materialId, limit)
+	if err != nil {
+		impl.logger.Errorw("error in fetching webhook deliveries", "materialId", materialId, "err", err)
+		return nil, err
+	}
+	dtos := make([]*WebhookDeliveryDto, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		dtos = append(dtos, &WebhookDeliveryDto{
+			Id:          delivery.Id,
+			Provider:    delivery.Provider,
+			Status:      delivery.Status,
+			Error:       delivery.Error,
+			MaterialIds: delivery.MaterialIds,
+			ReplayCount: delivery.ReplayCount,
+			Payload:     delivery.Payload,
+			CreatedOn:   delivery.CreatedOn,
+			UpdatedOn:   delivery.UpdatedOn,
+		})
+	}
+	return dtos, nil
+}
+
+func (impl WebhookIngestionServiceImpl) ReplayDelivery(deliveryId int) (*WebhookIngestionResponse, error) {
+	delivery, err := impl.deliveryRepository.FindById(deliveryId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching webhook delivery", "deliveryId", deliveryId, "err", err)
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	header := make(http.Header)
+	for key, value := range delivery.Headers {
+		header.Set(key, value)
+	}
+	impl.logger.Infow("replaying webhook delivery", "deliveryId", deliveryId, "provider", delivery.Provider, "status", delivery.Status)
+	response, err := impl.processWebhook(delivery.Provider, header, []byte(delivery.Payload))
+	delivery.ReplayCount++
+	impl.updateDeliveryResult(delivery, response, err)
+	return response, err
+}
+
+// saveDelivery doesn't fail the webhook when the delivery can't be saved, it is then only left out of the deliveries
+func (impl WebhookIngestionServiceImpl) saveDelivery(delivery *sql.WebhookDelivery) {
+	delivery.CreatedOn = time.Now()
+	delivery.UpdatedOn = delivery.CreatedOn
+	err := impl.deliveryRepository.Save(delivery)
+	if err != nil {
+		impl.logger.Errorw("error in saving webhook delivery", "provider", delivery.Provider, "err", err)
+	}
+}
+
+func (impl WebhookIngestionServiceImpl) updateDeliveryResult(delivery *sql.WebhookDelivery, response *WebhookIngestionResponse, processErr error) {
+	if delivery.Id == 0 {
+		return
+	}
+	delivery.Error = ""
+	switch {
+	case processErr != nil:
+		delivery.Status = sql.WEBHOOK_DELIVERY_STATUS_FAILED
+		delivery.Error = processErr.Error()
+	case response.Accepted:
+		delivery.Status = sql.WEBHOOK_DELIVERY_STATUS_PROCESSED
+		delivery.MaterialIds = response.MaterialIds
+	default:
+		delivery.Status = sql.WEBHOOK_DELIVERY_STATUS_IGNORED
+	}
+	delivery.UpdatedOn = time.Now()
+	err := impl.deliveryRepository.Update(delivery)
+	if err != nil {
+		impl.logger.Errorw("error in updating webhook delivery", "deliveryId", delivery.Id, "err", err)
+	}
+}
+
+func getWebhookEventHeaders(header http.Header) map[string]string {
+	eventHeaders := make(map[string]string)
+	for _, key := range WEBHOOK_EVENT_HEADERS {
+		if value := header.Get(key); len(value) > 0 {
+			eventHeaders[key] = value
+		}
+	}
+	return eventHeaders
+}
+
+// processWebhook parses the payload of a webhook with a valid signature and handles the push or pull request in it
+func (impl WebhookIngestionServiceImpl) processWebhook(provider string, header http.Header, payload []byte) (*WebhookIngestionResponse, error) {
+	pushEvents, err := ParsePushEvents(provider, header, payload)
+	if err != nil {
+		impl.logger.Errorw("error in parsing push webhook", "provider", provider, "err", err)
+		return nil, err
+	}
+	if len(pushEvents) > 0 {
+		return impl.handlePushEvents(pushEvents)
+	}
+	pullRequestEvent, err := ParsePullRequestEvent(provider, header, payload)
+	if err != nil {
+		impl.logger.Errorw("error in parsing pull request webhook", "provider", provider, "err", err)
+		return nil, err
+	}
+	if pullRequestEvent != nil {
+		return impl.handlePullRequestEvent(pullRequestEvent)
+	}
+	impl.logger.Debugw("ignoring webhook, not a push or pull request event", "provider", provider)
+	return &WebhookIngestionResponse{}, nil
+}
+
+// handlePushEvents polls the materials tracking any of the refs updated by the push, the events of a push are all
+// for the same repo
+func (impl WebhookIngestionServiceImpl) handlePushEvents(events []*PushEvent) (*WebhookIngestionResponse, error) {
+	var pushedEvents []*PushEvent
+	for _, event := range events {
+		if event.Deleted {
+			impl.logger.Debugw("ignoring push webhook for deleted ref", "branch", event.Branch, "tag", event.Tag)
+			continue
+		}
+		pushedEvents = append(pushedEvents, event)
+	}
+	if len(pushedEvents) == 0 {
+		return &WebhookIngestionResponse{}, nil
+	}
+	repoUrls := pushedEvents[0].RepoUrls
+	materials, err := impl.materialRepository.FindAllActiveByUrls(repoUrls)
+	if err != nil {
+		impl.logger.Errorw("error in fetching materials for push webhook", "repoUrls", repoUrls, "err", err)
+		return nil, err
+	}
+	var trackedMaterials []*sql.GitMaterial
+	response := &WebhookIngestionResponse{Accepted: true}
+	for _, material := range materials {
+		for _, event := range pushedEvents {
+			if event.IsTrackedBy(material.CiPipelineMaterials) {
+				trackedMaterials = append(trackedMaterials, material)
+				response.MaterialIds = append(response.MaterialIds, material.Id)
+				break
+			}
+		}
+	}
+	for _, event := range pushedEvents {
+		impl.logger.Infow("polling materials for push webhook", "provider", event.Provider, "branch", event.Branch, "tag", event.Tag,
+			"commit", event.Commit, "materialIds", response.MaterialIds)
+	}
+	impl.gitWatcher.RunOnWorker(trackedMaterials)
+	return response, nil
+}
+
+func (impl WebhookIngestionServiceImpl) handlePullRequestEvent(event *PullRequestEvent) (*WebhookIngestionResponse, error) {
+	if !impl.pullRequestFilter.Matches(event) {
+		impl.logger.Debugw("pull request webhook filtered out", "number", event.Number, "sourceBranch", event.SourceBranch,
+			"targetBranch", event.TargetBranch, "labels", event.Labels, "draft", event.Draft)
+		return &WebhookIngestionResponse{}, nil
+	}
+	materials, err := impl.materialRepository.FindAllActiveByUrls(event.RepoUrls)
+	if err != nil {
+		impl.logger.Errorw("error in fetching materials for pull request webhook", "repoUrls", event.RepoUrls, "err", err)
+		return nil, err
+	}
+	response := &WebhookIngestionResponse{Accepted: true}
+	for _, material := range materials {
+		notified := false
+		for _, ciPipelineMaterial := range material.CiPipelineMaterials {
+			if !ciPipelineMaterial.Active || ciPipelineMaterial.Type != sql.SOURCE_TYPE_PULL_REQUEST {
+				continue
+			}
+			err = impl.notifyPullRequest(material, ciPipelineMaterial, event)
+			if err != nil {
+				return nil, err
+			}
+			notified = true
+			response.CiPipelineMaterialIds = append(response.CiPipelineMaterialIds, ciPipelineMaterial.Id)
+		}
+		if notified {
+			response.MaterialIds = append(response.MaterialIds, material.Id)
+		}
+	}
+	impl.logger.Infow("notified pull request webhook", "provider", event.Provider, "number", event.Number, "action", event.Action,
+		"ciPipelineMaterialIds", response.CiPipelineMaterialIds)
+	return response, nil
+}
+
+func (impl WebhookIngestionServiceImpl) notifyPullRequest(gitMaterial *sql.GitMaterial, ciPipelineMaterial *sql.CiPipelineMaterial, event *PullRequestEvent) error {
+	pullRequest := event.PullRequestMaterial
+	material := &CiPipelineMaterialBean{
+		Id:            ciPipelineMaterial.Id,
+		GitMaterialId: ciPipelineMaterial.GitMaterialId,
+		Type:          ciPipelineMaterial.Type,
+		Value:         ciPipelineMaterial.Value,
+		Active:        ciPipelineMaterial.Active,
+		GitCommit: &GitCommitBase{
+			Commit:  pullRequest.SourceCommit,
+			Author:  pullRequest.Author,
+			Message: pullRequest.Title,
+			Branch:  pullRequest.SourceBranch,
+		},
+		PullRequest: &pullRequest,
+	}
+	if err := impl.eventPublisher.Enqueue(gitMaterial, []*CiPipelineMaterialBean{material}); err != nil {
+		impl.logger.Errorw("error in enqueueing pull request event", "ciPipelineMaterialId", ciPipelineMaterial.Id, "err", err)
+	}
+	mb, err := json.Marshal(material)
+	if err != nil {
+		impl.logger.Errorw("err in json marshaling", "err", err)
+		return err
+	}
+	err = impl.pubSubClient.Publish(pubsub.NEW_CI_MATERIAL_TOPIC, string(mb))
+	if err != nil {
+		impl.logger.Errorw("error in publishing pull request material", "ciPipelineMaterialId", ciPipelineMaterial.Id, "err", err)
+	}
+	return err
+}
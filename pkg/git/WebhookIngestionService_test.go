@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"errors"
+	"github.com/devtron-labs/common-lib/utils"
+	"github.com/devtron-labs/git-sensor/internals"
+	"github.com/devtron-labs/git-sensor/internals/sql"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// memoryDeliveryRepository keeps the webhook deliveries in memory, in the order of the ids
+type memoryDeliveryRepository struct {
+	deliveries []*sql.WebhookDelivery
+}
+
+func (impl *memoryDeliveryRepository) Save(delivery *sql.WebhookDelivery) error {
+	delivery.Id = len(impl.deliveries) + 1
+	impl.deliveries = append(impl.deliveries, delivery)
+	return nil
+}
+
+func (impl *memoryDeliveryRepository) Update(delivery *sql.WebhookDelivery) error {
+	return nil
+}
+
+func (impl *memoryDeliveryRepository) FindById(id int) (*sql.WebhookDelivery, error) {
+	for _, delivery := range impl.deliveries {
+		if delivery.Id == id {
+			return delivery, nil
+		}
+	}
+	return nil, nil
+}
+
+func (impl *memoryDeliveryRepository) FindRecent(materialId int, limit int) ([]*sql.WebhookDelivery, error) {
+	return impl.deliveries, nil
+}
+
+func (impl *memoryDeliveryRepository) DeleteCreatedBefore(createdBefore time.Time) (int, error) {
+	var kept []*sql.WebhookDelivery
+	for _, delivery := range impl.deliveries {
+		if !delivery.CreatedOn.Before(createdBefore) {
+			kept = append(kept, delivery)
+		}
+	}
+	deleted := len(impl.deliveries) - len(kept)
+	impl.deliveries = kept
+	return deleted, nil
+}
+
+// urlMaterialRepository finds the materials by their urls, the other methods are not used by the webhooks
+type urlMaterialRepository struct {
+	sql.MaterialRepository
+	materials []*sql.GitMaterial
+}
+
+func (impl *urlMaterialRepository) FindAllActiveByUrls(urls []string) ([]*sql.GitMaterial, error) {
+	var materials []*sql.GitMaterial
+	for _, material := range impl.materials {
+		for _, url := range urls {
+			if material.Url == url {
+				materials = append(materials, material)
+				break
+			}
+		}
+	}
+	return materials, nil
+}
+
+// recordingGitWatcher records the materials it is asked to poll
+type recordingGitWatcher struct {
+	polled []int
+}
+
+func (impl *recordingGitWatcher) PollAndUpdateGitMaterial(material *sql.GitMaterial) (*sql.GitMaterial, error) {
+	return material, nil
+}
+
+func (impl *recordingGitWatcher) RunOnWorker(materials []*sql.GitMaterial) {
+	for _, material := range materials {
+		impl.polled = append(impl.polled, material.Id)
+	}
+}
+
+func getTestWebhookIngestionService(t *testing.T) (*WebhookIngestionServiceImpl, *memoryDeliveryRepository, *recordingGitWatcher) {
+	logger, err := utils.NewSugardLogger()
+	assert.Nil(t, err)
+	deliveryRepository := &memoryDeliveryRepository{}
+	gitWatcher := &recordingGitWatcher{}
+	materialRepository := &urlMaterialRepository{materials: []*sql.GitMaterial{{Id: 1, Url: "https://gitlab.com/org/repo.git",
+		CiPipelineMaterials: []*sql.CiPipelineMaterial{{Id: 10, Type: sql.SOURCE_TYPE_BRANCH_FIXED, Value: "main", Active: true}}}}}
+	impl := &WebhookIngestionServiceImpl{
+		logger:             logger,
+		materialRepository: materialRepository,
+		deliveryRepository: deliveryRepository,
+		gitWatcher:         gitWatcher,
+		configuration:      &internals.Configuration{WebhookSecret: "secret", WebhookRetentionInDays: 7},
+	}
+	return impl, deliveryRepository, gitWatcher
+}
+
+func TestWebhookIngestionServiceImpl_HandleWebhook(t *testing.T) {
+	pushPayload := `{"ref":"refs/heads/main","after":"abc","project":{"git_http_url":"https://gitlab.com/org/repo.git"}}`
+	tests := []struct {
+		name           string
+		token          string
+		event          string
+		payload        string
+		expectedErr    error
+		expectedStatus string
+		expectedPolled []int
+	}{
+		{name: "push of a tracked branch", token: "secret", event: "Push Hook", payload: pushPayload,
+			expectedStatus: sql.WEBHOOK_DELIVERY_STATUS_PROCESSED, expectedPolled: []int{1}},
+		{name: "push of an untracked branch", token: "secret", event: "Push Hook",
+			payload:        `{"ref":"refs/heads/feature","after":"abc","project":{"git_http_url":"https://gitlab.com/org/repo.git"}}`,
+			expectedStatus: sql.WEBHOOK_DELIVERY_STATUS_PROCESSED},
+		{name: "not a push or pull request", token: "secret", event: "Issue Hook", payload: `{}`,
+			expectedStatus: sql.WEBHOOK_DELIVERY_STATUS_IGNORED},
+		{name: "invalid payload", token: "secret", event: "Push Hook", payload: `not json`,
+			expectedStatus: sql.WEBHOOK_DELIVERY_STATUS_FAILED},
+		{name: "invalid token is rejected and not saved", token: "other", event: "Push Hook", payload: pushPayload,
+			expectedErr: ErrInvalidWebhookSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl, deliveryRepository, gitWatcher := getTestWebhookIngestionService(t)
+			header := http.Header{}
+			header.Set("X-Gitlab-Token", tt.token)
+			header.Set("X-Gitlab-Event", tt.event)
+			_, err := impl.HandleWebhook(WEBHOOK_PROVIDER_GITLAB, header, []byte(tt.payload))
+			assert.Equal(t, tt.expectedPolled, gitWatcher.polled)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				assert.Empty(t, deliveryRepository.deliveries)
+				return
+			}
+			assert.Equal(t, 1, len(deliveryRepository.deliveries))
+			delivery := deliveryRepository.deliveries[0]
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Equal(t, tt.payload, delivery.Payload)
+			assert.Equal(t, map[string]string{"X-Gitlab-Event": tt.event}, delivery.Headers)
+			assert.Equal(t, tt.expectedStatus == sql.WEBHOOK_DELIVERY_STATUS_FAILED, len(delivery.Error) > 0)
+		})
+	}
+}
+
+func TestWebhookIngestionServiceImpl_ReplayDelivery(t *testing.T) {
+	impl, deliveryRepository, gitWatcher := getTestWebhookIngestionService(t)
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", "secret")
+	header.Set("X-Gitlab-Event", "Push Hook")
+	_, err := impl.HandleWebhook(WEBHOOK_PROVIDER_GITLAB, header, []byte(`{"ref":"refs/heads/main","after":"abc","project":{"git_http_url":"https://gitlab.com/org/repo.git"}}`))
+	assert.Nil(t, err)
+
+	// the saved headers are enough to parse the payload again, the token is not saved
+	response, err := impl.ReplayDelivery(deliveryRepository.deliveries[0].Id)
+	assert.Nil(t, err)
+	assert.True(t, response.Accepted)
+	assert.Equal(t, []int{1}, response.MaterialIds)
+	assert.Equal(t, []int{1, 1}, gitWatcher.polled)
+	assert.Equal(t, 1, deliveryRepository.deliveries[0].ReplayCount)
+	assert.Equal(t, sql.WEBHOOK_DELIVERY_STATUS_PROCESSED, deliveryRepository.deliveries[0].Status)
+
+	_, err = impl.ReplayDelivery(2)
+	assert.Equal(t, ErrWebhookDeliveryNotFound, err)
+}
+
+func TestWebhookIngestionServiceImpl_PruneDeliveries(t *testing.T) {
+	impl, deliveryRepository, _ := getTestWebhookIngestionService(t)
+	now := time.Now()
+	deliveryRepository.deliveries = []*sql.WebhookDelivery{{Id: 1, CreatedOn: now.AddDate(0, 0, -8)}, {Id: 2, CreatedOn: now.AddDate(0, 0, -6)}, {Id: 3, CreatedOn: now}}
+	impl.PruneDeliveries()
+	assert.Equal(t, []*sql.WebhookDelivery{{Id: 2, CreatedOn: now.AddDate(0, 0, -6)}, {Id: 3, CreatedOn: now}}, deliveryRepository.deliveries)
+}
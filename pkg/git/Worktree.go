@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WORKTREE_STORE_DIR holds the repos shared by the materials of the same url, their checkouts are worktrees of the store
+const WORKTREE_STORE_DIR = GIT_BASE_DIR + "worktree-stores/"
+
+// WorktreeStores serializes the fetches of the worktrees of a store, they update the same refs. It also keeps the refs
+// each worktree has seen, a fetch through one worktree brings in the commits of all of them
+type WorktreeStores struct {
+	mutex        sync.Mutex
+	locks        map[string]*sync.Mutex
+	refsChecksum map[string]string
+}
+
+func NewWorktreeStores() *WorktreeStores {
+	return &WorktreeStores{
+		locks:        make(map[string]*sync.Mutex),
+		refsChecksum: make(map[string]string),
+	}
+}
+
+// Lock locks the store till the returned func is called, the lock is not reentrant
+func (stores *WorktreeStores) Lock(store string) (unlock func()) {
+	stores.mutex.Lock()
+	lock, ok := stores.locks[store]
+	if !ok {
+		lock = &sync.Mutex{}
+		stores.locks[store] = lock
+	}
+	stores.mutex.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// LockStoreOf locks the store of the worktree at location till the returned func is called, nothing is locked when
+// location is not a worktree. Writes through a worktree, like fetches and gc, go to the objects and refs of its store
+func (stores *WorktreeStores) LockStoreOf(location string) (unlock func()) {
+	store := GetWorktreeStore(location)
+	if len(store) == 0 {
+		return func() {}
+	}
+	return stores.Lock(store)
+}
+
+// SwapRefsChecksum records the checksum of the refs seen by the worktree and reports if they changed since it was last seen
+func (stores *WorktreeStores) SwapRefsChecksum(location string, checksum string) (changed bool) {
+	stores.mutex.Lock()
+	defer stores.mutex.Unlock()
+	changed = stores.refsChecksum[location] != checksum
+	stores.refsChecksum[location] = checksum
+	return changed
+}
+
+// getWorktreeStoreLocation returns the store of the url, stores are not shared across git providers or across the
+// credential scopes of materials with their own credentials, as the credentials and ssh keys are configured in the store
+func getWorktreeStoreLocation(gitProviderId int, credentialScope string, url string) string {
+	return path.Join(WORKTREE_STORE_DIR, credentialScope, strconv.Itoa(gitProviderId), strings.TrimPrefix(url, "https://"))
+}
+
+// GetWorktreeStore returns the repo the worktree at location was added to, empty when location is not a linked
+// worktree. The .git of a worktree is a file pointing at its dir under the worktrees dir of the store
+func GetWorktreeStore(location string) string {
+	content, err := os.ReadFile(path.Join(location, ".git"))
+	if err != nil {
+		return ""
+	}
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !found {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(location, gitDir)
+	}
+	commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return ""
+	}
+	store := strings.TrimSpace(string(commonDir))
+	if !filepath.IsAbs(store) {
+		store = filepath.Join(gitDir, store)
+	}
+	return strings.TrimSuffix(filepath.Clean(store), "/.git")
+}
+
+// AddWorktree adds a worktree of the repo at worktreeDir, detached at the commit. Polling needs no working tree, so
+// nothing is checked out
+func (impl *GitManagerBaseImpl) AddWorktree(gitCtx GitContext, rootDir, worktreeDir, commit string) error {
+	cmdArgs := []string{"-C", rootDir, "worktree", "add", "--detach", "--no-checkout", worktreeDir, commit}
+	impl.logger.Debugw("git", cmdArgs)
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", cmdArgs...)
+	if err != nil {
+		impl.logger.Errorw("error in adding worktree", "rootDir", rootDir, "worktreeDir", worktreeDir, "opt", output, "errMsg", errMsg, "err", err)
+		return err
+	}
+	return nil
+}
+
+// PruneWorktrees removes the worktrees of the repo whose dirs are gone
+func (impl *GitManagerBaseImpl) PruneWorktrees(gitCtx GitContext, rootDir string) error {
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "worktree", "prune")
+	if err != nil {
+		impl.logger.Errorw("error in pruning worktrees", "rootDir", rootDir, "opt", output, "errMsg", errMsg, "err", err)
+		return err
+	}
+	return nil
+}
+
+// GetRefsChecksum returns a checksum of the remote branches and tags of the repo with their commits
+func (impl *GitManagerBaseImpl) GetRefsChecksum(gitCtx GitContext, rootDir string) (string, error) {
+	output, errMsg, err := impl.ExecuteCustomCommand(gitCtx, "git", "-C", rootDir, "for-each-ref", "--format=%(objectname) %(refname)", "refs/remotes", "refs/tags")
+	if err != nil {
+		impl.logger.Errorw("error in listing refs", "rootDir", rootDir, "errMsg", errMsg, "err", err)
+		return "", err
+	}
+	checksum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(checksum[:]), nil
+}
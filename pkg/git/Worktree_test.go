@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package git
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepositoryManager_Worktrees(t *testing.T) {
+	remoteDir, workDir := setupTestRemote(t)
+	repositoryManager := getTestRepositoryManager(t)
+	gitCtx := BuildGitContext(context.Background())
+	store := filepath.Join(t.TempDir(), "store")
+	assert.Nil(t, repositoryManager.gitManager.Init(gitCtx, store, remoteDir, true))
+	_, _, err := repositoryManager.gitManager.Fetch(gitCtx, store)
+	assert.Nil(t, err)
+	head := runTestGitCmd(t, workDir, "rev-parse", "HEAD")
+
+	worktrees := []string{filepath.Join(t.TempDir(), "1", "checkout"), filepath.Join(t.TempDir(), "2", "checkout")}
+	for _, worktree := range worktrees {
+		assert.Nil(t, repositoryManager.gitManager.AddWorktree(gitCtx, store, worktree, head))
+		assert.Equal(t, store, GetWorktreeStore(worktree))
+		assert.Equal(t, filepath.Join(store, ".git"), GetGitDir(worktree))
+		// the first poll of a worktree reports its refs as changed
+		assert.True(t, repositoryManager.haveWorktreeRefsChanged(gitCtx, worktree))
+	}
+	assert.Empty(t, GetWorktreeStore(store))
+	assert.False(t, repositoryManager.haveWorktreeRefsChanged(gitCtx, store))
+
+	// a fetch through one worktree brings in the commits for the other
+	runTestGitCmd(t, workDir, "commit", "--allow-empty", "-m", "third")
+	runTestGitCmd(t, workDir, "push", "origin", "main")
+	_, _, err = repositoryManager.gitManager.Fetch(gitCtx, worktrees[0])
+	assert.Nil(t, err)
+	assert.True(t, repositoryManager.haveWorktreeRefsChanged(gitCtx, worktrees[0]))
+	assert.False(t, repositoryManager.haveWorktreeRefsChanged(gitCtx, worktrees[0]))
+	output, _, err := repositoryManager.gitManager.Fetch(gitCtx, worktrees[1])
+	assert.Nil(t, err)
+	assert.Empty(t, output)
+	assert.True(t, repositoryManager.haveWorktreeRefsChanged(gitCtx, worktrees[1]))
+	assert.Equal(t, runTestGitCmd(t, workDir, "rev-parse", "HEAD"), runTestGitCmd(t, worktrees[1], "rev-parse", "refs/remotes/origin/main"))
+
+	assert.Nil(t, repositoryManager.removeCheckout(gitCtx, worktrees[0]))
+	assert.NoDirExists(t, worktrees[0])
+	assert.Equal(t, 2, len(strings.Split(runTestGitCmd(t, store, "worktree", "list"), "\n")))
+	_, err = os.Stat(worktrees[1])
+	assert.Nil(t, err)
+}
+
+func TestGetWorktreeStoreLocation(t *testing.T) {
+	url := "https://github.com/devtron-labs/git-sensor.git"
+	assert.Equal(t, WORKTREE_STORE_DIR+"1/github.com/devtron-labs/git-sensor.git", getWorktreeStoreLocation(1, "", url))
+	// materials with their own credentials get stores of their own
+	assert.Equal(t, WORKTREE_STORE_DIR+"material-7/1/github.com/devtron-labs/git-sensor.git", getWorktreeStoreLocation(1, "material-7", url))
+	assert.NotEqual(t, getWorktreeStoreLocation(1, "material-7", url), getWorktreeStoreLocation(1, "material-8", url))
+}
@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: gitSensorApi/api.proto
+
+package gitSensorApi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GitSensorApiService_FetchChanges_FullMethodName      = "/gitSensorApi.GitSensorApiService/FetchChanges"
+	GitSensorApiService_GetCommit_FullMethodName         = "/gitSensorApi.GitSensorApiService/GetCommit"
+	GitSensorApiService_GetDiff_FullMethodName           = "/gitSensorApi.GitSensorApiService/GetDiff"
+	GitSensorApiService_GetMaterialStatus_FullMethodName = "/gitSensorApi.GitSensorApiService/GetMaterialStatus"
+)
+
+// GitSensorApiServiceClient is the client API for GitSensorApiService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GitSensorApiServiceClient interface {
+	FetchChanges(ctx context.Context, in *FetchChangesRequest, opts ...grpc.CallOption) (*MaterialChanges, error)
+	GetCommit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*Commit, error)
+	GetDiff(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*CommitRangeDiff, error)
+	GetMaterialStatus(ctx context.Context, in *MaterialStatusRequest, opts ...grpc.CallOption) (*MaterialStatus, error)
+}
+
+type gitSensorApiServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGitSensorApiServiceClient(cc grpc.ClientConnInterface) GitSensorApiServiceClient {
+	return &gitSensorApiServiceClient{cc}
+}
+
+func (c *gitSensorApiServiceClient) FetchChanges(ctx context.Context, in *FetchChangesRequest, opts ...grpc.CallOption) (*MaterialChanges, error) {
+	out := new(MaterialChanges)
+	err := c.cc.Invoke(ctx, GitSensorApiService_FetchChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gitSensorApiServiceClient) GetCommit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*Commit, error) {
+	out := new(Commit)
+	err := c.cc.Invoke(ctx, GitSensorApiService_GetCommit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gitSensorApiServiceClient) GetDiff(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*CommitRangeDiff, error) {
+	out := new(CommitRangeDiff)
+	err := c.cc.Invoke(ctx, GitSensorApiService_GetDiff_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gitSensorApiServiceClient) GetMaterialStatus(ctx context.Context, in *MaterialStatusRequest, opts ...grpc.CallOption) (*MaterialStatus, error) {
+	out := new(MaterialStatus)
+	err := c.cc.Invoke(ctx, GitSensorApiService_GetMaterialStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GitSensorApiServiceServer is the server API for GitSensorApiService service.
+// All implementations must embed UnimplementedGitSensorApiServiceServer
+// for forward compatibility
+type GitSensorApiServiceServer interface {
+	FetchChanges(context.Context, *FetchChangesRequest) (*MaterialChanges, error)
+	GetCommit(context.Context, *CommitRequest) (*Commit, error)
+	GetDiff(context.Context, *DiffRequest) (*CommitRangeDiff, error)
+	GetMaterialStatus(context.Context, *MaterialStatusRequest) (*MaterialStatus, error)
+	mustEmbedUnimplementedGitSensorApiServiceServer()
+}
+
+// UnimplementedGitSensorApiServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedGitSensorApiServiceServer struct {
+}
+
+func (UnimplementedGitSensorApiServiceServer) FetchChanges(context.Context, *FetchChangesRequest) (*MaterialChanges, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchChanges not implemented")
+}
+func (UnimplementedGitSensorApiServiceServer) GetCommit(context.Context, *CommitRequest) (*Commit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommit not implemented")
+}
+func (UnimplementedGitSensorApiServiceServer) GetDiff(context.Context, *DiffRequest) (*CommitRangeDiff, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDiff not implemented")
+}
+func (UnimplementedGitSensorApiServiceServer) GetMaterialStatus(context.Context, *MaterialStatusRequest) (*MaterialStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMaterialStatus not implemented")
+}
+func (UnimplementedGitSensorApiServiceServer) mustEmbedUnimplementedGitSensorApiServiceServer() {}
+
+// UnsafeGitSensorApiServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GitSensorApiServiceServer will
+// result in compilation errors.
+type UnsafeGitSensorApiServiceServer interface {
+	mustEmbedUnimplementedGitSensorApiServiceServer()
+}
+
+func RegisterGitSensorApiServiceServer(s grpc.ServiceRegistrar, srv GitSensorApiServiceServer) {
+	s.RegisterService(&GitSensorApiService_ServiceDesc, srv)
+}
+
+func _GitSensorApiService_FetchChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GitSensorApiServiceServer).FetchChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GitSensorApiService_FetchChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GitSensorApiServiceServer).FetchChanges(ctx, req.(*FetchChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GitSensorApiService_GetCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GitSensorApiServiceServer).GetCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GitSensorApiService_GetCommit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GitSensorApiServiceServer).GetCommit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GitSensorApiService_GetDiff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GitSensorApiServiceServer).GetDiff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GitSensorApiService_GetDiff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GitSensorApiServiceServer).GetDiff(ctx, req.(*DiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GitSensorApiService_GetMaterialStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterialStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GitSensorApiServiceServer).GetMaterialStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GitSensorApiService_GetMaterialStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GitSensorApiServiceServer).GetMaterialStatus(ctx, req.(*MaterialStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GitSensorApiService_ServiceDesc is the grpc.ServiceDesc for GitSensorApiService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GitSensorApiService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitSensorApi.GitSensorApiService",
+	HandlerType: (*GitSensorApiServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchChanges",
+			Handler:    _GitSensorApiService_FetchChanges_Handler,
+		},
+		{
+			MethodName: "GetCommit",
+			Handler:    _GitSensorApiService_GetCommit_Handler,
+		},
+		{
+			MethodName: "GetDiff",
+			Handler:    _GitSensorApiService_GetDiff_Handler,
+		},
+		{
+			MethodName: "GetMaterialStatus",
+			Handler:    _GitSensorApiService_GetMaterialStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gitSensorApi/api.proto",
+}
@@ -0,0 +1,83 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v3.21.12
+// source: gitSensorWatch/watch.proto
+
+// the service is in the package of the GitSensorService, whose messages it reuses, so that clients create the stream
+// with the same service and method name
+
+package gitSensorWatch
+
+import (
+	gitSensor "github.com/devtron-labs/protos/gitSensor"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_gitSensorWatch_watch_proto protoreflect.FileDescriptor
+
+var file_gitSensorWatch_watch_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x67, 0x69, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x2f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x67, 0x69,
+	0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x1a, 0x17, 0x67, 0x69, 0x74, 0x53, 0x65, 0x6e,
+	0x73, 0x6f, 0x72, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x32, 0x79, 0x0a, 0x15, 0x47, 0x69, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x60, 0x0a, 0x14, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x4d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x12, 0x22, 0x2e, 0x67, 0x69, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x46, 0x65, 0x74, 0x63, 0x68, 0x53, 0x63, 0x6d, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x67, 0x69, 0x74, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x4d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x3a, 0x5a, 0x38,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x65, 0x76, 0x74, 0x72,
+	0x6f, 0x6e, 0x2d, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x67, 0x69, 0x74, 0x2d, 0x73, 0x65, 0x6e, 0x73,
+	0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x69, 0x74, 0x53, 0x65, 0x6e,
+	0x73, 0x6f, 0x72, 0x57, 0x61, 0x74, 0x63, 0x68, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var file_gitSensorWatch_watch_proto_goTypes = []interface{}{
+	(*gitSensor.FetchScmChangesRequest)(nil), // 0: gitService.FetchScmChangesRequest
+	(*gitSensor.MaterialChangeResponse)(nil), // 1: gitService.MaterialChangeResponse
+}
+var file_gitSensorWatch_watch_proto_depIdxs = []int32{
+	0, // 0: gitService.GitSensorWatchService.WatchMaterialChanges:input_type -> gitService.FetchScmChangesRequest
+	1, // 1: gitService.GitSensorWatchService.WatchMaterialChanges:output_type -> gitService.MaterialChangeResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_gitSensorWatch_watch_proto_init() }
+func file_gitSensorWatch_watch_proto_init() {
+	if File_gitSensorWatch_watch_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gitSensorWatch_watch_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gitSensorWatch_watch_proto_goTypes,
+		DependencyIndexes: file_gitSensorWatch_watch_proto_depIdxs,
+	}.Build()
+	File_gitSensorWatch_watch_proto = out.File
+	file_gitSensorWatch_watch_proto_rawDesc = nil
+	file_gitSensorWatch_watch_proto_goTypes = nil
+	file_gitSensorWatch_watch_proto_depIdxs = nil
+}
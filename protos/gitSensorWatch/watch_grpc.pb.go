@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: gitSensorWatch/watch.proto
+
+// the service is in the package of the GitSensorService, whose messages it reuses, so that clients create the stream
+// with the same service and method name
+
+package gitSensorWatch
+
+import (
+	context "context"
+	gitSensor "github.com/devtron-labs/protos/gitSensor"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GitSensorWatchService_WatchMaterialChanges_FullMethodName = "/gitService.GitSensorWatchService/WatchMaterialChanges"
+)
+
+// GitSensorWatchServiceClient is the client API for GitSensorWatchService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GitSensorWatchServiceClient interface {
+	WatchMaterialChanges(ctx context.Context, in *gitSensor.FetchScmChangesRequest, opts ...grpc.CallOption) (GitSensorWatchService_WatchMaterialChangesClient, error)
+}
+
+type gitSensorWatchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGitSensorWatchServiceClient(cc grpc.ClientConnInterface) GitSensorWatchServiceClient {
+	return &gitSensorWatchServiceClient{cc}
+}
+
+func (c *gitSensorWatchServiceClient) WatchMaterialChanges(ctx context.Context, in *gitSensor.FetchScmChangesRequest, opts ...grpc.CallOption) (GitSensorWatchService_WatchMaterialChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GitSensorWatchService_ServiceDesc.Streams[0], GitSensorWatchService_WatchMaterialChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gitSensorWatchServiceWatchMaterialChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GitSensorWatchService_WatchMaterialChangesClient interface {
+	Recv() (*gitSensor.MaterialChangeResponse, error)
+	grpc.ClientStream
+}
+
+type gitSensorWatchServiceWatchMaterialChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *gitSensorWatchServiceWatchMaterialChangesClient) Recv() (*gitSensor.MaterialChangeResponse, error) {
+	m := new(gitSensor.MaterialChangeResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GitSensorWatchServiceServer is the server API for GitSensorWatchService service.
+// All implementations must embed UnimplementedGitSensorWatchServiceServer
+// for forward compatibility
+type GitSensorWatchServiceServer interface {
+	WatchMaterialChanges(*gitSensor.FetchScmChangesRequest, GitSensorWatchService_WatchMaterialChangesServer) error
+	mustEmbedUnimplementedGitSensorWatchServiceServer()
+}
+
+// UnimplementedGitSensorWatchServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedGitSensorWatchServiceServer struct {
+}
+
+func (UnimplementedGitSensorWatchServiceServer) WatchMaterialChanges(*gitSensor.FetchScmChangesRequest, GitSensorWatchService_WatchMaterialChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMaterialChanges not implemented")
+}
+func (UnimplementedGitSensorWatchServiceServer) mustEmbedUnimplementedGitSensorWatchServiceServer() {}
+
+// UnsafeGitSensorWatchServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GitSensorWatchServiceServer will
+// result in compilation errors.
+type UnsafeGitSensorWatchServiceServer interface {
+	mustEmbedUnimplementedGitSensorWatchServiceServer()
+}
+
+func RegisterGitSensorWatchServiceServer(s grpc.ServiceRegistrar, srv GitSensorWatchServiceServer) {
+	s.RegisterService(&GitSensorWatchService_ServiceDesc, srv)
+}
+
+func _GitSensorWatchService_WatchMaterialChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(gitSensor.FetchScmChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GitSensorWatchServiceServer).WatchMaterialChanges(m, &gitSensorWatchServiceWatchMaterialChangesServer{stream})
+}
+
+type GitSensorWatchService_WatchMaterialChangesServer interface {
+	Send(*gitSensor.MaterialChangeResponse) error
+	grpc.ServerStream
+}
+
+type gitSensorWatchServiceWatchMaterialChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *gitSensorWatchServiceWatchMaterialChangesServer) Send(m *gitSensor.MaterialChangeResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// GitSensorWatchService_ServiceDesc is the grpc.ServiceDesc for GitSensorWatchService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GitSensorWatchService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gitService.GitSensorWatchService",
+	HandlerType: (*GitSensorWatchServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMaterialChanges",
+			Handler:       _GitSensorWatchService_WatchMaterialChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gitSensorWatch/watch.proto",
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+CREATE SEQUENCE IF NOT EXISTS git_commit_id_seq;
+
+CREATE TABLE IF NOT EXISTS "public"."git_commit"
+(
+    "id"                      integer     NOT NULL DEFAULT nextval('git_commit_id_seq'::regclass),
+    "ci_pipeline_material_id" integer     NOT NULL,
+    "commit_hash"             varchar(64) NOT NULL,
+    "author"                  varchar(250),
+    "commit_date"             timestamptz,
+    "commit_data"             text        NOT NULL,
+    "created_on"              timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    UNIQUE ("ci_pipeline_material_id", "commit_hash")
+);
+
+CREATE INDEX IF NOT EXISTS "git_commit_commit_hash_idx" ON "public"."git_commit" ("commit_hash");
+CREATE INDEX IF NOT EXISTS "git_commit_author_idx" ON "public"."git_commit" ("ci_pipeline_material_id", "author");
+CREATE INDEX IF NOT EXISTS "git_commit_commit_date_idx" ON "public"."git_commit" ("ci_pipeline_material_id", "commit_date" DESC);
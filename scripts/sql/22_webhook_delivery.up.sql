@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+CREATE SEQUENCE IF NOT EXISTS webhook_delivery_id_seq;
+
+CREATE TABLE IF NOT EXISTS "public"."webhook_delivery"
+(
+    "id"           integer     NOT NULL DEFAULT nextval('webhook_delivery_id_seq'::regclass),
+    "provider"     varchar(50) NOT NULL,
+    "headers"      json,
+    "payload"      text,
+    "status"       varchar(50) NOT NULL,
+    "error"        text,
+    "material_ids" jsonb       DEFAULT '[]',
+    "replay_count" integer     NOT NULL DEFAULT 0,
+    "created_on"   timestamptz NOT NULL,
+    "updated_on"   timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "webhook_delivery_created_on_idx" ON "public"."webhook_delivery" ("created_on" DESC);
+CREATE INDEX IF NOT EXISTS "webhook_delivery_material_ids_idx" ON "public"."webhook_delivery" USING GIN ("material_ids");
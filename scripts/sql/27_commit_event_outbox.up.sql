@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+ALTER TABLE "public"."git_material" ADD COLUMN IF NOT EXISTS "event_topic" varchar(250);
+
+CREATE SEQUENCE IF NOT EXISTS commit_event_outbox_id_seq;
+
+CREATE TABLE IF NOT EXISTS "public"."commit_event_outbox"
+(
+    "id"                      integer      NOT NULL DEFAULT nextval('commit_event_outbox_id_seq'::regclass),
+    "event_id"                varchar(100) NOT NULL,
+    "event_type"              varchar(50)  NOT NULL,
+    "topic"                   varchar(250) NOT NULL,
+    "git_material_id"         integer      NOT NULL,
+    "ci_pipeline_material_id" integer,
+    "payload"                 text         NOT NULL,
+    "attempts"                integer      NOT NULL DEFAULT 0,
+    "error"                   text,
+    "dead_lettered"           bool         NOT NULL DEFAULT false,
+    "created_on"              timestamptz  NOT NULL,
+    "updated_on"              timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "commit_event_outbox_event_id_idx" ON "public"."commit_event_outbox" ("event_id");
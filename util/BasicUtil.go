@@ -19,6 +19,7 @@ package util
 import (
 	"github.com/devtron-labs/git-sensor/internals/middleware"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -37,11 +38,22 @@ func Generate(size int) string {
 }
 
 func TriggerGitOperationMetrics(method string, startTime time.Time, err error) {
-	status := "Success"
+	middleware.GitOperationDuration.WithLabelValues(method, getMetricStatus(err)).Observe(time.Since(startTime).Seconds())
+}
+
+func TriggerGitCommandMetrics(command string, startTime time.Time, err error) {
+	middleware.GitCommandDuration.WithLabelValues(command, getMetricStatus(err)).Observe(time.Since(startTime).Seconds())
+}
+
+func TriggerGitFetchMetrics(gitMaterialId int, err error) {
+	middleware.GitFetchCounter.WithLabelValues(strconv.Itoa(gitMaterialId), getMetricStatus(err)).Inc()
+}
+
+func getMetricStatus(err error) string {
 	if err != nil {
-		status = "Failed"
+		return "Failed"
 	}
-	middleware.GitOperationDuration.WithLabelValues(method, status).Observe(time.Since(startTime).Seconds())
+	return "Success"
 }
 
 func GetPathRegex(path string) string {
@@ -53,15 +53,39 @@ func InitializeApp() (*app.App, error) {
 	webhookEventServiceImpl := git.NewWebhookEventServiceImpl(sugaredLogger, webhookEventRepositoryImpl, webhookEventParsedDataRepositoryImpl, webhookEventDataMappingRepositoryImpl, webhookEventDataMappingFilterResultRepositoryImpl, materialRepositoryImpl, pubSubClientServiceImpl, webhookEventBeanConverterImpl)
 	webhookEventParserImpl := git.NewWebhookEventParserImpl(sugaredLogger)
 	webhookHandlerImpl := git.NewWebhookHandlerImpl(sugaredLogger, webhookEventServiceImpl, webhookEventParserImpl)
-	gitWatcherImpl, err := git.NewGitWatcherImpl(repositoryManagerImpl, materialRepositoryImpl, sugaredLogger, ciPipelineMaterialRepositoryImpl, repositoryLocker, pubSubClientServiceImpl, webhookHandlerImpl, configuration, gitManagerImpl)
+	materialChangeBroadcasterImpl := git.NewMaterialChangeBroadcasterImpl(sugaredLogger)
+	gitCommitRepositoryImpl := sql.NewGitCommitRepositoryImpl(db)
+	providerApiClientImpl := git.NewProviderApiClientImpl(sugaredLogger, configuration)
+	ticketExtractor, err := git.NewTicketExtractor(configuration)
 	if err != nil {
 		return nil, err
 	}
-	repoManagerImpl := pkg.NewRepoManagerImpl(sugaredLogger, materialRepositoryImpl, repositoryManagerImpl, repositoryManagerAnalyticsImpl, gitProviderRepositoryImpl, ciPipelineMaterialRepositoryImpl, repositoryLocker, gitWatcherImpl, webhookEventRepositoryImpl, webhookEventParsedDataRepositoryImpl, webhookEventDataMappingRepositoryImpl, webhookEventDataMappingFilterResultRepositoryImpl, webhookEventBeanConverterImpl, configuration, gitManagerImpl)
-	restHandlerImpl := api.NewRestHandlerImpl(repoManagerImpl, sugaredLogger)
+	credentialResolverImpl := git.NewCredentialResolverImpl(sugaredLogger, configuration)
+	commitEventOutboxRepositoryImpl := sql.NewCommitEventOutboxRepositoryImpl(db)
+	commitEventPublisherImpl, err := git.NewCommitEventPublisherImpl(sugaredLogger, commitEventOutboxRepositoryImpl, pubSubClientServiceImpl, configuration)
+	if err != nil {
+		return nil, err
+	}
+	gitWatcherImpl, err := git.NewGitWatcherImpl(repositoryManagerImpl, materialRepositoryImpl, sugaredLogger, ciPipelineMaterialRepositoryImpl, repositoryLocker, pubSubClientServiceImpl, webhookHandlerImpl, configuration, gitManagerImpl, materialChangeBroadcasterImpl, gitCommitRepositoryImpl, providerApiClientImpl, ticketExtractor, credentialResolverImpl, commitEventPublisherImpl)
+	if err != nil {
+		return nil, err
+	}
+	jobManagerImpl := git.NewJobManagerImpl(sugaredLogger, configuration)
+	repoManagerImpl := pkg.NewRepoManagerImpl(sugaredLogger, materialRepositoryImpl, repositoryManagerImpl, repositoryManagerAnalyticsImpl, gitProviderRepositoryImpl, ciPipelineMaterialRepositoryImpl, repositoryLocker, gitWatcherImpl, webhookEventRepositoryImpl, webhookEventParsedDataRepositoryImpl, webhookEventDataMappingRepositoryImpl, webhookEventDataMappingFilterResultRepositoryImpl, webhookEventBeanConverterImpl, configuration, gitManagerImpl, gitCommitRepositoryImpl, providerApiClientImpl, ticketExtractor, jobManagerImpl, credentialResolverImpl)
+	webhookDeliveryRepositoryImpl := sql.NewWebhookDeliveryRepositoryImpl(db)
+	webhookIngestionServiceImpl, err := git.NewWebhookIngestionServiceImpl(sugaredLogger, materialRepositoryImpl, webhookDeliveryRepositoryImpl, gitWatcherImpl, pubSubClientServiceImpl, configuration, commitEventPublisherImpl)
+	if err != nil {
+		return nil, err
+	}
+	restHandlerImpl := api.NewRestHandlerImpl(repoManagerImpl, sugaredLogger, webhookIngestionServiceImpl, jobManagerImpl)
 	monitoringRouter := monitoring.NewMonitoringRouter(sugaredLogger)
 	muxRouter := api.NewMuxRouter(sugaredLogger, restHandlerImpl, monitoringRouter)
-	grpcHandlerImpl := api.NewGrpcHandlerImpl(repoManagerImpl, sugaredLogger)
-	appApp := app.NewApp(muxRouter, sugaredLogger, gitWatcherImpl, db, pubSubClientServiceImpl, grpcHandlerImpl)
+	grpcHandlerImpl := api.NewGrpcHandlerImpl(repoManagerImpl, sugaredLogger, materialChangeBroadcasterImpl)
+	grpcApiHandlerImpl := api.NewGrpcApiHandlerImpl(repoManagerImpl, sugaredLogger)
+	storageManagerImpl, err := git.NewStorageManagerImpl(sugaredLogger, materialRepositoryImpl, gitManagerImpl, repositoryLocker, configuration)
+	if err != nil {
+		return nil, err
+	}
+	appApp := app.NewApp(muxRouter, sugaredLogger, gitWatcherImpl, db, pubSubClientServiceImpl, grpcHandlerImpl, grpcApiHandlerImpl, storageManagerImpl, commitEventPublisherImpl, webhookIngestionServiceImpl, configuration)
 	return appApp, nil
 }
@@ -36,6 +36,7 @@ var CommonWireSet = wire.NewSet(app.NewApp,
 	api.NewRestHandlerImpl,
 	wire.Bind(new(api.RestHandler), new(*api.RestHandlerImpl)),
 	api.NewGrpcHandlerImpl,
+	api.NewGrpcApiHandlerImpl,
 	sql.NewMaterialRepositoryImpl,
 	wire.Bind(new(sql.MaterialRepository), new(*sql.MaterialRepositoryImpl)),
 	sql.NewDbConnection,
@@ -52,9 +53,30 @@ var CommonWireSet = wire.NewSet(app.NewApp,
 	wire.Bind(new(pkg.RepoManager), new(*pkg.RepoManagerImpl)),
 	git.NewGitWatcherImpl,
 	wire.Bind(new(git.GitWatcher), new(*git.GitWatcherImpl)),
+	git.NewMaterialChangeBroadcasterImpl,
+	wire.Bind(new(git.MaterialChangeBroadcaster), new(*git.MaterialChangeBroadcasterImpl)),
+	git.NewWebhookIngestionServiceImpl,
+	wire.Bind(new(git.WebhookIngestionService), new(*git.WebhookIngestionServiceImpl)),
+	git.NewStorageManagerImpl,
+	wire.Bind(new(git.StorageManager), new(*git.StorageManagerImpl)),
+	git.NewProviderApiClientImpl,
+	git.NewTicketExtractor,
+	git.NewJobManagerImpl,
+	wire.Bind(new(git.JobManager), new(*git.JobManagerImpl)),
+	wire.Bind(new(git.ProviderApiClient), new(*git.ProviderApiClientImpl)),
+	git.NewCredentialResolverImpl,
+	wire.Bind(new(git.CredentialResolver), new(*git.CredentialResolverImpl)),
 	internals.NewRepositoryLocker,
 	//internal.NewNatsConnection,
 	pubsub.NewPubSubClientServiceImpl,
+	sql.NewGitCommitRepositoryImpl,
+	wire.Bind(new(sql.GitCommitRepository), new(*sql.GitCommitRepositoryImpl)),
+	sql.NewWebhookDeliveryRepositoryImpl,
+	wire.Bind(new(sql.WebhookDeliveryRepository), new(*sql.WebhookDeliveryRepositoryImpl)),
+	sql.NewCommitEventOutboxRepositoryImpl,
+	wire.Bind(new(sql.CommitEventOutboxRepository), new(*sql.CommitEventOutboxRepositoryImpl)),
+	git.NewCommitEventPublisherImpl,
+	wire.Bind(new(git.CommitEventPublisher), new(*git.CommitEventPublisherImpl)),
 	sql.NewWebhookEventRepositoryImpl,
 	wire.Bind(new(sql.WebhookEventRepository), new(*sql.WebhookEventRepositoryImpl)),
 	sql.NewWebhookEventParsedDataRepositoryImpl,